@@ -0,0 +1,191 @@
+package archive
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"yt-vod-manager/internal/model"
+)
+
+const (
+	errorClassLocal       = "local"
+	errorClassInterrupted = "interrupted"
+	errorClassUnknown     = "unknown"
+
+	reasonDownloadError = "download_error"
+)
+
+// failureMatch is the classification of one failed attempt.
+type failureMatch struct {
+	Reason      string
+	Class       string
+	Retryable   bool
+	Remediation string
+}
+
+type compiledFailureRule struct {
+	rule   model.FailureRule
+	needle string
+	re     *regexp.Regexp
+}
+
+// failureClassifier checks user rules first, then the built-in rules.
+type failureClassifier struct {
+	rules []compiledFailureRule
+}
+
+func builtinRules(reason, class string, retryable bool, remediation string, patterns ...string) []model.FailureRule {
+	out := make([]model.FailureRule, 0, len(patterns))
+	for _, p := range patterns {
+		out = append(out, model.FailureRule{Pattern: p, Reason: reason, Class: class, Retryable: retryable, Remediation: remediation})
+	}
+	return out
+}
+
+// builtinFailureRules covers the common yt-dlp/YouTube messages. Order
+// matters: more specific messages come before the generic ones they contain
+// (a copyright takedown also says "Video unavailable").
+var builtinFailureRules = concatRules(
+	builtinRules("missing_dependency", "dependency", false,
+		"install ffmpeg/ffprobe (and yt-dlp) on PATH; run `yt-vod-manager doctor`",
+		"missing dependency:", "ffmpeg could not be found", "ffprobe could not be found", "ffmpeg not found", "ffprobe not found", "ffprobe and ffmpeg not found", "ffmpeg is not installed"),
+	builtinRules("bot_check", "rate_limited", true,
+		"YouTube wants a signed-in session: use --cookies or --browser-cookies, and lower --workers",
+		"confirm you're not a bot", "confirm that you're not a bot"),
+	builtinRules("rate_limited", "rate_limited", true,
+		"wait and resume later; lower --workers or spread workers over proxies",
+		"http error 429", "too many requests", "rate limit", "rate-limit"),
+	builtinRules("members_only", "access", false,
+		"use --cookies/--browser-cookies from an account with a membership for this channel",
+		"members-only", "members only", "join this channel to get access"),
+	builtinRules("age_restricted", "access", false,
+		"use --cookies/--browser-cookies from a signed-in account that passed age verification",
+		"confirm your age", "age-restricted", "age restricted", "inappropriate for some users"),
+	builtinRules("geo_blocked", "access", false,
+		"route downloads through a proxy in a region where the video is available (settings proxy add)",
+		"available in your country", "not available in your location", "geo restricted", "geo-restricted", "blocked it in your country"),
+	builtinRules("copyright_takedown", "removed", false,
+		"removed after a copyright claim; nothing to retry",
+		"copyright claim", "due to a copyright", "copyright grounds"),
+	builtinRules("private_video", "access", false,
+		"private: only cookies from an account that was granted access can download it",
+		"private video", "this video is private"),
+	builtinRules("video_removed", "removed", false,
+		"the video is gone from YouTube; nothing to retry",
+		"video unavailable", "has been removed", "account associated with this video has been terminated", "no longer available", "http error 404"),
+	builtinRules("upcoming_live", "upcoming", true,
+		"the premiere or live stream has not finished yet; it will be retried on a later sync",
+		"premieres in", "live event will begin", "this live event", "is not yet available"),
+	builtinRules("format_unavailable", "format", false,
+		"relax --quality/--video-codec/--container or update yt-dlp, then rerun with --retry-permanent",
+		"requested format is not available", "no video formats found"),
+	builtinRules("forbidden", "forbidden", false,
+		"usually an expired stream URL or a blocked IP: update yt-dlp, use cookies or a proxy, then rerun with --retry-permanent",
+		"http error 403", "403: forbidden", "403 forbidden"),
+	builtinRules("server_error", "server_error", true,
+		"YouTube-side error; resume later",
+		"http error 5", "service unavailable", "internal server error", "bad gateway"),
+	builtinRules("timeout", "network", true,
+		"check the network or proxy and resume later",
+		"timed out", "timeout"),
+	builtinRules("network_error", "network", true,
+		"check the network or proxy and resume later",
+		"connection reset", "connection refused", "connection aborted", "network is unreachable", "temporarily unavailable", "name resolution", "no route to host", "remote end closed connection"),
+)
+
+func concatRules(groups ...[]model.FailureRule) []model.FailureRule {
+	var out []model.FailureRule
+	for _, g := range groups {
+		out = append(out, g...)
+	}
+	return out
+}
+
+// EffectiveFailureRules returns custom rules followed by the built-in ones, in
+// the order they are checked.
+func EffectiveFailureRules(custom []model.FailureRule) []model.FailureRule {
+	return concatRules(custom, builtinFailureRules)
+}
+
+func newFailureClassifier(custom []model.FailureRule) (*failureClassifier, error) {
+	c := &failureClassifier{}
+	for i, r := range EffectiveFailureRules(custom) {
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("failure_rules[%d]: %w", i, err)
+		}
+		cr := compiledFailureRule{rule: r}
+		if r.Regex {
+			cr.re = regexp.MustCompile("(?i)" + r.Pattern)
+		} else {
+			cr.needle = normalizeFailureText(r.Pattern)
+		}
+		c.rules = append(c.rules, cr)
+	}
+	return c, nil
+}
+
+// classify matches the yt-dlp "ERROR:" lines first so titles or progress
+// output in the rest of the message cannot trigger a rule, then falls back to
+// the whole message.
+func (c *failureClassifier) classify(msg string) failureMatch {
+	var errLines []string
+	for _, line := range strings.Split(msg, "\n") {
+		if strings.Contains(line, "ERROR:") {
+			errLines = append(errLines, line)
+		}
+	}
+	if len(errLines) > 0 {
+		if m, ok := c.match(strings.Join(errLines, "\n")); ok {
+			return m
+		}
+	}
+	if m, ok := c.match(msg); ok {
+		return m
+	}
+	return failureMatch{
+		Reason:      reasonDownloadError,
+		Class:       errorClassUnknown,
+		Remediation: "unrecognized error; check the job log with `yt-vod-manager job show`",
+	}
+}
+
+func (c *failureClassifier) match(text string) (failureMatch, bool) {
+	norm := normalizeFailureText(text)
+	for _, cr := range c.rules {
+		hit := false
+		if cr.re != nil {
+			hit = cr.re.MatchString(text)
+		} else {
+			hit = strings.Contains(norm, cr.needle)
+		}
+		if hit {
+			return failureMatch{
+				Reason:      cr.rule.Reason,
+				Class:       firstNonEmptyString(cr.rule.Class, cr.rule.Reason),
+				Retryable:   cr.rule.Retryable,
+				Remediation: cr.rule.Remediation,
+			}, true
+		}
+	}
+	return failureMatch{}, false
+}
+
+// remediationFor looks up the hint for a stored job reason.
+func (c *failureClassifier) remediationFor(reason string) string {
+	if reason == reasonDownloadError {
+		return c.classify("").Remediation
+	}
+	for _, cr := range c.rules {
+		if cr.rule.Reason == reason && cr.rule.Remediation != "" {
+			return cr.rule.Remediation
+		}
+	}
+	return ""
+}
+
+// normalizeFailureText lowercases and folds typographic apostrophes, which
+// YouTube uses in messages like "Sign in to confirm you’re not a bot".
+func normalizeFailureText(s string) string {
+	return strings.ReplaceAll(strings.ToLower(s), "’", "'")
+}
@@ -0,0 +1,83 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"yt-vod-manager/internal/model"
+)
+
+// Each fixture is captured yt-dlp output named after the reason it must map to.
+func TestFailureClassifierFixtures(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "ytdlp_stderr", "*.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no classifier fixtures found")
+	}
+	c, err := newFailureClassifier(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		raw, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.TrimSuffix(filepath.Base(f), ".txt")
+		got := c.classify(string(raw))
+		if got.Reason != want {
+			t.Errorf("%s: got reason %q, want %q", filepath.Base(f), got.Reason, want)
+		}
+		if got.Remediation == "" {
+			t.Errorf("%s: expected a remediation hint", filepath.Base(f))
+		}
+	}
+}
+
+func TestFailureClassifierRetryability(t *testing.T) {
+	c, err := newFailureClassifier(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := c.classify("ERROR: unable to download video data: HTTP Error 429: Too Many Requests"); !m.Retryable || m.Class != "rate_limited" {
+		t.Fatalf("expected retryable rate_limited, got %+v", m)
+	}
+	if m := c.classify("ERROR: [youtube] x: Join this channel to get access to members-only content"); m.Retryable || m.Class != "access" {
+		t.Fatalf("expected permanent access failure, got %+v", m)
+	}
+}
+
+func TestFailureClassifierCustomRulesWin(t *testing.T) {
+	c, err := newFailureClassifier([]model.FailureRule{
+		{Pattern: `HTTP Error 403`, Reason: "forbidden_retry", Class: "forbidden", Retryable: true, Remediation: "retry later"},
+		{Pattern: `^ERROR: \[youtube\] \w+: Video unavailable$`, Regex: true, Reason: "gone"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := c.classify("ERROR: unable to download video data: HTTP Error 403: Forbidden"); m.Reason != "forbidden_retry" || !m.Retryable {
+		t.Fatalf("expected custom 403 rule to override the built-in, got %+v", m)
+	}
+	if m := c.classify("ERROR: [youtube] abc: Video unavailable"); m.Reason != "gone" || m.Class != "gone" {
+		t.Fatalf("expected regex rule to match with class defaulting to reason, got %+v", m)
+	}
+	if m := c.classify("ERROR: [youtube] abc: Video unavailable. This video has been removed by the uploader"); m.Reason != "video_removed" {
+		t.Fatalf("expected built-in rule after non-matching custom rules, got %+v", m)
+	}
+	if got := c.remediationFor("forbidden_retry"); got != "retry later" {
+		t.Fatalf("expected remediation lookup by reason, got %q", got)
+	}
+}
+
+func TestFailureClassifierRejectsInvalidRules(t *testing.T) {
+	if _, err := newFailureClassifier([]model.FailureRule{{Pattern: "(", Regex: true, Reason: "x"}}); err == nil {
+		t.Fatal("expected invalid regex to be rejected")
+	}
+	if _, err := newFailureClassifier([]model.FailureRule{{Pattern: "boom"}}); err == nil {
+		t.Fatal("expected rule without reason to be rejected")
+	}
+}
@@ -83,7 +83,22 @@ exit 0
 	if out.Jobs[0].Status != model.StatusFailedRetryable {
 		t.Fatalf("expected retryable status, got %s", out.Jobs[0].Status)
 	}
-	if out.Jobs[0].Reason != "transient_or_rate_limited" {
+	if out.Jobs[0].Reason != "rate_limited" {
 		t.Fatalf("unexpected retryable reason: %q", out.Jobs[0].Reason)
 	}
+	if len(out.Jobs[0].History) != 1 {
+		t.Fatalf("expected one attempt in history, got %+v", out.Jobs[0].History)
+	}
+	ev := out.Jobs[0].History[0]
+	if ev.Kind != model.JobEventAttempt || ev.Outcome != model.StatusFailedRetryable || ev.ErrorClass != "rate_limited" || ev.Worker != 1 {
+		t.Fatalf("unexpected attempt entry: %+v", ev)
+	}
+
+	shown, err := ShowJob(JobShowOptions{RunDir: runDir, Video: "retry123"})
+	if err != nil {
+		t.Fatalf("show job: %v", err)
+	}
+	if !shown.LogExists || shown.LogPath != filepath.Join(runDir, "logs", "0001_retry123.log") {
+		t.Fatalf("expected log path for the attempt, got %q (exists=%v)", shown.LogPath, shown.LogExists)
+	}
 }
@@ -0,0 +1,453 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+	"yt-vod-manager/internal/ytdlp"
+)
+
+const (
+	// ImportModeRecord leaves files where they are and records their paths.
+	ImportModeRecord   = "record"
+	ImportModeHardlink = "hardlink"
+	ImportModeMove     = "move"
+
+	ImportOutcomeMatched = "matched"
+	ImportOutcomeCreated = "created"
+	ImportOutcomeSkipped = "skipped"
+	ImportOutcomeFailed  = "failed"
+
+	reasonImported = "imported"
+)
+
+type ImportOptions struct {
+	RunID     string
+	RunDir    string
+	RunsDir   string
+	Latest    bool
+	OutputDir string
+	// Dir is searched recursively for media named with yt-dlp's [id] token.
+	Dir string
+	// ArchiveFile is a yt-dlp --download-archive file ("youtube <id>" lines).
+	ArchiveFile string
+	Mode        string
+	DryRun      bool
+	LockTTL     time.Duration
+}
+
+type ImportItem struct {
+	VideoID     string `json:"video_id"`
+	Title       string `json:"title,omitempty"`
+	From        string `json:"from,omitempty"`
+	Path        string `json:"path,omitempty"`
+	ArchiveOnly bool   `json:"archive_only,omitempty"`
+	Outcome     string `json:"outcome"`
+	Error       string `json:"error,omitempty"`
+}
+
+type ImportResult struct {
+	RunID      string `json:"run_id"`
+	RunDir     string `json:"run_dir"`
+	Mode       string `json:"mode"`
+	DryRun     bool   `json:"dry_run"`
+	FilesFound int    `json:"files_found"`
+	ArchiveIDs int    `json:"archive_ids"`
+	Matched    int    `json:"matched"`
+	Created    int    `json:"created"`
+	// ArchiveOnly counts imported IDs with no file; they are completed as
+	// held elsewhere (model.ReasonImportedArchive).
+	ArchiveOnly int          `json:"archive_only"`
+	Skipped     int          `json:"skipped"`
+	Failed      int          `json:"failed"`
+	Items       []ImportItem `json:"items"`
+}
+
+// Import marks videos downloaded outside this tool as completed: media found
+// under opts.Dir and IDs listed in a yt-dlp download archive. IDs the run does
+// not know yet get new jobs; the next refresh either matches them to the
+// listing or keeps them as tombstones. Files are recorded in place, or
+// hardlinked or moved (with their sidecars) into the output layout.
+func Import(opts ImportOptions) (ImportResult, error) {
+	mode, err := normalizeImportMode(opts.Mode)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	if strings.TrimSpace(opts.Dir) == "" && strings.TrimSpace(opts.ArchiveFile) == "" {
+		return ImportResult{}, fmt.Errorf("import needs a directory or a download archive file")
+	}
+	runDir, err := resolveRunDir(RunOptions{RunID: opts.RunID, RunDir: opts.RunDir, RunsDir: opts.RunsDir, Latest: opts.Latest})
+	if err != nil {
+		return ImportResult{}, err
+	}
+	runLock, err := runstore.AcquireRunLock(runDir, opts.LockTTL)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer func() {
+		_ = runLock.Release()
+	}()
+
+	jobsPath := filepath.Join(runDir, "manifest.jobs.json")
+	var mf model.JobsManifest
+	if err := runstore.ReadJobsManifest(jobsPath, &mf); err != nil {
+		return ImportResult{}, err
+	}
+	runMeta, _ := runstore.LoadRunMeta(runDir)
+	outputDir := firstNonEmptyString(opts.OutputDir, runMeta.OutputDir, filepath.Join(runDir, "downloads"))
+
+	files := map[string]string{}
+	if dir := strings.TrimSpace(opts.Dir); dir != "" {
+		st, err := os.Stat(dir)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		if !st.IsDir() {
+			return ImportResult{}, fmt.Errorf("%s is not a directory", dir)
+		}
+		if files, err = mediaPathsByVideoID(dir); err != nil {
+			return ImportResult{}, err
+		}
+	}
+	archived := []string{}
+	if path := strings.TrimSpace(opts.ArchiveFile); path != "" {
+		if archived, err = readDownloadArchive(path); err != nil {
+			return ImportResult{}, err
+		}
+	}
+	inOutput, err := indexMediaByVideoID(outputDir)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	res := ImportResult{
+		RunID:      firstNonEmptyString(mf.RunID, filepath.Base(runDir)),
+		RunDir:     runDir,
+		Mode:       mode,
+		DryRun:     opts.DryRun,
+		FilesFound: len(files),
+		ArchiveIDs: len(archived),
+		Items:      []ImportItem{},
+	}
+	ids := make([]string, 0, len(files)+len(archived))
+	for id := range files {
+		ids = append(ids, id)
+	}
+	for _, id := range archived {
+		if _, ok := files[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	byID := make(map[string]int, len(mf.Jobs))
+	for i, j := range mf.Jobs {
+		byID[strings.TrimSpace(j.VideoID)] = i
+	}
+	archiveFile := filepath.Join(runDir, "download-archive.txt")
+	now := time.Now().UTC().Format(time.RFC3339)
+	changed := false
+	for _, id := range ids {
+		src := files[id]
+		item := ImportItem{VideoID: id, From: src, ArchiveOnly: src == ""}
+		idx, exists := byID[id]
+		if exists {
+			j := &mf.Jobs[idx]
+			item.Title = j.Title
+			if j.Status == model.StatusCompleted && (src == "" || inOutput[id] || hasRecordedMedia(j)) {
+				item.Outcome = ImportOutcomeSkipped
+				item.Error = "already completed"
+				item.Path = j.MediaPath
+				res.Skipped++
+				res.Items = append(res.Items, item)
+				continue
+			}
+		}
+
+		var moves []RenameFile
+		if src != "" {
+			info := readImportInfo(src, id)
+			if item.Title == "" || isPlaceholderTitle(item.Title) {
+				item.Title = firstNonEmptyString(info.Title, titleFromFileName(src, id))
+			}
+			item.Path = src
+			if mode != ImportModeRecord {
+				if moves, err = importTargets(src, id, outputDir, info, item.Title); err != nil {
+					item.Outcome = ImportOutcomeFailed
+					item.Error = err.Error()
+					res.Failed++
+					res.Items = append(res.Items, item)
+					continue
+				}
+				item.Path = moves[len(moves)-1].To
+			}
+		}
+		item.Outcome = ImportOutcomeMatched
+		if !exists {
+			item.Outcome = ImportOutcomeCreated
+		}
+		if opts.DryRun {
+			countImport(&res, item)
+			continue
+		}
+
+		if err := placeFiles(moves, mode); err != nil {
+			item.Outcome = ImportOutcomeFailed
+			item.Error = err.Error()
+			res.Failed++
+			res.Items = append(res.Items, item)
+			continue
+		}
+		if !exists {
+			idx = len(mf.Jobs)
+			mf.Jobs = append(mf.Jobs, model.Job{
+				JobID:    fmt.Sprintf("imported:%d:%s", idx+1, id),
+				Index:    idx + 1,
+				VideoID:  id,
+				VideoURL: "https://www.youtube.com/watch?v=" + id,
+				Title:    item.Title,
+			})
+			byID[id] = idx
+		}
+		if err := completeImported(&mf.Jobs[idx], item, now); err != nil {
+			return res, err
+		}
+		appendDownloadArchive(archiveFile, id)
+		countImport(&res, item)
+		changed = true
+	}
+
+	if !changed {
+		return res, nil
+	}
+	recomputeCounts(&mf)
+	if err := runstore.WriteJSON(jobsPath, mf); err != nil {
+		return res, fmt.Errorf("persist jobs manifest: %w", err)
+	}
+	if err := saveRunMetaSnapshot(runDir, mf, outputDir); err != nil {
+		return res, err
+	}
+	runsDir := firstNonEmptyString(strings.TrimSpace(opts.RunsDir), filepath.Dir(runDir))
+	if err := updateMediaIndex(runsDir, mf.RunID, outputDir, mf.Jobs); err != nil {
+		fmt.Printf("warn  media index update failed (non-fatal): %v\n", err)
+	}
+	return res, nil
+}
+
+func normalizeImportMode(raw string) (string, error) {
+	switch v := strings.ToLower(strings.TrimSpace(raw)); v {
+	case "":
+		return ImportModeRecord, nil
+	case ImportModeRecord, ImportModeHardlink, ImportModeMove:
+		return v, nil
+	default:
+		return "", fmt.Errorf("import mode must be record, hardlink, or move")
+	}
+}
+
+func countImport(res *ImportResult, item ImportItem) {
+	if item.Outcome == ImportOutcomeCreated {
+		res.Created++
+	} else {
+		res.Matched++
+	}
+	if item.ArchiveOnly {
+		res.ArchiveOnly++
+	}
+	res.Items = append(res.Items, item)
+}
+
+func completeImported(j *model.Job, item ImportItem, at string) error {
+	reason := reasonImported
+	if item.ArchiveOnly {
+		reason = model.ReasonImportedArchive
+	}
+	if err := completeFromDisk(j, reason, item.Path, at); err != nil {
+		return err
+	}
+	if j.Title == "" || isPlaceholderTitle(j.Title) {
+		j.Title = firstNonEmptyString(item.Title, j.Title)
+	}
+	j.AppendHistory(model.JobEvent{At: at, Kind: model.JobEventImported, From: item.From, To: item.Path})
+	return nil
+}
+
+// completeFromDisk walks the job through running to completed, as a download
+// would, so the usual transition rules apply.
+func completeFromDisk(j *model.Job, reason, mediaPath, at string) error {
+	steps := []string{model.StatusRunning, model.StatusCompleted}
+	if j.Status != model.StatusRunning && !model.CanTransition(j.Status, model.StatusRunning) {
+		steps = append([]string{model.StatusPending}, steps...)
+	}
+	for _, status := range steps {
+		if err := model.TransitionJobStatus(j, status, reason); err != nil {
+			return err
+		}
+	}
+	j.MediaPath = mediaPath
+	j.CompletedAt = at
+	j.LastError = ""
+	if j.RemovedUpstream {
+		j.LocalCopy = mediaPath != ""
+	}
+	return nil
+}
+
+// hasRecordedMedia reports whether the job's media is still where it was
+// recorded, which may be outside the output dir for files imported in place.
+func hasRecordedMedia(j *model.Job) bool {
+	if strings.TrimSpace(j.MediaPath) == "" {
+		return false
+	}
+	st, err := os.Stat(j.MediaPath)
+	return err == nil && st.Mode().IsRegular()
+}
+
+// readDownloadArchive returns the YouTube IDs in a yt-dlp download archive.
+// Entries from other extractors are ignored.
+func readDownloadArchive(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	seen := map[string]bool{}
+	ids := make([]string, 0)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "youtube") || seen[fields[1]] {
+			continue
+		}
+		seen[fields[1]] = true
+		ids = append(ids, fields[1])
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read download archive %s: %w", path, err)
+	}
+	return ids, nil
+}
+
+type importInfo struct {
+	Title      string `json:"title"`
+	Uploader   string `json:"uploader"`
+	Channel    string `json:"channel"`
+	UploadDate string `json:"upload_date"`
+}
+
+// readImportInfo reads the .info.json yt-dlp writes next to the media, if any.
+func readImportInfo(mediaPath, videoID string) importInfo {
+	sidecars, err := jobSidecars(mediaPath, videoID)
+	if err != nil {
+		return importInfo{}
+	}
+	for _, f := range sidecars {
+		if !strings.HasSuffix(strings.ToLower(f), ".info.json") {
+			continue
+		}
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var info importInfo
+		if json.Unmarshal(data, &info) == nil {
+			return info
+		}
+	}
+	return importInfo{}
+}
+
+// titleFromFileName recovers a title from yt-dlp's default "<title> [<id>].ext".
+func titleFromFileName(path, videoID string) string {
+	name := filepath.Base(path)
+	if k := strings.Index(name, "["+videoID+"]"); k >= 0 {
+		name = name[:k]
+	}
+	return strings.TrimRight(strings.TrimSpace(name), "_- ")
+}
+
+// importTargets places the media and its sidecars where a download would put
+// them: <output>/<uploader>/<upload_date>_<title>_[<id>]<suffix>. yt-dlp
+// writes NA for fields it does not know, and so does this.
+func importTargets(src, videoID, outputDir string, info importInfo, title string) ([]RenameFile, error) {
+	sidecars, err := jobSidecars(src, videoID)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(outputDir, ytdlp.RestrictedTitle(firstNonEmptyString(info.Uploader, info.Channel, "NA")))
+	stem := ytdlp.OutputStem(firstNonEmptyString(info.UploadDate, "NA"), firstNonEmptyString(title, "NA"), videoID)
+	token := "[" + videoID + "]"
+	files := make([]RenameFile, 0, len(sidecars)+1)
+	for _, f := range append(sidecars, src) {
+		name := filepath.Base(f)
+		k := strings.Index(name, token)
+		if k < 0 {
+			continue
+		}
+		files = append(files, RenameFile{From: f, To: filepath.Join(dir, stem+name[k+len(token):])})
+	}
+	return files, nil
+}
+
+// placeFiles hardlinks or moves every file or none. A move that rename cannot
+// do (another file system) falls back to a verified copy, and the originals
+// are removed only once every copy is in place.
+func placeFiles(files []RenameFile, mode string) error {
+	todo := make([]RenameFile, 0, len(files))
+	for _, f := range files {
+		if sameFile(f.From, f.To) {
+			continue
+		}
+		if _, err := os.Lstat(f.To); err == nil {
+			return fmt.Errorf("target already exists: %s", f.To)
+		}
+		todo = append(todo, f)
+	}
+	if len(todo) == 0 {
+		return nil
+	}
+	if err := runstore.Mkdir(filepath.Dir(todo[0].To)); err != nil {
+		return err
+	}
+	undo := func(done []RenameFile) {
+		for _, f := range done {
+			_ = os.Remove(f.To)
+		}
+	}
+	if mode == ImportModeHardlink {
+		for i, f := range todo {
+			if err := runstore.LinkFile(f.From, f.To, runstore.LinkModeHardlink); err != nil {
+				undo(todo[:i])
+				return err
+			}
+		}
+		return nil
+	}
+	if err := renameFiles(todo); err == nil {
+		return nil
+	}
+	for i, f := range todo {
+		if _, _, err := copyVerified(f.From, f.To); err != nil {
+			undo(todo[:i])
+			return err
+		}
+	}
+	for _, f := range todo {
+		_ = os.Remove(f.From)
+	}
+	return nil
+}
+
+func sameFile(a, b string) bool {
+	sa, errA := os.Stat(a)
+	sb, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(sa, sb)
+}
@@ -0,0 +1,156 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+// writeImportFixture lays out a plain yt-dlp download dir and archive file and
+// a run that knows one of the two downloaded videos.
+func writeImportFixture(t *testing.T) (runsDir, runDir, srcDir, archiveFile string) {
+	t.Helper()
+	tmp := t.TempDir()
+	runsDir = filepath.Join(tmp, "runs")
+	runDir = filepath.Join(runsDir, "run1")
+	srcDir = filepath.Join(tmp, "old", "Chan")
+	if err := os.MkdirAll(srcDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"Known Talk [known123456].mp4":       "known",
+		"Known Talk [known123456].info.json": `{"title":"Known Talk","uploader":"Chan","upload_date":"20200102"}`,
+		"Unlisted Talk [extra123456].webm":   "extra",
+		"Unlisted Talk [extra123456].en.vtt": "subs",
+		"notes.txt":                          "not media",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	archiveFile = filepath.Join(tmp, "archive.txt")
+	if err := os.WriteFile(archiveFile, []byte("youtube known123456\nyoutube gone1234567\nvimeo 998877\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mf := model.JobsManifest{
+		RunID: "run1",
+		Jobs: []model.Job{
+			{JobID: "src:1:known123456", Index: 1, VideoID: "known123456", Title: "Known Talk", Status: model.StatusPending},
+			{JobID: "src:2:later123456", Index: 2, VideoID: "later123456", Title: "Later", Status: model.StatusPending},
+		},
+	}
+	if err := runstore.WriteJSON(filepath.Join(runDir, "manifest.jobs.json"), mf); err != nil {
+		t.Fatal(err)
+	}
+	return runsDir, runDir, srcDir, archiveFile
+}
+
+func readImportJobs(t *testing.T, runDir string) map[string]model.Job {
+	t.Helper()
+	var mf model.JobsManifest
+	if err := runstore.ReadJobsManifest(filepath.Join(runDir, "manifest.jobs.json"), &mf); err != nil {
+		t.Fatal(err)
+	}
+	jobs := make(map[string]model.Job, len(mf.Jobs))
+	for _, j := range mf.Jobs {
+		jobs[j.VideoID] = j
+	}
+	return jobs
+}
+
+func TestImportMovesFilesIntoLayoutAndCompletesJobs(t *testing.T) {
+	runsDir, runDir, srcDir, archiveFile := writeImportFixture(t)
+
+	plan, err := Import(ImportOptions{RunDir: runDir, RunsDir: runsDir, Dir: srcDir, ArchiveFile: archiveFile, Mode: ImportModeMove, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.FilesFound != 2 || plan.ArchiveIDs != 2 || plan.Matched != 1 || plan.Created != 2 || plan.ArchiveOnly != 1 {
+		t.Fatalf("unexpected dry-run plan: %+v", plan)
+	}
+	if jobs := readImportJobs(t, runDir); jobs["known123456"].Status != model.StatusPending || len(jobs) != 2 {
+		t.Fatalf("dry run must not change the manifest: %+v", jobs)
+	}
+
+	res, err := Import(ImportOptions{RunDir: runDir, RunsDir: runsDir, Dir: srcDir, ArchiveFile: archiveFile, Mode: ImportModeMove})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Matched != 1 || res.Created != 2 || res.Failed != 0 {
+		t.Fatalf("unexpected import result: %+v", res)
+	}
+	downloads := filepath.Join(runDir, "downloads")
+	for _, rel := range []string{
+		"Chan/20200102_Known_Talk_[known123456].mp4",
+		"Chan/20200102_Known_Talk_[known123456].info.json",
+		"NA/NA_Unlisted_Talk_[extra123456].webm",
+		"NA/NA_Unlisted_Talk_[extra123456].en.vtt",
+	} {
+		if _, err := os.Stat(filepath.Join(downloads, rel)); err != nil {
+			t.Fatalf("expected %s in the output layout: %v", rel, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(srcDir, "Known Talk [known123456].mp4")); !os.IsNotExist(err) {
+		t.Fatalf("expected source to be moved, stat err = %v", err)
+	}
+
+	jobs := readImportJobs(t, runDir)
+	known := jobs["known123456"]
+	if known.Status != model.StatusCompleted || known.MediaPath != filepath.Join(downloads, "Chan/20200102_Known_Talk_[known123456].mp4") {
+		t.Fatalf("unexpected imported job: %+v", known)
+	}
+	if ev := known.History[len(known.History)-1]; ev.Kind != model.JobEventImported || !strings.HasSuffix(ev.From, "Known Talk [known123456].mp4") {
+		t.Fatalf("expected import event, got %+v", known.History)
+	}
+	if extra := jobs["extra123456"]; extra.Status != model.StatusCompleted || extra.Title != "Unlisted Talk" || extra.VideoURL == "" {
+		t.Fatalf("unexpected created job: %+v", extra)
+	}
+	if gone := jobs["gone1234567"]; !gone.HeldElsewhere() {
+		t.Fatalf("expected archive-only job to be held elsewhere: %+v", gone)
+	}
+	if jobs["later123456"].Status != model.StatusPending {
+		t.Fatalf("unrelated job changed: %+v", jobs["later123456"])
+	}
+	data, err := os.ReadFile(filepath.Join(runDir, "download-archive.txt"))
+	if err != nil || !strings.Contains(string(data), "youtube gone1234567") {
+		t.Fatalf("expected imported IDs in the run's download archive, got %q: %v", data, err)
+	}
+
+	again, err := Import(ImportOptions{RunDir: runDir, RunsDir: runsDir, ArchiveFile: archiveFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Skipped != 2 || again.Matched+again.Created != 0 {
+		t.Fatalf("expected a second import to skip completed jobs, got %+v", again)
+	}
+}
+
+func TestImportRecordKeepsFilesInPlaceAndSurvivesReconcile(t *testing.T) {
+	runsDir, runDir, srcDir, _ := writeImportFixture(t)
+
+	if _, err := Import(ImportOptions{RunDir: runDir, RunsDir: runsDir, Dir: srcDir}); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(srcDir, "Known Talk [known123456].mp4")
+	jobs := readImportJobs(t, runDir)
+	if jobs["known123456"].MediaPath != src {
+		t.Fatalf("expected media recorded in place, got %+v", jobs["known123456"])
+	}
+
+	var mf model.JobsManifest
+	if err := runstore.ReadJobsManifest(filepath.Join(runDir, "manifest.jobs.json"), &mf); err != nil {
+		t.Fatal(err)
+	}
+	requeued, err := reconcileCompletedJobsWithDisk(&mf, filepath.Join(runDir, "downloads"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requeued) != 0 {
+		t.Fatalf("files imported in place must not be re-downloaded, requeued %v", requeued)
+	}
+}
@@ -0,0 +1,129 @@
+package archive
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+// JobEventAttempt is the history kind recorded for each download attempt.
+const JobEventAttempt = model.JobEventAttempt
+
+type JobShowOptions struct {
+	RunID   string
+	RunDir  string
+	RunsDir string
+	Latest  bool
+	// Video matches a job's video ID, job ID or video URL.
+	Video        string
+	FailureRules []model.FailureRule
+}
+
+type JobShowResult struct {
+	RunID     string    `json:"run_id"`
+	RunDir    string    `json:"run_dir"`
+	Job       model.Job `json:"job"`
+	LogPath   string    `json:"log_path"`
+	LogExists bool      `json:"log_exists"`
+	// Remediation is the classifier hint for the job's current failure reason.
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// attempt describes one download attempt while it is in flight.
+type attempt struct {
+	start  time.Time
+	worker int
+	proxy  string
+	log    string
+	class  string
+}
+
+// recordAttempt appends the finished attempt to the job's history. Call it
+// after the job's status transition so the outcome and reason are final.
+func recordAttempt(j *model.Job, a attempt, errText string) {
+	ev := model.JobEvent{
+		At:         a.start.UTC().Format(time.RFC3339),
+		DurationMS: time.Since(a.start).Milliseconds(),
+		Worker:     a.worker,
+		Proxy:      redactProxy(a.proxy),
+		Outcome:    j.Status,
+		Reason:     j.Reason,
+		Log:        a.log,
+	}
+	if errText != "" {
+		ev.Error = truncate(errText, 300)
+		ev.ErrorClass = firstNonEmptyString(a.class, errorClassUnknown)
+	}
+	j.AppendAttempt(ev)
+}
+
+func jobLogName(j model.Job) string {
+	return fmt.Sprintf("%04d_%s.log", j.Index, safeFileID(j.VideoID, j.Index))
+}
+
+// redactProxy drops credentials so proxy passwords never reach the manifest.
+func redactProxy(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	u.User = url.User(u.User.Username())
+	return u.String()
+}
+
+// ShowJob loads one job, including its attempt history, from a run manifest.
+func ShowJob(opts JobShowOptions) (JobShowResult, error) {
+	video := strings.TrimSpace(opts.Video)
+	if video == "" {
+		return JobShowResult{}, fmt.Errorf("video id is required")
+	}
+	classifier, err := newFailureClassifier(opts.FailureRules)
+	if err != nil {
+		return JobShowResult{}, err
+	}
+	runDir, err := resolveRunDir(RunOptions{RunID: opts.RunID, RunDir: opts.RunDir, RunsDir: opts.RunsDir, Latest: opts.Latest})
+	if err != nil {
+		return JobShowResult{}, err
+	}
+	var mf model.JobsManifest
+	if err := runstore.ReadJobsManifest(filepath.Join(runDir, "manifest.jobs.json"), &mf); err != nil {
+		return JobShowResult{}, fmt.Errorf("read jobs manifest: %w", err)
+	}
+	for _, j := range mf.Jobs {
+		if j.VideoID != video && j.JobID != video && j.VideoURL != video {
+			continue
+		}
+		res := JobShowResult{
+			RunID:   firstNonEmptyString(mf.RunID, filepath.Base(runDir)),
+			RunDir:  runDir,
+			Job:     j,
+			LogPath: filepath.Join(runDir, "logs", jobLogName(j)),
+		}
+		// The job index (and so the log name) can shift on refresh; prefer the
+		// log the last attempt actually wrote.
+		for k := len(j.History) - 1; k >= 0; k-- {
+			if j.History[k].Log != "" {
+				res.LogPath = filepath.Join(runDir, j.History[k].Log)
+				break
+			}
+		}
+		if _, err := os.Stat(res.LogPath); err == nil {
+			res.LogExists = true
+		}
+		if j.Status == model.StatusFailedRetryable || j.Status == model.StatusFailedPermanent {
+			res.Remediation = classifier.remediationFor(j.Reason)
+		}
+		return res, nil
+	}
+	return JobShowResult{}, fmt.Errorf("video %q not found in run %s", video, runDir)
+}
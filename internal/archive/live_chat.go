@@ -0,0 +1,281 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"yt-vod-manager/internal/ytdlp"
+)
+
+const (
+	liveChatFormatText = "txt"
+	liveChatFormatASS  = "ass"
+
+	liveChatReplaySuffix  = ".live_chat.json"
+	liveChatASSDisplaySec = 5.0
+)
+
+type liveChatMessage struct {
+	OffsetMs int64
+	Author   string
+	Amount   string
+	Text     string
+}
+
+type liveChatReplayLine struct {
+	ReplayChatItemAction struct {
+		VideoOffsetTimeMsec string           `json:"videoOffsetTimeMsec"`
+		Actions             []liveChatAction `json:"actions"`
+	} `json:"replayChatItemAction"`
+	VideoOffsetTimeMsec string `json:"videoOffsetTimeMsec"`
+}
+
+type liveChatAction struct {
+	AddChatItemAction *struct {
+		Item liveChatItem `json:"item"`
+	} `json:"addChatItemAction"`
+}
+
+type liveChatItem struct {
+	Text       *liveChatRenderer `json:"liveChatTextMessageRenderer"`
+	Paid       *liveChatRenderer `json:"liveChatPaidMessageRenderer"`
+	Membership *liveChatRenderer `json:"liveChatMembershipItemRenderer"`
+}
+
+type liveChatRenderer struct {
+	Message            *liveChatRuns  `json:"message"`
+	HeaderSubtext      *liveChatRuns  `json:"headerSubtext"`
+	AuthorName         liveChatSimple `json:"authorName"`
+	PurchaseAmountText liveChatSimple `json:"purchaseAmountText"`
+}
+
+type liveChatSimple struct {
+	SimpleText string `json:"simpleText"`
+}
+
+type liveChatRuns struct {
+	SimpleText string `json:"simpleText"`
+	Runs       []struct {
+		Text  string `json:"text"`
+		Emoji *struct {
+			EmojiID   string   `json:"emojiId"`
+			Shortcuts []string `json:"shortcuts"`
+		} `json:"emoji"`
+	} `json:"runs"`
+}
+
+func normalizeLiveChatFormat(raw string) string {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case liveChatFormatASS:
+		return liveChatFormatASS
+	default:
+		return liveChatFormatText
+	}
+}
+
+func archiveLiveChatReplay(dl ytdlp.DownloadOptions, videoID, format string) error {
+	if _, err := ytdlp.DownloadLiveChat(dl); err != nil {
+		return err
+	}
+	replayPath, err := findLiveChatReplay(dl.OutputDir, videoID)
+	if err != nil || replayPath == "" {
+		return err
+	}
+	_, _, err = renderLiveChatReplay(replayPath, format)
+	return err
+}
+
+func findLiveChatReplay(outputDir, videoID string) (string, error) {
+	videoID = strings.TrimSpace(videoID)
+	if strings.TrimSpace(outputDir) == "" || videoID == "" {
+		return "", nil
+	}
+	suffix := "[" + videoID + "]" + liveChatReplaySuffix
+	found := ""
+	err := filepath.WalkDir(outputDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || found != "" {
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), suffix) {
+			found = path
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	return found, nil
+}
+
+func renderLiveChatReplay(replayPath, format string) (string, int, error) {
+	messages, err := parseLiveChatReplay(replayPath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	format = normalizeLiveChatFormat(format)
+	var out string
+	if format == liveChatFormatASS {
+		out = formatLiveChatASS(messages)
+	} else {
+		out = formatLiveChatText(messages)
+	}
+	outPath := strings.TrimSuffix(replayPath, liveChatReplaySuffix) + ".live_chat." + format
+	if err := os.WriteFile(outPath, []byte(out), 0o644); err != nil {
+		return "", 0, fmt.Errorf("write live chat %s: %w", outPath, err)
+	}
+	return outPath, len(messages), nil
+}
+
+func parseLiveChatReplay(path string) ([]liveChatMessage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open live chat replay %s: %w", path, err)
+	}
+	defer f.Close()
+
+	messages := make([]liveChatMessage, 0)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		raw := strings.TrimSpace(sc.Text())
+		if raw == "" {
+			continue
+		}
+		var line liveChatReplayLine
+		if err := json.Unmarshal([]byte(raw), &line); err != nil {
+			continue
+		}
+		offsetRaw := line.ReplayChatItemAction.VideoOffsetTimeMsec
+		if offsetRaw == "" {
+			offsetRaw = line.VideoOffsetTimeMsec
+		}
+		offset, _ := strconv.ParseInt(strings.TrimSpace(offsetRaw), 10, 64)
+		if offset < 0 {
+			offset = 0
+		}
+		for _, action := range line.ReplayChatItemAction.Actions {
+			if action.AddChatItemAction == nil {
+				continue
+			}
+			if msg, ok := liveChatMessageFromItem(action.AddChatItemAction.Item); ok {
+				msg.OffsetMs = offset
+				messages = append(messages, msg)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read live chat replay %s: %w", path, err)
+	}
+	return messages, nil
+}
+
+func liveChatMessageFromItem(item liveChatItem) (liveChatMessage, bool) {
+	switch {
+	case item.Text != nil:
+		return liveChatMessage{
+			Author: item.Text.AuthorName.SimpleText,
+			Text:   item.Text.Message.String(),
+		}, true
+	case item.Paid != nil:
+		return liveChatMessage{
+			Author: item.Paid.AuthorName.SimpleText,
+			Amount: item.Paid.PurchaseAmountText.SimpleText,
+			Text:   item.Paid.Message.String(),
+		}, true
+	case item.Membership != nil:
+		return liveChatMessage{
+			Author: item.Membership.AuthorName.SimpleText,
+			Text:   item.Membership.HeaderSubtext.String(),
+		}, true
+	default:
+		return liveChatMessage{}, false
+	}
+}
+
+func (r *liveChatRuns) String() string {
+	if r == nil {
+		return ""
+	}
+	if len(r.Runs) == 0 {
+		return strings.TrimSpace(r.SimpleText)
+	}
+	var b strings.Builder
+	for _, run := range r.Runs {
+		switch {
+		case run.Text != "":
+			b.WriteString(run.Text)
+		case run.Emoji != nil && len(run.Emoji.Shortcuts) > 0:
+			b.WriteString(run.Emoji.Shortcuts[0])
+		case run.Emoji != nil:
+			b.WriteString(run.Emoji.EmojiID)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func formatLiveChatText(messages []liveChatMessage) string {
+	var b strings.Builder
+	for _, m := range messages {
+		author := strings.TrimSpace(m.Author)
+		if m.Amount != "" {
+			author += " (" + strings.TrimSpace(m.Amount) + ")"
+		}
+		text := strings.ReplaceAll(m.Text, "\n", " ")
+		fmt.Fprintf(&b, "[%s] %s: %s\n", formatChatClock(m.OffsetMs), author, text)
+	}
+	return b.String()
+}
+
+func formatLiveChatASS(messages []liveChatMessage) string {
+	var b strings.Builder
+	b.WriteString("[Script Info]\n")
+	b.WriteString("ScriptType: v4.00+\n")
+	b.WriteString("PlayResX: 1920\n")
+	b.WriteString("PlayResY: 1080\n")
+	b.WriteString("WrapStyle: 0\n\n")
+	b.WriteString("[V4+ Styles]\n")
+	b.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	b.WriteString("Style: Chat,Arial,36,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,7,40,40,40,1\n\n")
+	b.WriteString("[Events]\n")
+	b.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	for _, m := range messages {
+		start := float64(m.OffsetMs) / 1000.0
+		author := escapeASS(m.Author)
+		if m.Amount != "" {
+			author += " (" + escapeASS(m.Amount) + ")"
+		}
+		// Name is comma-delimited and cannot hold arbitrary authors; the
+		// author is shown in the Text prefix instead.
+		fmt.Fprintf(&b, "Dialogue: 0,%s,%s,Chat,,0,0,0,,{\\b1}%s{\\b0}: %s\n",
+			formatASSClock(start),
+			formatASSClock(start+liveChatASSDisplaySec),
+			author,
+			escapeASS(m.Text),
+		)
+	}
+	return b.String()
+}
+
+func formatChatClock(offsetMs int64) string {
+	total := offsetMs / 1000
+	return fmt.Sprintf("%d:%02d:%02d", total/3600, (total%3600)/60, total%60)
+}
+
+func formatASSClock(seconds float64) string {
+	cs := int64(seconds*100 + 0.5)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, (cs%360000)/6000, (cs%6000)/100, cs%100)
+}
+
+func escapeASS(s string) string {
+	r := strings.NewReplacer("\\", "\\\\", "{", "\\{", "}", "\\}", "\r", "", "\n", "\\N")
+	return r.Replace(strings.TrimSpace(s))
+}
@@ -0,0 +1,113 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const liveChatReplayFixture = `{"replayChatItemAction":{"videoOffsetTimeMsec":"1500","actions":[{"addChatItemAction":{"item":{"liveChatTextMessageRenderer":{"message":{"runs":[{"text":"hello "},{"emoji":{"emojiId":"x","shortcuts":[":wave:"]}}]},"authorName":{"simpleText":"alice"}}}}}]}}
+{"replayChatItemAction":{"videoOffsetTimeMsec":"3725000","actions":[{"addChatItemAction":{"item":{"liveChatPaidMessageRenderer":{"message":{"runs":[{"text":"gg {nice}"}]},"authorName":{"simpleText":"bob"},"purchaseAmountText":{"simpleText":"$5.00"}}}}}]}}
+{"replayChatItemAction":{"videoOffsetTimeMsec":"4000000","actions":[{"addBannerToLiveChatCommand":{}}]}}
+not json
+`
+
+func writeLiveChatFixture(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "uploader")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "20240101_Stream_[abc123].live_chat.json")
+	if err := os.WriteFile(path, []byte(liveChatReplayFixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseLiveChatReplay(t *testing.T) {
+	path := writeLiveChatFixture(t)
+	messages, err := parseLiveChatReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	if messages[0].Author != "alice" || messages[0].Text != "hello :wave:" || messages[0].OffsetMs != 1500 {
+		t.Fatalf("unexpected first message: %+v", messages[0])
+	}
+	if messages[1].Amount != "$5.00" {
+		t.Fatalf("expected paid amount, got %+v", messages[1])
+	}
+}
+
+func TestRenderLiveChatReplayText(t *testing.T) {
+	path := writeLiveChatFixture(t)
+	found, err := findLiveChatReplay(filepath.Dir(filepath.Dir(path)), "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found != path {
+		t.Fatalf("findLiveChatReplay = %q, want %q", found, path)
+	}
+
+	outPath, count, err := renderLiveChatReplay(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || !strings.HasSuffix(outPath, "[abc123].live_chat.txt") {
+		t.Fatalf("unexpected render result: %s (%d)", outPath, count)
+	}
+	raw, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[0:00:01] alice: hello :wave:\n[1:02:05] bob ($5.00): gg {nice}\n"
+	if string(raw) != want {
+		t.Fatalf("unexpected text output:\n%s", raw)
+	}
+}
+
+func TestRenderLiveChatReplayASS(t *testing.T) {
+	path := writeLiveChatFixture(t)
+	outPath, _, err := renderLiveChatReplay(path, "ASS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(raw)
+	if !strings.HasPrefix(out, "[Script Info]\n") {
+		t.Fatalf("missing ASS header:\n%s", out)
+	}
+	if !strings.Contains(out, "Dialogue: 0,0:00:01.50,0:00:06.50,Chat,,0,0,0,,{\\b1}alice{\\b0}: ") {
+		t.Fatalf("missing aligned dialogue line:\n%s", out)
+	}
+	if !strings.Contains(out, `gg \{nice\}`) {
+		t.Fatalf("expected override braces to be escaped:\n%s", out)
+	}
+}
+
+func TestFormatLiveChatASSKeepsCommaAuthorsInText(t *testing.T) {
+	out := formatLiveChatASS([]liveChatMessage{{OffsetMs: 2000, Author: "Smith, J", Text: "hi"}})
+	var line string
+	for _, l := range strings.Split(out, "\n") {
+		if strings.HasPrefix(l, "Dialogue: ") {
+			line = strings.TrimPrefix(l, "Dialogue: ")
+		}
+	}
+	fields := strings.SplitN(line, ",", 10)
+	if len(fields) != 10 {
+		t.Fatalf("expected 10 dialogue fields, got %d: %q", len(fields), line)
+	}
+	if fields[4] != "" || fields[8] != "" {
+		t.Fatalf("expected empty Name and Effect fields, got %q", fields)
+	}
+	if fields[9] != `{\b1}Smith, J{\b0}: hi` {
+		t.Fatalf("unexpected dialogue text: %q", fields[9])
+	}
+}
@@ -0,0 +1,124 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/ytdlp"
+)
+
+const workDirPrefix = ".ytvm-"
+
+var outputIDPattern = regexp.MustCompile(`\[([A-Za-z0-9_-]{6,})\]\.[^.]+$`)
+
+// mediaExt covers video containers and the audio files yt-dlp -x keeps
+// in their native codec for audio-only downloads.
+var mediaExt = map[string]struct{}{
+	"mp4": {}, "mkv": {}, "webm": {}, "m4v": {},
+	"mov": {}, "avi": {}, "flv": {}, "ts": {}, "m4a": {}, "mp3": {},
+	"opus": {}, "ogg": {}, "aac": {}, "flac": {}, "wav": {},
+}
+
+// audioExt are the mediaExt entries without a video stream.
+var audioExt = map[string]struct{}{
+	"m4a": {}, "mp3": {}, "opus": {}, "ogg": {}, "aac": {}, "flac": {}, "wav": {},
+}
+
+func isAudioMedia(path string) bool {
+	_, ok := audioExt[strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")]
+	return ok
+}
+
+func indexMediaByVideoID(root string) (map[string]bool, error) {
+	paths, err := mediaPathsByVideoID(root)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(paths))
+	for id := range paths {
+		ids[id] = true
+	}
+	return ids, nil
+}
+
+func mediaPathsByVideoID(root string) (map[string]string, error) {
+	paths := make(map[string]string)
+	if strings.TrimSpace(root) == "" {
+		return paths, nil
+	}
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), workDirPrefix) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := d.Name()
+		lower := strings.ToLower(name)
+		if strings.HasSuffix(lower, ".part") || strings.HasSuffix(lower, ".ytdl") || strings.HasSuffix(lower, ".tmp") {
+			return nil
+		}
+		ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+		if _, ok := mediaExt[ext]; !ok {
+			return nil
+		}
+		m := outputIDPattern.FindStringSubmatch(name)
+		if len(m) > 1 {
+			paths[m[1]] = path
+		}
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return paths, nil
+		}
+		return nil, err
+	}
+	return paths, nil
+}
+
+// outputRelPath is localPath relative to outputDir, or just its base name
+// when it lies outside (media linked in from another run's directory).
+func outputRelPath(outputDir, localPath string) string {
+	rel, err := filepath.Rel(outputDir, localPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.Base(localPath)
+	}
+	return rel
+}
+
+func recordJobMedia(j *model.Job, media ytdlp.MediaInfo) {
+	if strings.TrimSpace(media.Path) != "" {
+		j.MediaPath = media.Path
+	}
+	if !media.Known() {
+		return
+	}
+	j.FormatID = media.FormatID
+	j.Height = media.Height
+	j.VideoCodec = media.VideoCodec
+}
+
+func describeFormat(formatID string, height int, videoCodec string) string {
+	parts := make([]string, 0, 3)
+	if formatID != "" {
+		parts = append(parts, formatID)
+	}
+	if height > 0 {
+		parts = append(parts, fmt.Sprintf("%dp", height))
+	}
+	if videoCodec != "" {
+		parts = append(parts, videoCodec)
+	}
+	if len(parts) == 0 {
+		return "unknown"
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,235 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+	"yt-vod-manager/internal/ytdlp"
+)
+
+const (
+	mediaIndexFileName = "media-index.json"
+	reasonDeduplicated = "deduplicated"
+	dedupModeOff       = "off"
+)
+
+type mediaIndexEntry struct {
+	VideoID    string `json:"video_id"`
+	Path       string `json:"path"`
+	OutputDir  string `json:"output_dir"`
+	RunID      string `json:"run_id,omitempty"`
+	FormatID   string `json:"format_id,omitempty"`
+	Height     int    `json:"height,omitempty"`
+	VideoCodec string `json:"video_codec,omitempty"`
+	SizeBytes  int64  `json:"size_bytes,omitempty"`
+}
+
+// mediaIndex maps completed video IDs to the file that holds them, across all
+// runs in a workspace, so other projects can link instead of downloading.
+type mediaIndex struct {
+	SchemaVersion int                        `json:"schema_version"`
+	UpdatedAt     string                     `json:"updated_at,omitempty"`
+	Entries       map[string]mediaIndexEntry `json:"entries"`
+}
+
+type DedupRun struct {
+	RunID        string `json:"run_id"`
+	Deduplicated int    `json:"deduplicated"`
+	SavedBytes   int64  `json:"saved_bytes"`
+}
+
+type DedupReportResult struct {
+	RunsDir      string     `json:"runs_dir"`
+	IndexPath    string     `json:"index_path"`
+	IndexedFiles int        `json:"indexed_files"`
+	Deduplicated int        `json:"deduplicated"`
+	SavedBytes   int64      `json:"saved_bytes"`
+	Runs         []DedupRun `json:"runs"`
+}
+
+func mediaIndexPath(runsDir string) string {
+	return filepath.Join(runsDir, mediaIndexFileName)
+}
+
+// loadMediaIndex treats a missing or unreadable index as empty; it is rebuilt as runs complete.
+func loadMediaIndex(runsDir string) mediaIndex {
+	idx := mediaIndex{SchemaVersion: 1, Entries: map[string]mediaIndexEntry{}}
+	var stored mediaIndex
+	if err := runstore.ReadJSON(mediaIndexPath(runsDir), &stored); err != nil {
+		return idx
+	}
+	for id, e := range stored.Entries {
+		idx.Entries[id] = e
+	}
+	return idx
+}
+
+func (idx mediaIndex) lookup(videoID, outputDir string) (mediaIndexEntry, bool) {
+	e, ok := idx.Entries[strings.TrimSpace(videoID)]
+	if !ok || sameDir(e.OutputDir, outputDir) {
+		return mediaIndexEntry{}, false
+	}
+	st, err := os.Stat(e.Path)
+	if err != nil || !st.Mode().IsRegular() {
+		return mediaIndexEntry{}, false
+	}
+	return e, true
+}
+
+// linkFromMediaIndex places an existing copy of videoID into outputDir, keeping
+// the path it has relative to its own output dir.
+func linkFromMediaIndex(idx mediaIndex, mode, videoID, outputDir string) (ytdlp.MediaInfo, bool) {
+	if normalizeDedupMode(mode) == dedupModeOff {
+		return ytdlp.MediaInfo{}, false
+	}
+	e, ok := idx.lookup(videoID, outputDir)
+	if !ok {
+		return ytdlp.MediaInfo{}, false
+	}
+	rel, err := filepath.Rel(e.OutputDir, e.Path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		rel = filepath.Base(e.Path)
+	}
+	dst := filepath.Join(outputDir, rel)
+	if _, err := os.Lstat(dst); err != nil {
+		if err := runstore.LinkFile(e.Path, dst, normalizeDedupMode(mode)); err != nil {
+			return ytdlp.MediaInfo{}, false
+		}
+	}
+	return ytdlp.MediaInfo{FormatID: e.FormatID, Height: e.Height, VideoCodec: e.VideoCodec, Path: dst}, true
+}
+
+// updateMediaIndex registers completed downloads from this run. Entries that
+// still point at an existing file win, so linked copies never replace originals.
+func updateMediaIndex(runsDir, runID, outputDir string, jobs []model.Job) error {
+	onDisk, err := mediaPathsByVideoID(outputDir)
+	if err != nil {
+		return err
+	}
+	if abs, err := filepath.Abs(outputDir); err == nil {
+		outputDir = abs
+	}
+	idx := loadMediaIndex(runsDir)
+	changed := false
+	for _, j := range jobs {
+		if j.Status != model.StatusCompleted || j.Reason == reasonDeduplicated || strings.TrimSpace(j.VideoID) == "" {
+			continue
+		}
+		path := j.MediaPath
+		if _, err := os.Stat(path); strings.TrimSpace(path) == "" || err != nil {
+			path = onDisk[j.VideoID]
+		}
+		if path == "" {
+			continue
+		}
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		st, err := os.Stat(path)
+		if err != nil || !st.Mode().IsRegular() {
+			continue
+		}
+		if prev, ok := idx.Entries[j.VideoID]; ok && !sameDir(prev.OutputDir, outputDir) {
+			if _, err := os.Stat(prev.Path); err == nil {
+				continue
+			}
+		}
+		idx.Entries[j.VideoID] = mediaIndexEntry{
+			VideoID:    j.VideoID,
+			Path:       path,
+			OutputDir:  outputDir,
+			RunID:      runID,
+			FormatID:   j.FormatID,
+			Height:     j.Height,
+			VideoCodec: j.VideoCodec,
+			SizeBytes:  st.Size(),
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	idx.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	return runstore.WriteJSON(mediaIndexPath(runsDir), idx)
+}
+
+// DedupReport sums the media size of every job that was completed by linking.
+func DedupReport(runsDir string) (DedupReportResult, error) {
+	if strings.TrimSpace(runsDir) == "" {
+		runsDir = "runs"
+	}
+	res := DedupReportResult{
+		RunsDir:   runsDir,
+		IndexPath: mediaIndexPath(runsDir),
+		Runs:      []DedupRun{},
+	}
+	res.IndexedFiles = len(loadMediaIndex(runsDir).Entries)
+
+	runDirs, err := runstore.ListRunDirs(runsDir)
+	if err != nil {
+		return DedupReportResult{}, err
+	}
+	for _, runDir := range runDirs {
+		var mf model.JobsManifest
+		if err := runstore.ReadJobsManifest(filepath.Join(runDir, "manifest.jobs.json"), &mf); err != nil {
+			continue
+		}
+		run := DedupRun{RunID: firstNonEmptyString(mf.RunID, filepath.Base(runDir))}
+		for _, j := range mf.Jobs {
+			if j.Status != model.StatusCompleted || j.Reason != reasonDeduplicated {
+				continue
+			}
+			run.Deduplicated++
+			if st, err := os.Stat(j.MediaPath); err == nil {
+				run.SavedBytes += st.Size()
+			}
+		}
+		if run.Deduplicated == 0 {
+			continue
+		}
+		res.Deduplicated += run.Deduplicated
+		res.SavedBytes += run.SavedBytes
+		res.Runs = append(res.Runs, run)
+	}
+	sort.Slice(res.Runs, func(a, b int) bool {
+		return res.Runs[a].SavedBytes > res.Runs[b].SavedBytes
+	})
+	return res, nil
+}
+
+func normalizeDedupMode(raw string) string {
+	switch v := strings.ToLower(strings.TrimSpace(raw)); v {
+	case runstore.LinkModeHardlink, runstore.LinkModeReflink, runstore.LinkModeSymlink:
+		return v
+	default:
+		return dedupModeOff
+	}
+}
+
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
+
+// appendDownloadArchive records a linked video the same way yt-dlp would, so a
+// later plain yt-dlp pass over this run skips it too.
+func appendDownloadArchive(path, videoID string) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, _ = f.WriteString("youtube " + strings.TrimSpace(videoID) + "\n")
+}
@@ -0,0 +1,172 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+func writeDedupRun(t *testing.T, runDir, status string) {
+	t.Helper()
+	mf := model.JobsManifest{
+		SchemaVersion: 1,
+		RunID:         filepath.Base(runDir),
+		Total:         1,
+		Jobs: []model.Job{{
+			JobID:    "j1",
+			Index:    1,
+			VideoID:  "shared12345",
+			VideoURL: "https://www.youtube.com/watch?v=shared12345",
+			Title:    "Shared",
+			Status:   status,
+		}},
+	}
+	if err := runstore.WriteJSON(filepath.Join(runDir, "manifest.jobs.json"), mf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunLinksVideosArchivedByAnotherProject(t *testing.T) {
+	tmp := t.TempDir()
+	fakeBin := filepath.Join(tmp, "bin")
+	if err := os.MkdirAll(fakeBin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	failing := "#!/usr/bin/env bash\necho 'unexpected download' >&2\nexit 1\n"
+	for _, name := range []string{"yt-dlp", "ffmpeg"} {
+		if err := os.WriteFile(filepath.Join(fakeBin, name), []byte(failing), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", fakeBin+":"+os.Getenv("PATH"))
+
+	runsDir := filepath.Join(tmp, "runs")
+	channelRun := filepath.Join(runsDir, "channel")
+	playlistRun := filepath.Join(runsDir, "playlist")
+	original := filepath.Join(channelRun, "downloads", "chan", "20240101_Shared_[shared12345].mp4")
+	if err := os.MkdirAll(filepath.Dir(original), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(original, []byte("video-bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeDedupRun(t, channelRun, model.StatusCompleted)
+	writeDedupRun(t, playlistRun, model.StatusPending)
+
+	if _, err := Run(RunOptions{RunDir: channelRun, Workers: 1, NoSubs: true, DedupMode: runstore.LinkModeHardlink}); err != nil {
+		t.Fatalf("channel run failed: %v", err)
+	}
+	res, err := Run(RunOptions{RunDir: playlistRun, Workers: 1, NoSubs: true, DedupMode: runstore.LinkModeHardlink})
+	if err != nil {
+		t.Fatalf("playlist run failed: %v", err)
+	}
+	if res.Completed != 1 {
+		t.Fatalf("expected linked job to complete, got %+v", res)
+	}
+
+	var out model.JobsManifest
+	if err := runstore.ReadJSON(filepath.Join(playlistRun, "manifest.jobs.json"), &out); err != nil {
+		t.Fatal(err)
+	}
+	job := out.Jobs[0]
+	if job.Reason != reasonDeduplicated {
+		t.Fatalf("expected reason %q, got %q", reasonDeduplicated, job.Reason)
+	}
+	linked := filepath.Join(playlistRun, "downloads", "chan", "20240101_Shared_[shared12345].mp4")
+	if job.MediaPath != linked {
+		t.Fatalf("unexpected media path: %s", job.MediaPath)
+	}
+	a, errA := os.Stat(original)
+	b, errB := os.Stat(linked)
+	if errA != nil || errB != nil || !os.SameFile(a, b) {
+		t.Fatalf("expected hardlink to original media: %v %v", errA, errB)
+	}
+
+	report, err := DedupReport(runsDir)
+	if err != nil {
+		t.Fatalf("dedup report failed: %v", err)
+	}
+	if report.Deduplicated != 1 || report.SavedBytes != int64(len("video-bytes")) || report.IndexedFiles != 1 {
+		t.Fatalf("unexpected dedup report: %+v", report)
+	}
+}
+
+func TestLinkFromMediaIndexSkipsOffModeAndSameOutputDir(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "a", "x_[shared12345].mp4")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	idx := mediaIndex{Entries: map[string]mediaIndexEntry{
+		"shared12345": {VideoID: "shared12345", Path: path, OutputDir: filepath.Join(tmp, "a")},
+	}}
+	if _, ok := linkFromMediaIndex(idx, "off", "shared12345", filepath.Join(tmp, "b")); ok {
+		t.Fatal("expected off mode to skip linking")
+	}
+	if _, ok := linkFromMediaIndex(idx, "hardlink", "shared12345", filepath.Join(tmp, "a")); ok {
+		t.Fatal("expected same output dir to skip linking")
+	}
+	if _, ok := linkFromMediaIndex(idx, "symlink", "shared12345", filepath.Join(tmp, "b")); !ok {
+		t.Fatal("expected symlink into other output dir")
+	}
+	if target, err := os.Readlink(filepath.Join(tmp, "b", "x_[shared12345].mp4")); err != nil || target != path {
+		t.Fatalf("unexpected symlink target %q: %v", target, err)
+	}
+}
+
+func TestReconcileKeepsTombstonesInsteadOfRequeueing(t *testing.T) {
+	outputDir := t.TempDir()
+	kept := filepath.Join(outputDir, "20240101_Kept_[kept1234567].mp4")
+	if err := os.WriteFile(kept, []byte("video-bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mf := model.JobsManifest{Jobs: []model.Job{
+		{VideoID: "kept1234567", Status: model.StatusCompleted, RemovedUpstream: true},
+		{VideoID: "lost1234567", Status: model.StatusCompleted, RemovedUpstream: true, LocalCopy: true},
+	}}
+
+	missing, err := reconcileCompletedJobsWithDisk(&mf, outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 0 {
+		t.Fatalf("expected tombstones never requeued, got %v", missing)
+	}
+	if !mf.Jobs[0].LocalCopy || mf.Jobs[1].LocalCopy {
+		t.Fatalf("expected local copy flags to follow disk, got %+v", mf.Jobs)
+	}
+	if mf.Jobs[1].Status != model.StatusCompleted {
+		t.Fatalf("expected lost tombstone to stay completed, got %s", mf.Jobs[1].Status)
+	}
+}
+
+func TestMediaPathsFindsExtractedAudio(t *testing.T) {
+	root := t.TempDir()
+	var want []string
+	for _, ext := range []string{"opus", "ogg", "aac", "flac", "wav"} {
+		id := "audio0" + ext
+		path := filepath.Join(root, "Channel", "20240101_Talk_["+id+"]."+ext)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, id)
+	}
+	paths, err := mediaPathsByVideoID(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range want {
+		if paths[id] == "" {
+			t.Fatalf("audio-only output for %s not found: %v", id, paths)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+// mirrorSet copies completed media and sidecars to the mirror directories in
+// the background while the run keeps downloading. Each copy is verified by
+// size and SHA-256 and recorded as a replica on the job. Jobs whose replicas
+// are missing, failed or no longer on disk are queued again at the start of
+// every run, so failed copies are retried on later syncs.
+type mirrorSet struct {
+	dirs      []string
+	outputDir string
+	jobsPath  string
+	// deleteLocal removes the local files once every mirror holds a copy
+	// and the job is also in object storage.
+	deleteLocal bool
+
+	mf    *model.JobsManifest
+	mu    *sync.Mutex
+	logMu *sync.Mutex
+	queue chan int
+	done  chan struct{}
+}
+
+// newMirrorSet returns nil when no mirrors are configured. mu guards mf.
+func newMirrorSet(dirs []string, outputDir, jobsPath string, mf *model.JobsManifest, mu, logMu *sync.Mutex) *mirrorSet {
+	clean := make([]string, 0, len(dirs))
+	for _, d := range dirs {
+		if d = strings.TrimSpace(d); d != "" {
+			clean = append(clean, filepath.Clean(d))
+		}
+	}
+	if len(clean) == 0 {
+		return nil
+	}
+	return &mirrorSet{
+		dirs:      clean,
+		outputDir: outputDir,
+		jobsPath:  jobsPath,
+		mf:        mf,
+		mu:        mu,
+		logMu:     logMu,
+		// Each job is queued at most twice: by the backlog scan and by the
+		// worker that completes it.
+		queue: make(chan int, 2*len(mf.Jobs)+1),
+		done:  make(chan struct{}),
+	}
+}
+
+// start queues completed jobs that still lack a verified replica, then copies
+// in the background. Once stop is closed, queued copies are left for the
+// next run.
+func (m *mirrorSet) start(stop <-chan struct{}) {
+	m.mu.Lock()
+	for i := range m.mf.Jobs {
+		if m.needsCopy(&m.mf.Jobs[i]) {
+			m.queue <- i
+		}
+	}
+	m.mu.Unlock()
+
+	go func() {
+		defer close(m.done)
+		for i := range m.queue {
+			if stopRequested(stop) {
+				continue
+			}
+			m.replicate(i)
+		}
+	}()
+}
+
+func (m *mirrorSet) enqueue(i int) {
+	m.queue <- i
+}
+
+// wait blocks until every queued copy has finished.
+func (m *mirrorSet) wait() {
+	close(m.queue)
+	<-m.done
+}
+
+func (m *mirrorSet) needsCopy(j *model.Job) bool {
+	if j.Status != model.StatusCompleted || strings.TrimSpace(j.VideoID) == "" {
+		return false
+	}
+	if (j.RemovedUpstream && !j.LocalCopy) || j.HeldElsewhere() {
+		return false
+	}
+	for _, dir := range m.dirs {
+		r, ok := j.Replica(dir)
+		if !ok || !replicaIntact(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// replicaIntact is the cheap check made on every run: the copy is still on
+// disk at the recorded size. The hash is verified when the copy is made.
+func replicaIntact(r model.Replica) bool {
+	if r.Status != model.ReplicaCopied || r.Path == "" {
+		return false
+	}
+	st, err := os.Stat(r.Path)
+	return err == nil && st.Size() == r.Size
+}
+
+func (m *mirrorSet) replicate(i int) {
+	m.mu.Lock()
+	job := m.mf.Jobs[i]
+	job.Replicas = append([]model.Replica(nil), job.Replicas...)
+	m.mu.Unlock()
+
+	videoID := strings.TrimSpace(job.VideoID)
+	mediaPath := job.MediaPath
+	if _, err := os.Stat(mediaPath); strings.TrimSpace(mediaPath) == "" || err != nil {
+		paths, _ := mediaPathsByVideoID(m.outputDir)
+		mediaPath = paths[videoID]
+	}
+	var sidecars []string
+	var srcErr error
+	if mediaPath == "" {
+		srcErr = fmt.Errorf("no local media for %s to copy", videoID)
+	} else {
+		sidecars, srcErr = jobSidecars(mediaPath, videoID)
+	}
+
+	updates := make([]model.Replica, 0, len(m.dirs))
+	for _, dir := range m.dirs {
+		prev, ok := job.Replica(dir)
+		if ok && replicaIntact(prev) {
+			continue
+		}
+		r := model.Replica{Mirror: dir, Attempts: prev.Attempts + 1}
+		err := srcErr
+		if err == nil {
+			r.Path, r.Size, r.SHA256, err = m.copyTo(dir, mediaPath, sidecars)
+		}
+		if err != nil {
+			r.Status = model.ReplicaFailed
+			r.Error = truncate(err.Error(), 1200)
+			m.warn("warn  mirror copy of %s to %s failed (retried next run): %v\n", videoID, dir, err)
+		} else {
+			r.Status = model.ReplicaCopied
+			r.CopiedAt = time.Now().UTC().Format(time.RFC3339)
+		}
+		updates = append(updates, r)
+	}
+	if len(updates) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	j := &m.mf.Jobs[i]
+	for _, r := range updates {
+		j.SetReplica(r)
+	}
+	removeLocal := m.deleteLocal && j.RemoteKey != "" && mediaPath != "" && j.Replicated(m.dirs)
+	err := runstore.WriteJSON(m.jobsPath, *m.mf)
+	m.mu.Unlock()
+	if err != nil {
+		m.warn("warn  persist mirror status for %s: %v\n", videoID, err)
+		return
+	}
+	if removeLocal {
+		for _, f := range append(sidecars, mediaPath) {
+			if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+				m.warn("warn  could not delete local copy %s: %v\n", f, err)
+			}
+		}
+	}
+}
+
+func (m *mirrorSet) warn(format string, args ...any) {
+	m.logMu.Lock()
+	fmt.Printf(format, args...)
+	m.logMu.Unlock()
+}
+
+// copyTo copies the sidecars, then the media, into dir under the same
+// relative paths they have in the output directory, and returns the media
+// copy's path, size and SHA-256.
+func (m *mirrorSet) copyTo(dir, mediaPath string, sidecars []string) (string, int64, string, error) {
+	for _, f := range sidecars {
+		if _, _, err := copyVerified(f, filepath.Join(dir, outputRelPath(m.outputDir, f))); err != nil {
+			return "", 0, "", err
+		}
+	}
+	dst := filepath.Join(dir, outputRelPath(m.outputDir, mediaPath))
+	size, sum, err := copyVerified(mediaPath, dst)
+	if err != nil {
+		return "", 0, "", err
+	}
+	return dst, size, sum, nil
+}
+
+// copyVerified copies src to dst through a synced temp file and a rename, so
+// dst is never partially written, then reads dst back and compares its size
+// and SHA-256 with what was read from src.
+func copyVerified(src, dst string) (int64, string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, "", err
+	}
+	defer in.Close()
+	st, err := in.Stat()
+	if err != nil {
+		return 0, "", err
+	}
+	if err := runstore.Mkdir(filepath.Dir(dst)); err != nil {
+		return 0, "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), workDirPrefix+"mirror-*")
+	if err != nil {
+		return 0, "", err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), in)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("copy %s: %w", src, err)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	if size != st.Size() {
+		return 0, "", fmt.Errorf("copy %s: read %d bytes, expected %d", src, size, st.Size())
+	}
+	if err := os.Rename(tmpPath, dst); err != nil {
+		return 0, "", err
+	}
+	_ = os.Chtimes(dst, st.ModTime(), st.ModTime())
+
+	gotSize, gotSum, err := hashFile(dst)
+	if err != nil {
+		return 0, "", err
+	}
+	if gotSize != size || gotSum != sum {
+		return 0, "", fmt.Errorf("verify %s: copy does not match source (size %d/%d)", dst, gotSize, size)
+	}
+	return size, sum, nil
+}
+
+func hashFile(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,139 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/objstore/objstoretest"
+	"yt-vod-manager/internal/runstore"
+)
+
+// setupMirrorRuns prepares a playlist run whose only job is linked from a
+// completed channel run, so Run finishes it without downloading.
+func setupMirrorRuns(t *testing.T) (string, string) {
+	t.Helper()
+	tmp := t.TempDir()
+	fakeBin := filepath.Join(tmp, "bin")
+	if err := os.MkdirAll(fakeBin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	failing := "#!/usr/bin/env bash\necho 'unexpected download' >&2\nexit 1\n"
+	for _, name := range []string{"yt-dlp", "ffmpeg"} {
+		if err := os.WriteFile(filepath.Join(fakeBin, name), []byte(failing), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", fakeBin+":"+os.Getenv("PATH"))
+
+	runsDir := filepath.Join(tmp, "runs")
+	channelRun := filepath.Join(runsDir, "channel")
+	original := filepath.Join(channelRun, "downloads", "20240101_Shared_[shared12345].mp4")
+	if err := os.MkdirAll(filepath.Dir(original), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(original, []byte("video-bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeDedupRun(t, channelRun, model.StatusCompleted)
+	if _, err := Run(RunOptions{RunDir: channelRun, Workers: 1, NoSubs: true, DedupMode: runstore.LinkModeHardlink}); err != nil {
+		t.Fatalf("channel run failed: %v", err)
+	}
+	playlistRun := filepath.Join(runsDir, "playlist")
+	writeDedupRun(t, playlistRun, model.StatusPending)
+	return tmp, playlistRun
+}
+
+func TestRunCopiesToMirrorsAndRetriesFailedCopies(t *testing.T) {
+	tmp, playlistRun := setupMirrorRuns(t)
+	good := filepath.Join(tmp, "nas")
+	// A file where the mirror directory should be makes every copy fail.
+	bad := filepath.Join(tmp, "usb")
+	if err := os.WriteFile(bad, []byte("not a dir"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sidecar := filepath.Join(playlistRun, "downloads", "20240101_Shared_[shared12345].info.json")
+	if err := os.MkdirAll(filepath.Dir(sidecar), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sidecar, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run := func() model.Job {
+		t.Helper()
+		if _, err := Run(RunOptions{RunDir: playlistRun, Workers: 1, NoSubs: true, DedupMode: runstore.LinkModeHardlink, Mirrors: []string{good, bad}}); err != nil {
+			t.Fatalf("playlist run failed: %v", err)
+		}
+		var out model.JobsManifest
+		if err := runstore.ReadJobsManifest(filepath.Join(playlistRun, "manifest.jobs.json"), &out); err != nil {
+			t.Fatal(err)
+		}
+		return out.Jobs[0]
+	}
+
+	job := run()
+	sum := sha256.Sum256([]byte("video-bytes"))
+	r, ok := job.Replica(good)
+	if !ok || r.Status != model.ReplicaCopied || r.Size != int64(len("video-bytes")) || r.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Fatalf("expected verified replica on %s, got %+v", good, job.Replicas)
+	}
+	if data, err := os.ReadFile(r.Path); err != nil || string(data) != "video-bytes" {
+		t.Fatalf("unexpected mirrored media %q: %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(good, "20240101_Shared_[shared12345].info.json")); err != nil {
+		t.Fatalf("expected sidecar to be mirrored: %v", err)
+	}
+	if r, ok := job.Replica(bad); !ok || r.Status != model.ReplicaFailed || r.Error == "" || r.Attempts != 1 {
+		t.Fatalf("expected failed replica on %s, got %+v", bad, job.Replicas)
+	}
+	if job.Status != model.StatusCompleted || job.Replicated([]string{good, bad}) {
+		t.Fatalf("mirror failure must not fail the job, got %+v", job)
+	}
+
+	// The next sync retries the failed mirror and re-copies a damaged one.
+	if err := os.Remove(bad); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(r.Path, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	job = run()
+	if !job.Replicated([]string{good, bad}) {
+		t.Fatalf("expected both mirrors to be copied, got %+v", job.Replicas)
+	}
+	if r, _ := job.Replica(bad); r.Attempts != 2 {
+		t.Fatalf("expected second attempt on %s, got %+v", bad, r)
+	}
+	if data, _ := os.ReadFile(r.Path); string(data) != "video-bytes" {
+		t.Fatalf("expected damaged replica to be replaced, got %q", data)
+	}
+}
+
+func TestMirrorsDeferStorageDeleteLocal(t *testing.T) {
+	tmp, playlistRun := setupMirrorRuns(t)
+	srv := objstoretest.NewServer(t, "vods")
+	target := srv.Target("playlist")
+	target.DeleteLocal = true
+	mirror := filepath.Join(tmp, "nas")
+
+	if _, err := Run(RunOptions{RunDir: playlistRun, Workers: 1, NoSubs: true, DedupMode: runstore.LinkModeHardlink, Storage: &target, Mirrors: []string{mirror}}); err != nil {
+		t.Fatalf("playlist run failed: %v", err)
+	}
+	var out model.JobsManifest
+	if err := runstore.ReadJobsManifest(filepath.Join(playlistRun, "manifest.jobs.json"), &out); err != nil {
+		t.Fatal(err)
+	}
+	job := out.Jobs[0]
+	if job.RemoteKey == "" || !job.Replicated([]string{mirror}) {
+		t.Fatalf("expected upload and mirror copy, got %+v", job)
+	}
+	if _, err := os.Stat(filepath.Join(mirror, "20240101_Shared_[shared12345].mp4")); err != nil {
+		t.Fatalf("expected mirrored media: %v", err)
+	}
+	if _, err := os.Stat(job.MediaPath); !os.IsNotExist(err) {
+		t.Fatalf("expected local copy to be deleted once mirrored and uploaded, stat err = %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/objstore"
+)
+
+// remoteStore uploads a run's completed media to the project's storage
+// target. Keys mirror the layout under the output directory.
+type remoteStore struct {
+	client    *objstore.Client
+	outputDir string
+	// deleteLocal starts as the target's DeleteLocal; Run clears it when
+	// mirrors are configured, which then delete local files themselves.
+	deleteLocal bool
+}
+
+// newRemoteStore returns nil when no storage target is configured.
+func newRemoteStore(target *objstore.Target, outputDir string) (*remoteStore, error) {
+	if target == nil {
+		return nil, nil
+	}
+	client, err := objstore.New(*target)
+	if err != nil {
+		return nil, fmt.Errorf("storage target: %w", err)
+	}
+	return &remoteStore{client: client, outputDir: outputDir, deleteLocal: target.DeleteLocal}, nil
+}
+
+func (r *remoteStore) key(localPath string) string {
+	return r.client.Target().Key(filepath.ToSlash(outputRelPath(r.outputDir, localPath)))
+}
+
+// upload stores a job's media and its sidecars (subtitles, info JSON, live
+// chat: every file next to it carrying the same [id] token). Sidecars go
+// first, so a media object in the bucket means the whole set arrived. With
+// DeleteLocal the local files are removed once all uploads succeed.
+func (r *remoteStore) upload(videoID, mediaPath string) (objstore.Object, error) {
+	if _, err := os.Stat(mediaPath); strings.TrimSpace(mediaPath) == "" || err != nil {
+		paths, err := mediaPathsByVideoID(r.outputDir)
+		if err != nil {
+			return objstore.Object{}, err
+		}
+		mediaPath = paths[videoID]
+	}
+	if mediaPath == "" {
+		return objstore.Object{}, fmt.Errorf("no local media for %s to upload", videoID)
+	}
+
+	files, err := jobSidecars(mediaPath, videoID)
+	if err != nil {
+		return objstore.Object{}, err
+	}
+	for _, f := range files {
+		if _, err := r.client.Upload(r.key(f), f); err != nil {
+			return objstore.Object{}, err
+		}
+	}
+	obj, err := r.client.Upload(r.key(mediaPath), mediaPath)
+	if err != nil {
+		return objstore.Object{}, err
+	}
+
+	if r.deleteLocal {
+		for _, f := range append(files, mediaPath) {
+			if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+				return obj, fmt.Errorf("uploaded, but could not delete local copy: %w", err)
+			}
+		}
+	}
+	return obj, nil
+}
+
+func jobSidecars(mediaPath, videoID string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(mediaPath))
+	if err != nil {
+		return nil, err
+	}
+	token := "[" + videoID + "]"
+	files := make([]string, 0, 4)
+	for _, e := range entries {
+		name := e.Name()
+		lower := strings.ToLower(name)
+		if e.IsDir() || name == filepath.Base(mediaPath) || !strings.Contains(name, token) {
+			continue
+		}
+		if strings.HasSuffix(lower, ".part") || strings.HasSuffix(lower, ".ytdl") || strings.HasSuffix(lower, ".tmp") {
+			continue
+		}
+		files = append(files, filepath.Join(filepath.Dir(mediaPath), name))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// mediaObjects lists the target and maps video IDs to their media objects,
+// recognised by the same "[id].ext" naming as local files.
+func (r *remoteStore) mediaObjects() (map[string]objstore.Object, error) {
+	prefix := r.client.Target().Key("")
+	objects, err := r.client.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]objstore.Object, len(objects))
+	for _, o := range objects {
+		name := path.Base(o.Key)
+		ext := strings.TrimPrefix(strings.ToLower(path.Ext(name)), ".")
+		if _, ok := mediaExt[ext]; !ok {
+			continue
+		}
+		if m := outputIDPattern.FindStringSubmatch(name); len(m) > 1 {
+			out[m[1]] = o
+		}
+	}
+	return out, nil
+}
+
+// reconcile is reconcileCompletedJobsWithDisk for runs archived to object
+// storage: completed jobs are checked against the bucket listing. Media still
+// only on disk (an earlier upload failed) is uploaded now; jobs found in
+// neither place are requeued.
+func (r *remoteStore) reconcile(mf *model.JobsManifest) ([]string, error) {
+	remote, err := r.mediaObjects()
+	if err != nil {
+		return nil, err
+	}
+	local, err := mediaPathsByVideoID(r.outputDir)
+	if err != nil {
+		return nil, err
+	}
+	missingIDs := make([]string, 0)
+	for i := range mf.Jobs {
+		j := &mf.Jobs[i]
+		videoID := strings.TrimSpace(j.VideoID)
+		if j.Status != model.StatusCompleted || videoID == "" {
+			continue
+		}
+		if obj, ok := remote[videoID]; ok {
+			j.RemoteKey = obj.Key
+			j.RemoteETag = obj.ETag
+		} else {
+			j.RemoteKey = ""
+			j.RemoteETag = ""
+			if local[videoID] == "" && hasRecordedMedia(j) {
+				local[videoID] = j.MediaPath
+			}
+			if localPath := local[videoID]; localPath != "" {
+				obj, err := r.upload(videoID, localPath)
+				recordUpload(j, obj, err)
+				if err != nil {
+					fmt.Printf("warn  upload of %s failed (kept locally, retried next run): %v\n", videoID, err)
+				}
+			}
+		}
+		present := j.RemoteKey != "" || local[videoID] != ""
+		if j.RemovedUpstream {
+			// Gone upstream, so requeueing cannot help; just track the copy.
+			j.LocalCopy = present
+			continue
+		}
+		if !present && !j.HeldElsewhere() {
+			if err := model.TransitionJobStatus(j, model.StatusPending, "missing_remote_media"); err != nil {
+				return nil, err
+			}
+			j.CompletedAt = ""
+			j.LastError = "previously completed but media is missing from the storage target"
+			missingIDs = append(missingIDs, videoID)
+		}
+	}
+	return missingIDs, nil
+}
+
+func recordUpload(j *model.Job, obj objstore.Object, err error) {
+	if obj.Key != "" {
+		j.RemoteKey = obj.Key
+		j.RemoteETag = obj.ETag
+	}
+	if err != nil {
+		j.LastError = truncate("upload to storage target failed: "+err.Error(), 1200)
+		return
+	}
+	j.LastError = ""
+}
@@ -0,0 +1,134 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/objstore/objstoretest"
+	"yt-vod-manager/internal/runstore"
+)
+
+func TestRunUploadsToStorageAndReconcilesAgainstIt(t *testing.T) {
+	tmp := t.TempDir()
+	fakeBin := filepath.Join(tmp, "bin")
+	if err := os.MkdirAll(fakeBin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	failing := "#!/usr/bin/env bash\necho 'unexpected download' >&2\nexit 1\n"
+	for _, name := range []string{"yt-dlp", "ffmpeg"} {
+		if err := os.WriteFile(filepath.Join(fakeBin, name), []byte(failing), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", fakeBin+":"+os.Getenv("PATH"))
+
+	// Another project holds the media, so the run links it instead of
+	// downloading, and the linked copy is what gets uploaded.
+	runsDir := filepath.Join(tmp, "runs")
+	channelRun := filepath.Join(runsDir, "channel")
+	original := filepath.Join(channelRun, "downloads", "20240101_Shared_[shared12345].mp4")
+	if err := os.MkdirAll(filepath.Dir(original), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(original, []byte("video-bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeDedupRun(t, channelRun, model.StatusCompleted)
+	if _, err := Run(RunOptions{RunDir: channelRun, Workers: 1, NoSubs: true, DedupMode: runstore.LinkModeHardlink}); err != nil {
+		t.Fatalf("channel run failed: %v", err)
+	}
+
+	srv := objstoretest.NewServer(t, "vods")
+	target := srv.Target("playlist")
+	target.DeleteLocal = true
+	playlistRun := filepath.Join(runsDir, "playlist")
+	writeDedupRun(t, playlistRun, model.StatusPending)
+	run := func() model.Job {
+		t.Helper()
+		if _, err := Run(RunOptions{RunDir: playlistRun, Workers: 1, NoSubs: true, DedupMode: runstore.LinkModeHardlink, Storage: &target}); err != nil {
+			t.Fatalf("playlist run failed: %v", err)
+		}
+		var out model.JobsManifest
+		if err := runstore.ReadJobsManifest(filepath.Join(playlistRun, "manifest.jobs.json"), &out); err != nil {
+			t.Fatal(err)
+		}
+		return out.Jobs[0]
+	}
+
+	job := run()
+	key := "playlist/20240101_Shared_[shared12345].mp4"
+	if job.Status != model.StatusCompleted || job.RemoteKey != key || job.RemoteETag == "" {
+		t.Fatalf("expected uploaded completed job, got %+v", job)
+	}
+	if data, ok := srv.Object(key); !ok || string(data) != "video-bytes" {
+		t.Fatalf("unexpected stored object %q (%v)", data, ok)
+	}
+	if _, err := os.Stat(job.MediaPath); !os.IsNotExist(err) {
+		t.Fatalf("expected local copy to be deleted after upload, stat err = %v", err)
+	}
+
+	// Missing locally but present in the bucket: nothing to redo.
+	if job = run(); job.Status != model.StatusCompleted || job.RemoteKey != key {
+		t.Fatalf("expected job to stay completed from the remote listing, got %+v", job)
+	}
+
+	// Gone from the bucket too: requeued, relinked and uploaded again.
+	srv.Remove(key)
+	job = run()
+	if job.Status != model.StatusCompleted || job.RemoteKey != key {
+		t.Fatalf("expected job to be archived again, got %+v", job)
+	}
+	if _, ok := srv.Object(key); !ok {
+		t.Fatal("expected media to be uploaded again")
+	}
+}
+
+func TestRemoteReconcileBackfillsUploadsAndKeepsTombstones(t *testing.T) {
+	srv := objstoretest.NewServer(t, "vods")
+	outputDir := t.TempDir()
+	media := filepath.Join(outputDir, "20240101_Local_[local123456].mkv")
+	sidecar := filepath.Join(outputDir, "20240101_Local_[local123456].en.vtt")
+	for _, p := range []string{media, sidecar} {
+		if err := os.WriteFile(p, []byte(filepath.Base(p)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv.Put("p/20240101_Remote_[remote12345].mp4", []byte("remote"))
+
+	target := srv.Target("p")
+	remote, err := newRemoteStore(&target, outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mf := model.JobsManifest{Jobs: []model.Job{
+		{VideoID: "local123456", Status: model.StatusCompleted},
+		{VideoID: "remote12345", Status: model.StatusCompleted},
+		{VideoID: "gone1234567", Status: model.StatusCompleted},
+		{VideoID: "tomb1234567", Status: model.StatusCompleted, RemovedUpstream: true, LocalCopy: true},
+	}}
+	missing, err := remote.reconcile(&mf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(missing) != 1 || missing[0] != "gone1234567" || mf.Jobs[2].Status != model.StatusPending {
+		t.Fatalf("expected only gone1234567 to be requeued, got %v / %+v", missing, mf.Jobs[2])
+	}
+	if mf.Jobs[0].RemoteKey != "p/20240101_Local_[local123456].mkv" {
+		t.Fatalf("expected local-only media to be uploaded, got %+v", mf.Jobs[0])
+	}
+	if _, ok := srv.Object("p/20240101_Local_[local123456].en.vtt"); !ok {
+		t.Fatal("expected sidecar to be uploaded with its media")
+	}
+	if _, err := os.Stat(media); err != nil {
+		t.Fatalf("local copy must be kept without delete_local: %v", err)
+	}
+	if mf.Jobs[1].RemoteKey != "p/20240101_Remote_[remote12345].mp4" || mf.Jobs[1].RemoteETag == "" {
+		t.Fatalf("expected remote object to be recorded, got %+v", mf.Jobs[1])
+	}
+	if tomb := mf.Jobs[3]; tomb.Status != model.StatusCompleted || tomb.LocalCopy {
+		t.Fatalf("expected tombstone to stay completed without a saved copy, got %+v", tomb)
+	}
+}
@@ -0,0 +1,258 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+	"yt-vod-manager/internal/ytdlp"
+)
+
+const (
+	RenameOutcomePlanned = "planned"
+	RenameOutcomeRenamed = "renamed"
+	RenameOutcomeSkipped = "skipped"
+	RenameOutcomeFailed  = "failed"
+)
+
+type RenameOptions struct {
+	RunID     string
+	RunDir    string
+	RunsDir   string
+	Latest    bool
+	OutputDir string
+	DryRun    bool
+	LockTTL   time.Duration
+}
+
+type RenameFile struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type RenameItem struct {
+	VideoID string       `json:"video_id"`
+	Title   string       `json:"title,omitempty"`
+	From    string       `json:"from"`
+	To      string       `json:"to,omitempty"`
+	Files   []RenameFile `json:"files,omitempty"`
+	Outcome string       `json:"outcome"`
+	Error   string       `json:"error,omitempty"`
+}
+
+type RenameResult struct {
+	RunID   string       `json:"run_id"`
+	RunDir  string       `json:"run_dir"`
+	DryRun  bool         `json:"dry_run"`
+	Checked int          `json:"checked"`
+	Planned int          `json:"planned"`
+	Renamed int          `json:"renamed"`
+	Skipped int          `json:"skipped"`
+	Failed  int          `json:"failed"`
+	Items   []RenameItem `json:"items"`
+}
+
+// Rename moves completed media and its sidecars to the name the output
+// template gives the job's current title. The upload date prefix and the
+// [id] token are kept, so the media index still finds every file.
+func Rename(opts RenameOptions) (RenameResult, error) {
+	runDir, err := resolveRunDir(RunOptions{RunID: opts.RunID, RunDir: opts.RunDir, RunsDir: opts.RunsDir, Latest: opts.Latest})
+	if err != nil {
+		return RenameResult{}, err
+	}
+	runLock, err := runstore.AcquireRunLock(runDir, opts.LockTTL)
+	if err != nil {
+		return RenameResult{}, err
+	}
+	defer func() {
+		_ = runLock.Release()
+	}()
+
+	jobsPath := filepath.Join(runDir, "manifest.jobs.json")
+	var mf model.JobsManifest
+	if err := runstore.ReadJobsManifest(jobsPath, &mf); err != nil {
+		return RenameResult{}, err
+	}
+	runMeta, _ := runstore.LoadRunMeta(runDir)
+	outputDir := firstNonEmptyString(opts.OutputDir, runMeta.OutputDir, filepath.Join(runDir, "downloads"))
+	mediaPaths, err := mediaPathsByVideoID(outputDir)
+	if err != nil {
+		return RenameResult{}, err
+	}
+	runsDir := firstNonEmptyString(strings.TrimSpace(opts.RunsDir), filepath.Dir(runDir))
+
+	res := RenameResult{
+		RunID:  firstNonEmptyString(mf.RunID, filepath.Base(runDir)),
+		RunDir: runDir,
+		DryRun: opts.DryRun,
+		Items:  []RenameItem{},
+	}
+	changed := false
+	for i := range mf.Jobs {
+		j := &mf.Jobs[i]
+		if j.Status != model.StatusCompleted || isPlaceholderTitle(j.Title) {
+			continue
+		}
+		path := j.MediaPath
+		if _, err := os.Lstat(path); strings.TrimSpace(path) == "" || err != nil {
+			path = mediaPaths[j.VideoID]
+		}
+		if path == "" {
+			continue
+		}
+		res.Checked++
+
+		item, files := planRename(*j, path)
+		if item.Outcome == RenameOutcomeSkipped {
+			res.Skipped++
+			res.Items = append(res.Items, item)
+			continue
+		}
+		if item.From == item.To {
+			continue
+		}
+		if opts.DryRun {
+			res.Planned++
+			res.Items = append(res.Items, item)
+			continue
+		}
+
+		if err := renameFiles(files); err != nil {
+			item.Outcome = RenameOutcomeFailed
+			item.Error = err.Error()
+			res.Failed++
+			res.Items = append(res.Items, item)
+			continue
+		}
+		newPath := filepath.Join(filepath.Dir(path), item.To+filepath.Base(path)[len(item.From):])
+		j.MediaPath = newPath
+		j.AppendHistory(model.JobEvent{
+			At:   time.Now().UTC().Format(time.RFC3339),
+			Kind: model.JobEventRenamed,
+			From: item.From,
+			To:   item.To,
+		})
+		relinkRenamedMedia(runsDir, j.VideoID, path, newPath)
+		item.Outcome = RenameOutcomeRenamed
+		res.Renamed++
+		res.Items = append(res.Items, item)
+		changed = true
+	}
+	if changed {
+		if err := runstore.WriteJSON(jobsPath, mf); err != nil {
+			return res, fmt.Errorf("persist jobs manifest: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// planRename works out the new stem for a job's media and lists every file in
+// its directory that shares the old stem (subtitles, info JSON, live chat).
+func planRename(j model.Job, mediaPath string) (RenameItem, []RenameFile) {
+	item := RenameItem{VideoID: j.VideoID, Title: j.Title, Outcome: RenameOutcomePlanned}
+	name := filepath.Base(mediaPath)
+	token := "_[" + j.VideoID + "]"
+	end := strings.Index(name, token)
+	date, _, ok := strings.Cut(name, "_")
+	if end <= 0 || !ok || len(date) >= end {
+		item.From = name
+		item.Outcome = RenameOutcomeSkipped
+		item.Error = "file name does not follow the output template"
+		return item, nil
+	}
+	item.From = name[:end+len(token)]
+	item.To = ytdlp.OutputStem(date, j.Title, j.VideoID)
+	if item.From == item.To {
+		return item, nil
+	}
+
+	dir := filepath.Dir(mediaPath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		item.Outcome = RenameOutcomeSkipped
+		item.Error = err.Error()
+		return item, nil
+	}
+	files := make([]RenameFile, 0, 4)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), item.From+".") {
+			continue
+		}
+		suffix := e.Name()[len(item.From):]
+		files = append(files, RenameFile{From: filepath.Join(dir, e.Name()), To: filepath.Join(dir, item.To+suffix)})
+	}
+	sort.Slice(files, func(a, b int) bool { return files[a].From < files[b].From })
+	item.Files = files
+	return item, files
+}
+
+// renameFiles moves every file or none: targets must be free, and a failure
+// part-way rolls back the moves already made.
+func renameFiles(files []RenameFile) error {
+	for _, f := range files {
+		if _, err := os.Lstat(f.To); err == nil {
+			return fmt.Errorf("target already exists: %s", f.To)
+		}
+	}
+	for i, f := range files {
+		if err := os.Rename(f.From, f.To); err != nil {
+			for k := i - 1; k >= 0; k-- {
+				_ = os.Rename(files[k].To, files[k].From)
+			}
+			return fmt.Errorf("rename %s: %w", f.From, err)
+		}
+	}
+	return nil
+}
+
+// relinkRenamedMedia keeps dedup links pointing at a renamed original: the
+// workspace media index entry moves with it, and symlinks other runs made to
+// the old path are recreated. Hardlinks and reflinks are separate names and
+// need nothing.
+func relinkRenamedMedia(runsDir, videoID, oldPath, newPath string) {
+	oldAbs, errOld := filepath.Abs(oldPath)
+	newAbs, errNew := filepath.Abs(newPath)
+	if errOld != nil || errNew != nil {
+		return
+	}
+	idx := loadMediaIndex(runsDir)
+	if e, ok := idx.Entries[videoID]; ok && filepath.Clean(e.Path) == oldAbs {
+		e.Path = newAbs
+		idx.Entries[videoID] = e
+		idx.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		_ = runstore.WriteJSON(mediaIndexPath(runsDir), idx)
+	}
+
+	runDirs, err := runstore.ListRunDirs(runsDir)
+	if err != nil {
+		return
+	}
+	for _, runDir := range runDirs {
+		var mf model.JobsManifest
+		if err := runstore.ReadJobsManifest(filepath.Join(runDir, "manifest.jobs.json"), &mf); err != nil {
+			continue
+		}
+		for _, j := range mf.Jobs {
+			if j.VideoID != videoID || j.Reason != reasonDeduplicated || strings.TrimSpace(j.MediaPath) == "" {
+				continue
+			}
+			target, err := os.Readlink(j.MediaPath)
+			if err != nil || filepath.Clean(target) != oldAbs {
+				continue
+			}
+			if err := os.Remove(j.MediaPath); err == nil {
+				_ = os.Symlink(newAbs, j.MediaPath)
+			}
+		}
+	}
+}
+
+func isPlaceholderTitle(title string) bool {
+	t := strings.TrimSpace(title)
+	return t == "" || t == "[Private video]" || t == "[Deleted video]"
+}
@@ -0,0 +1,99 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+func TestRenameMovesMediaAndSidecarsToCurrentTitle(t *testing.T) {
+	runsDir := filepath.Join(t.TempDir(), "runs")
+	runDir := filepath.Join(runsDir, "run1")
+	mediaDir := filepath.Join(runDir, "downloads", "Chan")
+	if err := os.MkdirAll(mediaDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	oldStem := "20240101_Old_Title_[vid12345678]"
+	for _, suffix := range []string{".mp4", ".en.vtt", ".info.json"} {
+		if err := os.WriteFile(filepath.Join(mediaDir, oldStem+suffix), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A different video sharing the prefix must stay untouched.
+	other := filepath.Join(mediaDir, "20240101_Old_Title_[other123456].mp4")
+	if err := os.WriteFile(other, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mf := model.JobsManifest{
+		RunID: "run1",
+		Jobs: []model.Job{{
+			JobID:     "src:1:vid12345678",
+			Index:     1,
+			VideoID:   "vid12345678",
+			Title:     "New Title: Part 2",
+			Status:    model.StatusCompleted,
+			MediaPath: filepath.Join(mediaDir, oldStem+".mp4"),
+		}},
+	}
+	if err := runstore.WriteJSON(filepath.Join(runDir, "manifest.jobs.json"), mf); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := Rename(RenameOptions{RunDir: runDir, RunsDir: runsDir, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Planned != 1 || len(plan.Items) != 1 || len(plan.Items[0].Files) != 3 {
+		t.Fatalf("expected one planned rename of 3 files, got %+v", plan)
+	}
+	if _, err := os.Stat(filepath.Join(mediaDir, oldStem+".mp4")); err != nil {
+		t.Fatalf("dry run must not touch files: %v", err)
+	}
+
+	res, err := Rename(RenameOptions{RunDir: runDir, RunsDir: runsDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Renamed != 1 {
+		t.Fatalf("expected 1 renamed, got %+v", res)
+	}
+	newStem := "20240101_New_Title_-_Part_2_[vid12345678]"
+	for _, suffix := range []string{".mp4", ".en.vtt", ".info.json"} {
+		if _, err := os.Stat(filepath.Join(mediaDir, newStem+suffix)); err != nil {
+			t.Fatalf("expected renamed %s: %v", suffix, err)
+		}
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("unrelated video was moved: %v", err)
+	}
+	paths, err := mediaPathsByVideoID(filepath.Join(runDir, "downloads"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(paths["vid12345678"]) != newStem+".mp4" {
+		t.Fatalf("expected media index to find renamed file, got %v", paths)
+	}
+
+	var after model.JobsManifest
+	if err := runstore.ReadJSON(filepath.Join(runDir, "manifest.jobs.json"), &after); err != nil {
+		t.Fatal(err)
+	}
+	j := after.Jobs[0]
+	if filepath.Base(j.MediaPath) != newStem+".mp4" {
+		t.Fatalf("expected media path updated, got %s", j.MediaPath)
+	}
+	if n := len(j.History); n == 0 || j.History[n-1].Kind != model.JobEventRenamed || j.History[n-1].From != oldStem {
+		t.Fatalf("expected renamed history event, got %+v", j.History)
+	}
+
+	again, err := Rename(RenameOptions{RunDir: runDir, RunsDir: runsDir, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Planned != 0 {
+		t.Fatalf("expected nothing left to rename, got %+v", again)
+	}
+}
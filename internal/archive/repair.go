@@ -0,0 +1,155 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+const reasonRepaired = "repaired"
+
+type RepairJobsOptions struct {
+	RunID     string
+	RunDir    string
+	RunsDir   string
+	Latest    bool
+	OutputDir string
+	DryRun    bool
+	LockTTL   time.Duration
+}
+
+type RepairJobsResult struct {
+	RunID       string `json:"run_id"`
+	RunDir      string `json:"run_dir"`
+	DryRun      bool   `json:"dry_run"`
+	FromMedia   int    `json:"completed_from_media"`
+	FromArchive int    `json:"completed_from_archive"`
+	// Created counts jobs added for media of videos the listing lacks.
+	Created int `json:"created"`
+	// Unmatched counts download-archive IDs with neither a job nor media.
+	Unmatched int `json:"unmatched"`
+}
+
+// RepairJobs restores job state after a run's manifest was rebuilt from a
+// listing. Jobs whose media is in the output dir are completed with it. Jobs
+// only listed in the run's download-archive.txt are completed without a
+// path, so the next run's reconcile finds them in object storage or requeues
+// them. Media of videos the listing no longer has gets a job, as with import.
+func RepairJobs(opts RepairJobsOptions) (RepairJobsResult, error) {
+	runDir, err := resolveRunDir(RunOptions{RunID: opts.RunID, RunDir: opts.RunDir, RunsDir: opts.RunsDir, Latest: opts.Latest})
+	if err != nil {
+		return RepairJobsResult{}, err
+	}
+	runLock, err := runstore.AcquireRunLock(runDir, opts.LockTTL)
+	if err != nil {
+		return RepairJobsResult{}, err
+	}
+	defer func() {
+		_ = runLock.Release()
+	}()
+
+	jobsPath := filepath.Join(runDir, "manifest.jobs.json")
+	var mf model.JobsManifest
+	if err := runstore.ReadJobsManifest(jobsPath, &mf); err != nil {
+		return RepairJobsResult{}, err
+	}
+	runMeta, _ := runstore.LoadRunMeta(runDir)
+	outputDir := firstNonEmptyString(opts.OutputDir, runMeta.OutputDir, filepath.Join(runDir, "downloads"))
+	media, err := mediaPathsByVideoID(outputDir)
+	if err != nil {
+		return RepairJobsResult{}, err
+	}
+	archiveFile := filepath.Join(runDir, "download-archive.txt")
+	archived, err := readDownloadArchive(archiveFile)
+	if err != nil && !os.IsNotExist(err) {
+		return RepairJobsResult{}, err
+	}
+	inArchive := make(map[string]bool, len(archived))
+	for _, id := range archived {
+		inArchive[id] = true
+	}
+
+	res := RepairJobsResult{RunID: firstNonEmptyString(mf.RunID, filepath.Base(runDir)), RunDir: runDir, DryRun: opts.DryRun}
+	now := time.Now().UTC().Format(time.RFC3339)
+	known := make(map[string]bool, len(mf.Jobs))
+	for i := range mf.Jobs {
+		j := &mf.Jobs[i]
+		id := strings.TrimSpace(j.VideoID)
+		known[id] = true
+		if id == "" || j.Status == model.StatusCompleted {
+			continue
+		}
+		path := media[id]
+		if path == "" && !inArchive[id] {
+			continue
+		}
+		if path != "" {
+			res.FromMedia++
+		} else {
+			res.FromArchive++
+		}
+		if opts.DryRun {
+			continue
+		}
+		if err := completeFromDisk(j, reasonRepaired, path, now); err != nil {
+			return res, err
+		}
+	}
+
+	orphans := make([]string, 0)
+	for id := range media {
+		if !known[id] {
+			orphans = append(orphans, id)
+		}
+	}
+	sort.Strings(orphans)
+	for _, id := range orphans {
+		res.Created++
+		if opts.DryRun {
+			continue
+		}
+		idx := len(mf.Jobs)
+		mf.Jobs = append(mf.Jobs, model.Job{
+			JobID:    fmt.Sprintf("repaired:%d:%s", idx+1, id),
+			Index:    idx + 1,
+			VideoID:  id,
+			VideoURL: "https://www.youtube.com/watch?v=" + id,
+			Title:    firstNonEmptyString(readImportInfo(media[id], id).Title, titleFromFileName(media[id], id)),
+		})
+		if err := completeFromDisk(&mf.Jobs[idx], reasonRepaired, media[id], now); err != nil {
+			return res, err
+		}
+	}
+	for _, id := range archived {
+		if !known[id] && media[id] == "" {
+			res.Unmatched++
+		}
+	}
+	if opts.DryRun || res.FromMedia+res.FromArchive+res.Created == 0 {
+		return res, nil
+	}
+
+	for id := range media {
+		if !inArchive[id] {
+			appendDownloadArchive(archiveFile, id)
+		}
+	}
+	recomputeCounts(&mf)
+	if err := runstore.WriteJSON(jobsPath, mf); err != nil {
+		return res, fmt.Errorf("persist jobs manifest: %w", err)
+	}
+	if err := saveRunMetaSnapshot(runDir, mf, outputDir); err != nil {
+		return res, err
+	}
+	runsDir := firstNonEmptyString(strings.TrimSpace(opts.RunsDir), filepath.Dir(runDir))
+	if err := updateMediaIndex(runsDir, mf.RunID, outputDir, mf.Jobs); err != nil {
+		fmt.Printf("warn  media index update failed (non-fatal): %v\n", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,79 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+func TestRepairJobsCompletesFromMediaAndDownloadArchive(t *testing.T) {
+	runsDir := filepath.Join(t.TempDir(), "runs")
+	runDir := filepath.Join(runsDir, "run1")
+	mediaDir := filepath.Join(runDir, "downloads", "Chan")
+	if err := os.MkdirAll(mediaDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"20240101_A_[aaaaaaaaaaa].mp4", "20240102_Gone_Video_[ggggggggggg].mkv"} {
+		if err := os.WriteFile(filepath.Join(mediaDir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	archivePath := filepath.Join(runDir, "download-archive.txt")
+	if err := os.WriteFile(archivePath, []byte("youtube aaaaaaaaaaa\nyoutube bbbbbbbbbbb\nyoutube zzzzzzzzzzz\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A manifest rebuilt from a listing: every job pending.
+	mf := model.JobsManifest{
+		RunID: "run1",
+		Jobs: []model.Job{
+			{JobID: "src:1:aaaaaaaaaaa", Index: 1, VideoID: "aaaaaaaaaaa", Title: "A", Status: model.StatusPending},
+			{JobID: "src:2:bbbbbbbbbbb", Index: 2, VideoID: "bbbbbbbbbbb", Title: "B", Status: model.StatusPending},
+			{JobID: "src:3:ccccccccccc", Index: 3, VideoID: "ccccccccccc", Title: "C", Status: model.StatusSkippedPrivate, Reason: "private_or_unavailable"},
+		},
+	}
+	if err := runstore.WriteJSON(filepath.Join(runDir, "manifest.jobs.json"), mf); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := RepairJobs(RepairJobsOptions{RunDir: runDir, RunsDir: runsDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.FromMedia != 1 || res.FromArchive != 1 || res.Created != 1 || res.Unmatched != 1 {
+		t.Fatalf("unexpected repair result: %+v", res)
+	}
+	jobs := readImportJobs(t, runDir)
+	if a := jobs["aaaaaaaaaaa"]; a.Status != model.StatusCompleted || !strings.HasSuffix(a.MediaPath, "20240101_A_[aaaaaaaaaaa].mp4") {
+		t.Fatalf("expected a completed from disk, got %+v", a)
+	}
+	if b := jobs["bbbbbbbbbbb"]; b.Status != model.StatusCompleted || b.MediaPath != "" || b.HeldElsewhere() {
+		t.Fatalf("expected b completed from the archive and checked by the next run, got %+v", b)
+	}
+	if g := jobs["ggggggggggg"]; g.Status != model.StatusCompleted || g.Title != "20240102_Gone_Video" {
+		t.Fatalf("expected a job for unlisted media, got %+v", g)
+	}
+	if c := jobs["ccccccccccc"]; c.Status != model.StatusSkippedPrivate {
+		t.Fatalf("unrelated job changed: %+v", c)
+	}
+	data, err := os.ReadFile(archivePath)
+	if err != nil || strings.Count(string(data), "aaaaaaaaaaa") != 1 || !strings.Contains(string(data), "youtube ggggggggggg") {
+		t.Fatalf("unexpected download archive %q: %v", data, err)
+	}
+
+	// The next run requeues the archive-only job whose media is really gone.
+	var after model.JobsManifest
+	if err := runstore.ReadJobsManifest(filepath.Join(runDir, "manifest.jobs.json"), &after); err != nil {
+		t.Fatal(err)
+	}
+	requeued, err := reconcileCompletedJobsWithDisk(&after, filepath.Join(runDir, "downloads"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requeued) != 1 || requeued[0] != "bbbbbbbbbbb" {
+		t.Fatalf("expected only b requeued, got %v", requeued)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"slices"
 	"strings"
 	"sync"
@@ -12,6 +11,7 @@ import (
 	"time"
 
 	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/objstore"
 	"yt-vod-manager/internal/runstore"
 	"yt-vod-manager/internal/ytdlp"
 )
@@ -32,14 +32,31 @@ type RunOptions struct {
 	ProxyMode          string
 	Proxies            []string
 	NoSubs             bool
+	LiveChat           bool
+	LiveChatFormat     string
 	RetryPermanent     bool
 	StopOnRetryable    bool
 	Progress           bool
 	RawOutput          bool
 	Order              string
 	Quality            string
+	VideoCodec         string
+	HDR                string
+	Container          string
 	JSRuntime          string
 	DeliveryMode       string
+	AudioOnly          bool
+	WriteThumbnails    bool
+	DedupMode          string
+	LockTTL            time.Duration
+	// FailureRules are checked before the built-in failure classifier rules.
+	FailureRules []model.FailureRule
+	// Storage, when set, receives completed media and sidecars after each job.
+	Storage *objstore.Target
+	// Mirrors are directories each completed download is copied to.
+	Mirrors []string
+	// Stop, when closed, keeps new jobs from starting; in-flight downloads finish.
+	Stop <-chan struct{}
 }
 
 type RunResult struct {
@@ -54,6 +71,9 @@ type RunResult struct {
 	Remaining              int
 	EstimatedTotalBytes    int64
 	EstimatedCompleteBytes int64
+	DownloadedBytes        int64
+	DownloadSeconds        []float64
+	ErrorClasses           map[string]int
 }
 
 func Run(opts RunOptions) (RunResult, error) {
@@ -61,7 +81,7 @@ func Run(opts RunOptions) (RunResult, error) {
 	if err != nil {
 		return RunResult{}, err
 	}
-	runLock, err := runstore.AcquireRunLock(runDir)
+	runLock, err := runstore.AcquireRunLock(runDir, opts.LockTTL)
 	if err != nil {
 		return RunResult{}, err
 	}
@@ -77,10 +97,17 @@ func Run(opts RunOptions) (RunResult, error) {
 		return RunResult{}, err
 	}
 	opts.JSRuntime = effectiveJSRuntime
+	if err := ytdlp.CheckFormatOptions(opts.Quality, opts.VideoCodec, opts.HDR, opts.Container); err != nil {
+		return RunResult{}, err
+	}
+	classifier, err := newFailureClassifier(opts.FailureRules)
+	if err != nil {
+		return RunResult{}, err
+	}
 
 	jobsPath := filepath.Join(runDir, "manifest.jobs.json")
 	var mf model.JobsManifest
-	if err := runstore.ReadJSON(jobsPath, &mf); err != nil {
+	if err := runstore.ReadJobsManifest(jobsPath, &mf); err != nil {
 		return RunResult{}, err
 	}
 	if mf.RunID == "" {
@@ -106,11 +133,20 @@ func Run(opts RunOptions) (RunResult, error) {
 		return RunResult{}, err
 	}
 	archiveFile := filepath.Join(runDir, "download-archive.txt")
-	missingIDs, err := reconcileCompletedJobsWithDisk(&mf, outputDir)
+	remote, err := newRemoteStore(opts.Storage, outputDir)
+	if err != nil {
+		return RunResult{}, err
+	}
+	var missingIDs []string
+	if remote != nil {
+		missingIDs, err = remote.reconcile(&mf)
+	} else {
+		missingIDs, err = reconcileCompletedJobsWithDisk(&mf, outputDir)
+	}
 	if err != nil {
 		return RunResult{}, err
 	}
-	if len(missingIDs) > 0 {
+	if len(missingIDs) > 0 || remote != nil {
 		if _, err := pruneDownloadArchive(archiveFile, missingIDs); err != nil {
 			return RunResult{}, err
 		}
@@ -127,6 +163,8 @@ func Run(opts RunOptions) (RunResult, error) {
 	if err := runstore.Mkdir(logsDir); err != nil {
 		return RunResult{}, err
 	}
+	runsDir := filepath.Dir(runDir)
+	mediaIdx := loadMediaIndex(runsDir)
 
 	fragments := opts.Fragments
 	if fragments <= 0 {
@@ -168,6 +206,14 @@ func Run(opts RunOptions) (RunResult, error) {
 	var logMu sync.Mutex
 	var wg sync.WaitGroup
 	var fatalErr atomic.Value
+	mirrors := newMirrorSet(opts.Mirrors, outputDir, jobsPath, &mf, &stateMu, &logMu)
+	if mirrors != nil {
+		if remote != nil {
+			mirrors.deleteLocal, remote.deleteLocal = remote.deleteLocal, false
+		}
+		mirrors.start(opts.Stop)
+	}
+	stats := newRunStats()
 	setFatal := func(err error) {
 		if err == nil {
 			return
@@ -182,7 +228,7 @@ func Run(opts RunOptions) (RunResult, error) {
 		defer wg.Done()
 		workerProxy := proxyForWorker(workerID, proxyMode, proxies)
 		for i := range jobCh {
-			if stopAll.Load() {
+			if stopAll.Load() || stopRequested(opts.Stop) {
 				continue
 			}
 			if opts.StopOnRetryable && stopRetryable.Load() {
@@ -205,6 +251,7 @@ func Run(opts RunOptions) (RunResult, error) {
 				job.LastError = "video URL missing in manifest"
 				job.Attempts++
 				job.LastAttemptAt = time.Now().UTC().Format(time.RFC3339)
+				recordAttempt(job, attempt{start: time.Now(), worker: workerID, proxy: workerProxy, class: errorClassLocal}, job.LastError)
 				recomputeCounts(&mf)
 				if err := runstore.WriteJSON(jobsPath, mf); err != nil {
 					stateMu.Unlock()
@@ -273,8 +320,9 @@ func Run(opts RunOptions) (RunResult, error) {
 				logMu.Unlock()
 			}
 
-			logPath := filepath.Join(logsDir, fmt.Sprintf("%04d_%s.log", jobIndex, safeFileID(videoID, jobIndex)))
-			logFile, err := os.Create(logPath)
+			logName := jobLogName(mf.Jobs[i])
+			att := attempt{start: time.Now(), worker: workerID, proxy: workerProxy, log: filepath.Join("logs", logName)}
+			logFile, err := os.Create(filepath.Join(logsDir, logName))
 			if err != nil {
 				stateMu.Lock()
 				j := &mf.Jobs[i]
@@ -285,6 +333,8 @@ func Run(opts RunOptions) (RunResult, error) {
 					continue
 				}
 				j.LastError = err.Error()
+				att.class = errorClassLocal
+				recordAttempt(j, att, j.LastError)
 				recomputeCounts(&mf)
 				if err := runstore.WriteJSON(jobsPath, mf); err != nil {
 					stateMu.Unlock()
@@ -297,24 +347,39 @@ func Run(opts RunOptions) (RunResult, error) {
 				continue
 			}
 
-			_, dlErr := ytdlp.DownloadVideo(ytdlp.DownloadOptions{
-				VideoURL:           videoURL,
-				OutputDir:          outputDir,
-				Fragments:          fragments,
-				DownloadArchive:    archiveFile,
-				CookiesPath:        opts.CookiesPath,
-				CookiesFromBrowser: opts.CookiesFromBrowser,
-				Quality:            opts.Quality,
-				DeliveryMode:       opts.DeliveryMode,
-				DownloadLimitMBps:  opts.DownloadLimitMBps,
-				ProxyURL:           workerProxy,
-				Stdout:             os.Stdout,
-				Stderr:             os.Stderr,
-				LogWriter:          logFile,
-				EchoOutput:         opts.RawOutput && !dashboardEnabled,
-				Progress:           progress.Handle,
-				JSRuntime:          opts.JSRuntime,
-			})
+			var dlRes ytdlp.DownloadResult
+			var dlErr error
+			completedReason := ""
+			if linked, ok := linkFromMediaIndex(mediaIdx, opts.DedupMode, videoID, outputDir); ok {
+				progress.SetPhase("linked")
+				dlRes.Media = linked
+				completedReason = reasonDeduplicated
+				appendDownloadArchive(archiveFile, videoID)
+			} else {
+				dlRes, dlErr = ytdlp.DownloadVideo(ytdlp.DownloadOptions{
+					VideoURL:           videoURL,
+					OutputDir:          outputDir,
+					Fragments:          fragments,
+					DownloadArchive:    archiveFile,
+					CookiesPath:        opts.CookiesPath,
+					CookiesFromBrowser: opts.CookiesFromBrowser,
+					Quality:            opts.Quality,
+					VideoCodec:         opts.VideoCodec,
+					HDR:                opts.HDR,
+					Container:          opts.Container,
+					DeliveryMode:       opts.DeliveryMode,
+					DownloadLimitMBps:  opts.DownloadLimitMBps,
+					ProxyURL:           workerProxy,
+					Stdout:             os.Stdout,
+					Stderr:             os.Stderr,
+					LogWriter:          logFile,
+					EchoOutput:         opts.RawOutput && !dashboardEnabled,
+					Progress:           progress.Handle,
+					JSRuntime:          opts.JSRuntime,
+					AudioOnly:          opts.AudioOnly,
+					WriteThumbnails:    opts.WriteThumbnails,
+				})
+			}
 
 			processed.Add(1)
 			shouldStop := false
@@ -322,7 +387,7 @@ func Run(opts RunOptions) (RunResult, error) {
 			stateMu.Lock()
 			j := &mf.Jobs[i]
 			if dlErr == nil {
-				if err := model.TransitionJobStatus(j, model.StatusCompleted, ""); err != nil {
+				if err := model.TransitionJobStatus(j, model.StatusCompleted, completedReason); err != nil {
 					stateMu.Unlock()
 					setFatal(err)
 					_ = logFile.Close()
@@ -330,30 +395,37 @@ func Run(opts RunOptions) (RunResult, error) {
 				}
 				j.LastError = ""
 				j.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+				recordJobMedia(j, dlRes.Media)
+				if completedReason == "" {
+					stats.recordDownload(time.Since(att.start), j.MediaPath)
+				}
+				sidecarOpts := ytdlp.DownloadOptions{
+					VideoURL:           videoURL,
+					OutputDir:          outputDir,
+					CookiesPath:        opts.CookiesPath,
+					CookiesFromBrowser: opts.CookiesFromBrowser,
+					DeliveryMode:       opts.DeliveryMode,
+					DownloadLimitMBps:  opts.DownloadLimitMBps,
+					ProxyURL:           workerProxy,
+					Stdout:             os.Stdout,
+					Stderr:             os.Stderr,
+					LogWriter:          logFile,
+					EchoOutput:         opts.RawOutput && !dashboardEnabled,
+					Progress:           progress.Handle,
+					JSRuntime:          opts.JSRuntime,
+				}
 				if !opts.NoSubs {
 					progress.SetPhase("subtitles")
-					_, subErr := ytdlp.DownloadSubtitles(ytdlp.DownloadOptions{
-						VideoURL:           videoURL,
-						OutputDir:          outputDir,
-						CookiesPath:        opts.CookiesPath,
-						CookiesFromBrowser: opts.CookiesFromBrowser,
-						DeliveryMode:       opts.DeliveryMode,
-						SubLangs:           opts.SubLangs,
-						DownloadLimitMBps:  opts.DownloadLimitMBps,
-						ProxyURL:           workerProxy,
-						Stdout:             os.Stdout,
-						Stderr:             os.Stderr,
-						LogWriter:          logFile,
-						EchoOutput:         opts.RawOutput && !dashboardEnabled,
-						Progress:           progress.Handle,
-						JSRuntime:          opts.JSRuntime,
-					})
+					subOpts := sidecarOpts
+					subOpts.SubLangs = opts.SubLangs
+					_, subErr := ytdlp.DownloadSubtitles(subOpts)
 					if subErr != nil {
 						logMu.Lock()
 						fmt.Printf("[%d/%d] warn  subtitles failed for %s (non-fatal)\n", jobIndex, mf.Total, videoID)
 						logMu.Unlock()
 					}
 				}
+				recordAttempt(j, att, "")
 				recomputeCounts(&mf)
 				if err := runstore.WriteJSON(jobsPath, mf); err != nil {
 					stateMu.Unlock()
@@ -367,7 +439,37 @@ func Run(opts RunOptions) (RunResult, error) {
 						dash.SetSizeEstimate(sizeEstimator.completedBytes(mf.Jobs), sizeEstimator.totalBytes)
 					}
 				}
+				mediaPath := j.MediaPath
 				stateMu.Unlock()
+				// The chat replay can take as long as the video; fetch it
+				// without holding up the other workers.
+				if opts.LiveChat {
+					progress.SetPhase("live chat")
+					if chatErr := archiveLiveChatReplay(sidecarOpts, videoID, opts.LiveChatFormat); chatErr != nil {
+						logMu.Lock()
+						fmt.Printf("[%d/%d] warn  live chat failed for %s (non-fatal)\n", jobIndex, mf.Total, videoID)
+						logMu.Unlock()
+					}
+				}
+				if remote != nil {
+					progress.SetPhase("uploading")
+					obj, upErr := remote.upload(videoID, mediaPath)
+					stateMu.Lock()
+					recordUpload(&mf.Jobs[i], obj, upErr)
+					err := runstore.WriteJSON(jobsPath, mf)
+					stateMu.Unlock()
+					if err != nil {
+						setFatal(fmt.Errorf("persist jobs manifest: %w", err))
+					}
+					if upErr != nil {
+						logMu.Lock()
+						fmt.Printf("[%d/%d] warn  upload failed for %s (kept locally, retried next run): %v\n", jobIndex, mf.Total, videoID, upErr)
+						logMu.Unlock()
+					}
+				}
+				if mirrors != nil {
+					mirrors.enqueue(i)
+				}
 				doneMsg := fmt.Sprintf("[%d/%d] done  %s", jobIndex, mf.Total, videoID)
 				if progressEnabled {
 					progress.Stop(doneMsg)
@@ -378,29 +480,21 @@ func Run(opts RunOptions) (RunResult, error) {
 			} else {
 				j.CompletedAt = ""
 				j.LastError = truncate(dlErr.Error(), 1200)
-				if isDependencyError(dlErr.Error()) {
-					if err := model.TransitionJobStatus(j, model.StatusFailedPermanent, "missing_dependency"); err != nil {
-						stateMu.Unlock()
-						setFatal(err)
-						_ = logFile.Close()
-						continue
-					}
-				} else if isRetryableError(dlErr.Error()) {
-					if err := model.TransitionJobStatus(j, model.StatusFailedRetryable, "transient_or_rate_limited"); err != nil {
-						stateMu.Unlock()
-						setFatal(err)
-						_ = logFile.Close()
-						continue
-					}
+				failure := classifier.classify(dlErr.Error())
+				toStatus := model.StatusFailedPermanent
+				if failure.Retryable {
+					toStatus = model.StatusFailedRetryable
 					shouldStop = opts.StopOnRetryable
-				} else {
-					if err := model.TransitionJobStatus(j, model.StatusFailedPermanent, "download_error"); err != nil {
-						stateMu.Unlock()
-						setFatal(err)
-						_ = logFile.Close()
-						continue
-					}
 				}
+				if err := model.TransitionJobStatus(j, toStatus, failure.Reason); err != nil {
+					stateMu.Unlock()
+					setFatal(err)
+					_ = logFile.Close()
+					continue
+				}
+				att.class = failure.Class
+				recordAttempt(j, att, dlErr.Error())
+				stats.recordFailure(failure.Class)
 				recomputeCounts(&mf)
 				if err := runstore.WriteJSON(jobsPath, mf); err != nil {
 					stateMu.Unlock()
@@ -416,13 +510,13 @@ func Run(opts RunOptions) (RunResult, error) {
 				}
 				stateMu.Unlock()
 
-				failMsg := ""
-				if isDependencyError(dlErr.Error()) {
-					failMsg = fmt.Sprintf("[%d/%d] fail  %s (dependency)", jobIndex, mf.Total, videoID)
-				} else if isRetryableError(dlErr.Error()) {
-					failMsg = fmt.Sprintf("[%d/%d] fail  %s (retryable)", jobIndex, mf.Total, videoID)
-				} else {
-					failMsg = fmt.Sprintf("[%d/%d] fail  %s (permanent)", jobIndex, mf.Total, videoID)
+				kind := "permanent"
+				if failure.Retryable {
+					kind = "retryable"
+				}
+				failMsg := fmt.Sprintf("[%d/%d] fail  %s (%s, %s)", jobIndex, mf.Total, videoID, failure.Reason, kind)
+				if failure.Remediation != "" {
+					failMsg += ": " + failure.Remediation
 				}
 				if progressEnabled {
 					progress.Stop(failMsg)
@@ -449,7 +543,7 @@ func Run(opts RunOptions) (RunResult, error) {
 
 	dispatched := 0
 	for _, i := range orderIdx {
-		if stopAll.Load() {
+		if stopAll.Load() || stopRequested(opts.Stop) {
 			break
 		}
 		if opts.MaxJobs > 0 && dispatched >= opts.MaxJobs {
@@ -469,6 +563,9 @@ func Run(opts RunOptions) (RunResult, error) {
 	}
 	close(jobCh)
 	wg.Wait()
+	if mirrors != nil {
+		mirrors.wait()
+	}
 	if msg := fatalErr.Load(); msg != nil {
 		return RunResult{}, fmt.Errorf("%s", msg.(string))
 	}
@@ -483,6 +580,9 @@ func Run(opts RunOptions) (RunResult, error) {
 		stateMu.Unlock()
 		return RunResult{}, err
 	}
+	if err := updateMediaIndex(runsDir, mf.RunID, outputDir, mf.Jobs); err != nil {
+		fmt.Printf("warn  media index update failed (non-fatal): %v\n", err)
+	}
 	remaining := mf.Pending + mf.FailedRetryable + mf.Running
 	result := RunResult{
 		RunID:                  mf.RunID,
@@ -498,6 +598,7 @@ func Run(opts RunOptions) (RunResult, error) {
 		EstimatedCompleteBytes: sizeEstimator.completedBytes(mf.Jobs),
 	}
 	stateMu.Unlock()
+	stats.apply(&result)
 	return result, nil
 }
 
@@ -572,45 +673,16 @@ func resetStaleRunningJobs(mf *model.JobsManifest) {
 		if mf.Jobs[i].LastError == "" {
 			mf.Jobs[i].LastError = "previous run interrupted while this job was running"
 		}
+		mf.Jobs[i].AppendAttempt(model.JobEvent{
+			At:         mf.Jobs[i].LastAttemptAt,
+			Outcome:    mf.Jobs[i].Status,
+			Reason:     mf.Jobs[i].Reason,
+			ErrorClass: errorClassInterrupted,
+			Error:      mf.Jobs[i].LastError,
+		})
 	}
 }
 
-func isRetryableError(s string) bool {
-	text := strings.ToLower(s)
-	hints := []string{
-		"429",
-		"too many requests",
-		"rate limit",
-		"timed out",
-		"timeout",
-		"temporarily unavailable",
-		"connection reset",
-		"service unavailable",
-		"network is unreachable",
-		"http error 5",
-	}
-	for _, h := range hints {
-		if strings.Contains(text, h) {
-			return true
-		}
-	}
-	return false
-}
-
-func isDependencyError(s string) bool {
-	text := strings.ToLower(s)
-	hints := []string{
-		"ffmpeg could not be found",
-		"ffprobe could not be found",
-	}
-	for _, h := range hints {
-		if strings.Contains(text, h) {
-			return true
-		}
-	}
-	return false
-}
-
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
@@ -708,7 +780,13 @@ func reconcileCompletedJobsWithDisk(mf *model.JobsManifest, outputDir string) ([
 		if videoID == "" {
 			continue
 		}
-		if !present[videoID] {
+		onDisk := present[videoID] || hasRecordedMedia(j)
+		if j.RemovedUpstream {
+			// Gone upstream, so requeueing cannot help; just track the copy.
+			j.LocalCopy = onDisk
+			continue
+		}
+		if !onDisk && !j.HeldElsewhere() {
 			if err := model.TransitionJobStatus(j, model.StatusPending, "missing_local_media"); err != nil {
 				return nil, err
 			}
@@ -720,48 +798,6 @@ func reconcileCompletedJobsWithDisk(mf *model.JobsManifest, outputDir string) ([
 	return missingIDs, nil
 }
 
-var outputIDPattern = regexp.MustCompile(`\[([A-Za-z0-9_-]{6,})\]\.[^.]+$`)
-
-func indexMediaByVideoID(root string) (map[string]bool, error) {
-	ids := make(map[string]bool)
-	if strings.TrimSpace(root) == "" {
-		return ids, nil
-	}
-	mediaExt := map[string]struct{}{
-		"mp4": {}, "mkv": {}, "webm": {}, "m4v": {},
-		"mov": {}, "avi": {}, "flv": {}, "ts": {}, "m4a": {}, "mp3": {},
-	}
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
-		}
-		name := d.Name()
-		lower := strings.ToLower(name)
-		if strings.HasSuffix(lower, ".part") || strings.HasSuffix(lower, ".ytdl") || strings.HasSuffix(lower, ".tmp") {
-			return nil
-		}
-		ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
-		if _, ok := mediaExt[ext]; !ok {
-			return nil
-		}
-		m := outputIDPattern.FindStringSubmatch(name)
-		if len(m) > 1 {
-			ids[m[1]] = true
-		}
-		return nil
-	})
-	if err != nil {
-		if os.IsNotExist(err) {
-			return ids, nil
-		}
-		return nil, err
-	}
-	return ids, nil
-}
-
 func pruneDownloadArchive(path string, videoIDs []string) (int, error) {
 	if len(videoIDs) == 0 {
 		return 0, nil
@@ -812,3 +848,15 @@ func pruneDownloadArchive(path string, videoIDs []string) (int, error) {
 	}
 	return removed, nil
 }
+
+func stopRequested(stop <-chan struct{}) bool {
+	if stop == nil {
+		return false
+	}
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
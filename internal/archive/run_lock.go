@@ -0,0 +1,62 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"yt-vod-manager/internal/runstore"
+)
+
+// ErrRunLocked matches errors from runs that could not start because another
+// process holds the run directory lock.
+var ErrRunLocked = runstore.ErrRunLocked
+
+type RunLockInfo = runstore.RunLockInfo
+
+type UnlockOptions struct {
+	RunID   string
+	RunDir  string
+	RunsDir string
+	Latest  bool
+	LockTTL time.Duration
+	// Force removes the lock even when its owner still looks alive.
+	Force bool
+}
+
+type UnlockResult struct {
+	Lock    RunLockInfo `json:"lock"`
+	Removed bool        `json:"removed"`
+}
+
+func IsRunLocked(err error) bool {
+	return errors.Is(err, ErrRunLocked)
+}
+
+func InspectRunLock(opts UnlockOptions) (RunLockInfo, error) {
+	runDir, err := resolveRunDir(RunOptions{RunID: opts.RunID, RunDir: opts.RunDir, RunsDir: opts.RunsDir, Latest: opts.Latest})
+	if err != nil {
+		return RunLockInfo{}, err
+	}
+	return runstore.InspectRunLock(runDir, opts.LockTTL)
+}
+
+// Unlock removes a stale run lock, or any lock when Force is set.
+func Unlock(opts UnlockOptions) (UnlockResult, error) {
+	info, err := InspectRunLock(opts)
+	if err != nil {
+		return UnlockResult{}, err
+	}
+	res := UnlockResult{Lock: info}
+	if !info.Locked {
+		return res, nil
+	}
+	if !info.Stale && !opts.Force {
+		return res, fmt.Errorf("run lock for %s is held by a live owner (pid=%d host=%s); use --force to remove it anyway", info.RunDir, info.PID, info.Hostname)
+	}
+	if err := runstore.RemoveRunLock(info.RunDir); err != nil {
+		return res, err
+	}
+	res.Removed = true
+	return res, nil
+}
@@ -47,7 +47,7 @@ exit 0
 		t.Fatal(err)
 	}
 
-	lock, err := runstore.AcquireRunLock(runDir)
+	lock, err := runstore.AcquireRunLock(runDir, 0)
 	if err != nil {
 		t.Fatalf("acquire lock: %v", err)
 	}
@@ -67,3 +67,27 @@ exit 0
 		t.Fatalf("expected lock error, got %v", err)
 	}
 }
+
+func TestUnlockRequiresForceForLiveOwner(t *testing.T) {
+	runDir := t.TempDir()
+	lock, err := runstore.AcquireRunLock(runDir, 0)
+	if err != nil {
+		t.Fatalf("acquire lock: %v", err)
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+
+	res, err := Unlock(UnlockOptions{RunDir: runDir})
+	if err == nil || res.Removed || !res.Lock.Locked || !res.Lock.OwnerAlive {
+		t.Fatalf("expected live lock to be kept, got %+v err=%v", res, err)
+	}
+	res, err = Unlock(UnlockOptions{RunDir: runDir, Force: true})
+	if err != nil || !res.Removed {
+		t.Fatalf("expected forced unlock, got %+v err=%v", res, err)
+	}
+	res, err = Unlock(UnlockOptions{RunDir: runDir})
+	if err != nil || res.Lock.Locked {
+		t.Fatalf("expected no lock after forced unlock, got %+v err=%v", res, err)
+	}
+}
@@ -0,0 +1,49 @@
+package archive
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// runStats collects per-attempt numbers for metrics while workers run.
+type runStats struct {
+	mu              sync.Mutex
+	downloadedBytes int64
+	downloadSeconds []float64
+	errorClasses    map[string]int
+}
+
+func newRunStats() *runStats {
+	return &runStats{errorClasses: map[string]int{}}
+}
+
+func (s *runStats) recordDownload(elapsed time.Duration, mediaPath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.downloadSeconds = append(s.downloadSeconds, elapsed.Seconds())
+	if strings.TrimSpace(mediaPath) == "" {
+		return
+	}
+	if st, err := os.Stat(mediaPath); err == nil {
+		s.downloadedBytes += st.Size()
+	}
+}
+
+func (s *runStats) recordFailure(class string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errorClasses[firstNonEmptyString(class, errorClassUnknown)]++
+}
+
+func (s *runStats) apply(res *RunResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res.DownloadedBytes = s.downloadedBytes
+	res.DownloadSeconds = append([]float64(nil), s.downloadSeconds...)
+	res.ErrorClasses = make(map[string]int, len(s.errorClasses))
+	for k, v := range s.errorClasses {
+		res.ErrorClasses[k] = v
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/ytdlp"
 )
 
 type runSizeEstimator struct {
@@ -108,12 +109,18 @@ func estimateBytesFromDuration(durationSec float64, quality string) int64 {
 }
 
 func estimateMbpsForQuality(rawQuality string) float64 {
-	q := strings.ToLower(strings.TrimSpace(rawQuality))
+	q, _ := ytdlp.NormalizeQuality(rawQuality)
 	switch q {
-	case "720p", "720", "sd", "small":
-		return 3.5
-	case "1080p", "1080", "hd":
+	case "2160p":
+		return 20.0
+	case "1440p":
+		return 10.0
+	case "1080p":
 		return 6.0
+	case "720p":
+		return 3.5
+	case "480p":
+		return 1.5
 	default:
 		return 8.0
 	}
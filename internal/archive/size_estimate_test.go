@@ -55,6 +55,12 @@ func TestEstimateMbpsForQuality(t *testing.T) {
 	if got := estimateMbpsForQuality("1080p"); got != 6.0 {
 		t.Fatalf("1080p mbps = %v, want 6.0", got)
 	}
+	if got := estimateMbpsForQuality("4k"); got != 20.0 {
+		t.Fatalf("2160p mbps = %v, want 20.0", got)
+	}
+	if got := estimateMbpsForQuality("480p"); got != 1.5 {
+		t.Fatalf("480p mbps = %v, want 1.5", got)
+	}
 	if got := estimateMbpsForQuality("best"); got != 8.0 {
 		t.Fatalf("best mbps = %v, want 8.0", got)
 	}
@@ -0,0 +1,297 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+	"yt-vod-manager/internal/ytdlp"
+)
+
+const (
+	UpgradeOutcomeCandidate = "candidate"
+	UpgradeOutcomeUpgraded  = "upgraded"
+	UpgradeOutcomeUpToDate  = "up_to_date"
+	UpgradeOutcomeNotBetter = "not_better"
+	UpgradeOutcomeFailed    = "failed"
+)
+
+type UpgradeOptions struct {
+	RunID              string
+	RunDir             string
+	RunsDir            string
+	Latest             bool
+	OutputDir          string
+	CookiesPath        string
+	CookiesFromBrowser string
+	Fragments          int
+	MaxJobs            int
+	DownloadLimitMBps  float64
+	ProxyMode          string
+	Proxies            []string
+	Quality            string
+	VideoCodec         string
+	HDR                string
+	Container          string
+	JSRuntime          string
+	DeliveryMode       string
+	RawOutput          bool
+	DryRun             bool
+	LockTTL            time.Duration
+	// AudioOnly marks runs downloaded audio-only; their media has no video
+	// to upgrade, so every job is skipped.
+	AudioOnly bool
+}
+
+type UpgradeItem struct {
+	VideoID string `json:"video_id"`
+	Title   string `json:"title,omitempty"`
+	From    string `json:"from"`
+	To      string `json:"to,omitempty"`
+	Outcome string `json:"outcome"`
+	Error   string `json:"error,omitempty"`
+}
+
+type UpgradeResult struct {
+	RunID      string        `json:"run_id"`
+	RunDir     string        `json:"run_dir"`
+	DryRun     bool          `json:"dry_run"`
+	Checked    int           `json:"checked"`
+	Candidates int           `json:"candidates"`
+	Upgraded   int           `json:"upgraded"`
+	NotBetter  int           `json:"not_better"`
+	Failed     int           `json:"failed"`
+	Items      []UpgradeItem `json:"items"`
+}
+
+func Upgrade(opts UpgradeOptions) (UpgradeResult, error) {
+	runDir, err := resolveRunDir(RunOptions{RunID: opts.RunID, RunDir: opts.RunDir, RunsDir: opts.RunsDir, Latest: opts.Latest})
+	if err != nil {
+		return UpgradeResult{}, err
+	}
+	runLock, err := runstore.AcquireRunLock(runDir, opts.LockTTL)
+	if err != nil {
+		return UpgradeResult{}, err
+	}
+	defer func() {
+		_ = runLock.Release()
+	}()
+
+	if err := ytdlp.CheckDependencies(); err != nil {
+		return UpgradeResult{}, err
+	}
+	effectiveJSRuntime, err := ytdlp.CheckJSRuntime(opts.JSRuntime)
+	if err != nil {
+		return UpgradeResult{}, err
+	}
+	opts.JSRuntime = effectiveJSRuntime
+	if err := ytdlp.CheckFormatOptions(opts.Quality, opts.VideoCodec, opts.HDR, opts.Container); err != nil {
+		return UpgradeResult{}, err
+	}
+
+	jobsPath := filepath.Join(runDir, "manifest.jobs.json")
+	var mf model.JobsManifest
+	if err := runstore.ReadJobsManifest(jobsPath, &mf); err != nil {
+		return UpgradeResult{}, err
+	}
+	if mf.RunID == "" {
+		mf.RunID = filepath.Base(runDir)
+	}
+	runMeta, _ := runstore.LoadRunMeta(runDir)
+	outputDir := firstNonEmptyString(opts.OutputDir, runMeta.OutputDir, filepath.Join(runDir, "downloads"))
+	mediaPaths, err := mediaPathsByVideoID(outputDir)
+	if err != nil {
+		return UpgradeResult{}, err
+	}
+	logsDir := filepath.Join(runDir, "logs")
+	if err := runstore.Mkdir(logsDir); err != nil {
+		return UpgradeResult{}, err
+	}
+
+	res := UpgradeResult{RunID: mf.RunID, RunDir: runDir, DryRun: opts.DryRun, Items: []UpgradeItem{}}
+	targetHeight := ytdlp.QualityHeight(opts.Quality)
+	proxy := proxyForWorker(1, opts.ProxyMode, normalizeProxyList(opts.Proxies))
+
+	for i := range mf.Jobs {
+		job := &mf.Jobs[i]
+		// Tombstoned videos cannot be fetched again; never risk their only copy.
+		if job.Status != model.StatusCompleted || job.RemovedUpstream {
+			continue
+		}
+		oldPath := job.MediaPath
+		if _, err := os.Stat(oldPath); strings.TrimSpace(oldPath) == "" || err != nil {
+			oldPath = mediaPaths[job.VideoID]
+		}
+		// Audio files have no height; a video download is no upgrade of them.
+		if oldPath == "" || opts.AudioOnly || isAudioMedia(oldPath) {
+			continue
+		}
+		if job.Height <= 0 {
+			if info, err := ytdlp.ProbeMediaFile(oldPath); err == nil {
+				job.Height = info.Height
+				job.VideoCodec = firstNonEmptyString(job.VideoCodec, info.VideoCodec)
+			}
+		}
+		if targetHeight > 0 && job.Height >= targetHeight {
+			continue
+		}
+		if opts.MaxJobs > 0 && res.Checked >= opts.MaxJobs {
+			break
+		}
+		res.Checked++
+
+		item := UpgradeItem{
+			VideoID: job.VideoID,
+			Title:   job.Title,
+			From:    describeFormat(job.FormatID, job.Height, job.VideoCodec),
+		}
+		dl := ytdlp.DownloadOptions{
+			VideoURL:           job.VideoURL,
+			Fragments:          opts.Fragments,
+			CookiesPath:        opts.CookiesPath,
+			CookiesFromBrowser: opts.CookiesFromBrowser,
+			Quality:            opts.Quality,
+			VideoCodec:         opts.VideoCodec,
+			HDR:                opts.HDR,
+			Container:          opts.Container,
+			DeliveryMode:       opts.DeliveryMode,
+			DownloadLimitMBps:  opts.DownloadLimitMBps,
+			ProxyURL:           proxy,
+			Stdout:             os.Stdout,
+			Stderr:             os.Stderr,
+			EchoOutput:         opts.RawOutput,
+			JSRuntime:          opts.JSRuntime,
+		}
+		probe, err := ytdlp.ProbeFormat(dl)
+		if err != nil {
+			item.Outcome = UpgradeOutcomeFailed
+			item.Error = truncate(err.Error(), 400)
+			res.Failed++
+			res.Items = append(res.Items, item)
+			continue
+		}
+		item.To = describeFormat(probe.FormatID, probe.Height, probe.VideoCodec)
+		if probe.Height <= job.Height {
+			item.Outcome = UpgradeOutcomeUpToDate
+			res.Items = append(res.Items, item)
+			continue
+		}
+		res.Candidates++
+		if opts.DryRun {
+			item.Outcome = UpgradeOutcomeCandidate
+			res.Items = append(res.Items, item)
+			continue
+		}
+
+		fmt.Printf("[upgrade %d] %s: %s -> %s\n", res.Checked, job.VideoID, item.From, item.To)
+		newInfo, replaced, err := upgradeJobMedia(job, oldPath, outputDir, logsDir, dl)
+		newInfo.FormatID = firstNonEmptyString(newInfo.FormatID, probe.FormatID)
+		switch {
+		case err != nil:
+			item.Outcome = UpgradeOutcomeFailed
+			item.Error = truncate(err.Error(), 400)
+			res.Failed++
+		case !replaced:
+			item.Outcome = UpgradeOutcomeNotBetter
+			item.To = describeFormat(newInfo.FormatID, newInfo.Height, newInfo.VideoCodec)
+			res.NotBetter++
+		default:
+			item.Outcome = UpgradeOutcomeUpgraded
+			item.To = describeFormat(newInfo.FormatID, newInfo.Height, newInfo.VideoCodec)
+			job.AppendHistory(model.JobEvent{
+				At:   time.Now().UTC().Format(time.RFC3339),
+				Kind: model.JobEventFormatUpgrade,
+				From: item.From,
+				To:   item.To,
+			})
+			job.FormatID = newInfo.FormatID
+			job.Height = newInfo.Height
+			job.VideoCodec = newInfo.VideoCodec
+			job.MediaPath = newInfo.Path
+			res.Upgraded++
+		}
+		res.Items = append(res.Items, item)
+		if err := runstore.WriteJSON(jobsPath, mf); err != nil {
+			return res, fmt.Errorf("persist jobs manifest: %w", err)
+		}
+	}
+	if !opts.DryRun {
+		if err := runstore.WriteJSON(jobsPath, mf); err != nil {
+			return res, fmt.Errorf("persist jobs manifest: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// upgradeJobMedia downloads into a scratch dir next to the archive and only
+// moves the new file into place when it has a greater height than the old one.
+func upgradeJobMedia(job *model.Job, oldPath, outputDir, logsDir string, dl ytdlp.DownloadOptions) (ytdlp.MediaInfo, bool, error) {
+	workDir := filepath.Join(outputDir, workDirPrefix+"upgrade-"+safeFileID(job.VideoID, job.Index))
+	if err := os.RemoveAll(workDir); err != nil {
+		return ytdlp.MediaInfo{}, false, err
+	}
+	defer os.RemoveAll(workDir)
+
+	logPath := filepath.Join(logsDir, fmt.Sprintf("%04d_%s.upgrade.log", job.Index, safeFileID(job.VideoID, job.Index)))
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return ytdlp.MediaInfo{}, false, fmt.Errorf("open upgrade log: %w", err)
+	}
+	defer logFile.Close()
+
+	dl.OutputDir = workDir
+	dl.LogWriter = logFile
+	dlRes, err := ytdlp.DownloadVideo(dl)
+	if err != nil {
+		return ytdlp.MediaInfo{}, false, err
+	}
+	newPath := dlRes.Media.Path
+	if _, err := os.Stat(newPath); strings.TrimSpace(newPath) == "" || err != nil {
+		found, err := mediaPathsByVideoID(workDir)
+		if err != nil {
+			return ytdlp.MediaInfo{}, false, err
+		}
+		newPath = found[job.VideoID]
+	}
+	if newPath == "" {
+		return ytdlp.MediaInfo{}, false, fmt.Errorf("upgraded media for %s not found", job.VideoID)
+	}
+
+	newInfo := dlRes.Media
+	if probed, err := ytdlp.ProbeMediaFile(newPath); err == nil {
+		newInfo.Height = probed.Height
+		newInfo.VideoCodec = firstNonEmptyString(newInfo.VideoCodec, probed.VideoCodec)
+	}
+	oldHeight := job.Height
+	if probed, err := ytdlp.ProbeMediaFile(oldPath); err == nil {
+		oldHeight = probed.Height
+	}
+	if newInfo.Height <= oldHeight {
+		return newInfo, false, nil
+	}
+
+	target := filepath.Join(filepath.Dir(oldPath), filepath.Base(newPath))
+	if err := os.Rename(newPath, target); err != nil {
+		return ytdlp.MediaInfo{}, false, fmt.Errorf("replace media: %w", err)
+	}
+	if target != oldPath {
+		if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
+			return ytdlp.MediaInfo{}, false, fmt.Errorf("remove old media: %w", err)
+		}
+	}
+	newInfo.Path = target
+	return newInfo, true, nil
+}
+
+func firstNonEmptyString(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+func setupFakeUpgradeTools(t *testing.T, newHeight string) {
+	t.Helper()
+	fakeBin := filepath.Join(t.TempDir(), "bin")
+	if err := os.MkdirAll(fakeBin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ytScript := `#!/usr/bin/env bash
+set -euo pipefail
+out=""
+info=""
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --simulate) printf '137+140\t1080\tavc1.640028\n'; exit 0 ;;
+    -P) out="$2"; shift ;;
+    --print-to-file) info="$3"; shift 2 ;;
+  esac
+  shift
+done
+mkdir -p "$out/up"
+echo new > "$out/up/20240101_Demo_[vid123456].mp4"
+printf '137+140\t1080\tavc1.640028\t%s\n' "$out/up/20240101_Demo_[vid123456].mp4" > "$info"
+`
+	ffprobeScript := `#!/usr/bin/env bash
+set -euo pipefail
+path="${@: -1}"
+if grep -q new "$path"; then
+  printf 'codec_name=h264\nheight=` + newHeight + `\n'
+else
+  printf 'codec_name=vp9\nheight=720\n'
+fi
+`
+	for name, script := range map[string]string{"yt-dlp": ytScript, "ffprobe": ffprobeScript, "ffmpeg": "#!/usr/bin/env bash\nexit 0\n"} {
+		if err := os.WriteFile(filepath.Join(fakeBin, name), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", fakeBin+":"+os.Getenv("PATH"))
+}
+
+func setupUpgradeRun(t *testing.T) (string, string) {
+	t.Helper()
+	runDir := filepath.Join(t.TempDir(), "run")
+	oldPath := filepath.Join(runDir, "downloads", "up", "20240101_Demo_[vid123456].webm")
+	if err := os.MkdirAll(filepath.Dir(oldPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(oldPath, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mf := model.JobsManifest{
+		SchemaVersion: 1,
+		RunID:         "run1",
+		Total:         1,
+		Completed:     1,
+		Jobs: []model.Job{{
+			JobID:      "j1",
+			Index:      1,
+			VideoID:    "vid123456",
+			VideoURL:   "https://www.youtube.com/watch?v=vid123456",
+			Title:      "Demo",
+			Status:     model.StatusCompleted,
+			FormatID:   "247+251",
+			Height:     720,
+			VideoCodec: "vp9",
+		}},
+	}
+	if err := runstore.WriteJSON(filepath.Join(runDir, "manifest.jobs.json"), mf); err != nil {
+		t.Fatal(err)
+	}
+	return runDir, oldPath
+}
+
+func TestUpgradeReplacesLowerQualityMedia(t *testing.T) {
+	setupFakeUpgradeTools(t, "1080")
+	runDir, oldPath := setupUpgradeRun(t)
+
+	res, err := Upgrade(UpgradeOptions{RunDir: runDir, Quality: "1080p"})
+	if err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+	if res.Upgraded != 1 || res.Failed != 0 {
+		t.Fatalf("unexpected upgrade result: %+v", res)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Fatalf("expected old media to be replaced, stat err=%v", err)
+	}
+	newPath := filepath.Join(runDir, "downloads", "up", "20240101_Demo_[vid123456].mp4")
+	if _, err := os.Stat(newPath); err != nil {
+		t.Fatalf("expected upgraded media in place: %v", err)
+	}
+
+	var out model.JobsManifest
+	if err := runstore.ReadJSON(filepath.Join(runDir, "manifest.jobs.json"), &out); err != nil {
+		t.Fatal(err)
+	}
+	job := out.Jobs[0]
+	if job.Height != 1080 || job.FormatID != "137+140" || job.MediaPath != newPath {
+		t.Fatalf("unexpected job format after upgrade: %+v", job)
+	}
+	if len(job.History) != 1 || job.History[0].Kind != model.JobEventFormatUpgrade || job.History[0].From != "247+251 720p vp9" {
+		t.Fatalf("unexpected history: %+v", job.History)
+	}
+}
+
+func TestUpgradeKeepsOriginalWhenDownloadIsNotBetter(t *testing.T) {
+	setupFakeUpgradeTools(t, "720")
+	runDir, oldPath := setupUpgradeRun(t)
+
+	res, err := Upgrade(UpgradeOptions{RunDir: runDir, Quality: "1080p"})
+	if err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+	if res.NotBetter != 1 || res.Upgraded != 0 {
+		t.Fatalf("unexpected upgrade result: %+v", res)
+	}
+	if _, err := os.Stat(oldPath); err != nil {
+		t.Fatalf("expected original media to stay: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(runDir, "downloads", workDirPrefix+"upgrade-vid123456")); !os.IsNotExist(err) {
+		t.Fatalf("expected scratch dir cleanup, stat err=%v", err)
+	}
+}
+
+func TestUpgradeDryRunSkipsDownload(t *testing.T) {
+	setupFakeUpgradeTools(t, "1080")
+	runDir, oldPath := setupUpgradeRun(t)
+
+	res, err := Upgrade(UpgradeOptions{RunDir: runDir, Quality: "best", DryRun: true})
+	if err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+	if res.Candidates != 1 || len(res.Items) != 1 || res.Items[0].Outcome != UpgradeOutcomeCandidate {
+		t.Fatalf("unexpected dry-run result: %+v", res)
+	}
+	if _, err := os.Stat(oldPath); err != nil {
+		t.Fatalf("dry run must not touch media: %v", err)
+	}
+}
+
+func TestUpgradeSkipsAudioOnlyMedia(t *testing.T) {
+	setupFakeUpgradeTools(t, "1080")
+	runDir, videoPath := setupUpgradeRun(t)
+
+	res, err := Upgrade(UpgradeOptions{RunDir: runDir, Quality: "1080p", AudioOnly: true})
+	if err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+	if res.Checked != 0 || res.Upgraded != 0 {
+		t.Fatalf("audio-only runs must not be upgraded: %+v", res)
+	}
+
+	// An extracted audio file is skipped even when the run is not marked.
+	audioPath := filepath.Join(filepath.Dir(videoPath), "20240101_Demo_[vid123456].opus")
+	if err := os.Rename(videoPath, audioPath); err != nil {
+		t.Fatal(err)
+	}
+	var mf model.JobsManifest
+	jobsPath := filepath.Join(runDir, "manifest.jobs.json")
+	if err := runstore.ReadJSON(jobsPath, &mf); err != nil {
+		t.Fatal(err)
+	}
+	mf.Jobs[0].Height, mf.Jobs[0].VideoCodec, mf.Jobs[0].MediaPath = 0, "", audioPath
+	if err := runstore.WriteJSON(jobsPath, mf); err != nil {
+		t.Fatal(err)
+	}
+	res, err = Upgrade(UpgradeOptions{RunDir: runDir, Quality: "1080p"})
+	if err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+	if res.Checked != 0 || res.Upgraded != 0 {
+		t.Fatalf("audio files must not be replaced by video: %+v", res)
+	}
+	if _, err := os.Stat(audioPath); err != nil {
+		t.Fatalf("expected the audio file to stay: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"yt-vod-manager/internal/archive"
@@ -101,6 +102,10 @@ func runRefresh(args []string) error {
 	if *useBrowserCookies {
 		cookiesFromBrowser = discovery.DefaultBrowserCookieAgent
 	}
+	global, err := discovery.ReadGlobalSettings(strings.TrimSpace(*config))
+	if err != nil {
+		return err
+	}
 
 	res, err := discovery.Refresh(discovery.RefreshOptions{
 		RunID:              strings.TrimSpace(*runID),
@@ -111,6 +116,8 @@ func runRefresh(args []string) error {
 		CookiesPath:        strings.TrimSpace(*cookies),
 		CookiesFromBrowser: cookiesFromBrowser,
 		JSRuntime:          firstNonEmpty(strings.TrimSpace(*jsRuntime), projectDefaults.JSRuntime, discovery.DefaultJSRuntime),
+		LockTTL:            global.RunLockTTL(),
+		SnapshotRetention:  global.SnapshotRetention,
 	})
 	if err != nil {
 		return err
@@ -122,6 +129,7 @@ func runRefresh(args []string) error {
 	fmt.Printf("run_id: %s\n", res.RunID)
 	fmt.Printf("run_dir: %s\n", res.RunDir)
 	fmt.Printf("added_new: %d\n", res.Added)
+	fmt.Printf("removed_upstream: %d\n", res.RemovedUpstream)
 	fmt.Printf("total_entries: %d\n", res.TotalEntries)
 	fmt.Printf("pending: %d\n", res.Pending)
 	fmt.Printf("skipped_private: %d\n", res.SkippedPrivate)
@@ -144,7 +152,10 @@ func runArchive(args []string) error {
 	stopOnRetryable := fs.Bool("stop-on-retryable", true, "stop run after first retryable failure")
 	fragments := fs.Int("fragments", 0, "yt-dlp fragment concurrency (-N); 0 = project/default")
 	order := fs.String("order", "", "job processing order: oldest|newest|manifest")
-	quality := fs.String("quality", "", "quality preset: best|1080p|720p")
+	quality := fs.String("quality", "", "quality preset: best|2160p|1440p|1080p|720p|480p")
+	videoCodec := fs.String("video-codec", "", "video codec preference order: auto or list of av1,vp9,h264")
+	hdr := fs.String("hdr", "", "HDR preference: auto|on|off")
+	container := fs.String("container", "", "target container with remux: auto|mp4|mkv")
 	jsRuntime := fs.String("js-runtime", "", "JavaScript runtime override for yt-dlp extractor scripts: auto|deno|node|quickjs|bun, or ordered fallback list like node,quickjs")
 	delivery := fs.String("delivery", "auto", "delivery mode: auto|fragmented")
 	progress := fs.Bool("progress", true, "show live progress renderer")
@@ -152,6 +163,8 @@ func runArchive(args []string) error {
 	outputDir := fs.String("output-dir", "", "download output dir (default: <run_dir>/downloads)")
 	subtitles := fs.String("subtitles", "auto", "subtitle download: auto|yes|no")
 	subLangs := fs.String("sub-langs", "", "subtitle language preference: english|all")
+	liveChat := fs.String("live-chat", "auto", "live chat replay download: auto|yes|no")
+	liveChatFormat := fs.String("live-chat-format", "", "live chat render format: txt|ass")
 	cookies := fs.String("cookies", "", "path to cookies.txt")
 	useBrowserCookies := fs.Bool("browser-cookies", false, browserCookiesFlagHelp)
 	jsonOut := fs.Bool("json", false, "print JSON output")
@@ -203,13 +216,21 @@ func runArchive(args []string) error {
 	effectiveFragments := firstNonZero(*fragments, projectDefaults.Fragments, discovery.DefaultFragments)
 	effectiveOrder := firstNonEmpty(strings.TrimSpace(*order), projectDefaults.Order, discovery.DefaultOrder)
 	effectiveQuality := firstNonEmpty(strings.TrimSpace(*quality), projectDefaults.Quality, discovery.DefaultQuality)
+	effectiveVideoCodec := firstNonEmpty(strings.TrimSpace(*videoCodec), projectDefaults.VideoCodec)
+	effectiveHDR := firstNonEmpty(strings.TrimSpace(*hdr), projectDefaults.HDR)
+	effectiveContainer := firstNonEmpty(strings.TrimSpace(*container), projectDefaults.Container)
 	effectiveSubLangs := firstNonEmpty(strings.TrimSpace(*subLangs), projectDefaults.SubLangs, discovery.DefaultSubtitleLanguage)
 	effectiveJSRuntime := firstNonEmpty(strings.TrimSpace(*jsRuntime), projectDefaults.JSRuntime, discovery.DefaultJSRuntime)
 	effectiveDelivery := firstNonEmpty(strings.TrimSpace(*delivery), projectDefaults.DeliveryMode, "auto")
-	effectiveNoSubs, err := resolveNoSubs(strings.TrimSpace(*subtitles), projectDefaults.NoSubs)
+	effectiveNoSubs, err := resolveNoSubs(strings.TrimSpace(*subtitles), boolValue(projectDefaults.NoSubs))
 	if err != nil {
 		return err
 	}
+	effectiveLiveChat, err := resolveLiveChat(strings.TrimSpace(*liveChat), boolValue(projectDefaults.LiveChat))
+	if err != nil {
+		return err
+	}
+	effectiveLiveChatFormat := firstNonEmpty(strings.TrimSpace(*liveChatFormat), projectDefaults.LiveChatFormat, discovery.DefaultLiveChatFormat)
 
 	result, err := archive.Run(archive.RunOptions{
 		RunID:              strings.TrimSpace(*runID),
@@ -227,14 +248,26 @@ func runArchive(args []string) error {
 		ProxyMode:          networkSettings.ProxyMode,
 		Proxies:            networkSettings.Proxies,
 		NoSubs:             effectiveNoSubs,
+		LiveChat:           effectiveLiveChat,
+		LiveChatFormat:     effectiveLiveChatFormat,
 		RetryPermanent:     *retryPermanent,
 		StopOnRetryable:    *stopOnRetryable,
 		Progress:           *progress,
 		RawOutput:          *rawOutput,
 		Order:              effectiveOrder,
 		Quality:            effectiveQuality,
+		VideoCodec:         effectiveVideoCodec,
+		HDR:                effectiveHDR,
+		Container:          effectiveContainer,
 		JSRuntime:          effectiveJSRuntime,
 		DeliveryMode:       effectiveDelivery,
+		AudioOnly:          boolValue(projectDefaults.AudioOnly),
+		WriteThumbnails:    boolValue(projectDefaults.WriteThumbnails),
+		DedupMode:          global.DedupMode,
+		LockTTL:            global.RunLockTTL(),
+		FailureRules:       global.FailureRules,
+		Storage:            projectDefaults.Storage,
+		Mirrors:            discovery.ResolveMirrors(projectDefaults, global, firstNonEmpty(*runID, runIDFromDir(targetRunDir))),
 	})
 	if err != nil {
 		return err
@@ -264,3 +297,12 @@ func runArchive(args []string) error {
 	}
 	return nil
 }
+
+// runIDFromDir names ad hoc runs for global mirrors when only --run-dir is
+// given.
+func runIDFromDir(runDir string) string {
+	if strings.TrimSpace(runDir) == "" {
+		return ""
+	}
+	return filepath.Base(filepath.Clean(runDir))
+}
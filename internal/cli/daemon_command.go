@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"yt-vod-manager/internal/archive"
+	"yt-vod-manager/internal/discovery"
+)
+
+type daemonRunner struct {
+	configPath      string
+	runsDir         string
+	defaultSchedule string
+	poll            time.Duration
+	noRun           bool
+	metricsTextfile string
+
+	configStamp string
+	projects    []discovery.Project
+	global      discovery.GlobalSettings
+	state       discovery.DaemonState
+}
+
+func runDaemon(args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	defaultSchedule := fs.String("default-schedule", "", "schedule for active projects without their own (empty = skip them)")
+	poll := fs.Duration("poll", 30*time.Second, "how often to check for due projects and config changes")
+	once := fs.Bool("once", false, "sync projects that are due now, then exit")
+	noRun := fs.Bool("no-run", false, "only refresh sources on schedule; do not download")
+	metricsListen := fs.String("metrics-listen", "", "also serve /metrics on this address (e.g. 127.0.0.1:9108)")
+	metricsTextfile := fs.String("metrics-textfile", "", "write Prometheus metrics after each cycle (default: global metrics_textfile)")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *poll < time.Second {
+		return errors.New("--poll must be at least 1s")
+	}
+	if v := strings.TrimSpace(*defaultSchedule); v != "" {
+		if _, err := discovery.ParseSchedule(v); err != nil {
+			return fmt.Errorf("invalid --default-schedule: %w", err)
+		}
+	}
+
+	d := &daemonRunner{
+		configPath:      strings.TrimSpace(*config),
+		runsDir:         defaultIfEmpty(strings.TrimSpace(*runsDir), "runs"),
+		defaultSchedule: strings.TrimSpace(*defaultSchedule),
+		poll:            *poll,
+		noRun:           *noRun,
+		metricsTextfile: strings.TrimSpace(*metricsTextfile),
+	}
+	lock, err := discovery.AcquireDaemonLock(d.runsDir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+	state, err := discovery.LoadDaemonState(d.runsDir)
+	if err != nil {
+		return err
+	}
+	d.state = state
+	if _, err := d.reloadConfig(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if addr := strings.TrimSpace(*metricsListen); addr != "" {
+		go func() {
+			if err := serveMetrics(ctx, addr, d.configPath, d.runsDir); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				d.logf("warn  metrics server stopped: %v", err)
+			}
+		}()
+		d.logf("serving metrics on http://%s/metrics", addr)
+	}
+
+	d.logf("daemon started (config %s, runs %s, poll %s, %d project(s))", d.configPath, d.runsDir, d.poll, len(d.projects))
+	err = d.loop(ctx, *once)
+	if saveErr := discovery.SaveDaemonState(d.runsDir, d.state); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	d.logf("daemon stopped")
+	return err
+}
+
+func (d *daemonRunner) loop(ctx context.Context, once bool) error {
+	for {
+		if changed, err := d.reloadConfig(); err != nil {
+			d.logf("warn  config reload failed, keeping previous projects: %v", err)
+		} else if changed {
+			d.logf("config loaded: %d project(s)", len(d.projects))
+		}
+
+		jobs, warnings := discovery.PlanDaemon(&d.state, d.projects, d.defaultSchedule, time.Now())
+		for _, w := range warnings {
+			d.logf("warn  %s", w)
+		}
+		ran := false
+		for _, job := range jobs {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if job.NextRun.After(time.Now()) {
+				break
+			}
+			d.runJob(ctx, job)
+			ran = true
+		}
+		if ran {
+			if idx, err := discovery.UpdateSearchIndex(d.configPath, d.runsDir); err != nil {
+				d.logf("warn  search index update failed (non-fatal): %v", err)
+			} else {
+				for _, w := range idx.Warnings {
+					d.logf("warn  search index: %s", w)
+				}
+			}
+		}
+		if err := discovery.SaveDaemonState(d.runsDir, d.state); err != nil {
+			d.logf("warn  daemon state not saved: %v", err)
+		}
+		if once || ctx.Err() != nil {
+			return nil
+		}
+
+		wait := d.poll
+		jobs, _ = discovery.PlanDaemon(&d.state, d.projects, d.defaultSchedule, time.Now())
+		if len(jobs) > 0 {
+			if until := time.Until(jobs[0].NextRun); until < wait {
+				wait = max(until, 0)
+			}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// runJob runs one project's refresh/download cycle. Cycles run one at a time,
+// so a slow project delays the others instead of overlapping with them.
+func (d *daemonRunner) runJob(ctx context.Context, job discovery.DaemonJob) {
+	name := job.Project.Name
+	entry := d.state.Projects[name]
+	started := time.Now()
+	entry.LastStartedAt = started.UTC().Format(time.RFC3339)
+	d.state.Projects[name] = entry
+	if err := discovery.SaveDaemonState(d.runsDir, d.state); err != nil {
+		d.logf("warn  daemon state not saved: %v", err)
+	}
+	d.logf("[%s] scheduled sync starting (%s)", name, job.Schedule.Raw)
+
+	var out syncSourceOutcome
+	lock, err := discovery.AcquireSyncLock(d.runsDir, discovery.SyncLockOptions{TTL: d.global.RunLockTTL()})
+	if err == nil {
+		recorder := newSyncMetricsRecorder(d.configPath, d.runsDir, firstNonEmpty(d.metricsTextfile, d.global.MetricsTextfile))
+		out, err = syncSource(syncItemFromProject(job.Project), "["+name+"]", syncOptions{
+			RunsDir:         d.runsDir,
+			NoRun:           d.noRun,
+			StopOnRetryable: true,
+			Subtitles:       "auto",
+			LiveChat:        "auto",
+			Global:          d.global,
+			Stop:            ctx.Done(),
+		}, recorder)
+		recorder.flush()
+		_ = lock.Release()
+		if err == nil {
+			err = out.Err
+		}
+	}
+
+	finished := time.Now()
+	next := job.Schedule.Next(finished)
+	entry.LastFinishedAt = finished.UTC().Format(time.RFC3339)
+	entry.LastError = ""
+	switch {
+	case ctx.Err() != nil:
+		// Interrupted: pick the project up again as soon as the daemon restarts.
+		entry.LastResult = discovery.DaemonResultInterrupted
+		next = finished
+	case err != nil && archive.IsRunLocked(err):
+		// A manual sync or run holds the workspace or run lock; retry next poll.
+		entry.LastResult = discovery.DaemonResultSkipped
+		entry.LastError = err.Error()
+		next = finished.Add(d.poll)
+	case err != nil:
+		entry.LastResult = discovery.DaemonResultFailure
+		entry.LastError = err.Error()
+	default:
+		entry.LastResult = discovery.DaemonResultSuccess
+	}
+	entry.NextRunAt = next.UTC().Format(time.RFC3339)
+	d.state.Projects[name] = entry
+
+	msg := fmt.Sprintf("[%s] %s in %s; next run %s", name, entry.LastResult, finished.Sub(started).Round(time.Second), next.Local().Format(time.RFC3339))
+	if out.Ran {
+		msg += fmt.Sprintf(" (processed %d, pending %d)", out.Run.Processed, out.Run.Pending)
+	}
+	d.logf("%s", msg)
+}
+
+// reloadConfig re-reads the project config when its size or mtime changed.
+func (d *daemonRunner) reloadConfig() (bool, error) {
+	info, err := os.Stat(defaultIfEmpty(d.configPath, discovery.DefaultProjectsConfigPath))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	stamp := "missing"
+	if err == nil {
+		stamp = fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano())
+	}
+	if stamp == d.configStamp {
+		return false, nil
+	}
+	reg, err := discovery.LoadProjects(d.configPath)
+	if err != nil {
+		return false, err
+	}
+	d.projects = make([]discovery.Project, 0, len(reg.Projects))
+	for _, p := range reg.Projects {
+		d.projects = append(d.projects, discovery.EffectiveProject(p, reg.Global, reg.Profiles))
+	}
+	d.global = reg.Global
+	d.configStamp = stamp
+	return true, nil
+}
+
+func (d *daemonRunner) logf(format string, args ...any) {
+	fmt.Printf("%s %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"yt-vod-manager/internal/discovery"
+)
+
+func TestRunDaemonOnceSyncsDueProjectsAndPersistsNextRun(t *testing.T) {
+	tmp := t.TempDir()
+	setupFakeFlatPlaylistYTDLP(t, tmp)
+	cfg := filepath.Join(tmp, "projects.json")
+	runsDir := filepath.Join(tmp, "runs")
+	if _, err := discovery.AddProject(discovery.AddProjectOptions{ConfigPath: cfg, Name: "talks", SourceURL: "https://example.com/source", Schedule: "2h"}); err != nil {
+		t.Fatalf("add project failed: %v", err)
+	}
+	if _, err := discovery.AddProject(discovery.AddProjectOptions{ConfigPath: cfg, Name: "manual", SourceURL: "https://example.com/other"}); err != nil {
+		t.Fatalf("add project failed: %v", err)
+	}
+
+	start := time.Now()
+	output := captureStdout(t, func() {
+		if err := runDaemon([]string{"--config", cfg, "--runs-dir", runsDir, "--once", "--no-run"}); err != nil {
+			t.Fatalf("runDaemon failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "[talks] success") || strings.Contains(output, "[manual]") {
+		t.Fatalf("expected only the scheduled project to sync, got:\n%s", output)
+	}
+
+	st, err := discovery.LoadDaemonState(runsDir)
+	if err != nil {
+		t.Fatalf("load daemon state: %v", err)
+	}
+	entry, ok := st.Projects["talks"]
+	if !ok || entry.LastResult != discovery.DaemonResultSuccess {
+		t.Fatalf("unexpected daemon state: %+v", st)
+	}
+	next, err := time.Parse(time.RFC3339, entry.NextRunAt)
+	if err != nil || next.Before(start.Add(2*time.Hour-time.Minute)) {
+		t.Fatalf("expected next run about 2h out, got %q", entry.NextRunAt)
+	}
+
+	output = captureStdout(t, func() {
+		if err := runDaemon([]string{"--config", cfg, "--runs-dir", runsDir, "--once", "--no-run"}); err != nil {
+			t.Fatalf("runDaemon failed: %v", err)
+		}
+	})
+	if strings.Contains(output, "[talks]") {
+		t.Fatalf("expected no sync before the next scheduled time, got:\n%s", output)
+	}
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"yt-vod-manager/internal/archive"
+)
+
+func runDedup(args []string) error {
+	fs := flag.NewFlagSet("dedup", flag.ContinueOnError)
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	res, err := archive.DedupReport(strings.TrimSpace(*runsDir))
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(res)
+	}
+
+	fmt.Println("dedup summary")
+	fmt.Printf("index: %s\n", res.IndexPath)
+	fmt.Printf("indexed_files: %d\n", res.IndexedFiles)
+	fmt.Printf("deduplicated: %d\n", res.Deduplicated)
+	fmt.Printf("saved: %s\n", formatBytesIEC(res.SavedBytes))
+	for _, run := range res.Runs {
+		fmt.Printf("  %s: %d videos, %s\n", run.RunID, run.Deduplicated, formatBytesIEC(run.SavedBytes))
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"yt-vod-manager/internal/discovery"
+)
+
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	project := fs.String("project", "", "project name")
+	since := fs.String("since", "", "compare against the last snapshot at or before this date (YYYY-MM-DD or RFC3339)")
+	from := fs.String("from", "", "baseline snapshot (name or timestamp from --list)")
+	to := fs.String("to", "", "target snapshot (default newest)")
+	list := fs.Bool("list", false, "list available snapshots")
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*project) == "" {
+		return errors.New("--project is required")
+	}
+	if strings.TrimSpace(*since) != "" && strings.TrimSpace(*from) != "" {
+		return errors.New("use either --since or --from, not both")
+	}
+
+	if *list {
+		runDir, snaps, err := discovery.ListProjectSnapshots(strings.TrimSpace(*config), strings.TrimSpace(*project), strings.TrimSpace(*runsDir))
+		if err != nil {
+			return err
+		}
+		if *jsonOut {
+			return printJSON(map[string]any{"run_dir": runDir, "snapshots": snaps})
+		}
+		fmt.Printf("run_dir: %s\n", runDir)
+		if len(snaps) == 0 {
+			fmt.Println("no snapshots yet; each refresh/sync records one")
+		}
+		for _, s := range snaps {
+			fmt.Printf("  %s\n", s.Name)
+		}
+		return nil
+	}
+
+	res, err := discovery.DiffSnapshots(discovery.DiffOptions{
+		ConfigPath: strings.TrimSpace(*config),
+		Project:    strings.TrimSpace(*project),
+		RunsDir:    strings.TrimSpace(*runsDir),
+		Since:      strings.TrimSpace(*since),
+		From:       strings.TrimSpace(*from),
+		To:         strings.TrimSpace(*to),
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(res)
+	}
+
+	fmt.Printf("project: %s\n", res.Project)
+	fmt.Printf("from: %s (%d entries)\n", res.From.TakenAt, res.From.Entries)
+	fmt.Printf("to:   %s (%d entries)\n", res.To.TakenAt, res.To.Entries)
+	if res.Empty() {
+		fmt.Println("no changes")
+		return nil
+	}
+	printDiffVideos("added", res.Added)
+	printDiffVideos("removed", res.Removed)
+	if len(res.Reordered) > 0 {
+		fmt.Printf("reordered (%d):\n", len(res.Reordered))
+		for _, m := range res.Reordered {
+			fmt.Printf("  #%d -> #%d  %s  %s\n", m.From, m.To, m.VideoID, m.Title)
+		}
+	}
+	if len(res.Retitled) > 0 {
+		fmt.Printf("retitled (%d):\n", len(res.Retitled))
+		for _, r := range res.Retitled {
+			fmt.Printf("  %s  %q -> %q\n", r.VideoID, r.OldTitle, r.NewTitle)
+		}
+	}
+	printDiffVideos("made private", res.MadePrivate)
+	printDiffVideos("made public", res.MadePublic)
+	return nil
+}
+
+func printDiffVideos(label string, videos []discovery.DiffVideo) {
+	if len(videos) == 0 {
+		return
+	}
+	fmt.Printf("%s (%d):\n", label, len(videos))
+	for _, v := range videos {
+		fmt.Printf("  #%d  %s  %s\n", v.Position, v.VideoID, v.Title)
+	}
+}
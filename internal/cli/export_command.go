@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"yt-vod-manager/internal/discovery"
+)
+
+const (
+	exportFormatCSV      = "csv"
+	exportFormatMarkdown = "md"
+	exportFormatHTML     = "html"
+)
+
+var exportColumns = []string{"project", "index", "video_id", "title", "status", "reason", "attempts", "last_error", "completed_at"}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	project := fs.String("project", "", "project name(s), comma-separated")
+	allProjects := fs.Bool("all-projects", false, "export all configured projects into one report")
+	format := fs.String("format", "", "report format: csv|md|html (default: from --output extension, else md)")
+	output := fs.String("output", "", "write the report to this file instead of stdout")
+	totalsOutput := fs.String("totals-output", "", "also write the per-status totals as CSV to this file")
+	status := fs.String("status", "", "only include job status(es), comma-separated, e.g. failed_permanent,pending")
+	reason := fs.String("reason", "", "only include job reason(s), comma-separated")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*project) == "" && !*allProjects {
+		return errors.New("export target required: set --project or --all-projects")
+	}
+	outPath := strings.TrimSpace(*output)
+	effectiveFormat, err := resolveExportFormat(*format, outPath)
+	if err != nil {
+		return err
+	}
+
+	report, err := discovery.Export(discovery.ExportOptions{
+		ConfigPath: strings.TrimSpace(*config),
+		RunsDir:    strings.TrimSpace(*runsDir),
+		Project:    strings.TrimSpace(*project),
+		All:        *allProjects,
+		Status:     strings.TrimSpace(*status),
+		Reason:     strings.TrimSpace(*reason),
+	})
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if outPath != "" {
+		if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+			return err
+		}
+		f, err := os.Create(outPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	switch effectiveFormat {
+	case exportFormatCSV:
+		err = renderExportCSV(w, report)
+	case exportFormatHTML:
+		err = renderExportHTML(w, report)
+	default:
+		err = renderExportMarkdown(w, report)
+	}
+	if err != nil {
+		return err
+	}
+	if path := strings.TrimSpace(*totalsOutput); path != "" {
+		if err := writeExportTotalsFile(path, report); err != nil {
+			return err
+		}
+		if outPath != "" {
+			fmt.Printf("wrote totals: %s\n", path)
+		}
+	}
+	if outPath != "" {
+		fmt.Printf("wrote %s report: %s (%d rows)\n", effectiveFormat, outPath, report.Rows)
+		for _, t := range report.Totals {
+			fmt.Printf("  %s: %d\n", t.Status, t.Count)
+		}
+	}
+	return nil
+}
+
+func writeExportTotalsFile(path string, report discovery.ExportReport) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := renderExportTotalsCSV(f, report); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func resolveExportFormat(raw, outPath string) (string, error) {
+	v := strings.ToLower(strings.TrimSpace(raw))
+	if v == "" {
+		switch strings.ToLower(filepath.Ext(outPath)) {
+		case ".csv":
+			return exportFormatCSV, nil
+		case ".html", ".htm":
+			return exportFormatHTML, nil
+		default:
+			return exportFormatMarkdown, nil
+		}
+	}
+	switch v {
+	case exportFormatCSV, exportFormatHTML:
+		return v, nil
+	case exportFormatMarkdown, "markdown":
+		return exportFormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("invalid --format %q (expected csv, md, or html)", raw)
+	}
+}
+
+func exportRows(report discovery.ExportReport) [][]string {
+	rows := make([][]string, 0, report.Rows)
+	for _, p := range report.Projects {
+		for _, j := range p.Jobs {
+			rows = append(rows, []string{
+				p.Project,
+				strconv.Itoa(j.Index),
+				j.VideoID,
+				j.Title,
+				j.Status,
+				j.Reason,
+				strconv.Itoa(j.Attempts),
+				j.LastError,
+				j.CompletedAt,
+			})
+		}
+	}
+	return rows
+}
+
+var exportTotalsColumns = []string{"project", "run_id", "status", "count"}
+
+// renderExportCSV writes one row per job; totals go to a separate file (see
+// renderExportTotalsCSV) so the report stays a single table.
+func renderExportCSV(w io.Writer, report discovery.ExportReport) error {
+	return writeCSVTable(w, exportColumns, exportRows(report))
+}
+
+// renderExportTotalsCSV writes the per-status totals of the markdown report.
+func renderExportTotalsCSV(w io.Writer, report discovery.ExportReport) error {
+	return writeCSVTable(w, exportTotalsColumns, exportTotalsRows(report))
+}
+
+func writeCSVTable(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func exportTotalsRows(report discovery.ExportReport) [][]string {
+	var rows [][]string
+	for _, p := range report.Projects {
+		if len(p.Totals) == 0 {
+			rows = append(rows, []string{p.Project, p.RunID, "never_synced", "0"})
+		}
+		for _, t := range p.Totals {
+			rows = append(rows, []string{p.Project, p.RunID, t.Status, strconv.Itoa(t.Count)})
+		}
+	}
+	if len(report.Projects) > 1 {
+		for _, t := range report.Totals {
+			rows = append(rows, []string{"all", "", t.Status, strconv.Itoa(t.Count)})
+		}
+	}
+	return rows
+}
+
+func renderExportMarkdown(w io.Writer, report discovery.ExportReport) error {
+	var b strings.Builder
+	b.WriteString("# Archive report\n\n")
+	fmt.Fprintf(&b, "Generated: %s\n\n", report.GeneratedAt)
+	b.WriteString("## Totals\n\n| project | run | status | count |\n|---|---|---|---:|\n")
+	for _, p := range report.Projects {
+		if len(p.Totals) == 0 {
+			fmt.Fprintf(&b, "| %s | %s | never synced | 0 |\n", markdownCell(p.Project), markdownCell(p.RunID))
+		}
+		for _, t := range p.Totals {
+			fmt.Fprintf(&b, "| %s | %s | %s | %d |\n", markdownCell(p.Project), markdownCell(p.RunID), t.Status, t.Count)
+		}
+	}
+	if len(report.Projects) > 1 {
+		for _, t := range report.Totals {
+			fmt.Fprintf(&b, "| **all** | | %s | %d |\n", t.Status, t.Count)
+		}
+	}
+	b.WriteString("\n## Jobs\n\n| " + strings.Join(exportColumns, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat("---|", len(exportColumns)) + "\n")
+	for _, row := range exportRows(report) {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = markdownCell(v)
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func markdownCell(v string) string {
+	v = strings.Join(strings.Fields(v), " ")
+	return strings.ReplaceAll(v, "|", `\|`)
+}
+
+var exportHTMLTemplate = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Archive report</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2rem; width: 100%; }
+th, td { border: 1px solid #ddd; padding: 0.3rem 0.5rem; text-align: left; vertical-align: top; font-size: 0.9rem; }
+th { background: #f4f4f4; }
+td.num { text-align: right; }
+.completed { color: #1a7f37; }
+.failed_permanent { color: #cf222e; }
+.failed_retryable { color: #9a6700; }
+.error { max-width: 32rem; overflow-wrap: anywhere; color: #57606a; }
+</style>
+</head>
+<body>
+<h1>Archive report</h1>
+<p>Generated {{.Report.GeneratedAt}} &middot; {{.Report.Rows}} jobs listed</p>
+<h2>Totals</h2>
+<table>
+<tr><th>project</th><th>run</th><th>status</th><th>count</th></tr>
+{{- range .Report.Projects}}{{$p := .}}
+{{- if not .Totals}}
+<tr><td>{{$p.Project}}</td><td></td><td>never synced</td><td class="num">0</td></tr>
+{{- end}}
+{{- range .Totals}}
+<tr><td>{{$p.Project}}</td><td>{{$p.RunID}}</td><td class="{{.Status}}">{{.Status}}</td><td class="num">{{.Count}}</td></tr>
+{{- end}}
+{{- end}}
+{{- if .Combined}}{{range .Report.Totals}}
+<tr><th>all</th><th></th><th class="{{.Status}}">{{.Status}}</th><th class="num">{{.Count}}</th></tr>
+{{- end}}{{end}}
+</table>
+<h2>Jobs</h2>
+<table>
+<tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Rows}}
+<tr><td>{{index . 0}}</td><td class="num">{{index . 1}}</td><td>{{index . 2}}</td><td>{{index . 3}}</td><td class="{{index . 4}}">{{index . 4}}</td><td>{{index . 5}}</td><td class="num">{{index . 6}}</td><td class="error">{{index . 7}}</td><td>{{index . 8}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+func renderExportHTML(w io.Writer, report discovery.ExportReport) error {
+	return exportHTMLTemplate.Execute(w, map[string]any{
+		"Report":   report,
+		"Combined": len(report.Projects) > 1,
+		"Columns":  exportColumns,
+		"Rows":     exportRows(report),
+	})
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"yt-vod-manager/internal/discovery"
+	"yt-vod-manager/internal/model"
+)
+
+func sampleExportReport() discovery.ExportReport {
+	return discovery.ExportReport{
+		GeneratedAt: "2024-01-01T00:00:00Z",
+		Rows:        1,
+		Totals:      []discovery.ExportStatusCount{{Status: model.StatusFailedPermanent, Count: 1}},
+		Projects: []discovery.ExportProject{{
+			Project: "demo",
+			RunID:   "run1",
+			Totals:  []discovery.ExportStatusCount{{Status: model.StatusFailedPermanent, Count: 1}},
+			Jobs: []model.Job{{
+				Index:     3,
+				VideoID:   "abc123",
+				Title:     "A | B <script>",
+				Status:    model.StatusFailedPermanent,
+				Reason:    "download_error",
+				Attempts:  2,
+				LastError: "line one\nline two",
+			}},
+		}},
+	}
+}
+
+func TestRenderExportFormats(t *testing.T) {
+	report := sampleExportReport()
+
+	var csvOut strings.Builder
+	if err := renderExportCSV(&csvOut, report); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(csvOut.String(), strings.Join(exportColumns, ",")+"\n") || !strings.Contains(csvOut.String(), "\"line one\nline two\"") {
+		t.Fatalf("unexpected csv output:\n%s", csvOut.String())
+	}
+	records, err := csv.NewReader(strings.NewReader(csvOut.String())).ReadAll()
+	if err != nil || len(records) != 2 || records[1][4] != model.StatusFailedPermanent {
+		t.Fatalf("csv report must be one table: %v\n%s", err, csvOut.String())
+	}
+
+	var totalsOut strings.Builder
+	if err := renderExportTotalsCSV(&totalsOut, report); err != nil {
+		t.Fatal(err)
+	}
+	totals, err := csv.NewReader(strings.NewReader(totalsOut.String())).ReadAll()
+	if err != nil || len(totals) != 2 || strings.Join(totals[1], ",") != "demo,run1,failed_permanent,1" {
+		t.Fatalf("unexpected totals csv: %v\n%s", err, totalsOut.String())
+	}
+
+	var mdOut strings.Builder
+	if err := renderExportMarkdown(&mdOut, report); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(mdOut.String(), `| demo | 3 | abc123 | A \| B <script> | failed_permanent | download_error | 2 | line one line two |  |`) {
+		t.Fatalf("unexpected markdown output:\n%s", mdOut.String())
+	}
+	if !strings.Contains(mdOut.String(), "| demo | run1 | failed_permanent | 1 |") {
+		t.Fatalf("expected per-status totals in markdown:\n%s", mdOut.String())
+	}
+
+	var htmlOut strings.Builder
+	if err := renderExportHTML(&htmlOut, report); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(htmlOut.String(), "<script>") || !strings.Contains(htmlOut.String(), "A | B &lt;script&gt;") {
+		t.Fatalf("expected escaped html output:\n%s", htmlOut.String())
+	}
+}
+
+func TestResolveExportFormat(t *testing.T) {
+	cases := map[[2]string]string{
+		{"", "report.csv"}:  exportFormatCSV,
+		{"", "report.html"}: exportFormatHTML,
+		{"", ""}:            exportFormatMarkdown,
+		{"markdown", ""}:    exportFormatMarkdown,
+		{"HTML", "x.csv"}:   exportFormatHTML,
+	}
+	for in, want := range cases {
+		got, err := resolveExportFormat(in[0], in[1])
+		if err != nil || got != want {
+			t.Fatalf("resolveExportFormat(%q, %q) = %q, %v; want %q", in[0], in[1], got, err, want)
+		}
+	}
+	if _, err := resolveExportFormat("pdf", ""); err == nil {
+		t.Fatal("expected unsupported format to be rejected")
+	}
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"yt-vod-manager/internal/archive"
+	"yt-vod-manager/internal/discovery"
+)
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	project := fs.String("project", "", "project name (uses latest run for that project, creating an empty one if needed)")
+	runID := fs.String("run-id", "", "run id from runs/<run_id>")
+	runDir := fs.String("run-dir", "", "explicit run directory path")
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	latest := fs.Bool("latest", false, "use latest run when run-id/run-dir/project are not set")
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	dir := fs.String("dir", "", "directory of existing downloads (files named with [<video_id>])")
+	archiveFile := fs.String("archive", "", "yt-dlp --download-archive file")
+	mode := fs.String("mode", archive.ImportModeRecord, "record (keep files in place), hardlink, or move into the project's layout")
+	dryRun := fs.Bool("dry-run", false, "only report what would be imported")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	configPath := strings.TrimSpace(*config)
+	if strings.TrimSpace(*dir) == "" && strings.TrimSpace(*archiveFile) == "" {
+		return errors.New("import source required: set --dir, --archive, or both")
+	}
+
+	targetRunDir := strings.TrimSpace(*runDir)
+	projectDefaults := discovery.Project{}
+	created := false
+	if name := strings.TrimSpace(*project); name != "" {
+		var resolved string
+		var proj discovery.Project
+		var err error
+		if *dryRun {
+			resolved, proj, err = discovery.ResolveRunDirForProject(configPath, name, strings.TrimSpace(*runsDir))
+		} else {
+			resolved, proj, created, err = discovery.ResolveOrCreateRunDirForProject(configPath, name, strings.TrimSpace(*runsDir))
+		}
+		if err != nil {
+			return err
+		}
+		targetRunDir = resolved
+		projectDefaults = proj
+	}
+	if targetRunDir == "" && strings.TrimSpace(*runID) == "" && !*latest {
+		return errors.New("import target required: set --project, --run-id, --run-dir, or --latest")
+	}
+	global, err := discovery.ReadGlobalSettings(configPath)
+	if err != nil {
+		return err
+	}
+
+	res, err := archive.Import(archive.ImportOptions{
+		RunID:       strings.TrimSpace(*runID),
+		RunDir:      targetRunDir,
+		RunsDir:     strings.TrimSpace(*runsDir),
+		Latest:      *latest,
+		OutputDir:   projectDefaults.OutputDir,
+		Dir:         strings.TrimSpace(*dir),
+		ArchiveFile: strings.TrimSpace(*archiveFile),
+		Mode:        *mode,
+		DryRun:      *dryRun,
+		LockTTL:     global.RunLockTTL(),
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(res)
+	}
+
+	fmt.Println("import summary")
+	fmt.Printf("run_id: %s\n", res.RunID)
+	if created {
+		fmt.Println("run: created empty run (filled in by the next sync)")
+	}
+	fmt.Printf("mode: %s\n", res.Mode)
+	fmt.Printf("dry_run: %t\n", res.DryRun)
+	fmt.Printf("files_found: %d\n", res.FilesFound)
+	fmt.Printf("archive_ids: %d\n", res.ArchiveIDs)
+	fmt.Printf("matched: %d\n", res.Matched)
+	fmt.Printf("created: %d\n", res.Created)
+	fmt.Printf("archive_only: %d\n", res.ArchiveOnly)
+	fmt.Printf("skipped: %d\n", res.Skipped)
+	fmt.Printf("failed: %d\n", res.Failed)
+	for _, item := range res.Items {
+		if item.Outcome == archive.ImportOutcomeSkipped {
+			continue
+		}
+		line := fmt.Sprintf("  %s %s: %s", item.Outcome, item.VideoID, defaultIfEmpty(item.Path, "(archive only)"))
+		if item.From != "" && item.From != item.Path {
+			line += " (from " + item.From + ")"
+		}
+		if item.Error != "" {
+			line += " (" + item.Error + ")"
+		}
+		fmt.Println(line)
+	}
+	if res.DryRun && res.Matched+res.Created > 0 {
+		fmt.Println("next: rerun without --dry-run to import")
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
@@ -77,3 +78,25 @@ func boolPtr(v bool) *bool {
 	b := v
 	return &b
 }
+
+func boolValue(v *bool) bool {
+	return v != nil && *v
+}
+
+// setFlags reports which flags were given on the command line, so a flag
+// left at its default can mean "unset".
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+// flagBool is v when the flag was given and nil otherwise.
+func flagBool(set map[string]bool, name string, v bool) *bool {
+	if !set[name] {
+		return nil
+	}
+	return boolPtr(v)
+}
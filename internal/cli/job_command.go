@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/archive"
+	"yt-vod-manager/internal/discovery"
+)
+
+func runJob(args []string) error {
+	if len(args) == 0 {
+		printJobUsage()
+		return nil
+	}
+	switch args[0] {
+	case "show":
+		return runJobShow(args[1:])
+	case "help", "-h", "--help":
+		printJobUsage()
+		return nil
+	default:
+		printJobUsage()
+		return fmt.Errorf("unknown job subcommand %q", args[0])
+	}
+}
+
+func printJobUsage() {
+	fmt.Println("usage:")
+	fmt.Println("  yt-vod-manager job show --project <name> --video <video_id> [--json]")
+	fmt.Println("  yt-vod-manager job show --run-id <id> --video <video_id>")
+}
+
+func runJobShow(args []string) error {
+	fs := flag.NewFlagSet("job show", flag.ContinueOnError)
+	project := fs.String("project", "", "project name (uses latest run for that project)")
+	video := fs.String("video", "", "video id (or job id / video URL)")
+	runID := fs.String("run-id", "", "run id from runs/<run_id>")
+	runDir := fs.String("run-dir", "", "explicit run directory path")
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	latest := fs.Bool("latest", false, "use latest run in runs-dir")
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*video) == "" {
+		return errors.New("--video is required")
+	}
+
+	targetRunDir := strings.TrimSpace(*runDir)
+	if strings.TrimSpace(*project) != "" {
+		resolved, _, err := discovery.ResolveRunDirForProject(strings.TrimSpace(*config), strings.TrimSpace(*project), strings.TrimSpace(*runsDir))
+		if err != nil {
+			return err
+		}
+		targetRunDir = resolved
+	}
+	if targetRunDir == "" && strings.TrimSpace(*runID) == "" && !*latest {
+		return errors.New("job target required: set --project, --run-id, --run-dir, or --latest")
+	}
+
+	global, err := discovery.ReadGlobalSettings(strings.TrimSpace(*config))
+	if err != nil {
+		return err
+	}
+	res, err := archive.ShowJob(archive.JobShowOptions{
+		RunID:        strings.TrimSpace(*runID),
+		RunDir:       targetRunDir,
+		RunsDir:      strings.TrimSpace(*runsDir),
+		Latest:       *latest,
+		Video:        strings.TrimSpace(*video),
+		FailureRules: global.FailureRules,
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(res)
+	}
+
+	j := res.Job
+	fmt.Printf("run: %s\n", res.RunID)
+	fmt.Printf("job: %s\n", j.JobID)
+	fmt.Printf("video: %s\n", j.VideoID)
+	fmt.Printf("title: %s\n", j.Title)
+	fmt.Printf("url: %s\n", j.VideoURL)
+	if j.Reason != "" {
+		fmt.Printf("status: %s (%s)\n", j.Status, j.Reason)
+	} else {
+		fmt.Printf("status: %s\n", j.Status)
+	}
+	fmt.Printf("attempts: %d\n", j.Attempts)
+	if j.LastAttemptAt != "" {
+		fmt.Printf("last_attempt_at: %s\n", j.LastAttemptAt)
+	}
+	if j.CompletedAt != "" {
+		fmt.Printf("completed_at: %s\n", j.CompletedAt)
+	}
+	if j.MediaPath != "" {
+		fmt.Printf("media: %s\n", j.MediaPath)
+	}
+	if j.LastError != "" {
+		fmt.Printf("last_error: %s\n", j.LastError)
+	}
+	if res.Remediation != "" {
+		fmt.Printf("hint: %s\n", res.Remediation)
+	}
+
+	fmt.Println("history:")
+	if len(j.History) == 0 {
+		fmt.Println("  (none recorded)")
+	}
+	for _, ev := range j.History {
+		if ev.Kind != archive.JobEventAttempt {
+			fmt.Printf("  %s  %s %s -> %s\n", ev.At, ev.Kind, defaultIfEmpty(ev.From, "?"), defaultIfEmpty(ev.To, "?"))
+			continue
+		}
+		line := fmt.Sprintf("  %s  %s", defaultIfEmpty(ev.At, "(unknown time)"), ev.Outcome)
+		if ev.Reason != "" {
+			line += " (" + ev.Reason + ")"
+		}
+		if ev.ErrorClass != "" {
+			line += " class=" + ev.ErrorClass
+		}
+		if ev.DurationMS > 0 {
+			line += " took=" + (time.Duration(ev.DurationMS) * time.Millisecond).Round(100*time.Millisecond).String()
+		}
+		if ev.Worker > 0 {
+			line += fmt.Sprintf(" worker=%d", ev.Worker)
+		}
+		if ev.Proxy != "" {
+			line += " proxy=" + ev.Proxy
+		}
+		fmt.Println(line)
+		if ev.Error != "" {
+			fmt.Printf("      %s\n", ev.Error)
+		}
+	}
+
+	if res.LogExists {
+		fmt.Printf("log: %s\n", res.LogPath)
+	} else {
+		fmt.Printf("log: %s (not found)\n", res.LogPath)
+	}
+	return nil
+}
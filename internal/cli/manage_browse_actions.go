@@ -50,10 +50,20 @@ func toggleProjectActiveCmd(configPath string, project discovery.Project) tea.Cm
 			Fragments:           project.Fragments,
 			Order:               project.Order,
 			Quality:             project.Quality,
+			VideoCodec:          project.VideoCodec,
+			HDR:                 project.HDR,
+			Container:           project.Container,
 			JSRuntime:           project.JSRuntime,
 			DeliveryMode:        project.DeliveryMode,
 			NoSubs:              project.NoSubs,
 			SubLangs:            project.SubLangs,
+			LiveChat:            project.LiveChat,
+			LiveChatFormat:      project.LiveChatFormat,
+			Schedule:            project.Schedule,
+			WriteThumbnails:     project.WriteThumbnails,
+			AudioOnly:           project.AudioOnly,
+			Storage:             project.Storage,
+			Mirrors:             project.Mirrors,
 			Active:              boolPtr(nextActive),
 			ReplaceIfNameExists: true,
 		}
@@ -58,12 +58,22 @@ type manageForm struct {
 	Input       textinput.Model
 	Error       string
 	Saving      bool
+
+	// Storage has no form fields; edits keep the project's target as is.
+	Storage *discovery.StorageTarget
+	// Inherited maps project fields that were unset to the inherited value
+	// shown; saving that value unchanged keeps the field unset.
+	Inherited map[string]string
+	// Global keeps the global settings the global form has no fields for.
+	Global discovery.GlobalSettings
 }
 
 type manageModel struct {
 	configPath string
+	runsDir    string
 	projects   []discovery.Project
 	global     discovery.GlobalSettings
+	profiles   []discovery.ProjectProfile
 	cursor     int
 	width      int
 	height     int
@@ -73,12 +83,15 @@ type manageModel struct {
 	confirmDeleteName string
 	statusMessage     string
 	launchSyncActive  bool
+	syncLock          *discovery.SyncLockInfo
 	fatalErr          error
 }
 
 type manageLoadedMsg struct {
 	projects []discovery.Project
 	global   discovery.GlobalSettings
+	profiles []discovery.ProjectProfile
+	syncLock *discovery.SyncLockInfo
 	err      error
 }
 
@@ -104,6 +117,7 @@ var (
 func runManage(args []string) error {
 	fs := flag.NewFlagSet("manage", flag.ContinueOnError)
 	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
 	fs.SetOutput(flag.CommandLine.Output())
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -114,6 +128,7 @@ func runManage(args []string) error {
 
 	m := manageModel{
 		configPath: strings.TrimSpace(*config),
+		runsDir:    defaultIfEmpty(strings.TrimSpace(*runsDir), "runs"),
 		mode:       manageModeBrowse,
 		cursor:     0,
 	}
@@ -132,6 +147,7 @@ func runManage(args []string) error {
 				"--all-projects",
 				"--active-only",
 				"--config", fm.configPath,
+				"--runs-dir", fm.runsDir,
 			})
 		}
 		return fm.fatalErr
@@ -140,7 +156,7 @@ func runManage(args []string) error {
 }
 
 func (m manageModel) Init() tea.Cmd {
-	return loadProjectsCmd(m.configPath)
+	return loadProjectsCmd(m.configPath, m.runsDir)
 }
 
 func (m manageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -159,6 +175,8 @@ func (m manageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.projects = msg.projects
 		m.global = msg.global
+		m.profiles = msg.profiles
+		m.syncLock = msg.syncLock
 		if m.cursor < 0 {
 			m.cursor = 0
 		}
@@ -180,7 +198,7 @@ func (m manageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.mode = manageModeBrowse
 		m.form = nil
 		m.statusMessage = msg.message
-		return m, loadProjectsCmd(m.configPath)
+		return m, loadProjectsCmd(m.configPath, m.runsDir)
 	case manageDeleteMsg:
 		if msg.err != nil {
 			m.statusMessage = "error: " + msg.err.Error()
@@ -191,7 +209,7 @@ func (m manageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.mode = manageModeBrowse
 		m.confirmDeleteName = ""
 		m.statusMessage = msg.message
-		return m, loadProjectsCmd(m.configPath)
+		return m, loadProjectsCmd(m.configPath, m.runsDir)
 	}
 
 	keyMsg, ok := msg.(tea.KeyMsg)
@@ -237,11 +255,11 @@ func (m manageModel) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, toggleProjectActiveCmd(m.configPath, selected)
 	case "n":
 		m.mode = manageModeForm
-		m.form = newManageForm(nil, m.width)
+		m.form = newManageForm(nil, m.global, m.profiles, m.width)
 		m.statusMessage = ""
 		return m, nil
 	case "r":
-		return m, loadProjectsCmd(m.configPath)
+		return m, loadProjectsCmd(m.configPath, m.runsDir)
 	case "enter", "e":
 		if m.isActionCursor() {
 			switch m.selectedActionIndex() {
@@ -259,7 +277,7 @@ func (m manageModel) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		if m.cursor == len(m.projects) {
 			m.mode = manageModeForm
-			m.form = newManageForm(nil, m.width)
+			m.form = newManageForm(nil, m.global, m.profiles, m.width)
 			m.statusMessage = ""
 			return m, nil
 		}
@@ -269,7 +287,7 @@ func (m manageModel) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		selected := m.projects[m.cursor]
 		m.mode = manageModeForm
-		m.form = newManageForm(&selected, m.width)
+		m.form = newManageForm(&selected, m.global, m.profiles, m.width)
 		m.statusMessage = ""
 		return m, nil
 	case "d":
@@ -367,6 +385,8 @@ func (m manageModel) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.form.Error = err.Error()
 				return m, nil
 			}
+			// Settings without a form field are carried over unchanged.
+			global.FailureRules = m.global.FailureRules
 			m.form.Error = ""
 			m.form.Saving = true
 			return m, saveGlobalSettingsCmd(m.configPath, global)
@@ -508,13 +528,20 @@ func (m manageModel) renderDetailsPanel(width int) string {
 			lines = append(lines, "Sync Active Projects")
 			lines = append(lines, "")
 			lines = append(lines, "Runs sync for all projects with active=yes.")
-			lines = append(lines, "Press Enter to launch sync view.")
+			if m.syncLock != nil {
+				lines = append(lines, "")
+				lines = append(lines, manageErrorStyle.Render(describeSyncLock(*m.syncLock)))
+				lines = append(lines, "Enter waits for it to finish before syncing.")
+			} else {
+				lines = append(lines, "Press Enter to launch sync view.")
+			}
 		case manageActionGlobalSettings:
 			lines = append(lines, "Global Settings")
 			lines = append(lines, kv("workers", strconv.Itoa(m.global.Workers)))
 			lines = append(lines, kv("download_limit_mb_s", formatFloat(m.global.DownloadLimitMBps)))
 			lines = append(lines, kv("proxy_mode", m.global.ProxyMode))
 			lines = append(lines, kv("proxies", strconv.Itoa(len(m.global.Proxies))))
+			lines = append(lines, kv("dedup_mode", m.global.DedupMode))
 			lines = append(lines, "")
 			lines = append(lines, "Press Enter to edit global defaults.")
 		default:
@@ -526,23 +553,38 @@ func (m manageModel) renderDetailsPanel(width int) string {
 		lines = append(lines, "Press Enter or n to create a project.")
 		lines = append(lines, "The wizard guides source URL, defaults, and settings.")
 	} else if len(m.projects) > 0 {
-		p := m.projects[m.cursor]
+		// Show what a sync would use; `settings explain` names the layers.
+		p := discovery.EffectiveProject(m.projects[m.cursor], m.global, m.profiles)
 		lines = append(lines, "Project Details")
 		lines = append(lines, "")
 		lines = append(lines, kv("name", p.Name))
 		lines = append(lines, kv("source", p.SourceURL))
 		lines = append(lines, kv("active", yesNo(isProjectActive(p))))
-		lines = append(lines, kv("quality", defaultIfEmpty(p.Quality, discovery.DefaultQuality)))
-		lines = append(lines, kv("js_runtime", defaultIfEmpty(p.JSRuntime, discovery.DefaultJSRuntime)))
+		lines = append(lines, kv("profile", p.Profile))
+		lines = append(lines, kv("quality", p.Quality))
+		lines = append(lines, kv("video_codec", defaultIfEmpty(p.VideoCodec, "auto")))
+		lines = append(lines, kv("hdr", defaultIfEmpty(p.HDR, "auto")))
+		lines = append(lines, kv("container", defaultIfEmpty(p.Container, "auto")))
+		lines = append(lines, kv("js_runtime", p.JSRuntime))
 		lines = append(lines, kv("output_dir", defaultIfEmpty(p.OutputDir, "(run default)")))
 		lines = append(lines, kv("browser_cookies", yesNo(strings.TrimSpace(p.CookiesFromBrowser) != "")))
 		lines = append(lines, kv("cookies_file", yesNo(strings.TrimSpace(p.CookiesPath) != "")))
-		lines = append(lines, kv("workers", formatWorkerOverride(p.Workers)))
-		lines = append(lines, kv("fragments", formatIntDefault(p.Fragments)))
-		lines = append(lines, kv("order", defaultIfEmpty(p.Order, discovery.DefaultOrder)))
+		lines = append(lines, kv("workers", strconv.Itoa(p.Workers)))
+		lines = append(lines, kv("fragments", strconv.Itoa(p.Fragments)))
+		lines = append(lines, kv("order", p.Order))
 		lines = append(lines, kv("delivery", defaultIfEmpty(p.DeliveryMode, "(sync default: auto)")))
-		lines = append(lines, kv("subtitles", yesNo(!p.NoSubs)))
+		lines = append(lines, kv("subtitles", yesNo(!boolValue(p.NoSubs))))
 		lines = append(lines, kv("subtitle_language", normalizeSubtitleChoice(p.SubLangs)))
+		lines = append(lines, kv("live_chat", yesNo(boolValue(p.LiveChat))))
+		lines = append(lines, kv("thumbnails", yesNo(boolValue(p.WriteThumbnails))))
+		lines = append(lines, kv("audio_only", yesNo(boolValue(p.AudioOnly))))
+		lines = append(lines, kv("schedule", defaultIfEmpty(p.Schedule, "(none)")))
+		if p.Storage != nil {
+			lines = append(lines, kv("storage", p.Storage.String()))
+		}
+		if len(p.Mirrors) > 0 {
+			lines = append(lines, kv("mirrors", strings.Join(p.Mirrors, ", ")))
+		}
 	} else {
 		lines = append(lines, "No projects configured")
 		lines = append(lines, "")
@@ -557,6 +599,9 @@ func (m manageModel) renderDetailsPanel(width int) string {
 
 func (m manageModel) renderStatusLine(width int) string {
 	msg := strings.TrimSpace(m.statusMessage)
+	if msg == "" && m.syncLock != nil {
+		msg = describeSyncLock(*m.syncLock) + "; press r to refresh."
+	}
 	if msg == "" {
 		msg = "Tip: space toggles project active; go down to Actions to sync active projects."
 	}
@@ -627,13 +672,17 @@ func (m manageModel) viewDeleteConfirm() string {
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, panel)
 }
 
-func loadProjectsCmd(configPath string) tea.Cmd {
+func loadProjectsCmd(configPath, runsDir string) tea.Cmd {
 	return func() tea.Msg {
 		reg, err := discovery.LoadProjects(configPath)
 		if err != nil {
 			return manageLoadedMsg{err: err}
 		}
-		return manageLoadedMsg{projects: reg.Projects, global: reg.Global}
+		msg := manageLoadedMsg{projects: reg.Projects, global: reg.Global, profiles: reg.Profiles}
+		if info, err := discovery.InspectSyncLock(runsDir, reg.Global.RunLockTTL()); err == nil && info.Locked {
+			msg.syncLock = &info
+		}
+		return msg
 	}
 }
 
@@ -659,238 +708,3 @@ func deleteProjectCmd(configPath, name string) tea.Cmd {
 		return manageDeleteMsg{message: "project removed: " + name}
 	}
 }
-
-func newManageForm(existing *discovery.Project, width int) *manageForm {
-	f := &manageForm{Kind: manageFormKindProject}
-	if existing == nil {
-		f.Title = "New Project Wizard"
-		f.IsEdit = false
-		f.Fields = []manageFormField{
-			{Key: "source", Label: "Source URL", Help: "Playlist or channel URL", Kind: manageFieldString, Required: true},
-			{Key: "name", Label: "Project Name", Help: "Optional; leave empty for auto-name", Kind: manageFieldString},
-			{Key: "active", Label: "Active", Help: "Included in 'Sync Active Projects'", Kind: manageFieldBool, Value: "y"},
-			{Key: "quality", Label: "Quality", Help: "Best, 1080p, or 720p", Kind: manageFieldSelect, Value: discovery.DefaultQuality, Options: []string{"best", "1080p", "720p"}},
-			{Key: "js_runtime", Label: "JS Runtime", Help: "Extractor JavaScript runtime. Auto follows yt-dlp default.", Kind: manageFieldSelect, Value: discovery.DefaultJSRuntime, Options: []string{discovery.JSRuntimeAuto, discovery.JSRuntimeDeno, discovery.JSRuntimeNode, discovery.JSRuntimeQuickJS, discovery.JSRuntimeBun}},
-			{Key: "workers", Label: "Workers", Help: "Project override; 0 inherits global/default", Kind: manageFieldInt, Value: "0"},
-			{Key: "fragments", Label: "Fragments", Help: "How many chunks per video stream", Kind: manageFieldInt, Value: strconv.Itoa(discovery.DefaultFragments)},
-			{Key: "order", Label: "Download Order", Help: "Oldest first is safest for large backfills", Kind: manageFieldSelect, Value: discovery.DefaultOrder, Options: []string{"oldest", "newest", "manifest"}},
-			{Key: "subtitles", Label: "Subtitles", Help: "Download subtitles when available", Kind: manageFieldBool, Value: "y"},
-			{Key: "sub_langs", Label: "Subtitle Language", Help: "English or all available languages", Kind: manageFieldSelect, Value: discovery.DefaultSubtitleLanguage, Options: []string{"english", "all"}},
-			{Key: "use_browser_cookies", Label: "Browser Cookies", Help: browserCookiesFormHelp, Kind: manageFieldBool, Value: "n"},
-			{Key: "cookies_path", Label: "Cookies File Path", Help: "Optional cookies.txt path", Kind: manageFieldString},
-			{Key: "output_dir", Label: "Output Dir", Help: "Optional override", Kind: manageFieldString},
-			{Key: "delivery", Label: "Delivery Mode", Help: "Auto is recommended", Kind: manageFieldSelect, Value: "auto", Options: []string{"auto", "fragmented"}},
-		}
-	} else {
-		f.Title = "Edit Project: " + existing.Name
-		f.IsEdit = true
-		f.ProjectName = existing.Name
-		f.Fields = []manageFormField{
-			{Key: "source", Label: "Source URL", Help: "Playlist or channel URL", Kind: manageFieldString, Required: true, Value: existing.SourceURL},
-			{Key: "active", Label: "Active", Help: "Included in 'Sync Active Projects'", Kind: manageFieldBool, Value: boolToYN(isProjectActive(*existing))},
-			{Key: "quality", Label: "Quality", Help: "Best, 1080p, or 720p", Kind: manageFieldSelect, Value: defaultIfEmpty(existing.Quality, discovery.DefaultQuality), Options: []string{"best", "1080p", "720p"}},
-			{Key: "js_runtime", Label: "JS Runtime", Help: "Extractor JavaScript runtime. Auto follows yt-dlp default.", Kind: manageFieldSelect, Value: defaultIfEmpty(existing.JSRuntime, discovery.DefaultJSRuntime), Options: []string{discovery.JSRuntimeAuto, discovery.JSRuntimeDeno, discovery.JSRuntimeNode, discovery.JSRuntimeQuickJS, discovery.JSRuntimeBun}},
-			{Key: "workers", Label: "Workers", Help: "Project override; 0 inherits global/default", Kind: manageFieldInt, Value: strconv.Itoa(existing.Workers)},
-			{Key: "fragments", Label: "Fragments", Help: "How many chunks per video stream", Kind: manageFieldInt, Value: strconv.Itoa(maxInt(existing.Fragments, discovery.DefaultFragments))},
-			{Key: "order", Label: "Download Order", Help: "Oldest first is safest for large backfills", Kind: manageFieldSelect, Value: defaultIfEmpty(existing.Order, discovery.DefaultOrder), Options: []string{"oldest", "newest", "manifest"}},
-			{Key: "subtitles", Label: "Subtitles", Help: "Download subtitles when available", Kind: manageFieldBool, Value: boolToYN(!existing.NoSubs)},
-			{Key: "sub_langs", Label: "Subtitle Language", Help: "English or all available languages", Kind: manageFieldSelect, Value: normalizeSubtitleChoice(existing.SubLangs), Options: []string{"english", "all"}},
-			{Key: "use_browser_cookies", Label: "Browser Cookies", Help: browserCookiesFormHelp, Kind: manageFieldBool, Value: boolToYN(strings.TrimSpace(existing.CookiesFromBrowser) != "")},
-			{Key: "cookies_path", Label: "Cookies File Path", Help: "Optional cookies.txt path", Kind: manageFieldString, Value: existing.CookiesPath},
-			{Key: "output_dir", Label: "Output Dir", Help: "Optional override", Kind: manageFieldString, Value: existing.OutputDir},
-			{Key: "delivery", Label: "Delivery Mode", Help: "Auto is recommended", Kind: manageFieldSelect, Value: defaultIfEmpty(existing.DeliveryMode, "auto"), Options: []string{"auto", "fragmented"}},
-		}
-	}
-
-	input := textinput.New()
-	input.Prompt = "> "
-	input.CharLimit = 1024
-	input.Width = clampInt(width-8, 20, 120)
-	f.Input = input
-	f.loadFieldIntoInput()
-	f.Input.Focus()
-	return f
-}
-
-func (f *manageForm) currentField() manageFormField {
-	if len(f.Fields) == 0 {
-		return manageFormField{}
-	}
-	if f.Index < 0 {
-		f.Index = 0
-	}
-	if f.Index >= len(f.Fields) {
-		f.Index = len(f.Fields) - 1
-	}
-	return f.Fields[f.Index]
-}
-
-func (f *manageForm) commitInput() {
-	if f == nil || len(f.Fields) == 0 {
-		return
-	}
-	f.Fields[f.Index].Value = strings.TrimSpace(f.Input.Value())
-}
-
-func (f *manageForm) loadFieldIntoInput() {
-	if f == nil || len(f.Fields) == 0 {
-		return
-	}
-	f.Input.SetValue(f.Fields[f.Index].Value)
-	f.Input.CursorEnd()
-}
-
-func (f *manageForm) toggleBoolField() {
-	if f == nil || len(f.Fields) == 0 {
-		return
-	}
-	curr := f.Fields[f.Index]
-	if curr.Kind != manageFieldBool {
-		return
-	}
-	v, ok := parseBool(curr.Value)
-	if !ok {
-		v = false
-	}
-	curr.Value = boolToYN(!v)
-	f.Fields[f.Index] = curr
-	f.loadFieldIntoInput()
-}
-
-func (f *manageForm) setBoolField(v bool) {
-	if f == nil || len(f.Fields) == 0 {
-		return
-	}
-	curr := f.Fields[f.Index]
-	if curr.Kind != manageFieldBool {
-		return
-	}
-	curr.Value = boolToYN(v)
-	f.Fields[f.Index] = curr
-	f.loadFieldIntoInput()
-}
-
-func (f *manageForm) nextSelectOption() {
-	if f == nil || len(f.Fields) == 0 {
-		return
-	}
-	curr := f.Fields[f.Index]
-	if curr.Kind != manageFieldSelect || len(curr.Options) == 0 {
-		return
-	}
-	current := strings.TrimSpace(curr.Value)
-	pos := 0
-	for i, opt := range curr.Options {
-		if strings.EqualFold(opt, current) {
-			pos = i
-			break
-		}
-	}
-	pos = (pos + 1) % len(curr.Options)
-	curr.Value = curr.Options[pos]
-	f.Fields[f.Index] = curr
-	f.loadFieldIntoInput()
-}
-
-func (f *manageForm) prevSelectOption() {
-	if f == nil || len(f.Fields) == 0 {
-		return
-	}
-	curr := f.Fields[f.Index]
-	if curr.Kind != manageFieldSelect || len(curr.Options) == 0 {
-		return
-	}
-	current := strings.TrimSpace(curr.Value)
-	pos := 0
-	for i, opt := range curr.Options {
-		if strings.EqualFold(opt, current) {
-			pos = i
-			break
-		}
-	}
-	pos = (pos - 1 + len(curr.Options)) % len(curr.Options)
-	curr.Value = curr.Options[pos]
-	f.Fields[f.Index] = curr
-	f.loadFieldIntoInput()
-}
-
-func (f *manageForm) toAddProjectOptions(configPath string) (discovery.AddProjectOptions, error) {
-	if f == nil {
-		return discovery.AddProjectOptions{}, errors.New("internal form error")
-	}
-	vals := make(map[string]string, len(f.Fields))
-	for _, field := range f.Fields {
-		v := strings.TrimSpace(field.Value)
-		if field.Required && v == "" {
-			return discovery.AddProjectOptions{}, fmt.Errorf("%s is required", strings.ToLower(field.Label))
-		}
-		switch field.Kind {
-		case manageFieldInt:
-			if v == "" {
-				v = "0"
-			}
-			n, err := strconv.Atoi(v)
-			if err != nil || n < 0 {
-				return discovery.AddProjectOptions{}, fmt.Errorf("%s must be an integer >= 0", strings.ToLower(field.Label))
-			}
-		case manageFieldBool:
-			if _, ok := parseBool(v); !ok {
-				return discovery.AddProjectOptions{}, fmt.Errorf("%s must be y or n", strings.ToLower(field.Label))
-			}
-		case manageFieldSelect:
-			if len(field.Options) == 0 {
-				break
-			}
-			matched := false
-			for _, opt := range field.Options {
-				if strings.EqualFold(opt, v) {
-					v = opt
-					matched = true
-					break
-				}
-			}
-			if !matched {
-				return discovery.AddProjectOptions{}, fmt.Errorf("%s has invalid value", strings.ToLower(field.Label))
-			}
-		}
-		vals[field.Key] = v
-	}
-
-	workers, _ := strconv.Atoi(defaultIfEmpty(vals["workers"], "0"))
-	fragments, _ := strconv.Atoi(defaultIfEmpty(vals["fragments"], "0"))
-	subtitlesOn, _ := parseBool(defaultIfEmpty(vals["subtitles"], "y"))
-	active, _ := parseBool(defaultIfEmpty(vals["active"], "y"))
-	useBrowserCookies, _ := parseBool(defaultIfEmpty(vals["use_browser_cookies"], "n"))
-	cookiesFromBrowser := ""
-	if useBrowserCookies {
-		cookiesFromBrowser = discovery.DefaultBrowserCookieAgent
-	}
-	subLangs := normalizeSubtitleValue(vals["sub_langs"])
-
-	name := strings.TrimSpace(vals["name"])
-	replace := false
-	if f.IsEdit {
-		name = f.ProjectName
-		replace = true
-	}
-
-	return discovery.AddProjectOptions{
-		ConfigPath:          configPath,
-		Name:                name,
-		SourceURL:           strings.TrimSpace(vals["source"]),
-		Profile:             discovery.DefaultProfileName,
-		OutputDir:           strings.TrimSpace(vals["output_dir"]),
-		CookiesPath:         strings.TrimSpace(vals["cookies_path"]),
-		CookiesFromBrowser:  cookiesFromBrowser,
-		Workers:             workers,
-		Fragments:           fragments,
-		Order:               strings.TrimSpace(vals["order"]),
-		Quality:             strings.TrimSpace(vals["quality"]),
-		JSRuntime:           strings.TrimSpace(vals["js_runtime"]),
-		DeliveryMode:        strings.TrimSpace(vals["delivery"]),
-		NoSubs:              !subtitlesOn,
-		SubLangs:            subLangs,
-		Active:              boolPtr(active),
-		ReplaceIfNameExists: replace,
-	}, nil
-}
@@ -4,12 +4,14 @@ import (
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"yt-vod-manager/internal/discovery"
 )
 
 func TestManageBoolFieldSupportsYN(t *testing.T) {
 	m := manageModel{
 		mode: manageModeForm,
-		form: newManageForm(nil, 80),
+		form: newManageForm(nil, discovery.GlobalSettings{}, nil, 80),
 	}
 	if m.form == nil {
 		t.Fatal("expected form")
@@ -35,7 +37,7 @@ func TestManageBoolFieldSupportsYN(t *testing.T) {
 func TestManageBoolFieldSupportsArrowAndSpace(t *testing.T) {
 	m := manageModel{
 		mode: manageModeForm,
-		form: newManageForm(nil, 80),
+		form: newManageForm(nil, discovery.GlobalSettings{}, nil, 80),
 	}
 	if m.form == nil {
 		t.Fatal("expected form")
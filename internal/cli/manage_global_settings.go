@@ -13,13 +13,19 @@ import (
 
 func newManageGlobalForm(global discovery.GlobalSettings, width int) *manageForm {
 	f := &manageForm{
-		Kind:  manageFormKindGlobal,
-		Title: "Global Settings",
+		Kind:   manageFormKindGlobal,
+		Title:  "Global Settings",
+		Global: global,
 		Fields: []manageFormField{
 			{Key: "workers", Label: "Workers", Help: "Default workers when project override is 0", Kind: manageFieldInt, Value: strconv.Itoa(global.Workers)},
 			{Key: "download_limit_mb_s", Label: "Download Limit MB/s", Help: "0 disables global rate limit", Kind: manageFieldString, Value: formatFloat(global.DownloadLimitMBps)},
 			{Key: "proxy_mode", Label: "Proxy Mode", Help: "off or per_worker", Kind: manageFieldSelect, Value: defaultIfEmpty(global.ProxyMode, discovery.ProxyModeOff), Options: []string{discovery.ProxyModeOff, discovery.ProxyModePerWorker}},
 			{Key: "proxies", Label: "Proxies", Help: "Comma-separated list. One proxy per worker when mode=per_worker.", Kind: manageFieldString, Value: strings.Join(global.Proxies, ", ")},
+			{Key: "dedup_mode", Label: "Dedup Mode", Help: "Link videos already archived by another project instead of downloading again", Kind: manageFieldSelect, Value: defaultIfEmpty(global.DedupMode, discovery.DefaultDedupMode), Options: []string{discovery.DedupModeOff, discovery.DedupModeHardlink, discovery.DedupModeReflink, discovery.DedupModeSymlink}},
+			{Key: "lock_ttl_minutes", Label: "Lock TTL (min)", Help: "Minutes a run lock survives without heartbeat before it is recovered", Kind: manageFieldInt, Value: strconv.Itoa(global.LockTTLMinutes)},
+			{Key: "snapshot_retention", Label: "Snapshot Retention", Help: "Raw source snapshots kept per run for diff", Kind: manageFieldInt, Value: strconv.Itoa(global.SnapshotRetention)},
+			{Key: "mirrors", Label: "Mirrors", Help: "Comma-separated mirror roots; each project is copied to <root>/<project>", Kind: manageFieldString, Value: strings.Join(global.Mirrors, ", ")},
+			{Key: "metrics_textfile", Label: "Metrics Textfile", Help: "Prometheus textfile written after each sync (empty disables)", Kind: manageFieldString, Value: global.MetricsTextfile},
 		},
 	}
 
@@ -74,18 +80,33 @@ func (f *manageForm) toGlobalSettings() (discovery.GlobalSettings, error) {
 		return discovery.GlobalSettings{}, fmt.Errorf("download limit mb/s must be a number >= 0")
 	}
 
+	lockTTL, _ := strconv.Atoi(defaultIfEmpty(vals["lock_ttl_minutes"], "0"))
+	if lockTTL <= 0 {
+		return discovery.GlobalSettings{}, fmt.Errorf("lock ttl must be >= 1 minute")
+	}
+
+	snapshotRetention, _ := strconv.Atoi(defaultIfEmpty(vals["snapshot_retention"], "0"))
+	if snapshotRetention <= 0 {
+		return discovery.GlobalSettings{}, fmt.Errorf("snapshot retention must be >= 1")
+	}
+
 	mode := strings.ToLower(strings.TrimSpace(vals["proxy_mode"]))
 	proxies := parseProxyValueList(vals["proxies"])
 	if mode == discovery.ProxyModePerWorker && len(proxies) == 0 {
 		return discovery.GlobalSettings{}, fmt.Errorf("proxy mode per_worker requires at least one proxy")
 	}
 
-	return discovery.GlobalSettings{
-		Workers:           workers,
-		DownloadLimitMBps: downloadLimit,
-		ProxyMode:         mode,
-		Proxies:           proxies,
-	}, nil
+	global := f.Global
+	global.Workers = workers
+	global.DownloadLimitMBps = downloadLimit
+	global.ProxyMode = mode
+	global.Proxies = proxies
+	global.DedupMode = vals["dedup_mode"]
+	global.MetricsTextfile = vals["metrics_textfile"]
+	global.LockTTLMinutes = lockTTL
+	global.SnapshotRetention = snapshotRetention
+	global.Mirrors = parseProxyValueList(vals["mirrors"])
+	return global, nil
 }
 
 func saveGlobalSettingsCmd(configPath string, global discovery.GlobalSettings) tea.Cmd {
@@ -99,11 +120,12 @@ func saveGlobalSettingsCmd(configPath string, global discovery.GlobalSettings) t
 		}
 		return manageSaveMsg{
 			message: fmt.Sprintf(
-				"updated global settings: workers=%d limit=%sMB/s proxy_mode=%s proxies=%d",
+				"updated global settings: workers=%d limit=%sMB/s proxy_mode=%s proxies=%d dedup_mode=%s",
 				res.Global.Workers,
 				formatFloat(res.Global.DownloadLimitMBps),
 				res.Global.ProxyMode,
 				len(res.Global.Proxies),
+				res.Global.DedupMode,
 			),
 		}
 	}
@@ -2,7 +2,6 @@ package cli
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -98,20 +97,6 @@ func defaultIfEmpty(v, def string) string {
 	return v
 }
 
-func formatIntDefault(v int) string {
-	if v <= 0 {
-		return "(runtime default)"
-	}
-	return strconv.Itoa(v)
-}
-
-func formatWorkerOverride(v int) string {
-	if v <= 0 {
-		return "(inherit global/default)"
-	}
-	return strconv.Itoa(v)
-}
-
 func yesNo(v bool) string {
 	if v {
 		return "yes"
@@ -0,0 +1,317 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"yt-vod-manager/internal/discovery"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+var (
+	manageQualityOptions   = []string{"best", "2160p", "1440p", "1080p", "720p", "480p"}
+	manageHDROptions       = []string{"auto", "on", "off"}
+	manageContainerOptions = []string{"auto", "mp4", "mkv"}
+)
+
+func newManageForm(existing *discovery.Project, global discovery.GlobalSettings, profiles []discovery.ProjectProfile, width int) *manageForm {
+	f := &manageForm{Kind: manageFormKindProject}
+	base := discovery.Project{}
+	if existing != nil {
+		base = *existing
+	}
+	// Fields show the values a sync would use; unset settings stay unset on
+	// save unless they are changed.
+	p := discovery.EffectiveProject(base, global, profiles)
+	f.Fields = []manageFormField{
+		{Key: "source", Label: "Source URL", Help: "Playlist or channel URL", Kind: manageFieldString, Required: true, Value: base.SourceURL},
+		{Key: "active", Label: "Active", Help: "Included in 'Sync Active Projects'", Kind: manageFieldBool, Value: boolToYN(isProjectActive(base))},
+		{Key: "profile", Label: "Profile", Help: "Named settings bundle (see `profile list`); default uses the global settings", Kind: manageFieldString, Value: defaultIfEmpty(base.Profile, discovery.DefaultProfileName)},
+		{Key: "quality", Label: "Quality", Help: "Height cap; best keeps the highest available", Kind: manageFieldSelect, Value: p.Quality, Options: manageQualityOptions},
+		{Key: "video_codec", Label: "Video Codec", Help: "Preference order like av1,vp9,h264; empty for auto", Kind: manageFieldString, Value: p.VideoCodec},
+		{Key: "hdr", Label: "HDR", Help: "Prefer (on) or avoid (off) HDR formats", Kind: manageFieldSelect, Value: defaultIfEmpty(p.HDR, "auto"), Options: manageHDROptions},
+		{Key: "container", Label: "Container", Help: "Remux into mp4 or mkv; auto keeps yt-dlp output", Kind: manageFieldSelect, Value: defaultIfEmpty(p.Container, "auto"), Options: manageContainerOptions},
+		{Key: "js_runtime", Label: "JS Runtime", Help: "Extractor JavaScript runtime. Auto follows yt-dlp default.", Kind: manageFieldSelect, Value: p.JSRuntime, Options: []string{discovery.JSRuntimeAuto, discovery.JSRuntimeDeno, discovery.JSRuntimeNode, discovery.JSRuntimeQuickJS, discovery.JSRuntimeBun}},
+		{Key: "workers", Label: "Workers", Help: "Project override; 0 inherits global/default", Kind: manageFieldInt, Value: strconv.Itoa(base.Workers)},
+		{Key: "fragments", Label: "Fragments", Help: "How many chunks per video stream", Kind: manageFieldInt, Value: strconv.Itoa(p.Fragments)},
+		{Key: "order", Label: "Download Order", Help: "Oldest first is safest for large backfills", Kind: manageFieldSelect, Value: p.Order, Options: []string{"oldest", "newest", "manifest"}},
+		{Key: "subtitles", Label: "Subtitles", Help: "Download subtitles when available", Kind: manageFieldBool, Value: boolToYN(!boolValue(p.NoSubs))},
+		{Key: "sub_langs", Label: "Subtitle Language", Help: "English or all available languages", Kind: manageFieldSelect, Value: normalizeSubtitleChoice(p.SubLangs), Options: []string{"english", "all"}},
+		{Key: "live_chat", Label: "Live Chat", Help: "Archive chat replays of past streams", Kind: manageFieldBool, Value: boolToYN(boolValue(p.LiveChat))},
+		{Key: "live_chat_format", Label: "Live Chat Format", Help: "Timestamped text or ASS subtitle track", Kind: manageFieldSelect, Value: p.LiveChatFormat, Options: []string{discovery.LiveChatFormatText, discovery.LiveChatFormatASS}},
+		{Key: "thumbnails", Label: "Thumbnails", Help: "Save each video's thumbnail next to the media", Kind: manageFieldBool, Value: boolToYN(boolValue(p.WriteThumbnails))},
+		{Key: "audio_only", Label: "Audio Only", Help: "Download and extract only the audio track", Kind: manageFieldBool, Value: boolToYN(boolValue(p.AudioOnly))},
+		{Key: "schedule", Label: "Schedule", Help: "Daemon cadence: interval like 6h or cron like 0 3 * * *; empty to skip", Kind: manageFieldString, Value: p.Schedule},
+		{Key: "use_browser_cookies", Label: "Browser Cookies", Help: browserCookiesFormHelp, Kind: manageFieldBool, Value: boolToYN(strings.TrimSpace(p.CookiesFromBrowser) != "")},
+		{Key: "cookies_path", Label: "Cookies File Path", Help: "Optional cookies.txt path", Kind: manageFieldString, Value: p.CookiesPath},
+		{Key: "output_dir", Label: "Output Dir", Help: "Optional override", Kind: manageFieldString, Value: p.OutputDir},
+		{Key: "mirrors", Label: "Mirrors", Help: "Comma-separated copy destinations; empty uses the global mirrors", Kind: manageFieldString, Value: strings.Join(base.Mirrors, ", ")},
+		{Key: "delivery", Label: "Delivery Mode", Help: "Auto is recommended", Kind: manageFieldSelect, Value: defaultIfEmpty(p.DeliveryMode, "auto"), Options: []string{"auto", "fragmented"}},
+	}
+	if existing == nil {
+		f.Title = "New Project Wizard"
+		name := manageFormField{Key: "name", Label: "Project Name", Help: "Optional; leave empty for auto-name", Kind: manageFieldString}
+		f.Fields = append(f.Fields[:1], append([]manageFormField{name}, f.Fields[1:]...)...)
+	} else {
+		f.Title = "Edit Project: " + existing.Name
+		f.IsEdit = true
+		f.ProjectName = existing.Name
+		f.Storage = existing.Storage
+	}
+
+	unset := map[string]bool{
+		"quality":             base.Quality == "",
+		"video_codec":         base.VideoCodec == "",
+		"hdr":                 base.HDR == "",
+		"container":           base.Container == "",
+		"js_runtime":          base.JSRuntime == "",
+		"fragments":           base.Fragments == 0,
+		"order":               base.Order == "",
+		"subtitles":           base.NoSubs == nil,
+		"sub_langs":           base.SubLangs == "",
+		"live_chat":           base.LiveChat == nil,
+		"live_chat_format":    base.LiveChatFormat == "",
+		"thumbnails":          base.WriteThumbnails == nil,
+		"audio_only":          base.AudioOnly == nil,
+		"schedule":            base.Schedule == "",
+		"use_browser_cookies": base.CookiesFromBrowser == "",
+		"cookies_path":        base.CookiesPath == "",
+		"output_dir":          base.OutputDir == "",
+		"delivery":            base.DeliveryMode == "",
+	}
+	f.Inherited = make(map[string]string, len(unset))
+	for _, field := range f.Fields {
+		if unset[field.Key] {
+			f.Inherited[field.Key] = field.Value
+		}
+	}
+
+	input := textinput.New()
+	input.Prompt = "> "
+	input.CharLimit = 1024
+	input.Width = clampInt(width-8, 20, 120)
+	f.Input = input
+	f.loadFieldIntoInput()
+	f.Input.Focus()
+	return f
+}
+
+func (f *manageForm) currentField() manageFormField {
+	if len(f.Fields) == 0 {
+		return manageFormField{}
+	}
+	if f.Index < 0 {
+		f.Index = 0
+	}
+	if f.Index >= len(f.Fields) {
+		f.Index = len(f.Fields) - 1
+	}
+	return f.Fields[f.Index]
+}
+
+func (f *manageForm) commitInput() {
+	if f == nil || len(f.Fields) == 0 {
+		return
+	}
+	f.Fields[f.Index].Value = strings.TrimSpace(f.Input.Value())
+}
+
+func (f *manageForm) loadFieldIntoInput() {
+	if f == nil || len(f.Fields) == 0 {
+		return
+	}
+	f.Input.SetValue(f.Fields[f.Index].Value)
+	f.Input.CursorEnd()
+}
+
+func (f *manageForm) toggleBoolField() {
+	if f == nil || len(f.Fields) == 0 {
+		return
+	}
+	curr := f.Fields[f.Index]
+	if curr.Kind != manageFieldBool {
+		return
+	}
+	v, ok := parseBool(curr.Value)
+	if !ok {
+		v = false
+	}
+	curr.Value = boolToYN(!v)
+	f.Fields[f.Index] = curr
+	f.loadFieldIntoInput()
+}
+
+func (f *manageForm) setBoolField(v bool) {
+	if f == nil || len(f.Fields) == 0 {
+		return
+	}
+	curr := f.Fields[f.Index]
+	if curr.Kind != manageFieldBool {
+		return
+	}
+	curr.Value = boolToYN(v)
+	f.Fields[f.Index] = curr
+	f.loadFieldIntoInput()
+}
+
+func (f *manageForm) nextSelectOption() {
+	if f == nil || len(f.Fields) == 0 {
+		return
+	}
+	curr := f.Fields[f.Index]
+	if curr.Kind != manageFieldSelect || len(curr.Options) == 0 {
+		return
+	}
+	current := strings.TrimSpace(curr.Value)
+	pos := 0
+	for i, opt := range curr.Options {
+		if strings.EqualFold(opt, current) {
+			pos = i
+			break
+		}
+	}
+	pos = (pos + 1) % len(curr.Options)
+	curr.Value = curr.Options[pos]
+	f.Fields[f.Index] = curr
+	f.loadFieldIntoInput()
+}
+
+func (f *manageForm) prevSelectOption() {
+	if f == nil || len(f.Fields) == 0 {
+		return
+	}
+	curr := f.Fields[f.Index]
+	if curr.Kind != manageFieldSelect || len(curr.Options) == 0 {
+		return
+	}
+	current := strings.TrimSpace(curr.Value)
+	pos := 0
+	for i, opt := range curr.Options {
+		if strings.EqualFold(opt, current) {
+			pos = i
+			break
+		}
+	}
+	pos = (pos - 1 + len(curr.Options)) % len(curr.Options)
+	curr.Value = curr.Options[pos]
+	f.Fields[f.Index] = curr
+	f.loadFieldIntoInput()
+}
+
+func (f *manageForm) toAddProjectOptions(configPath string) (discovery.AddProjectOptions, error) {
+	if f == nil {
+		return discovery.AddProjectOptions{}, errors.New("internal form error")
+	}
+	vals := make(map[string]string, len(f.Fields))
+	for _, field := range f.Fields {
+		v := strings.TrimSpace(field.Value)
+		if field.Required && v == "" {
+			return discovery.AddProjectOptions{}, fmt.Errorf("%s is required", strings.ToLower(field.Label))
+		}
+		switch field.Kind {
+		case manageFieldInt:
+			if v == "" {
+				v = "0"
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return discovery.AddProjectOptions{}, fmt.Errorf("%s must be an integer >= 0", strings.ToLower(field.Label))
+			}
+		case manageFieldBool:
+			if _, ok := parseBool(v); !ok {
+				return discovery.AddProjectOptions{}, fmt.Errorf("%s must be y or n", strings.ToLower(field.Label))
+			}
+		case manageFieldSelect:
+			if len(field.Options) == 0 {
+				break
+			}
+			matched := false
+			for _, opt := range field.Options {
+				if strings.EqualFold(opt, v) {
+					v = opt
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return discovery.AddProjectOptions{}, fmt.Errorf("%s has invalid value", strings.ToLower(field.Label))
+			}
+		}
+		vals[field.Key] = v
+	}
+	for key, shown := range f.Inherited {
+		if strings.EqualFold(vals[key], shown) {
+			vals[key] = ""
+		}
+	}
+
+	workers, _ := strconv.Atoi(defaultIfEmpty(vals["workers"], "0"))
+	fragments, _ := strconv.Atoi(defaultIfEmpty(vals["fragments"], "0"))
+	var noSubs, liveChat, thumbnails, audioOnly *bool
+	if v := vals["subtitles"]; v != "" {
+		subtitlesOn, _ := parseBool(v)
+		noSubs = boolPtr(!subtitlesOn)
+	}
+	if v := vals["live_chat"]; v != "" {
+		on, _ := parseBool(v)
+		liveChat = boolPtr(on)
+	}
+	if v := vals["thumbnails"]; v != "" {
+		on, _ := parseBool(v)
+		thumbnails = boolPtr(on)
+	}
+	if v := vals["audio_only"]; v != "" {
+		on, _ := parseBool(v)
+		audioOnly = boolPtr(on)
+	}
+	active, _ := parseBool(defaultIfEmpty(vals["active"], "y"))
+	useBrowserCookies, _ := parseBool(defaultIfEmpty(vals["use_browser_cookies"], "n"))
+	cookiesFromBrowser := ""
+	if useBrowserCookies {
+		cookiesFromBrowser = discovery.DefaultBrowserCookieAgent
+	}
+	subLangs := ""
+	if vals["sub_langs"] != "" {
+		subLangs = normalizeSubtitleValue(vals["sub_langs"])
+	}
+
+	name := strings.TrimSpace(vals["name"])
+	replace := false
+	if f.IsEdit {
+		name = f.ProjectName
+		replace = true
+	}
+
+	return discovery.AddProjectOptions{
+		ConfigPath:          configPath,
+		Name:                name,
+		SourceURL:           strings.TrimSpace(vals["source"]),
+		Profile:             defaultIfEmpty(vals["profile"], discovery.DefaultProfileName),
+		OutputDir:           strings.TrimSpace(vals["output_dir"]),
+		CookiesPath:         strings.TrimSpace(vals["cookies_path"]),
+		CookiesFromBrowser:  cookiesFromBrowser,
+		Workers:             workers,
+		Fragments:           fragments,
+		Order:               strings.TrimSpace(vals["order"]),
+		Quality:             strings.TrimSpace(vals["quality"]),
+		VideoCodec:          strings.TrimSpace(vals["video_codec"]),
+		HDR:                 strings.TrimSpace(vals["hdr"]),
+		Container:           strings.TrimSpace(vals["container"]),
+		JSRuntime:           strings.TrimSpace(vals["js_runtime"]),
+		DeliveryMode:        strings.TrimSpace(vals["delivery"]),
+		NoSubs:              noSubs,
+		SubLangs:            subLangs,
+		LiveChat:            liveChat,
+		LiveChatFormat:      strings.TrimSpace(vals["live_chat_format"]),
+		Schedule:            strings.TrimSpace(vals["schedule"]),
+		WriteThumbnails:     thumbnails,
+		AudioOnly:           audioOnly,
+		Storage:             f.Storage,
+		Mirrors:             parseProxyValueList(vals["mirrors"]),
+		Active:              boolPtr(active),
+		ReplaceIfNameExists: replace,
+	}, nil
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"yt-vod-manager/internal/archive"
+	"yt-vod-manager/internal/discovery"
+	"yt-vod-manager/internal/metrics"
+)
+
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+func runMetrics(args []string) error {
+	fs := flag.NewFlagSet("metrics", flag.ContinueOnError)
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	textfile := fs.String("textfile", "", "write metrics to this textfile-collector path instead of stdout")
+	listen := fs.String("listen", "", "serve /metrics on this address (e.g. 127.0.0.1:9108) until interrupted")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	configPath := strings.TrimSpace(*config)
+	runs := strings.TrimSpace(*runsDir)
+
+	if addr := strings.TrimSpace(*listen); addr != "" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		fmt.Printf("serving metrics on http://%s/metrics\n", addr)
+		return serveMetrics(ctx, addr, configPath, runs)
+	}
+
+	families, err := collectMetricFamilies(configPath, runs)
+	if err != nil {
+		return err
+	}
+	if path := strings.TrimSpace(*textfile); path != "" {
+		if err := metrics.WriteTextfile(path, families); err != nil {
+			return err
+		}
+		fmt.Printf("wrote metrics: %s\n", path)
+		return nil
+	}
+	return metrics.WriteText(os.Stdout, families)
+}
+
+// serveMetrics renders fresh metrics on every scrape and stops when ctx is done.
+func serveMetrics(ctx context.Context, addr, configPath, runsDir string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		families, err := collectMetricFamilies(configPath, runsDir)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var buf bytes.Buffer
+		if err := metrics.WriteText(&buf, families); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", metricsContentType)
+		_, _ = w.Write(buf.Bytes())
+	})
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+// collectMetricFamilies combines job gauges from project status with the
+// counters that sync persists in the metrics state file.
+func collectMetricFamilies(configPath, runsDir string) ([]metrics.Family, error) {
+	state, err := metrics.LoadState(metrics.StatePath(defaultIfEmpty(runsDir, "runs")))
+	if err != nil {
+		return nil, err
+	}
+	jobs := metrics.Family{Name: "ytvm_jobs", Help: "Jobs in each project's latest run by status.", Type: metrics.TypeGauge}
+	synced := metrics.Family{Name: "ytvm_project_synced", Help: "1 when the project has at least one run.", Type: metrics.TypeGauge}
+	status, err := discovery.ProjectStatus(discovery.ProjectStatusOptions{ConfigPath: configPath, All: true, RunsDir: runsDir})
+	if err != nil && !errors.Is(err, discovery.ErrNoProjectsConfigured) {
+		return nil, err
+	}
+	for _, row := range status.Rows {
+		project := metrics.Label{Name: "project", Value: row.Project}
+		counts := row.StatusCounts()
+		statuses := make([]string, 0, len(counts))
+		for s := range counts {
+			statuses = append(statuses, s)
+		}
+		sort.Strings(statuses)
+		for _, s := range statuses {
+			jobs.Samples = append(jobs.Samples, metrics.Sample{
+				Labels: []metrics.Label{project, {Name: "status", Value: s}},
+				Value:  float64(counts[s]),
+			})
+		}
+		synced.Samples = append(synced.Samples, metrics.Sample{Labels: []metrics.Label{project}, Value: boolFloat(row.RunID != "")})
+	}
+	return append([]metrics.Family{jobs, synced}, state.Families()...), nil
+}
+
+func boolFloat(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// syncMetricsRecorder accumulates per-project counters during a sync and
+// persists them (plus the optional textfile) once at the end.
+type syncMetricsRecorder struct {
+	state      *metrics.State
+	statePath  string
+	textfile   string
+	configPath string
+	runsDir    string
+}
+
+func newSyncMetricsRecorder(configPath, runsDir, textfile string) *syncMetricsRecorder {
+	runsDir = defaultIfEmpty(runsDir, "runs")
+	statePath := metrics.StatePath(runsDir)
+	state, err := metrics.LoadState(statePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warn  metrics state unreadable, starting fresh: %v\n", err)
+		state = metrics.NewState()
+	}
+	return &syncMetricsRecorder{
+		state:      state,
+		statePath:  statePath,
+		textfile:   strings.TrimSpace(textfile),
+		configPath: configPath,
+		runsDir:    runsDir,
+	}
+}
+
+func (r *syncMetricsRecorder) refreshed(project string, elapsed time.Duration) {
+	r.state.Project(project).LastRefreshSeconds = elapsed.Seconds()
+}
+
+func (r *syncMetricsRecorder) ran(project string, res archive.RunResult) {
+	p := r.state.Project(project)
+	p.DownloadedBytes += float64(res.DownloadedBytes)
+	for _, secs := range res.DownloadSeconds {
+		p.DownloadSeconds.Observe(secs)
+	}
+	for class, n := range res.ErrorClasses {
+		p.Errors[class] += float64(n)
+	}
+}
+
+func (r *syncMetricsRecorder) failed(project string, err error) {
+	p := r.state.Project(project)
+	p.Syncs[metrics.SyncResultFailure]++
+	if archive.IsRunLocked(err) {
+		p.LockContention++
+	}
+}
+
+func (r *syncMetricsRecorder) succeeded(project string) {
+	p := r.state.Project(project)
+	p.Syncs[metrics.SyncResultSuccess]++
+	p.LastSuccessUnix = float64(time.Now().Unix())
+}
+
+func (r *syncMetricsRecorder) flush() {
+	if err := r.state.Save(r.statePath); err != nil {
+		fmt.Fprintf(os.Stderr, "warn  metrics state not saved (non-fatal): %v\n", err)
+		return
+	}
+	if r.textfile == "" {
+		return
+	}
+	families, err := collectMetricFamilies(r.configPath, r.runsDir)
+	if err == nil {
+		err = metrics.WriteTextfile(r.textfile, families)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warn  metrics textfile not written (non-fatal): %v\n", err)
+	}
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"yt-vod-manager/internal/discovery"
+)
+
+func runMigrate(args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	dryRun := fs.Bool("dry-run", false, "only report files that would be migrated")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	configPath := strings.TrimSpace(*config)
+
+	global, err := discovery.ReadGlobalSettings(configPath)
+	if err != nil && !*dryRun {
+		return err
+	}
+	res, err := discovery.Migrate(discovery.MigrateOptions{
+		ConfigPath: configPath,
+		RunsDir:    strings.TrimSpace(*runsDir),
+		DryRun:     *dryRun,
+		LockTTL:    global.RunLockTTL(),
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		if err := printJSON(res); err != nil {
+			return err
+		}
+	} else {
+		printMigrateResult(res)
+	}
+	if res.Failed > 0 {
+		return fmt.Errorf("%d file(s) could not be migrated", res.Failed)
+	}
+	return nil
+}
+
+func printMigrateResult(res discovery.MigrateResult) {
+	fmt.Println("migrate summary")
+	fmt.Printf("dry_run: %t\n", res.DryRun)
+	fmt.Printf("checked: %d\n", res.Checked)
+	if res.DryRun {
+		fmt.Printf("pending: %d\n", res.Pending)
+	} else {
+		fmt.Printf("migrated: %d\n", res.Migrated)
+	}
+	fmt.Printf("failed: %d\n", res.Failed)
+	for _, f := range res.Files {
+		if f.Error != "" {
+			fmt.Printf("  error %s: %s\n", f.Path, f.Error)
+			continue
+		}
+		fmt.Printf("  %s (%s v%d -> v%d)\n", f.Path, f.Schema, f.From, f.To)
+		for _, c := range f.Changes {
+			fmt.Printf("      %s\n", c)
+		}
+		if f.Backup != "" {
+			fmt.Printf("      backup: %s\n", f.Backup)
+		}
+	}
+	if res.DryRun && res.Pending > 0 {
+		fmt.Println("next: rerun without --dry-run to migrate (originals are kept as <file>.v<N>.bak)")
+	}
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"yt-vod-manager/internal/discovery"
+)
+
+func runProfile(args []string) error {
+	if len(args) == 0 {
+		printProfileUsage()
+		return nil
+	}
+	switch args[0] {
+	case "list":
+		return runProfileList(args[1:])
+	case "show":
+		return runProfileShow(args[1:])
+	case "create":
+		return runProfileSave(args[1:], false)
+	case "edit":
+		return runProfileSave(args[1:], true)
+	case "remove":
+		return runProfileRemove(args[1:])
+	case "help", "-h", "--help":
+		printProfileUsage()
+		return nil
+	default:
+		printProfileUsage()
+		return fmt.Errorf("unknown profile subcommand %q", args[0])
+	}
+}
+
+func runProfileList(args []string) error {
+	fs := flag.NewFlagSet("profile list", flag.ContinueOnError)
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	configPath := strings.TrimSpace(*config)
+	reg, err := discovery.LoadProjects(configPath)
+	if err != nil {
+		return err
+	}
+	usage := make(map[string]int)
+	for _, p := range reg.Projects {
+		usage[p.Profile]++
+	}
+	if *jsonOut {
+		return printJSON(map[string]any{
+			"config_path": configPath,
+			"profiles":    reg.Profiles,
+		})
+	}
+	fmt.Printf("config: %s\n", configPath)
+	fmt.Printf("- %s (built-in: global settings only) | projects: %d\n", discovery.DefaultProfileName, usage[discovery.DefaultProfileName])
+	for _, p := range reg.Profiles {
+		line := fmt.Sprintf("- %s | %s | projects: %d", p.Name, defaultIfEmpty(describeDownloadSettings(p.DownloadSettings), "(no settings)"), usage[p.Name])
+		if p.Description != "" {
+			line += " | " + p.Description
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func runProfileShow(args []string) error {
+	fs := flag.NewFlagSet("profile show", flag.ContinueOnError)
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	name := fs.String("name", "", "profile name")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*name) == "" {
+		return errors.New("--name is required")
+	}
+
+	p, err := discovery.FindProfileByName(strings.TrimSpace(*config), strings.TrimSpace(*name))
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(p)
+	}
+	printProfile(p)
+	return nil
+}
+
+// runProfileSave creates a profile, or with edit changes only the settings
+// given; "default" unsets a setting so projects fall through to global.
+func runProfileSave(args []string, edit bool) error {
+	fs := flag.NewFlagSet("profile create", flag.ContinueOnError)
+	if edit {
+		fs = flag.NewFlagSet("profile edit", flag.ContinueOnError)
+	}
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	name := fs.String("name", "", "profile name")
+	description := fs.String("description", "", "short description shown by profile list")
+	download := addDownloadSettingFlags(fs)
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*name) == "" {
+		return errors.New("--name is required")
+	}
+
+	configPath := strings.TrimSpace(*config)
+	profile := discovery.ProjectProfile{Name: strings.TrimSpace(*name)}
+	if edit {
+		existing, err := discovery.FindProfileByName(configPath, profile.Name)
+		if err != nil {
+			return err
+		}
+		profile = existing
+	}
+	setDefaultString(&profile.Description, *description)
+	if err := download.apply(&profile.DownloadSettings); err != nil {
+		return err
+	}
+
+	res, err := discovery.SaveProfile(discovery.SaveProfileOptions{
+		ConfigPath: configPath,
+		Profile:    profile,
+		Replace:    edit,
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(res)
+	}
+	action := "created"
+	if !res.Created {
+		action = "updated"
+	}
+	fmt.Printf("profile %s: %s\n", action, res.Profile.Name)
+	printProfile(res.Profile)
+	if res.Created {
+		fmt.Printf("next: yt-vod-manager add --source <url> --profile %s\n", res.Profile.Name)
+	}
+	return nil
+}
+
+func runProfileRemove(args []string) error {
+	fs := flag.NewFlagSet("profile remove", flag.ContinueOnError)
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	name := fs.String("name", "", "profile name")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*name) == "" {
+		return errors.New("--name is required")
+	}
+
+	removed, err := discovery.RemoveProfile(strings.TrimSpace(*config), strings.TrimSpace(*name))
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(removed)
+	}
+	fmt.Printf("removed profile: %s\n", removed.Name)
+	return nil
+}
+
+func printProfile(p discovery.ProjectProfile) {
+	fmt.Printf("name: %s\n", p.Name)
+	if p.Description != "" {
+		fmt.Printf("description: %s\n", p.Description)
+	}
+	fmt.Printf("settings: %s\n", defaultIfEmpty(describeDownloadSettings(p.DownloadSettings), "(none; projects use the global settings)"))
+}
+
+// describeDownloadSettings lists the settings that are set, as key=value.
+func describeDownloadSettings(s discovery.DownloadSettings) string {
+	parts := make([]string, 0, 13)
+	add := func(key, value string) {
+		if value != "" {
+			parts = append(parts, key+"="+value)
+		}
+	}
+	if s.Fragments > 0 {
+		add("fragments", strconv.Itoa(s.Fragments))
+	}
+	add("order", s.Order)
+	add("quality", s.Quality)
+	add("video_codec", s.VideoCodec)
+	add("hdr", s.HDR)
+	add("container", s.Container)
+	add("js_runtime", s.JSRuntime)
+	add("delivery_mode", s.DeliveryMode)
+	if s.NoSubs != nil {
+		add("subtitles", yesNo(!*s.NoSubs))
+	}
+	add("sub_langs", s.SubLangs)
+	if s.LiveChat != nil {
+		add("live_chat", yesNo(*s.LiveChat))
+	}
+	add("live_chat_format", s.LiveChatFormat)
+	add("schedule", s.Schedule)
+	if s.WriteThumbnails != nil {
+		add("thumbnails", yesNo(*s.WriteThumbnails))
+	}
+	if s.AudioOnly != nil {
+		add("audio_only", yesNo(*s.AudioOnly))
+	}
+	return strings.Join(parts, " ")
+}
+
+func printProfileUsage() {
+	fmt.Println("profile commands:")
+	fmt.Println("  profile list")
+	fmt.Println("  profile show --name <profile>")
+	fmt.Println("  profile create --name <profile> [--quality 2160p] [--sub-langs all] [--subtitles yes|no] [--thumbnails yes] [--audio-only yes] ...")
+	fmt.Println("  profile edit --name <profile> [--quality 720p] [--order default] ...  (\"default\" unsets)")
+	fmt.Println("  profile remove --name <profile>")
+}
@@ -93,14 +93,31 @@ func runAddProject(args []string) error {
 	outputDir := fs.String("output-dir", "", "project output directory override")
 	cookies := fs.String("cookies", "", "path to cookies.txt")
 	useBrowserCookies := fs.Bool("browser-cookies", false, browserCookiesFlagHelp)
+	profile := fs.String("profile", discovery.DefaultProfileName, "named settings profile to inherit unset settings from (see `profile list`)")
 	workers := fs.Int("workers", 0, "project worker override (0 = inherit global/default)")
-	fragments := fs.Int("fragments", discovery.DefaultFragments, "default yt-dlp fragment concurrency for this project")
-	order := fs.String("order", discovery.DefaultOrder, "default order: oldest|newest|manifest")
-	quality := fs.String("quality", discovery.DefaultQuality, "quality preset: best|1080p|720p")
-	jsRuntime := fs.String("js-runtime", discovery.DefaultJSRuntime, "JavaScript runtime for yt-dlp extractor scripts: auto|deno|node|quickjs|bun, or ordered fallback list like node,quickjs (auto follows yt-dlp default)")
+	fragments := fs.Int("fragments", 0, "yt-dlp fragment concurrency for this project (0 = inherit global/default)")
+	order := fs.String("order", "", "order: oldest|newest|manifest (empty inherits global, else oldest)")
+	quality := fs.String("quality", "", "quality preset: best|2160p|1440p|1080p|720p|480p (empty inherits global, else best)")
+	videoCodec := fs.String("video-codec", "", "video codec preference order: auto or list of av1,vp9,h264")
+	hdr := fs.String("hdr", "", "HDR preference: auto|on|off")
+	container := fs.String("container", "", "target container with remux: auto|mp4|mkv")
+	jsRuntime := fs.String("js-runtime", "", "JavaScript runtime for yt-dlp extractor scripts: auto|deno|node|quickjs|bun, or ordered fallback list like node,quickjs (empty inherits global, else auto)")
 	delivery := fs.String("delivery", "", "default delivery mode: auto|fragmented")
-	subtitles := fs.Bool("subtitles", true, "download subtitles by default")
-	subLangs := fs.String("sub-langs", discovery.DefaultSubtitleLanguage, "default subtitle language: english|all")
+	subtitles := fs.Bool("subtitles", true, "download subtitles (unset inherits global, else true)")
+	subLangs := fs.String("sub-langs", "", "subtitle language: english|all (empty inherits global, else english)")
+	liveChat := fs.Bool("live-chat", false, "download live chat replays of past streams (unset inherits global, else false)")
+	liveChatFormat := fs.String("live-chat-format", "", "live chat render format: txt|ass (default txt)")
+	schedule := fs.String("schedule", "", "daemon schedule: interval like 6h / @every 30m, or cron like \"0 3 * * *\"")
+	thumbnails := fs.Bool("thumbnails", false, "save video thumbnails (unset inherits global, else false)")
+	audioOnly := fs.Bool("audio-only", false, "download only the audio track (unset inherits global, else false)")
+	storageEndpoint := fs.String("storage-endpoint", "", "S3-compatible endpoint to upload completed media to, e.g. https://s3.example.com")
+	storageBucket := fs.String("storage-bucket", "", "storage bucket")
+	storagePrefix := fs.String("storage-prefix", "", "key prefix inside the bucket")
+	storageRegion := fs.String("storage-region", "", "storage region (default us-east-1)")
+	storageCredentials := fs.String("storage-credentials-file", "", "AWS-style credentials file (default: AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY env)")
+	storageVirtualHosted := fs.Bool("storage-virtual-hosted", false, "use bucket.endpoint host names instead of path-style requests (needed for newer AWS buckets)")
+	storageDeleteLocal := fs.Bool("storage-delete-local", false, "delete local media and sidecars once uploaded")
+	mirrors := fs.String("mirrors", "", "comma-separated directories each completed download is copied to (default: global mirrors)")
 	replace := fs.Bool("replace", false, "replace project if it already exists")
 	jsonOut := fs.Bool("json", false, "print JSON output")
 	fs.SetOutput(flag.CommandLine.Output())
@@ -108,6 +125,7 @@ func runAddProject(args []string) error {
 		return err
 	}
 
+	given := setFlags(fs)
 	src := strings.TrimSpace(*source)
 	if src == "" {
 		var err error
@@ -125,7 +143,7 @@ func runAddProject(args []string) error {
 		ConfigPath:          strings.TrimSpace(*config),
 		Name:                strings.TrimSpace(*name),
 		SourceURL:           src,
-		Profile:             discovery.DefaultProfileName,
+		Profile:             defaultIfEmpty(strings.TrimSpace(*profile), discovery.DefaultProfileName),
 		OutputDir:           strings.TrimSpace(*outputDir),
 		CookiesPath:         strings.TrimSpace(*cookies),
 		CookiesFromBrowser:  cookiesFromBrowser,
@@ -133,10 +151,20 @@ func runAddProject(args []string) error {
 		Fragments:           *fragments,
 		Order:               strings.TrimSpace(*order),
 		Quality:             strings.TrimSpace(*quality),
+		VideoCodec:          strings.TrimSpace(*videoCodec),
+		HDR:                 strings.TrimSpace(*hdr),
+		Container:           strings.TrimSpace(*container),
 		JSRuntime:           strings.TrimSpace(*jsRuntime),
 		DeliveryMode:        strings.TrimSpace(*delivery),
-		NoSubs:              !*subtitles,
+		NoSubs:              flagBool(given, "subtitles", !*subtitles),
 		SubLangs:            strings.TrimSpace(*subLangs),
+		LiveChat:            flagBool(given, "live-chat", *liveChat),
+		LiveChatFormat:      strings.TrimSpace(*liveChatFormat),
+		Schedule:            strings.TrimSpace(*schedule),
+		WriteThumbnails:     flagBool(given, "thumbnails", *thumbnails),
+		AudioOnly:           flagBool(given, "audio-only", *audioOnly),
+		Storage:             storageFromFlags(*storageEndpoint, *storageBucket, *storagePrefix, *storageRegion, *storageCredentials, *storageVirtualHosted, *storageDeleteLocal),
+		Mirrors:             parseProxyValueList(*mirrors),
 		Active:              boolPtr(true),
 		ReplaceIfNameExists: *replace,
 	})
@@ -194,6 +222,7 @@ func runStatus(args []string) error {
 	all := fs.Bool("all", true, "show all configured projects")
 	runsDir := fs.String("runs-dir", "runs", "runs directory")
 	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	removed := fs.Bool("removed", false, "list videos that are gone upstream")
 	jsonOut := fs.Bool("json", false, "print JSON output")
 	fs.SetOutput(flag.CommandLine.Output())
 	if err := fs.Parse(args); err != nil {
@@ -226,6 +255,9 @@ func runStatus(args []string) error {
 		return printJSON(res)
 	}
 
+	if res.Sync != nil {
+		fmt.Println(describeSyncLock(*res.Sync))
+	}
 	for _, row := range res.Rows {
 		fmt.Printf("%s [%s]\n", row.Project, row.State)
 		fmt.Printf("  source: %s\n", row.SourceURL)
@@ -234,15 +266,50 @@ func runStatus(args []string) error {
 			fmt.Printf("  run: %s\n", row.RunID)
 		}
 		fmt.Printf("  completed/pending/fail: %d/%d/%d\n", row.Completed, row.Pending, row.FailedRetryable+row.FailedPermanent)
+		if row.RemovedUpstream > 0 {
+			fmt.Printf("  gone upstream: %d (saved locally: %d)\n", row.RemovedUpstream, row.RemovedSaved)
+		}
+		if len(row.Mirrors) > 0 {
+			fmt.Printf("  replication lag: %s\n", describeMirrorLag(row))
+		}
+		if *removed {
+			for _, v := range row.RemovedVideos {
+				saved := "not saved"
+				if v.LocalCopy {
+					saved = "saved"
+				}
+				fmt.Printf("    %s  %s  [%s, gone since %s]\n", v.VideoID, defaultIfEmpty(v.Title, "(untitled)"), saved, defaultIfEmpty(v.RemovedAt, "?"))
+			}
+		}
 	}
 	fmt.Println("totals")
 	fmt.Printf("  projects: %d\n", res.Totals.Projects)
 	fmt.Printf("  healthy: %d\n", res.Totals.Healthy)
 	fmt.Printf("  attention: %d\n", res.Totals.Attention)
 	fmt.Printf("  never_synced: %d\n", res.Totals.NeverSynced)
+	if res.Totals.RemovedUpstream > 0 {
+		fmt.Printf("  saved but gone upstream: %d of %d\n", res.Totals.RemovedSaved, res.Totals.RemovedUpstream)
+	}
+	if res.Totals.MirrorLag > 0 {
+		fmt.Printf("  not yet mirrored: %d\n", res.Totals.MirrorLag)
+	}
 	return nil
 }
 
+func describeMirrorLag(row discovery.ProjectStatusItem) string {
+	if row.MirrorLag == 0 {
+		return fmt.Sprintf("none (%d mirror(s))", len(row.Mirrors))
+	}
+	out := fmt.Sprintf("%d video(s) behind", row.MirrorLag)
+	if row.MirrorFailed > 0 {
+		out += fmt.Sprintf(", %d with failed copies", row.MirrorFailed)
+	}
+	if row.MirrorLagSince != "" {
+		out += ", oldest completed " + row.MirrorLagSince
+	}
+	return out
+}
+
 func runRemoveProject(args []string) error {
 	fs := flag.NewFlagSet("remove", flag.ContinueOnError)
 	name := fs.String("name", "", "project name")
@@ -282,3 +349,18 @@ func runRemoveProject(args []string) error {
 	fmt.Printf("removed project: %s (%s)\n", res.Project.Name, res.Project.SourceURL)
 	return nil
 }
+
+func storageFromFlags(endpoint, bucket, prefix, region, credentialsFile string, virtualHosted, deleteLocal bool) *discovery.StorageTarget {
+	if strings.TrimSpace(endpoint) == "" && strings.TrimSpace(bucket) == "" {
+		return nil
+	}
+	return &discovery.StorageTarget{
+		Endpoint:        strings.TrimSpace(endpoint),
+		Bucket:          strings.TrimSpace(bucket),
+		Prefix:          strings.TrimSpace(prefix),
+		Region:          strings.TrimSpace(region),
+		CredentialsFile: strings.TrimSpace(credentialsFile),
+		VirtualHosted:   virtualHosted,
+		DeleteLocal:     deleteLocal,
+	}
+}
@@ -126,3 +126,25 @@ func TestRefreshRequiresExplicitTarget(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestProjectSetKeepsUnmentionedSettings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "projects.json")
+	if err := Run([]string{"add", "--name", "demo", "--source", "https://example.com/source", "--config", configPath, "--quality", "1080p", "--order", "newest"}); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if err := Run([]string{"project", "set", "--name", "demo", "--config", configPath, "--active=false", "--unset", "order"}); err != nil {
+		t.Fatalf("project set failed: %v", err)
+	}
+	reg, err := discovery.LoadProjects(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := reg.Projects[0]
+	if p.Quality != "1080p" || p.Order != "" || p.Active == nil || *p.Active {
+		t.Fatalf("unexpected project after set: quality=%q order=%q active=%v", p.Quality, p.Order, p.Active)
+	}
+	err = Run([]string{"project", "set", "--name", "demo", "--config", configPath, "--order", "oldest", "--unset", "order"})
+	if err == nil || !strings.Contains(err.Error(), "conflicts") {
+		t.Fatalf("expected conflicting flags to fail, got %v", err)
+	}
+}
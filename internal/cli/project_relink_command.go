@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"yt-vod-manager/internal/discovery"
+)
+
+// runProjectRelink attaches existing runs to a project, typically after its
+// source URL changed and the runs were recorded under the old one.
+func runProjectRelink(args []string) error {
+	fs := flag.NewFlagSet("project relink", flag.ContinueOnError)
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	name := fs.String("name", "", "project to attach the runs to")
+	from := fs.String("from", "", "attach the runs recorded with this (old) source URL")
+	runIDs := fs.String("run", "", "attach these runs instead (comma-separated run ids)")
+	dryRun := fs.Bool("dry-run", false, "only list the runs that would be attached")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*name) == "" {
+		return errors.New("--name is required")
+	}
+	if strings.TrimSpace(*from) == "" && strings.TrimSpace(*runIDs) == "" {
+		return errors.New("project relink requires --from <old source url> or --run <run id>")
+	}
+
+	configPath := strings.TrimSpace(*config)
+	global, err := discovery.ReadGlobalSettings(configPath)
+	if err != nil {
+		return err
+	}
+	res, err := discovery.RelinkProjectRuns(discovery.RelinkRunsOptions{
+		ConfigPath: configPath,
+		RunsDir:    strings.TrimSpace(*runsDir),
+		Name:       strings.TrimSpace(*name),
+		FromSource: strings.TrimSpace(*from),
+		RunIDs:     parseProxyValueList(*runIDs),
+		DryRun:     *dryRun,
+		LockTTL:    global.RunLockTTL(),
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(res)
+	}
+
+	fmt.Printf("project: %s (id %s)\n", res.Project, res.ProjectID)
+	fmt.Printf("dry_run: %t\n", res.DryRun)
+	if len(res.Items) == 0 {
+		fmt.Println("no matching runs")
+		return nil
+	}
+	for _, item := range res.Items {
+		line := fmt.Sprintf("  %s %s: %s", item.Outcome, item.RunID, item.SourceURL)
+		if item.Reason != "" {
+			line += " (" + item.Reason + ")"
+		}
+		fmt.Println(line)
+	}
+	if res.DryRun {
+		fmt.Printf("would_link: %d\n", res.Linked)
+		return nil
+	}
+	fmt.Printf("linked: %d\n", res.Linked)
+	fmt.Printf("next: yt-vod-manager status --project %s\n", res.Project)
+	return nil
+}
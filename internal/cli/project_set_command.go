@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"yt-vod-manager/internal/discovery"
+)
+
+func runProject(args []string) error {
+	if len(args) == 0 {
+		printProjectUsage()
+		return nil
+	}
+	switch args[0] {
+	case "set":
+		return runProjectSet(args[1:])
+	case "relink":
+		return runProjectRelink(args[1:])
+	case "help", "-h", "--help":
+		printProjectUsage()
+		return nil
+	default:
+		printProjectUsage()
+		return fmt.Errorf("unknown project subcommand %q", args[0])
+	}
+}
+
+// runProjectSet changes only the settings given on the command line; the
+// rest of the project stays as configured.
+func runProjectSet(args []string) error {
+	fs := flag.NewFlagSet("project set", flag.ContinueOnError)
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	name := fs.String("name", "", "project to change")
+	rename := fs.String("rename", "", "new project name")
+	source := fs.String("source", "", "new source URL (playlist/channel)")
+	active := fs.Bool("active", true, "whether --active-only syncs include the project")
+	profile := fs.String("profile", "", "named settings profile (see `profile list`)")
+	outputDir := fs.String("output-dir", "", "project output directory")
+	cookies := fs.String("cookies", "", "path to cookies.txt")
+	useBrowserCookies := fs.Bool("browser-cookies", false, browserCookiesFlagHelp)
+	workers := fs.Int("workers", -1, "project worker override (0 unsets, -1 keeps current)")
+	download := addDownloadSettingFlags(fs)
+	mirrors := fs.String("mirrors", "", "comma-separated directories each completed download is copied to")
+	unset := fs.String("unset", "", "comma-separated settings to reset to inherit, e.g. quality,sub_langs (see `settings explain`)")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*name) == "" {
+		return errors.New("--name is required")
+	}
+
+	given := setFlags(fs)
+	unsetKeys := parseProxyValueList(*unset)
+	for _, raw := range unsetKeys {
+		key, ok := discovery.ProjectSettingKey(raw)
+		if !ok {
+			continue
+		}
+		for flagName := range given {
+			if k, ok := discovery.ProjectSettingKey(flagName); ok && k == key {
+				return fmt.Errorf("--%s conflicts with --unset %s", flagName, raw)
+			}
+		}
+	}
+	changes := 0
+	for f := range given {
+		if f != "name" && f != "config" && f != "json" {
+			changes++
+		}
+	}
+	if changes == 0 {
+		return errors.New("nothing to change (pass settings to set, --unset, or --rename)")
+	}
+
+	res, err := discovery.UpdateProject(discovery.UpdateProjectOptions{
+		ConfigPath: strings.TrimSpace(*config),
+		Name:       strings.TrimSpace(*name),
+		Update: func(p *discovery.Project) error {
+			if v := strings.TrimSpace(*rename); v != "" {
+				p.Name = v
+			}
+			if v := strings.TrimSpace(*source); v != "" {
+				p.SourceURL = v
+			}
+			if given["active"] {
+				p.Active = boolPtr(*active)
+			}
+			setDefaultString(&p.Profile, *profile)
+			setDefaultString(&p.OutputDir, *outputDir)
+			setDefaultString(&p.CookiesPath, *cookies)
+			if given["browser-cookies"] {
+				p.CookiesFromBrowser = ""
+				if *useBrowserCookies {
+					p.CookiesFromBrowser = discovery.DefaultBrowserCookieAgent
+				}
+			}
+			if *workers != -1 {
+				if *workers < 0 {
+					return errors.New("--workers must be >= 0")
+				}
+				p.Workers = *workers
+			}
+			ds := p.DownloadSettings()
+			if err := download.apply(&ds); err != nil {
+				return err
+			}
+			p.SetDownloadSettings(ds)
+			if given["mirrors"] {
+				p.Mirrors = parseProxyValueList(*mirrors)
+			}
+			return nil
+		},
+		Unset: unsetKeys,
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(res)
+	}
+
+	if res.PreviousName != "" {
+		fmt.Printf("project renamed: %s -> %s\n", res.PreviousName, res.Project.Name)
+	} else {
+		fmt.Printf("project updated: %s\n", res.Project.Name)
+	}
+	for _, w := range res.Warnings {
+		fmt.Printf("warning: %s\n", w)
+	}
+	fmt.Printf("next: yt-vod-manager settings explain --project %s\n", res.Project.Name)
+	return nil
+}
+
+func printProjectUsage() {
+	fmt.Println("project commands:")
+	fmt.Println("  project set --name <project> [--quality 1080p] [--active=false] [--rename <new>] [--unset quality,order] ...")
+	fmt.Println("  project relink --name <project> (--from <old source url> | --run <run id,...>) [--dry-run]")
+}
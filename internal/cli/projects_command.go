@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"yt-vod-manager/internal/discovery"
+)
+
+func runProjects(args []string) error {
+	if len(args) == 0 {
+		printProjectsUsage()
+		return nil
+	}
+	switch args[0] {
+	case "import":
+		return runProjectsImport(args[1:])
+	case "export":
+		return runProjectsExport(args[1:])
+	case "help", "-h", "--help":
+		printProjectsUsage()
+		return nil
+	default:
+		printProjectsUsage()
+		return fmt.Errorf("unknown projects subcommand %q", args[0])
+	}
+}
+
+func runProjectsImport(args []string) error {
+	fs := flag.NewFlagSet("projects import", flag.ContinueOnError)
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	file := fs.String("file", "", "subscriptions.csv (YouTube Takeout), OPML, fetchlist, or a CSV from projects export")
+	format := fs.String("format", "", "input format: takeout|opml|fetchlist|csv (default: detected from the file)")
+	profile := fs.String("profile", discovery.DefaultProfileName, "named settings profile for new projects (see `profile list`)")
+	workers := fs.Int("workers", 0, "worker override for new projects (0 = inherit global/default)")
+	fragments := fs.Int("fragments", 0, "yt-dlp fragment concurrency for new projects (0 = inherit global/default)")
+	order := fs.String("order", "", "order for new projects: oldest|newest|manifest (empty inherits global)")
+	quality := fs.String("quality", "", "quality preset for new projects: best|2160p|1440p|1080p|720p|480p (empty inherits global)")
+	jsRuntime := fs.String("js-runtime", "", "JavaScript runtime for new projects: auto|deno|node|quickjs|bun, or ordered fallback list (empty inherits global)")
+	subtitles := fs.Bool("subtitles", true, "download subtitles for new projects (unset inherits global)")
+	subLangs := fs.String("sub-langs", "", "subtitle language for new projects: english|all (empty inherits global)")
+	liveChat := fs.Bool("live-chat", false, "download live chat replays for new projects (unset inherits global)")
+	schedule := fs.String("schedule", "", "daemon schedule for new projects: interval like 6h, or cron like \"0 3 * * *\"")
+	mirrors := fs.String("mirrors", "", "comma-separated mirror directories for new projects (default: global mirrors)")
+	inactive := fs.Bool("inactive", false, "add new projects as inactive, so --active-only syncs skip them until enabled")
+	dryRun := fs.Bool("dry-run", false, "only list the projects that would be added")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	path := strings.TrimSpace(*file)
+	if path == "" && fs.NArg() == 1 {
+		path = strings.TrimSpace(fs.Arg(0))
+	}
+	if path == "" {
+		return errors.New("projects import requires --file")
+	}
+
+	given := setFlags(fs)
+	template := discovery.DefaultProjectTemplate()
+	template.Profile = defaultIfEmpty(strings.TrimSpace(*profile), discovery.DefaultProfileName)
+	template.Workers = *workers
+	template.Fragments = *fragments
+	template.Order = strings.TrimSpace(*order)
+	template.Quality = strings.TrimSpace(*quality)
+	template.JSRuntime = strings.TrimSpace(*jsRuntime)
+	template.NoSubs = flagBool(given, "subtitles", !*subtitles)
+	template.SubLangs = strings.TrimSpace(*subLangs)
+	template.LiveChat = flagBool(given, "live-chat", *liveChat)
+	template.Schedule = strings.TrimSpace(*schedule)
+	template.Mirrors = parseProxyValueList(*mirrors)
+	template.Active = boolPtr(!*inactive)
+
+	res, err := discovery.ImportProjects(discovery.ImportProjectsOptions{
+		ConfigPath: strings.TrimSpace(*config),
+		Path:       path,
+		Format:     strings.TrimSpace(*format),
+		Template:   template,
+		DryRun:     *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(res)
+	}
+
+	fmt.Println("projects import summary")
+	fmt.Printf("config: %s\n", res.ConfigPath)
+	fmt.Printf("format: %s\n", res.Format)
+	fmt.Printf("dry_run: %t\n", res.DryRun)
+	fmt.Printf("read: %d\n", res.Read)
+	if res.DryRun {
+		fmt.Printf("would_create: %d\n", res.Created)
+	} else {
+		fmt.Printf("created: %d\n", res.Created)
+		fmt.Printf("failed: %d\n", res.Failed)
+	}
+	fmt.Printf("already_tracked: %d\n", res.Exists)
+	for _, item := range res.Items {
+		line := fmt.Sprintf("  %s %s: %s", item.Outcome, item.Name, item.SourceURL)
+		if item.Error != "" {
+			line += " (" + item.Error + ")"
+		}
+		fmt.Println(line)
+	}
+	if res.DryRun && res.Created > 0 {
+		fmt.Println("next: rerun without --dry-run to add the projects")
+	}
+	return nil
+}
+
+func runProjectsExport(args []string) error {
+	fs := flag.NewFlagSet("projects export", flag.ContinueOnError)
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	format := fs.String("format", "", "output format: opml|csv (default: from --output extension, else opml)")
+	output := fs.String("output", "", "write to this file instead of stdout")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	outPath := strings.TrimSpace(*output)
+	effectiveFormat := strings.ToLower(strings.TrimSpace(*format))
+	if effectiveFormat == "" {
+		effectiveFormat = discovery.ProjectsFormatOPML
+		if strings.EqualFold(filepath.Ext(outPath), ".csv") {
+			effectiveFormat = discovery.ProjectsFormatCSV
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if outPath != "" {
+		if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+			return err
+		}
+		f, err := os.Create(outPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	n, err := discovery.ExportProjects(w, strings.TrimSpace(*config), effectiveFormat)
+	if err != nil {
+		return err
+	}
+	if outPath != "" {
+		fmt.Printf("wrote %d project(s) as %s: %s\n", n, effectiveFormat, outPath)
+	}
+	return nil
+}
+
+func printProjectsUsage() {
+	fmt.Println("projects commands:")
+	fmt.Println("  projects import --file <subscriptions.csv|feeds.opml|fetchlist.txt|projects.csv> [--dry-run] [--profile <name>] [--quality ...] [--inactive]")
+	fmt.Println("  projects export [--format opml|csv] [--output <file>]")
+}
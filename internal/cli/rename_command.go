@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"yt-vod-manager/internal/archive"
+	"yt-vod-manager/internal/discovery"
+)
+
+func runRename(args []string) error {
+	fs := flag.NewFlagSet("rename", flag.ContinueOnError)
+	project := fs.String("project", "", "project name (uses latest run for that project)")
+	runID := fs.String("run-id", "", "run id from runs/<run_id>")
+	runDir := fs.String("run-dir", "", "explicit run directory path")
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	latest := fs.Bool("latest", false, "use latest run when run-id/run-dir/project are not set")
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	dryRun := fs.Bool("dry-run", false, "only report files that would be renamed")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	configPath := strings.TrimSpace(*config)
+
+	targetRunDir := strings.TrimSpace(*runDir)
+	projectDefaults := discovery.Project{}
+	if strings.TrimSpace(*project) != "" {
+		resolved, proj, err := discovery.ResolveRunDirForProject(configPath, strings.TrimSpace(*project), strings.TrimSpace(*runsDir))
+		if err != nil {
+			return err
+		}
+		targetRunDir = resolved
+		projectDefaults = proj
+	}
+	if targetRunDir == "" && strings.TrimSpace(*runID) == "" && !*latest {
+		return errors.New("rename target required: set --project, --run-id, --run-dir, or --latest")
+	}
+	global, err := discovery.ReadGlobalSettings(configPath)
+	if err != nil {
+		return err
+	}
+
+	res, err := archive.Rename(archive.RenameOptions{
+		RunID:     strings.TrimSpace(*runID),
+		RunDir:    targetRunDir,
+		RunsDir:   strings.TrimSpace(*runsDir),
+		Latest:    *latest,
+		OutputDir: projectDefaults.OutputDir,
+		DryRun:    *dryRun,
+		LockTTL:   global.RunLockTTL(),
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(res)
+	}
+
+	fmt.Println("rename summary")
+	fmt.Printf("run_id: %s\n", res.RunID)
+	fmt.Printf("dry_run: %t\n", res.DryRun)
+	fmt.Printf("checked: %d\n", res.Checked)
+	if res.DryRun {
+		fmt.Printf("pending_renames: %d\n", res.Planned)
+	} else {
+		fmt.Printf("renamed: %d\n", res.Renamed)
+		fmt.Printf("failed: %d\n", res.Failed)
+	}
+	fmt.Printf("skipped: %d\n", res.Skipped)
+	for _, item := range res.Items {
+		line := fmt.Sprintf("  %s %s: %s -> %s", item.Outcome, item.VideoID, item.From, defaultIfEmpty(item.To, "?"))
+		if item.Error != "" {
+			line += " (" + item.Error + ")"
+		}
+		fmt.Println(line)
+		if res.DryRun {
+			for _, f := range item.Files {
+				fmt.Printf("      %s -> %s\n", filepath.Base(f.From), filepath.Base(f.To))
+			}
+		}
+	}
+	if res.DryRun && res.Planned > 0 {
+		fmt.Println("next: rerun without --dry-run to rename files")
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"yt-vod-manager/internal/archive"
+	"yt-vod-manager/internal/discovery"
+)
+
+type repairOutput struct {
+	Run  discovery.RepairResult    `json:"run"`
+	Jobs *archive.RepairJobsResult `json:"jobs,omitempty"`
+}
+
+func runRepair(args []string) error {
+	fs := flag.NewFlagSet("repair", flag.ContinueOnError)
+	project := fs.String("project", "", "project name (finds its latest run even when state files are unreadable)")
+	runID := fs.String("run-id", "", "run id from runs/<run_id>")
+	runDir := fs.String("run-dir", "", "explicit run directory path")
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	source := fs.String("source", "", "source URL, when neither the project nor run.json has it")
+	fresh := fs.Bool("fresh", false, "rebuild from a new listing instead of the last good raw snapshot")
+	cookies := fs.String("cookies", "", "path to cookies.txt (used with a fresh listing)")
+	useBrowserCookies := fs.Bool("browser-cookies", false, browserCookiesFlagHelp)
+	jsRuntime := fs.String("js-runtime", "", "JavaScript runtime override for yt-dlp extractor scripts: auto|deno|node|quickjs|bun, or ordered fallback list like node,quickjs")
+	dryRun := fs.Bool("dry-run", false, "only report what is broken and how it would be rebuilt")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	configPath := strings.TrimSpace(*config)
+
+	targetRunDir := strings.TrimSpace(*runDir)
+	projectDefaults := discovery.Project{}
+	if strings.TrimSpace(*project) != "" {
+		if !*dryRun {
+			if err := discovery.PersistProjectIDs(configPath); err != nil {
+				return err
+			}
+		}
+		resolved, proj, err := discovery.ResolveRunDirForRepair(configPath, strings.TrimSpace(*project), strings.TrimSpace(*runsDir))
+		if err != nil {
+			return err
+		}
+		targetRunDir = resolved
+		projectDefaults = proj
+		if strings.TrimSpace(*cookies) == "" {
+			*cookies = proj.CookiesPath
+		}
+	}
+	if targetRunDir == "" && strings.TrimSpace(*runID) == "" {
+		return errors.New("repair target required: set --project, --run-id, or --run-dir")
+	}
+	cookiesFromBrowser := strings.TrimSpace(projectDefaults.CookiesFromBrowser)
+	if *useBrowserCookies {
+		cookiesFromBrowser = discovery.DefaultBrowserCookieAgent
+	}
+	global, err := discovery.ReadGlobalSettings(configPath)
+	if err != nil {
+		return err
+	}
+
+	out := repairOutput{}
+	out.Run, err = discovery.RepairRun(discovery.RepairOptions{
+		RunID:              strings.TrimSpace(*runID),
+		RunDir:             targetRunDir,
+		RunsDir:            strings.TrimSpace(*runsDir),
+		SourceURL:          firstNonEmpty(strings.TrimSpace(*source), projectDefaults.SourceURL),
+		Profile:            projectDefaults.Profile,
+		OutputDir:          projectDefaults.OutputDir,
+		ProjectID:          projectDefaults.ID,
+		Fresh:              *fresh,
+		CookiesPath:        strings.TrimSpace(*cookies),
+		CookiesFromBrowser: cookiesFromBrowser,
+		JSRuntime:          firstNonEmpty(strings.TrimSpace(*jsRuntime), projectDefaults.JSRuntime, discovery.DefaultJSRuntime),
+		DryRun:             *dryRun,
+		LockTTL:            global.RunLockTTL(),
+		SnapshotRetention:  global.SnapshotRetention,
+	})
+	if err != nil {
+		return err
+	}
+	// Job state only needs rebuilding when the manifest was; a dry run has
+	// no rebuilt manifest to match files against yet.
+	if out.Run.ManifestRebuilt {
+		jobs, err := archive.RepairJobs(archive.RepairJobsOptions{
+			RunDir:    out.Run.RunDir,
+			RunsDir:   strings.TrimSpace(*runsDir),
+			OutputDir: projectDefaults.OutputDir,
+			LockTTL:   global.RunLockTTL(),
+		})
+		if err != nil {
+			return err
+		}
+		out.Jobs = &jobs
+	}
+	if *jsonOut {
+		return printJSON(out)
+	}
+
+	res := out.Run
+	fmt.Println("repair summary")
+	fmt.Printf("run_id: %s\n", res.RunID)
+	fmt.Printf("dry_run: %t\n", res.DryRun)
+	fmt.Printf("manifest: %s\n", describeRepairFile(res.Manifest, res.ManifestError))
+	fmt.Printf("run_meta: %s\n", describeRepairFile(res.RunMeta, res.RunMetaError))
+	fmt.Printf("temp_files: %d\n", len(res.TempFiles))
+	for _, f := range res.TempFiles {
+		fmt.Printf("  %s\n", f)
+	}
+	if !res.NeedsRepair() {
+		fmt.Println("nothing to repair")
+		return nil
+	}
+	if res.RebuiltFrom != "" {
+		fmt.Printf("rebuilt_from: %s\n", res.RebuiltFrom)
+		fmt.Printf("jobs: %d\n", res.Jobs)
+	}
+	if res.DryRun {
+		if res.Manifest != discovery.RepairFileOK {
+			fmt.Println("note: completed jobs are matched against media on disk and download-archive.txt after the rebuild")
+		}
+		fmt.Println("next: rerun without --dry-run to repair")
+		return nil
+	}
+	if res.QuarantineDir != "" {
+		fmt.Printf("quarantined: %d file(s) in %s\n", len(res.Quarantined), res.QuarantineDir)
+	}
+	fmt.Printf("run_meta_restored: %t\n", res.RunMetaRestored)
+	if out.Jobs != nil {
+		fmt.Printf("completed_from_media: %d\n", out.Jobs.FromMedia)
+		fmt.Printf("completed_from_archive: %d\n", out.Jobs.FromArchive)
+		fmt.Printf("created: %d\n", out.Jobs.Created)
+		if out.Jobs.Unmatched > 0 {
+			fmt.Printf("unmatched_archive_ids: %d\n", out.Jobs.Unmatched)
+		}
+	}
+	return nil
+}
+
+func describeRepairFile(state, errText string) string {
+	if errText == "" || state == discovery.RepairFileOK {
+		return state
+	}
+	return state + " (" + errText + ")"
+}
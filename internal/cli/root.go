@@ -26,6 +26,12 @@ func Run(args []string) error {
 		err = runAddProject(args[1:])
 	case "list":
 		err = runListProjects(args[1:])
+	case "project":
+		err = runProject(args[1:])
+	case "projects":
+		err = runProjects(args[1:])
+	case "profile":
+		err = runProfile(args[1:])
 	case "manage":
 		err = runManage(args[1:])
 	case "settings":
@@ -36,6 +42,32 @@ func Run(args []string) error {
 		err = runStatus(args[1:])
 	case "remove":
 		err = runRemoveProject(args[1:])
+	case "upgrade":
+		err = runUpgrade(args[1:])
+	case "dedup":
+		err = runDedup(args[1:])
+	case "search":
+		err = runSearch(args[1:])
+	case "export":
+		err = runExport(args[1:])
+	case "metrics":
+		err = runMetrics(args[1:])
+	case "daemon":
+		err = runDaemon(args[1:])
+	case "unlock":
+		err = runUnlock(args[1:])
+	case "job":
+		err = runJob(args[1:])
+	case "diff":
+		err = runDiff(args[1:])
+	case "rename":
+		err = runRename(args[1:])
+	case "import":
+		err = runImport(args[1:])
+	case "migrate":
+		err = runMigrate(args[1:])
+	case "repair":
+		err = runRepair(args[1:])
 	case "help", "-h", "--help":
 		printRootUsage()
 		return nil
@@ -66,17 +98,33 @@ func printRootUsage() {
 	fmt.Println("  doctor    run dependency and filesystem preflight checks")
 	fmt.Println("  add       add/update a project source in config")
 	fmt.Println("  list      list configured projects")
+	fmt.Println("  project   change individual settings of a project, rename it, or relink its runs")
+	fmt.Println("  projects  import projects from YouTube Takeout, OPML, or a fetchlist; export as OPML/CSV")
+	fmt.Println("  profile   manage named settings profiles projects can share")
 	fmt.Println("  manage    interactive project manager (wizard + editor)")
 	fmt.Println("  settings  show/update global runtime settings")
 	fmt.Println("  self-update update the CLI from GitHub Releases")
 	fmt.Println("  sync      sync project(s), source URL(s), or fetchlist")
+	fmt.Println("  daemon    stay resident and sync projects on their schedules")
 	fmt.Println("  status    status rollup for project(s)")
+	fmt.Println("  job       show one video's attempt history and log file")
+	fmt.Println("  diff      show how a project's source changed between snapshots")
+	fmt.Println("  search    find videos across projects by title, metadata, or subtitle text")
+	fmt.Println("  export    write a project's job list as a CSV, Markdown, or HTML report")
+	fmt.Println("  metrics   print, write, or serve Prometheus metrics")
 	fmt.Println("  remove    remove a project from config")
+	fmt.Println("  upgrade   re-download archived videos below the current quality preset")
+	fmt.Println("  rename    rename archived files after upstream title changes")
+	fmt.Println("  dedup     report space saved by linking videos shared across projects")
+	fmt.Println("  import    mark videos downloaded outside this tool as completed")
 	fmt.Println()
 	fmt.Println("Advanced Commands:")
 	fmt.Println("  discover  fetch source manifest via yt-dlp and write normalized jobs")
 	fmt.Println("  refresh   merge new source entries into an existing run")
 	fmt.Println("  run       download pending jobs and checkpoint progress after each video")
+	fmt.Println("  unlock    show a run's lock owner and remove a stale (or --force) lock")
+	fmt.Println("  migrate   upgrade state files to this version's schema (--dry-run reports)")
+	fmt.Println("  repair    rebuild a run's lost or corrupted state files from snapshots and disk")
 	fmt.Println()
 	fmt.Println("Notes:")
 	fmt.Println("  - Use --json on commands for machine-readable output")
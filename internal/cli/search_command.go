@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"yt-vod-manager/internal/discovery"
+)
+
+func runSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	query := fs.String("query", "", "search terms (all must match); trailing arguments are used when empty")
+	titleOnly := fs.Bool("title", false, "match titles only (skip metadata and subtitle text)")
+	project := fs.String("project", "", "limit to project name(s), comma-separated")
+	status := fs.String("status", "", "limit to job status(es), comma-separated, e.g. completed,failed_permanent")
+	after := fs.String("after", "", "only videos uploaded (or completed) on/after YYYY-MM-DD")
+	before := fs.String("before", "", "only videos uploaded (or completed) on/before YYYY-MM-DD")
+	limit := fs.Int("limit", discovery.DefaultSearchLimit, "max results to print (0 = no limit)")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	q := firstNonEmpty(strings.TrimSpace(*query), strings.Join(fs.Args(), " "))
+	if q == "" {
+		return errors.New("search query required: yt-vod-manager search [flags] <terms>")
+	}
+
+	res, err := discovery.Search(discovery.SearchOptions{
+		ConfigPath: strings.TrimSpace(*config),
+		RunsDir:    strings.TrimSpace(*runsDir),
+		Query:      q,
+		TitleOnly:  *titleOnly,
+		Project:    strings.TrimSpace(*project),
+		Status:     strings.TrimSpace(*status),
+		After:      strings.TrimSpace(*after),
+		Before:     strings.TrimSpace(*before),
+		Limit:      *limit,
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(res)
+	}
+
+	fmt.Printf("query: %s\n", res.Query)
+	fmt.Printf("matches: %d\n", res.Total)
+	for _, w := range res.Warnings {
+		fmt.Printf("warning: %s\n", w)
+	}
+	for _, hit := range res.Hits {
+		fmt.Println()
+		fmt.Printf("%s  [%s] %s\n", hit.VideoID, hit.Project, hit.Title)
+		fmt.Printf("  status: %s", hit.Status)
+		if hit.Reason != "" {
+			fmt.Printf(" (%s)", hit.Reason)
+		}
+		fmt.Println()
+		if hit.UploadDate != "" {
+			fmt.Printf("  uploaded: %s\n", hit.UploadDate)
+		}
+		fmt.Printf("  path: %s\n", defaultIfEmpty(hit.Path, "(not downloaded)"))
+		fmt.Printf("  matched: %s\n", strings.Join(hit.MatchedIn, ","))
+		for _, sub := range hit.SubtitleHits {
+			fmt.Printf("    %s [%s] %s\n", sub.Start, sub.Lang, sub.Text)
+		}
+		if more := hit.SubtitleHitCount - len(hit.SubtitleHits); more > 0 {
+			fmt.Printf("    ... %d more subtitle hit(s)\n", more)
+		}
+	}
+	if res.Truncated {
+		fmt.Println()
+		fmt.Printf("showing first %d of %d matches; use --limit 0 for all\n", len(res.Hits), res.Total)
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 
+	"yt-vod-manager/internal/archive"
 	"yt-vod-manager/internal/discovery"
 )
 
@@ -20,8 +21,12 @@ func runSettings(args []string) error {
 		return runSettingsShow(args[1:])
 	case "set":
 		return runSettingsSet(args[1:])
+	case "explain":
+		return runSettingsExplain(args[1:])
 	case "proxy":
 		return runSettingsProxy(args[1:])
+	case "failure-rules":
+		return runSettingsFailureRules(args[1:])
 	case "help", "-h", "--help":
 		printSettingsUsage()
 		return nil
@@ -55,23 +60,73 @@ func runSettingsShow(args []string) error {
 	fmt.Printf("workers: %d\n", global.Workers)
 	fmt.Printf("download_limit_mb_s: %s\n", formatFloat(global.DownloadLimitMBps))
 	fmt.Printf("proxy_mode: %s\n", global.ProxyMode)
+	fmt.Printf("dedup_mode: %s\n", global.DedupMode)
+	fmt.Printf("metrics_textfile: %s\n", defaultIfEmpty(global.MetricsTextfile, "(off)"))
+	fmt.Printf("lock_ttl_minutes: %d\n", global.LockTTLMinutes)
+	fmt.Printf("snapshot_retention: %d\n", global.SnapshotRetention)
+	fmt.Printf("mirrors: %s\n", defaultIfEmpty(strings.Join(global.Mirrors, ", "), "(none)"))
+	fmt.Printf("failure_rules: %d custom (see `settings failure-rules`)\n", len(global.FailureRules))
 	if len(global.Proxies) == 0 {
 		fmt.Println("proxies: (none)")
-		return nil
-	}
-	fmt.Println("proxies:")
-	for i, p := range global.Proxies {
-		fmt.Printf("  %d. %s\n", i+1, p)
+	} else {
+		fmt.Println("proxies:")
+		for i, p := range global.Proxies {
+			fmt.Printf("  %d. %s\n", i+1, p)
+		}
 	}
+	printProjectDefaults(global)
 	return nil
 }
 
+// printProjectDefaults lists what projects inherit when they leave a setting
+// unset, and whether that is a global setting or a built-in default.
+func printProjectDefaults(global discovery.GlobalSettings) {
+	fmt.Println("project defaults:")
+	fmt.Printf("  output_root: %s\n", defaultIfEmpty(global.OutputRoot, "(default: inside each run)"))
+	storage := "(none)"
+	if global.Storage != nil {
+		storage = global.Storage.String()
+	}
+	fmt.Printf("  storage: %s\n", storage)
+	_, sources := discovery.ResolveProjectSettings(discovery.Project{}, global, nil)
+	for _, src := range sources {
+		switch src.Key {
+		case "workers", "output_dir", "storage", "mirrors":
+			continue
+		}
+		if src.Source == discovery.SettingFromDefault {
+			fmt.Printf("  %s: %s (default)\n", src.Key, src.Value)
+			continue
+		}
+		fmt.Printf("  %s: %s\n", src.Key, src.Value)
+	}
+}
+
 func runSettingsSet(args []string) error {
 	fs := flag.NewFlagSet("settings set", flag.ContinueOnError)
 	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
 	workers := fs.Int("workers", -1, "global worker default (>=1, -1 keeps current)")
 	downloadLimit := fs.Float64("download-limit-mb-s", -1, "global download limit in MB/s (>=0, 0 disables, -1 keeps current)")
 	proxyMode := fs.String("proxy-mode", "", "proxy mode: off|per_worker (empty keeps current)")
+	dedupMode := fs.String("dedup-mode", "", "cross-project dedup: off|hardlink|reflink|symlink (empty keeps current)")
+	metricsTextfile := fs.String("metrics-textfile", "", "Prometheus textfile written after each sync; \"off\" disables (empty keeps current)")
+	lockTTL := fs.Int("lock-ttl-minutes", -1, "minutes a run lock survives without heartbeat before it is recovered (>=1, -1 keeps current)")
+	snapshotRetention := fs.Int("snapshot-retention", -1, "raw source snapshots kept per run (>=1, -1 keeps current)")
+	mirrors := fs.String("mirrors", "", "comma-separated mirror roots; projects are copied to <root>/<project>; \"none\" clears (empty keeps current)")
+
+	// Project defaults. Empty keeps the current value; "default" unsets it
+	// so projects fall back to the built-in default.
+	outputRoot := fs.String("output-root", "", "root for project output; each project uses <root>/<project>")
+	cookies := fs.String("cookies", "", "cookies.txt for projects without their own")
+	cookiesFromBrowser := fs.String("cookies-from-browser", "", "browser to read cookies from, e.g. chrome")
+	download := addDownloadSettingFlags(fs)
+	storageEndpoint := fs.String("storage-endpoint", "", "S3-compatible endpoint; projects upload under <prefix>/<project>")
+	storageBucket := fs.String("storage-bucket", "", "storage bucket; \"none\" clears the storage default")
+	storagePrefix := fs.String("storage-prefix", "", "key prefix inside the bucket")
+	storageRegion := fs.String("storage-region", "", "storage region (default us-east-1)")
+	storageCredentials := fs.String("storage-credentials-file", "", "AWS-style credentials file")
+	storageVirtualHosted := fs.Bool("storage-virtual-hosted", false, "use bucket.endpoint host names instead of path-style requests")
+	storageDeleteLocal := fs.Bool("storage-delete-local", false, "delete local media and sidecars once uploaded")
 	jsonOut := fs.Bool("json", false, "print JSON output")
 	fs.SetOutput(flag.CommandLine.Output())
 	if err := fs.Parse(args); err != nil {
@@ -103,6 +158,52 @@ func runSettingsSet(args []string) error {
 		}
 		global.ProxyMode = mode
 	}
+	if strings.TrimSpace(*dedupMode) != "" {
+		mode := strings.ToLower(strings.TrimSpace(*dedupMode))
+		switch mode {
+		case discovery.DedupModeOff, discovery.DedupModeHardlink, discovery.DedupModeReflink, discovery.DedupModeSymlink:
+		default:
+			return errors.New("--dedup-mode must be off, hardlink, reflink, or symlink")
+		}
+		global.DedupMode = mode
+	}
+	if v := strings.TrimSpace(*metricsTextfile); v != "" {
+		if strings.EqualFold(v, "off") {
+			v = ""
+		}
+		global.MetricsTextfile = v
+	}
+	if *lockTTL != -1 {
+		if *lockTTL <= 0 {
+			return errors.New("--lock-ttl-minutes must be >= 1")
+		}
+		global.LockTTLMinutes = *lockTTL
+	}
+	if *snapshotRetention != -1 {
+		if *snapshotRetention <= 0 {
+			return errors.New("--snapshot-retention must be >= 1")
+		}
+		global.SnapshotRetention = *snapshotRetention
+	}
+	if v := strings.TrimSpace(*mirrors); v != "" {
+		if strings.EqualFold(v, "none") {
+			global.Mirrors = nil
+		} else {
+			global.Mirrors = parseProxyValueList(v)
+		}
+	}
+
+	setDefaultString(&global.OutputRoot, *outputRoot)
+	setDefaultString(&global.CookiesPath, *cookies)
+	setDefaultString(&global.CookiesFromBrowser, *cookiesFromBrowser)
+	if err := download.apply(&global.DownloadSettings); err != nil {
+		return err
+	}
+	if strings.EqualFold(strings.TrimSpace(*storageBucket), "none") {
+		global.Storage = nil
+	} else if target := storageFromFlags(*storageEndpoint, *storageBucket, *storagePrefix, *storageRegion, *storageCredentials, *storageVirtualHosted, *storageDeleteLocal); target != nil {
+		global.Storage = target
+	}
 
 	res, err := discovery.UpdateGlobalSettings(discovery.UpdateGlobalSettingsOptions{
 		ConfigPath: configPath,
@@ -119,7 +220,150 @@ func runSettingsSet(args []string) error {
 	fmt.Printf("workers: %d\n", res.Global.Workers)
 	fmt.Printf("download_limit_mb_s: %s\n", formatFloat(res.Global.DownloadLimitMBps))
 	fmt.Printf("proxy_mode: %s\n", res.Global.ProxyMode)
+	fmt.Printf("dedup_mode: %s\n", res.Global.DedupMode)
+	fmt.Printf("metrics_textfile: %s\n", defaultIfEmpty(res.Global.MetricsTextfile, "(off)"))
+	fmt.Printf("lock_ttl_minutes: %d\n", res.Global.LockTTLMinutes)
+	fmt.Printf("snapshot_retention: %d\n", res.Global.SnapshotRetention)
+	fmt.Printf("mirrors: %s\n", defaultIfEmpty(strings.Join(res.Global.Mirrors, ", "), "(none)"))
 	fmt.Printf("proxies: %d\n", len(res.Global.Proxies))
+	printProjectDefaults(res.Global)
+	return nil
+}
+
+// downloadSettingFlags set the values of a DownloadSettings layer: the
+// global defaults or a profile.
+type downloadSettingFlags struct {
+	fragments      *int
+	order          *string
+	quality        *string
+	videoCodec     *string
+	hdr            *string
+	container      *string
+	jsRuntime      *string
+	delivery       *string
+	subtitles      *string
+	subLangs       *string
+	liveChat       *string
+	liveChatFormat *string
+	schedule       *string
+	thumbnails     *string
+	audioOnly      *string
+}
+
+func addDownloadSettingFlags(fs *flag.FlagSet) *downloadSettingFlags {
+	return &downloadSettingFlags{
+		fragments:      fs.Int("fragments", -1, "yt-dlp fragment concurrency (0 unsets, -1 keeps current)"),
+		order:          fs.String("order", "", "order: oldest|newest|manifest"),
+		quality:        fs.String("quality", "", "quality preset: best|2160p|1440p|1080p|720p|480p"),
+		videoCodec:     fs.String("video-codec", "", "video codec preference order: list of av1,vp9,h264"),
+		hdr:            fs.String("hdr", "", "HDR preference: on|off"),
+		container:      fs.String("container", "", "target container with remux: mp4|mkv"),
+		jsRuntime:      fs.String("js-runtime", "", "JavaScript runtime: auto|deno|node|quickjs|bun, or ordered fallback list"),
+		delivery:       fs.String("delivery", "", "delivery mode: auto|fragmented"),
+		subtitles:      fs.String("subtitles", "", "download subtitles: yes|no|default"),
+		subLangs:       fs.String("sub-langs", "", "subtitle language: english|all"),
+		liveChat:       fs.String("live-chat", "", "download live chat replays: yes|no|default"),
+		liveChatFormat: fs.String("live-chat-format", "", "live chat render format: txt|ass"),
+		schedule:       fs.String("schedule", "", "daemon schedule: interval like 6h, or cron like \"0 3 * * *\""),
+		thumbnails:     fs.String("thumbnails", "", "save video thumbnails: yes|no|default"),
+		audioOnly:      fs.String("audio-only", "", "download only the audio track: yes|no|default"),
+	}
+}
+
+func (f *downloadSettingFlags) apply(dst *discovery.DownloadSettings) error {
+	if *f.fragments != -1 {
+		if *f.fragments < 0 {
+			return errors.New("--fragments must be >= 0")
+		}
+		dst.Fragments = *f.fragments
+	}
+	setDefaultString(&dst.Order, *f.order)
+	setDefaultString(&dst.Quality, *f.quality)
+	setDefaultString(&dst.VideoCodec, *f.videoCodec)
+	setDefaultString(&dst.HDR, *f.hdr)
+	setDefaultString(&dst.Container, *f.container)
+	setDefaultString(&dst.JSRuntime, *f.jsRuntime)
+	setDefaultString(&dst.DeliveryMode, *f.delivery)
+	setDefaultString(&dst.SubLangs, *f.subLangs)
+	setDefaultString(&dst.LiveChatFormat, *f.liveChatFormat)
+	setDefaultString(&dst.Schedule, *f.schedule)
+	if err := setDefaultBool(&dst.NoSubs, *f.subtitles, true); err != nil {
+		return fmt.Errorf("--subtitles: %w", err)
+	}
+	if err := setDefaultBool(&dst.LiveChat, *f.liveChat, false); err != nil {
+		return fmt.Errorf("--live-chat: %w", err)
+	}
+	if err := setDefaultBool(&dst.WriteThumbnails, *f.thumbnails, false); err != nil {
+		return fmt.Errorf("--thumbnails: %w", err)
+	}
+	if err := setDefaultBool(&dst.AudioOnly, *f.audioOnly, false); err != nil {
+		return fmt.Errorf("--audio-only: %w", err)
+	}
+	return nil
+}
+
+// setDefaultString applies a project-default flag: empty keeps the current
+// value and "default" unsets it.
+func setDefaultString(dst *string, raw string) {
+	v := strings.TrimSpace(raw)
+	switch {
+	case v == "":
+	case strings.EqualFold(v, "default"):
+		*dst = ""
+	default:
+		*dst = v
+	}
+}
+
+// setDefaultBool applies a yes|no|default flag. invert stores the negation,
+// for settings like no_subs that a flag names positively.
+func setDefaultBool(dst **bool, raw string, invert bool) error {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "":
+	case "default":
+		*dst = nil
+	case "yes", "true":
+		*dst = boolPtr(!invert)
+	case "no", "false":
+		*dst = boolPtr(invert)
+	default:
+		return fmt.Errorf("invalid value %q (use yes|no|default)", raw)
+	}
+	return nil
+}
+
+// runSettingsExplain shows a project's effective settings and whether each
+// comes from the project, the global settings, or the built-in defaults.
+func runSettingsExplain(args []string) error {
+	fs := flag.NewFlagSet("settings explain", flag.ContinueOnError)
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	project := fs.String("project", "", "project name")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*project) == "" {
+		return errors.New("--project is required")
+	}
+
+	p, sources, err := discovery.ExplainProjectSettings(strings.TrimSpace(*config), strings.TrimSpace(*project))
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(map[string]any{
+			"config_path": strings.TrimSpace(*config),
+			"project":     p.Name,
+			"settings":    sources,
+		})
+	}
+	fmt.Printf("project: %s\n", p.Name)
+	fmt.Printf("source: %s\n", p.SourceURL)
+	fmt.Printf("profile: %s\n", p.Profile)
+	for _, src := range sources {
+		fmt.Printf("  %-20s %-32s %s\n", src.Key, src.Value, src.Source)
+	}
 	return nil
 }
 
@@ -271,13 +515,56 @@ func runSettingsProxyRemove(args []string) error {
 	return nil
 }
 
+// runSettingsFailureRules lists the classifier rules in match order. Custom
+// rules live in the global "failure_rules" list of the config file.
+func runSettingsFailureRules(args []string) error {
+	fs := flag.NewFlagSet("settings failure-rules", flag.ContinueOnError)
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	global, err := discovery.ReadGlobalSettings(strings.TrimSpace(*config))
+	if err != nil {
+		return err
+	}
+	rules := archive.EffectiveFailureRules(global.FailureRules)
+	if *jsonOut {
+		return printJSON(map[string]any{
+			"custom": len(global.FailureRules),
+			"rules":  rules,
+		})
+	}
+	for i, r := range rules {
+		source := "builtin"
+		if i < len(global.FailureRules) {
+			source = "custom"
+		}
+		kind := "permanent"
+		if r.Retryable {
+			kind = "retryable"
+		}
+		pattern := fmt.Sprintf("%q", r.Pattern)
+		if r.Regex {
+			pattern = "re:" + pattern
+		}
+		fmt.Printf("%3d. [%s] %s -> %s (%s)\n", i+1, source, pattern, r.Reason, kind)
+	}
+	return nil
+}
+
 func printSettingsUsage() {
 	fmt.Println("settings commands:")
 	fmt.Println("  settings show")
 	fmt.Println("  settings set [--workers N] [--download-limit-mb-s N] [--proxy-mode off|per_worker]")
+	fmt.Println("  settings set [--quality 1080p] [--subtitles yes|no|default] [--order ...] ...  (project defaults; \"default\" unsets)")
+	fmt.Println("  settings explain --project <name>")
 	fmt.Println("  settings proxy list")
 	fmt.Println("  settings proxy add --value <proxy-url>")
 	fmt.Println("  settings proxy remove --value <proxy-url> | --index <n>")
+	fmt.Println("  settings failure-rules [--json]")
 }
 
 func printSettingsProxyUsage() {
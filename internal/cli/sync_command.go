@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"bufio"
 	"errors"
 	"flag"
 	"fmt"
@@ -15,6 +14,7 @@ import (
 
 type syncSourceItem struct {
 	Project            string
+	ProjectID          string
 	SourceURL          string
 	Profile            string
 	OutputDir          string
@@ -24,10 +24,19 @@ type syncSourceItem struct {
 	Fragments          int
 	Order              string
 	Quality            string
+	VideoCodec         string
+	HDR                string
+	Container          string
 	JSRuntime          string
 	DeliveryMode       string
 	NoSubs             bool
 	SubLangs           string
+	LiveChat           bool
+	LiveChatFormat     string
+	AudioOnly          bool
+	WriteThumbnails    bool
+	Storage            *discovery.StorageTarget
+	Mirrors            []string
 }
 
 type syncSourceReport struct {
@@ -40,6 +49,7 @@ type syncSourceReport struct {
 	TotalEntries        int    `json:"total_entries"`
 	Pending             int    `json:"pending_count"`
 	SkippedPrivate      int    `json:"skipped_private_count"`
+	RemovedUpstream     int    `json:"removed_upstream_count,omitempty"`
 	ProcessedNow        int    `json:"processed_now,omitempty"`
 	CompletedTotal      int    `json:"completed_total,omitempty"`
 	PendingTotal        int    `json:"pending_total,omitempty"`
@@ -60,6 +70,48 @@ type syncResult struct {
 	PendingTotal    int                `json:"pending_total,omitempty"`
 	Failures        int                `json:"failures"`
 	Reports         []syncSourceReport `json:"reports"`
+	Skipped         string             `json:"skipped,omitempty"`
+}
+
+// syncOptions carries invocation-level overrides; empty/zero values fall
+// back to project settings and then to built-in defaults.
+type syncOptions struct {
+	RunsDir            string
+	NoRun              bool
+	MaxJobs            int
+	Workers            int
+	DownloadLimitMBps  *float64
+	RetryPermanent     bool
+	StopOnRetryable    bool
+	Fragments          int
+	Order              string
+	Quality            string
+	VideoCodec         string
+	HDR                string
+	Container          string
+	JSRuntime          string
+	Delivery           string
+	Progress           bool
+	RawOutput          bool
+	OutputDir          string
+	Subtitles          string
+	SubLangs           string
+	LiveChat           string
+	LiveChatFormat     string
+	CookiesPath        string
+	CookiesFromBrowser string
+	Quiet              bool
+	Global             discovery.GlobalSettings
+	Stop               <-chan struct{}
+}
+
+// syncSourceOutcome is the result of one refresh + download cycle. Err is a
+// failure of this source only; other sources can still be processed.
+type syncSourceOutcome struct {
+	Report syncSourceReport
+	Run    archive.RunResult
+	Ran    bool
+	Err    error
 }
 
 func runSync(args []string) error {
@@ -81,7 +133,10 @@ func runSync(args []string) error {
 	stopOnRetryable := fs.Bool("stop-on-retryable", true, "stop run after first retryable failure")
 	fragments := fs.Int("fragments", 0, "yt-dlp fragment concurrency (-N); 0 = project/default")
 	order := fs.String("order", "", "job processing order: oldest|newest|manifest")
-	quality := fs.String("quality", "", "quality preset: best|1080p|720p")
+	quality := fs.String("quality", "", "quality preset: best|2160p|1440p|1080p|720p|480p")
+	videoCodec := fs.String("video-codec", "", "video codec preference order: auto or list of av1,vp9,h264")
+	hdr := fs.String("hdr", "", "HDR preference: auto|on|off")
+	container := fs.String("container", "", "target container with remux: auto|mp4|mkv")
 	jsRuntime := fs.String("js-runtime", "", "JavaScript runtime override for yt-dlp extractor scripts: auto|deno|node|quickjs|bun, or ordered fallback list like node,quickjs")
 	delivery := fs.String("delivery", "", "delivery mode: auto|fragmented")
 	progress := fs.Bool("progress", true, "show live progress renderer")
@@ -89,14 +144,26 @@ func runSync(args []string) error {
 	outputDir := fs.String("output-dir", "", "download output dir (default: <run_dir>/downloads)")
 	subtitles := fs.String("subtitles", "auto", "subtitle download: auto|yes|no")
 	subLangs := fs.String("sub-langs", "", "subtitle language preference: english|all")
+	liveChat := fs.String("live-chat", "auto", "live chat replay download: auto|yes|no")
+	liveChatFormat := fs.String("live-chat-format", "", "live chat render format: txt|ass")
+	metricsTextfile := fs.String("metrics-textfile", "", "write Prometheus metrics to this path after sync (default: global metrics_textfile)")
 	cookies := fs.String("cookies", "", "path to cookies.txt")
 	useBrowserCookies := fs.Bool("browser-cookies", false, browserCookiesFlagHelp)
+	wait := fs.Bool("wait", true, "wait for another sync on this runs directory to finish")
+	noWait := fs.Bool("no-wait", false, "exit without syncing if another sync is running")
+	waitTimeout := fs.Duration("wait-timeout", 0, "give up waiting for another sync after this long (0 = no limit)")
 	jsonOut := fs.Bool("json", false, "print JSON output")
 
 	fs.SetOutput(flag.CommandLine.Output())
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	if *noWait {
+		*wait = false
+	}
+	if *waitTimeout < 0 {
+		return errors.New("--wait-timeout must be >= 0")
+	}
 	if *downloadLimitMBps < -1 {
 		return errors.New("--download-limit-mb-s must be >= 0, or -1 to keep global/default")
 	}
@@ -126,7 +193,68 @@ func runSync(args []string) error {
 	if *useBrowserCookies {
 		cliCookiesFromBrowser = discovery.DefaultBrowserCookieAgent
 	}
-	progressEnabled := *progress && !*jsonOut
+	opts := syncOptions{
+		RunsDir:            strings.TrimSpace(*runsDir),
+		NoRun:              *noRun,
+		MaxJobs:            *maxJobs,
+		Workers:            *workers,
+		DownloadLimitMBps:  limitOverride,
+		RetryPermanent:     *retryPermanent,
+		StopOnRetryable:    *stopOnRetryable,
+		Fragments:          *fragments,
+		Order:              strings.TrimSpace(*order),
+		Quality:            strings.TrimSpace(*quality),
+		VideoCodec:         strings.TrimSpace(*videoCodec),
+		HDR:                strings.TrimSpace(*hdr),
+		Container:          strings.TrimSpace(*container),
+		JSRuntime:          strings.TrimSpace(*jsRuntime),
+		Delivery:           strings.TrimSpace(*delivery),
+		Progress:           *progress && !*jsonOut,
+		RawOutput:          *rawOutput,
+		OutputDir:          strings.TrimSpace(*outputDir),
+		Subtitles:          strings.TrimSpace(*subtitles),
+		SubLangs:           strings.TrimSpace(*subLangs),
+		LiveChat:           strings.TrimSpace(*liveChat),
+		LiveChatFormat:     strings.TrimSpace(*liveChatFormat),
+		CookiesPath:        strings.TrimSpace(*cookies),
+		CookiesFromBrowser: cliCookiesFromBrowser,
+		Quiet:              *jsonOut,
+		Global:             global,
+	}
+
+	lock, err := discovery.AcquireSyncLock(opts.RunsDir, discovery.SyncLockOptions{
+		TTL:     global.RunLockTTL(),
+		Wait:    *wait,
+		Timeout: *waitTimeout,
+		OnWait: func(info discovery.SyncLockInfo) {
+			if !*jsonOut {
+				fmt.Printf("sync: another %s; waiting...\n", describeSyncLock(info))
+			}
+		},
+	})
+	if err != nil {
+		if !*wait && discovery.IsSyncLocked(err) {
+			reason := "sync in progress"
+			if info, inspectErr := discovery.InspectSyncLock(opts.RunsDir, global.RunLockTTL()); inspectErr == nil && info.Locked {
+				reason = describeSyncLock(info)
+			}
+			if *jsonOut {
+				return printJSON(syncResult{Sources: len(items), Reports: []syncSourceReport{}, Skipped: reason})
+			}
+			fmt.Printf("%s; skip\n", reason)
+			return nil
+		}
+		return err
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+	if strings.TrimSpace(*project) != "" || *allProjects {
+		if err := discovery.PersistProjectIDs(configPath); err != nil {
+			return err
+		}
+	}
+
 	if !*jsonOut {
 		if *noRun {
 			fmt.Printf("sync: refreshing %d source(s)...\n", len(items))
@@ -135,6 +263,9 @@ func runSync(args []string) error {
 		}
 	}
 
+	recorder := newSyncMetricsRecorder(configPath, opts.RunsDir, firstNonEmpty(strings.TrimSpace(*metricsTextfile), global.MetricsTextfile))
+	defer recorder.flush()
+
 	totalProcessed := 0
 	totalCompleted := 0
 	totalPending := 0
@@ -145,30 +276,15 @@ func runSync(args []string) error {
 	reports := make([]syncSourceReport, 0, len(items))
 
 	for idx, item := range items {
-		report := syncSourceReport{
-			Project:   item.Project,
-			SourceURL: item.SourceURL,
-		}
-		effectiveJSRuntime := firstNonEmpty(strings.TrimSpace(*jsRuntime), item.JSRuntime, discovery.DefaultJSRuntime)
-		report.EffectiveJSRuntime = effectiveJSRuntime
-		sourceLabel := firstNonEmpty(item.Project, item.SourceURL)
-		if !*jsonOut {
-			fmt.Printf("[%d/%d] refreshing %s\n", idx+1, len(items), sourceLabel)
-		}
-		refreshStart := time.Now()
-		upsert, err := discovery.UpsertBySource(discovery.UpsertOptions{
-			SourceURL:          item.SourceURL,
-			Profile:            firstNonEmpty(item.Profile, discovery.DefaultProfileName),
-			RunsDir:            strings.TrimSpace(*runsDir),
-			CookiesPath:        firstNonEmpty(strings.TrimSpace(*cookies), item.CookiesPath),
-			CookiesFromBrowser: firstNonEmpty(cliCookiesFromBrowser, item.CookiesFromBrowser),
-			JSRuntime:          effectiveJSRuntime,
-		})
+		out, err := syncSource(item, fmt.Sprintf("[%d/%d]", idx+1, len(items)), opts, recorder)
 		if err != nil {
+			return err
+		}
+		report := out.Report
+		totalAdded += report.AddedNewEntries
+		reports = append(reports, report)
+		if out.Err != nil {
 			failures++
-			report.Error = err.Error()
-			reports = append(reports, report)
-			fmt.Fprintf(os.Stderr, "sync failed for %s: %v\n", item.SourceURL, err)
 			if !*continueOnError {
 				result := syncResult{Sources: len(items), AddedNewEntries: totalAdded, Failures: failures, Reports: reports}
 				if !*noRun {
@@ -179,132 +295,26 @@ func runSync(args []string) error {
 				if *jsonOut {
 					_ = printJSON(result)
 				}
-				return err
+				return out.Err
 			}
 			continue
 		}
-
-		runDir := upsert.Result.RunDir
-		runID := upsert.Result.RunID
-		report.RunID = runID
-		report.RunDir = runDir
-		if upsert.Created {
-			report.Created = true
-			report.TotalEntries = upsert.Result.TotalEntries
-			report.Pending = upsert.Result.Pending
-			report.SkippedPrivate = upsert.Result.SkippedPrivate
-			report.AddedNewEntries = upsert.Result.Pending + upsert.Result.SkippedPrivate
-		} else {
-			runDir = upsert.Refresh.RunDir
-			runID = upsert.Refresh.RunID
-			report.RunID = runID
-			report.RunDir = runDir
-			report.TotalEntries = upsert.Refresh.TotalEntries
-			report.Pending = upsert.Refresh.Pending
-			report.SkippedPrivate = upsert.Refresh.SkippedPrivate
-			report.AddedNewEntries = upsert.Refresh.Added
-		}
-		totalAdded += report.AddedNewEntries
-		if !*jsonOut {
-			fmt.Printf("[%d/%d] refreshed in %s (+%d new, pending %d)\n",
-				idx+1,
-				len(items),
-				time.Since(refreshStart).Round(time.Millisecond),
-				report.AddedNewEntries,
-				report.Pending,
-			)
-		}
-		if !*jsonOut {
-			fmt.Printf("[%d/%d] effective js runtime: %s\n", idx+1, len(items), effectiveJSRuntime)
-		}
-
-		if *noRun {
-			reports = append(reports, report)
+		if !out.Ran {
 			continue
 		}
+		totalProcessed += out.Run.Processed
+		totalCompleted += out.Run.Completed
+		totalPending += out.Run.Pending
+		totalEstimatedBytes += out.Run.EstimatedTotalBytes
+		totalEstimatedDoneBytes += out.Run.EstimatedCompleteBytes
+	}
 
-		networkSettings, err := discovery.ResolveRuntimeNetworkSettings(
-			discovery.Project{Workers: item.Workers},
-			global,
-			*workers,
-			limitOverride,
-		)
-		if err != nil {
-			return fmt.Errorf("resolve runtime settings for %s: %w", sourceLabel, err)
-		}
-		effectiveFragments := firstNonZero(*fragments, item.Fragments)
-		effectiveOrder := firstNonEmpty(strings.TrimSpace(*order), item.Order, discovery.DefaultOrder)
-		effectiveQuality := firstNonEmpty(strings.TrimSpace(*quality), item.Quality, discovery.DefaultQuality)
-		effectiveDelivery := firstNonEmpty(strings.TrimSpace(*delivery), item.DeliveryMode, "auto")
-		effectiveOutputDir := firstNonEmpty(strings.TrimSpace(*outputDir), item.OutputDir)
-		effectiveSubLangs := firstNonEmpty(strings.TrimSpace(*subLangs), item.SubLangs, discovery.DefaultSubtitleLanguage)
-		effectiveNoSubs, err := resolveNoSubs(strings.TrimSpace(*subtitles), item.NoSubs)
-		if err != nil {
-			return err
-		}
-
-		if !*jsonOut {
-			fmt.Printf("[%d/%d] starting download phase...\n", idx+1, len(items))
-		}
-		res, runErr := archive.Run(archive.RunOptions{
-			RunDir:             runDir,
-			RunID:              runID,
-			RunsDir:            strings.TrimSpace(*runsDir),
-			Latest:             false,
-			OutputDir:          effectiveOutputDir,
-			CookiesPath:        firstNonEmpty(strings.TrimSpace(*cookies), item.CookiesPath),
-			CookiesFromBrowser: firstNonEmpty(cliCookiesFromBrowser, item.CookiesFromBrowser),
-			SubLangs:           effectiveSubLangs,
-			Fragments:          effectiveFragments,
-			MaxJobs:            *maxJobs,
-			Workers:            networkSettings.Workers,
-			DownloadLimitMBps:  networkSettings.DownloadLimitMBps,
-			ProxyMode:          networkSettings.ProxyMode,
-			Proxies:            networkSettings.Proxies,
-			NoSubs:             effectiveNoSubs,
-			RetryPermanent:     *retryPermanent,
-			StopOnRetryable:    *stopOnRetryable,
-			Progress:           progressEnabled,
-			RawOutput:          *rawOutput,
-			Order:              effectiveOrder,
-			Quality:            effectiveQuality,
-			JSRuntime:          effectiveJSRuntime,
-			DeliveryMode:       effectiveDelivery,
-		})
-		if runErr != nil {
-			failures++
-			report.Error = runErr.Error()
-			reports = append(reports, report)
-			fmt.Fprintf(os.Stderr, "run failed for %s: %v\n", item.SourceURL, runErr)
-			if !*continueOnError {
-				result := syncResult{Sources: len(items), AddedNewEntries: totalAdded, Failures: failures, Reports: reports}
-				if !*noRun {
-					result.ProcessedNow = totalProcessed
-					result.CompletedTotal = totalCompleted
-					result.PendingTotal = totalPending
-				}
-				if *jsonOut {
-					_ = printJSON(result)
-				}
-				return runErr
-			}
-			continue
+	if idx, err := discovery.UpdateSearchIndex(configPath, opts.RunsDir); err != nil {
+		fmt.Fprintf(os.Stderr, "warn  search index update failed (non-fatal): %v\n", err)
+	} else {
+		for _, w := range idx.Warnings {
+			fmt.Fprintf(os.Stderr, "warn  search index: %s\n", w)
 		}
-
-		totalProcessed += res.Processed
-		totalCompleted += res.Completed
-		totalPending += res.Pending
-		report.ProcessedNow = res.Processed
-		report.CompletedTotal = res.Completed
-		report.PendingTotal = res.Pending
-		report.FailedRetryable = res.FailedRetryable
-		report.FailedPermanent = res.FailedPermanent
-		report.Remaining = res.Remaining
-		report.EstimatedTotalBytes = res.EstimatedTotalBytes
-		report.EstimatedDoneBytes = res.EstimatedCompleteBytes
-		totalEstimatedBytes += res.EstimatedTotalBytes
-		totalEstimatedDoneBytes += res.EstimatedCompleteBytes
-		reports = append(reports, report)
 	}
 
 	result := syncResult{
@@ -344,6 +354,170 @@ func runSync(args []string) error {
 	return nil
 }
 
+// describeSyncLock summarizes the holder of the workspace sync lock.
+func describeSyncLock(info discovery.SyncLockInfo) string {
+	if !info.Locked {
+		return "no sync running"
+	}
+	if info.PID <= 0 {
+		return "sync in progress (no owner record)"
+	}
+	msg := fmt.Sprintf("sync in progress (pid %d on %s since %s)", info.PID, defaultIfEmpty(info.Hostname, "unknown host"), info.CreatedAt)
+	if info.Stale {
+		msg += "; lock looks stale: " + info.StaleReason
+	}
+	return msg
+}
+
+// syncSource refreshes one source through UpsertBySource and, unless NoRun is
+// set, downloads its pending jobs with archive.Run. The returned error aborts
+// the whole sync (invalid settings); per-source failures go into Err.
+func syncSource(item syncSourceItem, prefix string, opts syncOptions, recorder *syncMetricsRecorder) (syncSourceOutcome, error) {
+	report := syncSourceReport{
+		Project:   item.Project,
+		SourceURL: item.SourceURL,
+	}
+	effectiveJSRuntime := firstNonEmpty(opts.JSRuntime, item.JSRuntime, discovery.DefaultJSRuntime)
+	report.EffectiveJSRuntime = effectiveJSRuntime
+	sourceLabel := firstNonEmpty(item.Project, item.SourceURL)
+	if !opts.Quiet {
+		fmt.Printf("%s refreshing %s\n", prefix, sourceLabel)
+	}
+	refreshStart := time.Now()
+	upsert, err := discovery.UpsertBySource(discovery.UpsertOptions{
+		SourceURL:          item.SourceURL,
+		Profile:            firstNonEmpty(item.Profile, discovery.DefaultProfileName),
+		ProjectID:          item.ProjectID,
+		RunsDir:            opts.RunsDir,
+		CookiesPath:        firstNonEmpty(opts.CookiesPath, item.CookiesPath),
+		CookiesFromBrowser: firstNonEmpty(opts.CookiesFromBrowser, item.CookiesFromBrowser),
+		JSRuntime:          effectiveJSRuntime,
+		LockTTL:            opts.Global.RunLockTTL(),
+		SnapshotRetention:  opts.Global.SnapshotRetention,
+	})
+	if err != nil {
+		recorder.failed(sourceLabel, err)
+		report.Error = err.Error()
+		fmt.Fprintf(os.Stderr, "sync failed for %s: %v\n", item.SourceURL, err)
+		return syncSourceOutcome{Report: report, Err: err}, nil
+	}
+
+	runDir := upsert.Result.RunDir
+	runID := upsert.Result.RunID
+	report.RunID = runID
+	report.RunDir = runDir
+	if upsert.Created {
+		report.Created = true
+		report.TotalEntries = upsert.Result.TotalEntries
+		report.Pending = upsert.Result.Pending
+		report.SkippedPrivate = upsert.Result.SkippedPrivate
+		report.AddedNewEntries = upsert.Result.Pending + upsert.Result.SkippedPrivate
+	} else {
+		runDir = upsert.Refresh.RunDir
+		runID = upsert.Refresh.RunID
+		report.RunID = runID
+		report.RunDir = runDir
+		report.TotalEntries = upsert.Refresh.TotalEntries
+		report.Pending = upsert.Refresh.Pending
+		report.SkippedPrivate = upsert.Refresh.SkippedPrivate
+		report.AddedNewEntries = upsert.Refresh.Added
+		report.RemovedUpstream = upsert.Refresh.RemovedUpstream
+	}
+	recorder.refreshed(sourceLabel, time.Since(refreshStart))
+	if !opts.Quiet {
+		fmt.Printf("%s refreshed in %s (+%d new, pending %d)\n",
+			prefix,
+			time.Since(refreshStart).Round(time.Millisecond),
+			report.AddedNewEntries,
+			report.Pending,
+		)
+		fmt.Printf("%s effective js runtime: %s\n", prefix, effectiveJSRuntime)
+	}
+
+	if opts.NoRun {
+		recorder.succeeded(sourceLabel)
+		return syncSourceOutcome{Report: report}, nil
+	}
+
+	networkSettings, err := discovery.ResolveRuntimeNetworkSettings(
+		discovery.Project{Workers: item.Workers},
+		opts.Global,
+		opts.Workers,
+		opts.DownloadLimitMBps,
+	)
+	if err != nil {
+		return syncSourceOutcome{}, fmt.Errorf("resolve runtime settings for %s: %w", sourceLabel, err)
+	}
+	effectiveNoSubs, err := resolveNoSubs(opts.Subtitles, item.NoSubs)
+	if err != nil {
+		return syncSourceOutcome{}, err
+	}
+	effectiveLiveChat, err := resolveLiveChat(opts.LiveChat, item.LiveChat)
+	if err != nil {
+		return syncSourceOutcome{}, err
+	}
+
+	if !opts.Quiet {
+		fmt.Printf("%s starting download phase...\n", prefix)
+	}
+	res, runErr := archive.Run(archive.RunOptions{
+		RunDir:             runDir,
+		RunID:              runID,
+		RunsDir:            opts.RunsDir,
+		Latest:             false,
+		OutputDir:          firstNonEmpty(opts.OutputDir, item.OutputDir),
+		CookiesPath:        firstNonEmpty(opts.CookiesPath, item.CookiesPath),
+		CookiesFromBrowser: firstNonEmpty(opts.CookiesFromBrowser, item.CookiesFromBrowser),
+		SubLangs:           firstNonEmpty(opts.SubLangs, item.SubLangs, discovery.DefaultSubtitleLanguage),
+		Fragments:          firstNonZero(opts.Fragments, item.Fragments),
+		MaxJobs:            opts.MaxJobs,
+		Workers:            networkSettings.Workers,
+		DownloadLimitMBps:  networkSettings.DownloadLimitMBps,
+		ProxyMode:          networkSettings.ProxyMode,
+		Proxies:            networkSettings.Proxies,
+		NoSubs:             effectiveNoSubs,
+		LiveChat:           effectiveLiveChat,
+		LiveChatFormat:     firstNonEmpty(opts.LiveChatFormat, item.LiveChatFormat, discovery.DefaultLiveChatFormat),
+		AudioOnly:          item.AudioOnly,
+		WriteThumbnails:    item.WriteThumbnails,
+		RetryPermanent:     opts.RetryPermanent,
+		StopOnRetryable:    opts.StopOnRetryable,
+		Progress:           opts.Progress,
+		RawOutput:          opts.RawOutput,
+		Order:              firstNonEmpty(opts.Order, item.Order, discovery.DefaultOrder),
+		Quality:            firstNonEmpty(opts.Quality, item.Quality, discovery.DefaultQuality),
+		VideoCodec:         firstNonEmpty(opts.VideoCodec, item.VideoCodec),
+		HDR:                firstNonEmpty(opts.HDR, item.HDR),
+		Container:          firstNonEmpty(opts.Container, item.Container),
+		JSRuntime:          effectiveJSRuntime,
+		DeliveryMode:       firstNonEmpty(opts.Delivery, item.DeliveryMode, "auto"),
+		DedupMode:          opts.Global.DedupMode,
+		LockTTL:            opts.Global.RunLockTTL(),
+		FailureRules:       opts.Global.FailureRules,
+		Storage:            item.Storage,
+		Mirrors:            discovery.ResolveMirrors(discovery.Project{Name: item.Project, Mirrors: item.Mirrors}, opts.Global, runID),
+		Stop:               opts.Stop,
+	})
+	recorder.ran(sourceLabel, res)
+	if runErr != nil {
+		recorder.failed(sourceLabel, runErr)
+		report.Error = runErr.Error()
+		fmt.Fprintf(os.Stderr, "run failed for %s: %v\n", item.SourceURL, runErr)
+		return syncSourceOutcome{Report: report, Run: res, Err: runErr}, nil
+	}
+
+	recorder.succeeded(sourceLabel)
+	report.ProcessedNow = res.Processed
+	report.CompletedTotal = res.Completed
+	report.PendingTotal = res.Pending
+	report.FailedRetryable = res.FailedRetryable
+	report.FailedPermanent = res.FailedPermanent
+	report.Remaining = res.Remaining
+	report.EstimatedTotalBytes = res.EstimatedTotalBytes
+	report.EstimatedDoneBytes = res.EstimatedCompleteBytes
+	return syncSourceOutcome{Report: report, Run: res, Ran: true}, nil
+}
+
 func collectSyncItems(singleSource, fetchlistPath, projectNames string, allProjects bool, activeOnly bool, configPath string) ([]syncSourceItem, error) {
 	hasSourceInputs := strings.TrimSpace(singleSource) != "" || strings.TrimSpace(fetchlistPath) != ""
 	hasProjectInputs := strings.TrimSpace(projectNames) != "" || allProjects
@@ -375,22 +549,7 @@ func collectSyncItems(singleSource, fetchlistPath, projectNames string, allProje
 			return nil, err
 		}
 		for _, p := range projects {
-			appendSource(syncSourceItem{
-				Project:            p.Name,
-				SourceURL:          p.SourceURL,
-				Profile:            firstNonEmpty(p.Profile, discovery.DefaultProfileName),
-				OutputDir:          p.OutputDir,
-				CookiesPath:        p.CookiesPath,
-				CookiesFromBrowser: p.CookiesFromBrowser,
-				Workers:            p.Workers,
-				Fragments:          p.Fragments,
-				Order:              p.Order,
-				Quality:            p.Quality,
-				JSRuntime:          p.JSRuntime,
-				DeliveryMode:       p.DeliveryMode,
-				NoSubs:             p.NoSubs,
-				SubLangs:           p.SubLangs,
-			})
+			appendSource(syncItemFromProject(p))
 		}
 		if len(items) == 0 {
 			return nil, fmt.Errorf("no projects selected")
@@ -404,30 +563,12 @@ func collectSyncItems(singleSource, fetchlistPath, projectNames string, allProje
 	})
 
 	if strings.TrimSpace(fetchlistPath) != "" {
-		f, err := os.Open(fetchlistPath)
+		entries, err := discovery.ReadFetchlist(fetchlistPath)
 		if err != nil {
-			return nil, fmt.Errorf("open fetchlist %s: %w", fetchlistPath, err)
-		}
-		defer f.Close()
-
-		sc := bufio.NewScanner(f)
-		for sc.Scan() {
-			line := strings.TrimSpace(sc.Text())
-			if line == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-			item := syncSourceItem{Profile: discovery.DefaultProfileName}
-			if strings.Contains(line, "|") {
-				parts := strings.SplitN(line, "|", 2)
-				item.Project = strings.TrimSpace(parts[0])
-				item.SourceURL = strings.TrimSpace(parts[1])
-			} else {
-				item.SourceURL = line
-			}
-			appendSource(item)
+			return nil, err
 		}
-		if err := sc.Err(); err != nil {
-			return nil, fmt.Errorf("read fetchlist %s: %w", fetchlistPath, err)
+		for _, e := range entries {
+			appendSource(syncSourceItem{Project: e.Name, SourceURL: e.SourceURL, Profile: discovery.DefaultProfileName})
 		}
 	}
 
@@ -437,6 +578,35 @@ func collectSyncItems(singleSource, fetchlistPath, projectNames string, allProje
 	return items, nil
 }
 
+func syncItemFromProject(p discovery.Project) syncSourceItem {
+	return syncSourceItem{
+		Project:            p.Name,
+		ProjectID:          p.ID,
+		SourceURL:          p.SourceURL,
+		Profile:            firstNonEmpty(p.Profile, discovery.DefaultProfileName),
+		OutputDir:          p.OutputDir,
+		CookiesPath:        p.CookiesPath,
+		CookiesFromBrowser: p.CookiesFromBrowser,
+		Workers:            p.Workers,
+		Fragments:          p.Fragments,
+		Order:              p.Order,
+		Quality:            p.Quality,
+		VideoCodec:         p.VideoCodec,
+		HDR:                p.HDR,
+		Container:          p.Container,
+		JSRuntime:          p.JSRuntime,
+		DeliveryMode:       p.DeliveryMode,
+		NoSubs:             boolValue(p.NoSubs),
+		SubLangs:           p.SubLangs,
+		LiveChat:           boolValue(p.LiveChat),
+		LiveChatFormat:     p.LiveChatFormat,
+		AudioOnly:          boolValue(p.AudioOnly),
+		WriteThumbnails:    boolValue(p.WriteThumbnails),
+		Storage:            p.Storage,
+		Mirrors:            p.Mirrors,
+	}
+}
+
 func firstNonEmpty(values ...string) string {
 	for _, v := range values {
 		if strings.TrimSpace(v) != "" {
@@ -476,3 +646,17 @@ func resolveNoSubs(mode string, projectNoSubs bool) (bool, error) {
 		return false, fmt.Errorf("invalid --subtitles value %q (use auto|yes|no)", mode)
 	}
 }
+
+func resolveLiveChat(mode string, projectLiveChat bool) (bool, error) {
+	m := strings.ToLower(strings.TrimSpace(mode))
+	switch m {
+	case "", "auto":
+		return projectLiveChat, nil
+	case "yes", "true":
+		return true, nil
+	case "no", "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid --live-chat value %q (use auto|yes|no)", mode)
+	}
+}
@@ -109,6 +109,49 @@ func TestRunSyncJSONNoRunRemainsMachineReadable(t *testing.T) {
 	}
 }
 
+func TestRunSyncNoWaitSkipsWhileAnotherSyncRuns(t *testing.T) {
+	tmp := t.TempDir()
+	setupFakeFlatPlaylistYTDLP(t, tmp)
+	runsDir := filepath.Join(tmp, "runs")
+
+	lock, err := discovery.AcquireSyncLock(runsDir, discovery.SyncLockOptions{})
+	if err != nil {
+		t.Fatalf("acquire sync lock: %v", err)
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+
+	output := captureStdout(t, func() {
+		err := runSync([]string{
+			"--source", "https://example.com/source",
+			"--runs-dir", runsDir,
+			"--no-run",
+			"--no-wait",
+		})
+		if err != nil {
+			t.Fatalf("runSync failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "sync in progress (pid ") || !strings.Contains(output, "; skip") {
+		t.Fatalf("expected skip message with owner, got:\n%s", output)
+	}
+	if strings.Contains(output, "refreshing") {
+		t.Fatalf("expected no refresh while locked, got:\n%s", output)
+	}
+
+	err = runSync([]string{
+		"--source", "https://example.com/source",
+		"--runs-dir", runsDir,
+		"--no-run",
+		"--wait-timeout", "50ms",
+		"--json",
+	})
+	if !discovery.IsSyncLocked(err) {
+		t.Fatalf("expected wait timeout on locked workspace, got %v", err)
+	}
+}
+
 func setupFakeFlatPlaylistYTDLP(t *testing.T, tmp string) {
 	t.Helper()
 	fakeBin := filepath.Join(tmp, "bin")
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"yt-vod-manager/internal/archive"
+	"yt-vod-manager/internal/discovery"
+)
+
+func runUnlock(args []string) error {
+	fs := flag.NewFlagSet("unlock", flag.ContinueOnError)
+	runID := fs.String("run-id", "", "run id from runs/<run_id>")
+	runDir := fs.String("run-dir", "", "explicit run directory path")
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	project := fs.String("project", "", "project name (uses latest run for that project)")
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	force := fs.Bool("force", false, "remove the lock even if its owner still looks alive")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	configPath := strings.TrimSpace(*config)
+
+	targetRunDir := strings.TrimSpace(*runDir)
+	if strings.TrimSpace(*project) != "" {
+		resolved, _, err := discovery.ResolveRunDirForProject(configPath, strings.TrimSpace(*project), strings.TrimSpace(*runsDir))
+		if err != nil {
+			return err
+		}
+		targetRunDir = resolved
+	}
+	if targetRunDir == "" && strings.TrimSpace(*runID) == "" {
+		return errors.New("unlock target required: set --run-id, --run-dir, or --project")
+	}
+	global, err := discovery.ReadGlobalSettings(configPath)
+	if err != nil {
+		return err
+	}
+
+	res, err := archive.Unlock(archive.UnlockOptions{
+		RunID:   strings.TrimSpace(*runID),
+		RunDir:  targetRunDir,
+		RunsDir: strings.TrimSpace(*runsDir),
+		LockTTL: global.RunLockTTL(),
+		Force:   *force,
+	})
+	if *jsonOut {
+		if jsonErr := printJSON(res); jsonErr != nil {
+			return jsonErr
+		}
+		return err
+	}
+	printRunLockInfo(res.Lock)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !res.Lock.Locked:
+		fmt.Println("nothing to unlock")
+	case res.Removed:
+		fmt.Printf("removed lock: %s\n", res.Lock.LockPath)
+	}
+	return nil
+}
+
+func printRunLockInfo(info archive.RunLockInfo) {
+	fmt.Printf("run_dir: %s\n", info.RunDir)
+	if !info.Locked {
+		fmt.Println("lock: none")
+		return
+	}
+	fmt.Printf("lock: %s\n", info.LockPath)
+	if info.PID > 0 {
+		fmt.Printf("owner_pid: %d\n", info.PID)
+		fmt.Printf("owner_host: %s\n", defaultIfEmpty(info.Hostname, "(unknown)"))
+		fmt.Printf("created_at: %s\n", info.CreatedAt)
+		fmt.Printf("heartbeat_at: %s\n", defaultIfEmpty(info.HeartbeatAt, "(none)"))
+		switch {
+		case !info.SameHost:
+			fmt.Println("owner_alive: unknown (different host)")
+		default:
+			fmt.Printf("owner_alive: %s\n", yesNo(info.OwnerAlive))
+		}
+	} else {
+		fmt.Println("owner: (no owner record)")
+	}
+	if info.Stale {
+		fmt.Printf("stale: yes (%s)\n", info.StaleReason)
+	} else {
+		fmt.Println("stale: no")
+	}
+}
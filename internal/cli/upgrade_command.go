@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"yt-vod-manager/internal/archive"
+	"yt-vod-manager/internal/discovery"
+)
+
+func runUpgrade(args []string) error {
+	fs := flag.NewFlagSet("upgrade", flag.ContinueOnError)
+	project := fs.String("project", "", "project name (uses latest run for that project)")
+	runID := fs.String("run-id", "", "run id from runs/<run_id>")
+	runDir := fs.String("run-dir", "", "explicit run directory path")
+	runsDir := fs.String("runs-dir", "runs", "runs directory")
+	latest := fs.Bool("latest", false, "use latest run when run-id/run-dir/project are not set")
+	config := fs.String("config", discovery.DefaultProjectsConfigPath, "project config path")
+	quality := fs.String("quality", "", "target quality preset (default: project quality)")
+	videoCodec := fs.String("video-codec", "", "video codec preference order: auto or list of av1,vp9,h264")
+	hdr := fs.String("hdr", "", "HDR preference: auto|on|off")
+	container := fs.String("container", "", "target container with remux: auto|mp4|mkv")
+	maxJobs := fs.Int("max-jobs", 0, "max videos to check this invocation (0 = no limit)")
+	dryRun := fs.Bool("dry-run", false, "only report videos that would be upgraded")
+	rawOutput := fs.Bool("raw-output", false, "print raw yt-dlp/ffmpeg output lines (verbose)")
+	cookies := fs.String("cookies", "", "path to cookies.txt")
+	useBrowserCookies := fs.Bool("browser-cookies", false, browserCookiesFlagHelp)
+	jsRuntime := fs.String("js-runtime", "", "JavaScript runtime override for yt-dlp extractor scripts: auto|deno|node|quickjs|bun, or ordered fallback list like node,quickjs")
+	jsonOut := fs.Bool("json", false, "print JSON output")
+	fs.SetOutput(flag.CommandLine.Output())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	configPath := strings.TrimSpace(*config)
+
+	targetRunDir := strings.TrimSpace(*runDir)
+	projectDefaults := discovery.Project{}
+	if strings.TrimSpace(*project) != "" {
+		resolved, proj, err := discovery.ResolveRunDirForProject(configPath, strings.TrimSpace(*project), strings.TrimSpace(*runsDir))
+		if err != nil {
+			return err
+		}
+		targetRunDir = resolved
+		projectDefaults = proj
+	}
+	if targetRunDir == "" && strings.TrimSpace(*runID) == "" && !*latest {
+		return errors.New("upgrade target required: set --project, --run-id, --run-dir, or --latest")
+	}
+	cookiesFromBrowser := strings.TrimSpace(projectDefaults.CookiesFromBrowser)
+	if *useBrowserCookies {
+		cookiesFromBrowser = discovery.DefaultBrowserCookieAgent
+	}
+	global, err := discovery.ReadGlobalSettings(configPath)
+	if err != nil {
+		return err
+	}
+	networkSettings, err := discovery.ResolveRuntimeNetworkSettings(projectDefaults, global, 1, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := archive.Upgrade(archive.UpgradeOptions{
+		RunID:              strings.TrimSpace(*runID),
+		RunDir:             targetRunDir,
+		RunsDir:            strings.TrimSpace(*runsDir),
+		Latest:             *latest,
+		OutputDir:          projectDefaults.OutputDir,
+		CookiesPath:        firstNonEmpty(strings.TrimSpace(*cookies), projectDefaults.CookiesPath),
+		CookiesFromBrowser: cookiesFromBrowser,
+		Fragments:          firstNonZero(projectDefaults.Fragments, discovery.DefaultFragments),
+		MaxJobs:            *maxJobs,
+		DownloadLimitMBps:  networkSettings.DownloadLimitMBps,
+		ProxyMode:          networkSettings.ProxyMode,
+		Proxies:            networkSettings.Proxies,
+		Quality:            firstNonEmpty(strings.TrimSpace(*quality), projectDefaults.Quality, discovery.DefaultQuality),
+		VideoCodec:         firstNonEmpty(strings.TrimSpace(*videoCodec), projectDefaults.VideoCodec),
+		HDR:                firstNonEmpty(strings.TrimSpace(*hdr), projectDefaults.HDR),
+		Container:          firstNonEmpty(strings.TrimSpace(*container), projectDefaults.Container),
+		JSRuntime:          firstNonEmpty(strings.TrimSpace(*jsRuntime), projectDefaults.JSRuntime, discovery.DefaultJSRuntime),
+		DeliveryMode:       firstNonEmpty(projectDefaults.DeliveryMode, "auto"),
+		AudioOnly:          boolValue(projectDefaults.AudioOnly),
+		RawOutput:          *rawOutput,
+		DryRun:             *dryRun,
+		LockTTL:            global.RunLockTTL(),
+	})
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(res)
+	}
+
+	fmt.Println("upgrade summary")
+	fmt.Printf("run_id: %s\n", res.RunID)
+	fmt.Printf("dry_run: %t\n", res.DryRun)
+	fmt.Printf("checked: %d\n", res.Checked)
+	fmt.Printf("candidates: %d\n", res.Candidates)
+	fmt.Printf("upgraded: %d\n", res.Upgraded)
+	fmt.Printf("not_better: %d\n", res.NotBetter)
+	fmt.Printf("failed: %d\n", res.Failed)
+	for _, item := range res.Items {
+		if item.Outcome == archive.UpgradeOutcomeUpToDate {
+			continue
+		}
+		line := fmt.Sprintf("  %s %s: %s -> %s", item.Outcome, item.VideoID, item.From, defaultIfEmpty(item.To, "?"))
+		if item.Error != "" {
+			line += " (" + item.Error + ")"
+		}
+		fmt.Println(line)
+	}
+	if res.DryRun && res.Candidates > 0 {
+		fmt.Println("next: rerun without --dry-run to download upgrades")
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/runstore"
+)
+
+const (
+	DaemonStateFileName = "daemon-state.json"
+	daemonLockDirName   = ".daemon"
+
+	DaemonResultSuccess     = "success"
+	DaemonResultFailure     = "failure"
+	DaemonResultSkipped     = "skipped"
+	DaemonResultInterrupted = "interrupted"
+)
+
+// DaemonState persists per-project schedule progress so restarts keep cadence.
+type DaemonState struct {
+	SchemaVersion int                           `json:"schema_version"`
+	UpdatedAt     string                        `json:"updated_at,omitempty"`
+	Projects      map[string]DaemonProjectState `json:"projects"`
+}
+
+type DaemonProjectState struct {
+	Schedule       string `json:"schedule"`
+	NextRunAt      string `json:"next_run_at"`
+	LastStartedAt  string `json:"last_started_at,omitempty"`
+	LastFinishedAt string `json:"last_finished_at,omitempty"`
+	LastResult     string `json:"last_result,omitempty"`
+	LastError      string `json:"last_error,omitempty"`
+}
+
+type DaemonJob struct {
+	Project  Project
+	Schedule Schedule
+	NextRun  time.Time
+}
+
+type DaemonLock struct {
+	lock runstore.RunLock
+}
+
+func DaemonStatePath(runsDir string) string {
+	return filepath.Join(defaultIfEmpty(runsDir, "runs"), DaemonStateFileName)
+}
+
+// LoadDaemonState returns an empty state when the daemon never ran.
+func LoadDaemonState(runsDir string) (DaemonState, error) {
+	st := DaemonState{SchemaVersion: 1, Projects: map[string]DaemonProjectState{}}
+	if err := runstore.ReadJSON(DaemonStatePath(runsDir), &st); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return st, nil
+		}
+		return DaemonState{}, err
+	}
+	if st.Projects == nil {
+		st.Projects = map[string]DaemonProjectState{}
+	}
+	return st, nil
+}
+
+func SaveDaemonState(runsDir string, st DaemonState) error {
+	st.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	return runstore.WriteJSON(DaemonStatePath(runsDir), st)
+}
+
+// AcquireDaemonLock keeps two daemons from scheduling the same workspace.
+func AcquireDaemonLock(runsDir string) (DaemonLock, error) {
+	dir := filepath.Join(defaultIfEmpty(runsDir, "runs"), daemonLockDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return DaemonLock{}, fmt.Errorf("create daemon dir %s: %w", dir, err)
+	}
+	lock, err := runstore.AcquireRunLock(dir, 0)
+	if err != nil {
+		if errors.Is(err, runstore.ErrRunLocked) {
+			return DaemonLock{}, fmt.Errorf("daemon already running for %s: %w", runsDir, err)
+		}
+		return DaemonLock{}, err
+	}
+	return DaemonLock{lock: lock}, nil
+}
+
+func (l DaemonLock) Release() error {
+	return l.lock.Release()
+}
+
+// PlanDaemon reconciles saved state with the active, scheduled projects and
+// returns them ordered by next run. Projects without their own schedule use
+// defaultSchedule; when that is empty they are not scheduled.
+func PlanDaemon(st *DaemonState, projects []Project, defaultSchedule string, now time.Time) ([]DaemonJob, []string) {
+	if st.Projects == nil {
+		st.Projects = map[string]DaemonProjectState{}
+	}
+	jobs := make([]DaemonJob, 0, len(projects))
+	warnings := make([]string, 0)
+	scheduled := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		if !isProjectActive(p) {
+			continue
+		}
+		raw := defaultIfEmpty(p.Schedule, strings.TrimSpace(defaultSchedule))
+		if raw == "" {
+			continue
+		}
+		sched, err := ParseSchedule(raw)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("project %s: %v", p.Name, err))
+			continue
+		}
+		entry := st.Projects[p.Name]
+		next, err := time.Parse(time.RFC3339, entry.NextRunAt)
+		if err != nil || entry.Schedule != sched.Raw {
+			next = firstRun(sched, entry, now)
+			entry.Schedule = sched.Raw
+			entry.NextRunAt = next.UTC().Format(time.RFC3339)
+			st.Projects[p.Name] = entry
+		}
+		scheduled[p.Name] = true
+		jobs = append(jobs, DaemonJob{Project: p, Schedule: sched, NextRun: next})
+	}
+	for name := range st.Projects {
+		if !scheduled[name] {
+			delete(st.Projects, name)
+		}
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].NextRun.Equal(jobs[j].NextRun) {
+			return jobs[i].Project.Name < jobs[j].Project.Name
+		}
+		return jobs[i].NextRun.Before(jobs[j].NextRun)
+	})
+	return jobs, warnings
+}
+
+// firstRun starts interval schedules right away (or one interval after the
+// last finished cycle); cron schedules wait for their next match.
+func firstRun(sched Schedule, entry DaemonProjectState, now time.Time) time.Time {
+	if !sched.IsInterval() {
+		return sched.Next(now)
+	}
+	if last, err := time.Parse(time.RFC3339, entry.LastFinishedAt); err == nil {
+		if next := sched.Next(last); next.After(now) {
+			return next
+		}
+	}
+	return now
+}
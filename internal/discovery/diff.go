@@ -0,0 +1,262 @@
+package discovery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/runstore"
+)
+
+type SourceSnapshot = runstore.Snapshot
+
+type DiffOptions struct {
+	ConfigPath string
+	Project    string
+	RunsDir    string
+	// Since picks the newest snapshot taken at or before it as the baseline
+	// (the oldest one when all are newer). Accepts YYYY-MM-DD or RFC3339.
+	Since string
+	// From and To name snapshots explicitly (file name or its timestamp).
+	// To defaults to the newest snapshot; From to the one before To.
+	From string
+	To   string
+}
+
+type DiffResult struct {
+	Project     string          `json:"project"`
+	RunDir      string          `json:"run_dir"`
+	From        DiffSnapshotRef `json:"from"`
+	To          DiffSnapshotRef `json:"to"`
+	Added       []DiffVideo     `json:"added"`
+	Removed     []DiffVideo     `json:"removed"`
+	Reordered   []DiffMove      `json:"reordered"`
+	Retitled    []DiffRetitle   `json:"retitled"`
+	MadePrivate []DiffVideo     `json:"made_private"`
+	MadePublic  []DiffVideo     `json:"made_public"`
+}
+
+type DiffSnapshotRef struct {
+	Name    string `json:"name"`
+	TakenAt string `json:"taken_at"`
+	Entries int    `json:"entries"`
+}
+
+type DiffVideo struct {
+	VideoID  string `json:"video_id"`
+	Title    string `json:"title,omitempty"`
+	Position int    `json:"position"`
+}
+
+type DiffMove struct {
+	VideoID string `json:"video_id"`
+	Title   string `json:"title,omitempty"`
+	From    int    `json:"from_position"`
+	To      int    `json:"to_position"`
+}
+
+type DiffRetitle struct {
+	VideoID  string `json:"video_id"`
+	OldTitle string `json:"old_title"`
+	NewTitle string `json:"new_title"`
+}
+
+// Empty reports whether the two snapshots list the same videos identically.
+func (r DiffResult) Empty() bool {
+	return len(r.Added)+len(r.Removed)+len(r.Reordered)+len(r.Retitled)+len(r.MadePrivate)+len(r.MadePublic) == 0
+}
+
+// ListProjectSnapshots returns the raw source snapshots of a project's latest
+// run, oldest first.
+func ListProjectSnapshots(configPath, project, runsDir string) (string, []SourceSnapshot, error) {
+	runDir, _, err := ResolveRunDirForProject(configPath, project, runsDir)
+	if err != nil {
+		return "", nil, err
+	}
+	snaps, err := runstore.ListSnapshots(runDir)
+	if err != nil {
+		return "", nil, err
+	}
+	return runDir, snaps, nil
+}
+
+func DiffSnapshots(opts DiffOptions) (DiffResult, error) {
+	runDir, snaps, err := ListProjectSnapshots(normalizeConfigPath(opts.ConfigPath), opts.Project, opts.RunsDir)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	if len(snaps) < 2 {
+		return DiffResult{}, fmt.Errorf("project %q has %d snapshot(s); diff needs at least 2 (each refresh/sync adds one)", opts.Project, len(snaps))
+	}
+
+	toIdx := len(snaps) - 1
+	if v := strings.TrimSpace(opts.To); v != "" {
+		if toIdx, err = findSnapshot(snaps, v); err != nil {
+			return DiffResult{}, err
+		}
+	}
+	fromIdx := toIdx - 1
+	switch {
+	case strings.TrimSpace(opts.From) != "":
+		if fromIdx, err = findSnapshot(snaps, strings.TrimSpace(opts.From)); err != nil {
+			return DiffResult{}, err
+		}
+	case strings.TrimSpace(opts.Since) != "":
+		since, err := parseDiffSince(opts.Since)
+		if err != nil {
+			return DiffResult{}, err
+		}
+		fromIdx = 0
+		for i, s := range snaps[:toIdx] {
+			if !s.TakenAt.After(since) {
+				fromIdx = i
+			}
+		}
+	}
+	if fromIdx < 0 {
+		return DiffResult{}, fmt.Errorf("no snapshot older than %s", snaps[toIdx].Name)
+	}
+
+	from, err := loadSnapshotEntries(snaps[fromIdx])
+	if err != nil {
+		return DiffResult{}, err
+	}
+	to, err := loadSnapshotEntries(snaps[toIdx])
+	if err != nil {
+		return DiffResult{}, err
+	}
+
+	res := diffEntries(from, to)
+	res.Project = strings.TrimSpace(opts.Project)
+	res.RunDir = runDir
+	res.From = DiffSnapshotRef{Name: snaps[fromIdx].Name, TakenAt: snaps[fromIdx].TakenAt.Format(time.RFC3339), Entries: len(from)}
+	res.To = DiffSnapshotRef{Name: snaps[toIdx].Name, TakenAt: snaps[toIdx].TakenAt.Format(time.RFC3339), Entries: len(to)}
+	return res, nil
+}
+
+func findSnapshot(snaps []SourceSnapshot, ref string) (int, error) {
+	for i, s := range snaps {
+		if s.Name == ref || strings.Contains(s.Name, "_"+ref) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("snapshot %q not found (see diff --list)", ref)
+}
+
+func parseDiffSince(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q (want YYYY-MM-DD or RFC3339)", raw)
+	}
+	return t, nil
+}
+
+func loadSnapshotEntries(s SourceSnapshot) ([]sourceEntry, error) {
+	raw, err := runstore.ReadSnapshot(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	src, err := parseSourceManifest(raw, "")
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", s.Name, err)
+	}
+	return src.Entries, nil
+}
+
+func diffEntries(from, to []sourceEntry) DiffResult {
+	res := DiffResult{
+		Added:       []DiffVideo{},
+		Removed:     []DiffVideo{},
+		Reordered:   []DiffMove{},
+		Retitled:    []DiffRetitle{},
+		MadePrivate: []DiffVideo{},
+		MadePublic:  []DiffVideo{},
+	}
+	fromPos := make(map[string]int, len(from))
+	for i, e := range from {
+		fromPos[fallbackVideoID(e.ID, i+1)] = i
+	}
+	toPos := make(map[string]int, len(to))
+	for i, e := range to {
+		toPos[fallbackVideoID(e.ID, i+1)] = i
+	}
+
+	for i, e := range from {
+		id := fallbackVideoID(e.ID, i+1)
+		if _, ok := toPos[id]; !ok {
+			res.Removed = append(res.Removed, DiffVideo{VideoID: id, Title: e.Title, Position: i + 1})
+		}
+	}
+
+	// Videos kept in both snapshots, in their new order.
+	common := make([]string, 0, len(to))
+	for i, e := range to {
+		id := fallbackVideoID(e.ID, i+1)
+		j, ok := fromPos[id]
+		if !ok {
+			res.Added = append(res.Added, DiffVideo{VideoID: id, Title: e.Title, Position: i + 1})
+			continue
+		}
+		common = append(common, id)
+		old := from[j]
+		switch {
+		case !old.Private && e.Private:
+			res.MadePrivate = append(res.MadePrivate, DiffVideo{VideoID: id, Title: old.Title, Position: i + 1})
+		case old.Private && !e.Private:
+			res.MadePublic = append(res.MadePublic, DiffVideo{VideoID: id, Title: e.Title, Position: i + 1})
+		case !old.Private && old.Title != "" && e.Title != "" && old.Title != e.Title:
+			res.Retitled = append(res.Retitled, DiffRetitle{VideoID: id, OldTitle: old.Title, NewTitle: e.Title})
+		}
+	}
+
+	// Additions and removals shift positions without reordering anything, so
+	// only videos outside the longest run kept in the old relative order count
+	// as moved.
+	keep := longestOrderedRun(common, fromPos)
+	for _, id := range common {
+		if keep[id] {
+			continue
+		}
+		res.Reordered = append(res.Reordered, DiffMove{
+			VideoID: id,
+			Title:   to[toPos[id]].Title,
+			From:    fromPos[id] + 1,
+			To:      toPos[id] + 1,
+		})
+	}
+	return res
+}
+
+// longestOrderedRun returns the largest set of ids (in their new order) whose
+// old positions are still increasing.
+func longestOrderedRun(ids []string, oldPos map[string]int) map[string]bool {
+	tails := make([]int, 0, len(ids))
+	prev := make([]int, len(ids))
+	for i, id := range ids {
+		p := oldPos[id]
+		k := sort.Search(len(tails), func(n int) bool { return oldPos[ids[tails[n]]] >= p })
+		if k > 0 {
+			prev[i] = tails[k-1]
+		} else {
+			prev[i] = -1
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	keep := make(map[string]bool, len(tails))
+	if len(tails) == 0 {
+		return keep
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		keep[ids[i]] = true
+	}
+	return keep
+}
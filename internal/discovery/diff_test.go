@@ -0,0 +1,86 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"yt-vod-manager/internal/runstore"
+)
+
+func TestDiffEntriesClassifiesChanges(t *testing.T) {
+	from := []sourceEntry{
+		{ID: "a", Title: "A"},
+		{ID: "b", Title: "B"},
+		{ID: "c", Title: "C"},
+		{ID: "d", Title: "D"},
+		{ID: "e", Title: "E"},
+	}
+	to := []sourceEntry{
+		{ID: "n", Title: "New"},
+		{ID: "a", Title: "A"},
+		{ID: "d", Title: "D (remastered)"},
+		{ID: "c", Title: "C"},
+		{ID: "e", Title: "[Private video]", Private: true},
+	}
+
+	res := diffEntries(from, to)
+	if len(res.Added) != 1 || res.Added[0].VideoID != "n" || res.Added[0].Position != 1 {
+		t.Fatalf("unexpected added: %+v", res.Added)
+	}
+	if len(res.Removed) != 1 || res.Removed[0].VideoID != "b" {
+		t.Fatalf("unexpected removed: %+v", res.Removed)
+	}
+	// Only one of c/d swapped; the insertion of n must not count as a move.
+	if len(res.Reordered) != 1 {
+		t.Fatalf("expected a single move, got %+v", res.Reordered)
+	}
+	if len(res.Retitled) != 1 || res.Retitled[0].OldTitle != "D" || res.Retitled[0].NewTitle != "D (remastered)" {
+		t.Fatalf("unexpected retitled: %+v", res.Retitled)
+	}
+	if len(res.MadePrivate) != 1 || res.MadePrivate[0].VideoID != "e" || res.MadePrivate[0].Title != "E" {
+		t.Fatalf("unexpected made private: %+v", res.MadePrivate)
+	}
+	if res.Empty() {
+		t.Fatal("expected non-empty diff")
+	}
+	if !diffEntries(from, from).Empty() {
+		t.Fatal("expected identical listings to produce an empty diff")
+	}
+}
+
+func TestSaveSourceSnapshotSeedsFromExistingRawManifest(t *testing.T) {
+	runDir := t.TempDir()
+	rawPath := filepath.Join(runDir, "manifest.raw.json")
+	if err := os.WriteFile(rawPath, []byte(`{"id":"src","entries":[{"id":"a","title":"A"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(rawPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	next := []byte(`{"id":"src","entries":[{"id":"a","title":"A"},{"id":"b","title":"B"}]}`)
+	if err := saveSourceSnapshot(runDir, rawPath, time.Now().UTC(), next, 0); err != nil {
+		t.Fatal(err)
+	}
+	snaps, err := runstore.ListSnapshots(runDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 2 {
+		t.Fatalf("expected seeded + new snapshot, got %d", len(snaps))
+	}
+	from, err := loadSnapshotEntries(snaps[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := loadSnapshotEntries(snaps[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := diffEntries(from, to); len(res.Added) != 1 || res.Added[0].VideoID != "b" {
+		t.Fatalf("expected b added between snapshots, got %+v", res)
+	}
+}
@@ -0,0 +1,123 @@
+package discovery
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+type ExportOptions struct {
+	ConfigPath string
+	RunsDir    string
+	Project    string
+	All        bool
+	Status     string
+	Reason     string
+}
+
+type ExportReport struct {
+	GeneratedAt string              `json:"generated_at"`
+	Rows        int                 `json:"rows"`
+	Totals      []ExportStatusCount `json:"totals"`
+	Projects    []ExportProject     `json:"projects"`
+}
+
+type ExportProject struct {
+	Project   string              `json:"project"`
+	SourceURL string              `json:"source_url"`
+	RunID     string              `json:"run_id,omitempty"`
+	Totals    []ExportStatusCount `json:"totals"`
+	Jobs      []model.Job         `json:"jobs"`
+}
+
+type ExportStatusCount struct {
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+}
+
+// Export collects the jobs of each selected project's latest run. Totals always
+// cover every job so filtered reports still show what is archived and missing.
+func Export(opts ExportOptions) (ExportReport, error) {
+	configPath := normalizeConfigPath(opts.ConfigPath)
+	runsDir := defaultIfEmpty(opts.RunsDir, "runs")
+	statuses, err := parseStatusFilter(opts.Status)
+	if err != nil {
+		return ExportReport{}, err
+	}
+	reasons := map[string]bool{}
+	for _, r := range strings.Split(opts.Reason, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			reasons[r] = true
+		}
+	}
+
+	projects, err := ResolveProjectSelection(configPath, opts.Project, opts.All)
+	if err != nil {
+		return ExportReport{}, err
+	}
+	report := ExportReport{
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Projects:    make([]ExportProject, 0, len(projects)),
+	}
+	overall := map[string]int{}
+	for _, p := range projects {
+		item := ExportProject{Project: p.Name, SourceURL: p.SourceURL, Jobs: []model.Job{}}
+		runDir, err := latestProjectRunDir(runsDir, p)
+		if err != nil {
+			return ExportReport{}, err
+		}
+		counts := map[string]int{}
+		if runDir != "" {
+			var mf model.JobsManifest
+			if err := runstore.ReadJobsManifest(filepath.Join(runDir, "manifest.jobs.json"), &mf); err != nil {
+				return ExportReport{}, fmt.Errorf("project %s: %w", p.Name, err)
+			}
+			item.RunID = defaultIfEmpty(mf.RunID, filepath.Base(runDir))
+			for _, j := range mf.Jobs {
+				counts[j.Status]++
+				overall[j.Status]++
+				if len(statuses) > 0 && !statuses[j.Status] {
+					continue
+				}
+				if len(reasons) > 0 && !reasons[j.Reason] {
+					continue
+				}
+				item.Jobs = append(item.Jobs, j)
+			}
+		}
+		item.Totals = statusCounts(counts)
+		report.Rows += len(item.Jobs)
+		report.Projects = append(report.Projects, item)
+	}
+	report.Totals = statusCounts(overall)
+	return report, nil
+}
+
+func statusCounts(counts map[string]int) []ExportStatusCount {
+	out := make([]ExportStatusCount, 0, len(counts))
+	for _, s := range model.KnownStatuses() {
+		if counts[s] > 0 {
+			out = append(out, ExportStatusCount{Status: s, Count: counts[s]})
+		}
+	}
+	return out
+}
+
+func parseStatusFilter(raw string) (map[string]bool, error) {
+	statuses := map[string]bool{}
+	for _, s := range strings.Split(raw, ",") {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "" {
+			continue
+		}
+		if !model.IsKnownStatus(s) {
+			return nil, fmt.Errorf("unknown status %q", s)
+		}
+		statuses[s] = true
+	}
+	return statuses, nil
+}
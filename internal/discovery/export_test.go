@@ -0,0 +1,40 @@
+package discovery
+
+import (
+	"testing"
+
+	"yt-vod-manager/internal/model"
+)
+
+func TestExportFiltersJobsButKeepsFullTotals(t *testing.T) {
+	cfg, runsDir, _ := setupSearchWorkspace(t)
+	if _, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: "empty", SourceURL: "https://example.com/empty"}); err != nil {
+		t.Fatalf("add project failed: %v", err)
+	}
+
+	report, err := Export(ExportOptions{ConfigPath: cfg, RunsDir: runsDir, All: true, Status: "pending"})
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if len(report.Projects) != 2 || report.Rows != 1 {
+		t.Fatalf("unexpected export shape: %+v", report)
+	}
+	talks := report.Projects[1]
+	if talks.Project != "talks" || len(talks.Jobs) != 1 || talks.Jobs[0].VideoID != "talk000002" {
+		t.Fatalf("unexpected filtered jobs: %+v", talks)
+	}
+	want := []ExportStatusCount{{Status: model.StatusCompleted, Count: 1}, {Status: model.StatusPending, Count: 1}}
+	if len(talks.Totals) != len(want) || talks.Totals[0] != want[0] || talks.Totals[1] != want[1] {
+		t.Fatalf("unexpected totals: %+v", talks.Totals)
+	}
+	if len(report.Projects[0].Totals) != 0 || report.Projects[0].RunID != "" {
+		t.Fatalf("expected never-synced project without totals: %+v", report.Projects[0])
+	}
+}
+
+func TestExportRejectsUnknownStatus(t *testing.T) {
+	cfg, runsDir, _ := setupSearchWorkspace(t)
+	if _, err := Export(ExportOptions{ConfigPath: cfg, RunsDir: runsDir, Project: "talks", Status: "done"}); err == nil {
+		t.Fatal("expected unknown status to be rejected")
+	}
+}
@@ -6,11 +6,18 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"yt-vod-manager/internal/model"
 )
 
 const (
 	ProxyModeOff       = "off"
 	ProxyModePerWorker = "per_worker"
+
+	DedupModeOff      = "off"
+	DedupModeHardlink = "hardlink"
+	DedupModeReflink  = "reflink"
+	DedupModeSymlink  = "symlink"
 )
 
 type GlobalSettings struct {
@@ -18,6 +25,23 @@ type GlobalSettings struct {
 	DownloadLimitMBps float64  `json:"download_limit_mb_s,omitempty"`
 	ProxyMode         string   `json:"proxy_mode,omitempty"`
 	Proxies           []string `json:"proxies,omitempty"`
+	DedupMode         string   `json:"dedup_mode,omitempty"`
+	MetricsTextfile   string   `json:"metrics_textfile,omitempty"`
+	LockTTLMinutes    int      `json:"lock_ttl_minutes,omitempty"`
+	SnapshotRetention int      `json:"snapshot_retention,omitempty"`
+	// Mirrors are root directories; each project is copied to <root>/<name>.
+	Mirrors []string `json:"mirrors,omitempty"`
+	// FailureRules are checked before the built-in failure classifier rules.
+	FailureRules []model.FailureRule `json:"failure_rules,omitempty"`
+
+	// Project defaults: used by every project that leaves the setting unset.
+	// OutputRoot and Storage are shared roots; each project gets
+	// <root>/<name> or a <prefix>/<name> key prefix.
+	OutputRoot         string         `json:"output_root,omitempty"`
+	CookiesPath        string         `json:"cookies_path,omitempty"`
+	CookiesFromBrowser string         `json:"cookies_from_browser,omitempty"`
+	Storage            *StorageTarget `json:"storage,omitempty"`
+	DownloadSettings
 }
 
 type RuntimeNetworkSettings struct {
@@ -43,6 +67,9 @@ func defaultGlobalSettings() GlobalSettings {
 		DownloadLimitMBps: DefaultDownloadLimitMBps,
 		ProxyMode:         DefaultProxyMode,
 		Proxies:           []string{},
+		DedupMode:         DefaultDedupMode,
+		LockTTLMinutes:    DefaultLockTTLMinutes,
+		SnapshotRetention: DefaultSnapshotRetention,
 	}
 }
 
@@ -56,9 +83,43 @@ func normalizeGlobalSettings(raw GlobalSettings) GlobalSettings {
 	}
 	norm.ProxyMode = normalizeProxyMode(norm.ProxyMode)
 	norm.Proxies = normalizeProxyList(norm.Proxies)
+	norm.DedupMode = normalizeDedupMode(norm.DedupMode)
+	norm.MetricsTextfile = strings.TrimSpace(norm.MetricsTextfile)
+	if norm.LockTTLMinutes <= 0 {
+		norm.LockTTLMinutes = DefaultLockTTLMinutes
+	}
+	if norm.SnapshotRetention <= 0 {
+		norm.SnapshotRetention = DefaultSnapshotRetention
+	}
+	norm.Mirrors = normalizeMirrorList(norm.Mirrors)
+
+	norm.OutputRoot = strings.TrimSpace(norm.OutputRoot)
+	norm.CookiesPath = strings.TrimSpace(norm.CookiesPath)
+	norm.CookiesFromBrowser = strings.TrimSpace(norm.CookiesFromBrowser)
+	norm.Storage = normalizeStorageTarget(norm.Storage)
+	norm.DownloadSettings = normalizeDownloadSettings(norm.DownloadSettings)
 	return norm
 }
 
+// validateProjectDefaults applies the AddProject checks to the project
+// defaults of the global settings.
+func validateProjectDefaults(g GlobalSettings) error {
+	if err := validateDownloadSettings(g.DownloadSettings); err != nil {
+		return err
+	}
+	if storage := normalizeStorageTarget(g.Storage); storage != nil {
+		if err := storage.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunLockTTL is how long a run lock stays valid without a heartbeat.
+func (g GlobalSettings) RunLockTTL() time.Duration {
+	return time.Duration(normalizeGlobalSettings(g).LockTTLMinutes) * time.Minute
+}
+
 func normalizeProxyMode(raw string) string {
 	switch strings.ToLower(strings.TrimSpace(raw)) {
 	case "", ProxyModeOff:
@@ -70,6 +131,15 @@ func normalizeProxyMode(raw string) string {
 	}
 }
 
+func normalizeDedupMode(raw string) string {
+	switch v := strings.ToLower(strings.TrimSpace(raw)); v {
+	case DedupModeOff, DedupModeHardlink, DedupModeReflink, DedupModeSymlink:
+		return v
+	default:
+		return DefaultDedupMode
+	}
+}
+
 func normalizeProxyList(raw []string) []string {
 	out := make([]string, 0, len(raw))
 	seen := make(map[string]bool, len(raw))
@@ -113,6 +183,14 @@ func UpdateGlobalSettings(opts UpdateGlobalSettingsOptions) (UpdateGlobalSetting
 	if err != nil {
 		return UpdateGlobalSettingsResult{}, err
 	}
+	if err := validateProjectDefaults(opts.Global); err != nil {
+		return UpdateGlobalSettingsResult{}, err
+	}
+	for i, r := range opts.Global.FailureRules {
+		if err := r.Validate(); err != nil {
+			return UpdateGlobalSettingsResult{}, fmt.Errorf("failure_rules[%d]: %w", i, err)
+		}
+	}
 	reg.Global = normalizeGlobalSettings(opts.Global)
 	reg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
 	if err := saveProjectRegistry(configPath, reg); err != nil {
@@ -1,6 +1,10 @@
 package discovery
 
-import "testing"
+import (
+	"testing"
+
+	"yt-vod-manager/internal/model"
+)
 
 func TestReadGlobalSettingsDefaultsWhenConfigMissing(t *testing.T) {
 	tmp := t.TempDir()
@@ -65,3 +69,24 @@ func TestResolveRuntimeNetworkSettingsRequiresEnoughProxies(t *testing.T) {
 		t.Fatal("expected error when workers exceed proxy count")
 	}
 }
+
+func TestUpdateGlobalSettingsKeepsAndValidatesFailureRules(t *testing.T) {
+	cfg := t.TempDir() + "/projects.json"
+	rule := model.FailureRule{Pattern: "HTTP Error 403", Reason: "forbidden", Retryable: true}
+
+	if _, err := UpdateGlobalSettings(UpdateGlobalSettingsOptions{ConfigPath: cfg, Global: GlobalSettings{FailureRules: []model.FailureRule{rule}}}); err != nil {
+		t.Fatalf("update global settings: %v", err)
+	}
+	global, err := ReadGlobalSettings(cfg)
+	if err != nil {
+		t.Fatalf("read global settings: %v", err)
+	}
+	if len(global.FailureRules) != 1 || global.FailureRules[0] != rule {
+		t.Fatalf("expected failure rule to round-trip, got %+v", global.FailureRules)
+	}
+
+	bad := GlobalSettings{FailureRules: []model.FailureRule{{Pattern: "(", Regex: true, Reason: "x"}}}
+	if _, err := UpdateGlobalSettings(UpdateGlobalSettingsOptions{ConfigPath: cfg, Global: bad}); err == nil {
+		t.Fatal("expected invalid regex rule to be rejected")
+	}
+}
@@ -0,0 +1,104 @@
+package discovery
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+type MigrationPlan = runstore.MigrationPlan
+
+type MigrateOptions struct {
+	ConfigPath string
+	RunsDir    string
+	DryRun     bool
+	LockTTL    time.Duration
+}
+
+type MigrateResult struct {
+	DryRun   bool            `json:"dry_run"`
+	Checked  int             `json:"checked"`
+	Pending  int             `json:"pending"`
+	Migrated int             `json:"migrated"`
+	Failed   int             `json:"failed"`
+	Files    []MigrationPlan `json:"files"`
+}
+
+// Migrate brings projects.json and every run's manifest.jobs.json and run.json
+// to the schema versions of this binary, backing up each file it rewrites.
+// With DryRun it only reports what would change.
+func Migrate(opts MigrateOptions) (MigrateResult, error) {
+	runsDir := strings.TrimSpace(opts.RunsDir)
+	if runsDir == "" {
+		runsDir = "runs"
+	}
+	res := MigrateResult{DryRun: opts.DryRun, Files: []MigrationPlan{}}
+	if !opts.DryRun {
+		lock, err := AcquireSyncLock(runsDir, SyncLockOptions{TTL: opts.LockTTL})
+		if err != nil {
+			return res, err
+		}
+		defer func() {
+			_ = lock.Release()
+		}()
+	}
+
+	var reg ProjectRegistry
+	res.check(normalizeConfigPath(opts.ConfigPath), runstore.SchemaProjects, &reg, opts.DryRun)
+
+	runDirs, err := runstore.ListRunDirs(runsDir)
+	if err != nil {
+		return res, err
+	}
+	for _, runDir := range runDirs {
+		if !opts.DryRun {
+			lock, err := runstore.AcquireRunLock(runDir, opts.LockTTL)
+			if err != nil {
+				res.Failed++
+				res.Files = append(res.Files, MigrationPlan{Path: runDir, Error: err.Error()})
+				continue
+			}
+			var mf model.JobsManifest
+			var meta runstore.RunMeta
+			res.check(runstore.JobsManifestPath(runDir), runstore.SchemaJobsManifest, &mf, false)
+			res.check(runstore.RunMetaPath(runDir), runstore.SchemaRunMeta, &meta, false)
+			_ = lock.Release()
+			continue
+		}
+		res.check(runstore.JobsManifestPath(runDir), runstore.SchemaJobsManifest, nil, true)
+		res.check(runstore.RunMetaPath(runDir), runstore.SchemaRunMeta, nil, true)
+	}
+	return res, nil
+}
+
+func (res *MigrateResult) check(path, schema string, v any, dryRun bool) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	res.Checked++
+	var plan MigrationPlan
+	var err error
+	if dryRun {
+		plan, err = runstore.PlanMigration(path, schema)
+	} else {
+		plan, err = runstore.UpgradeFile(path, schema, v)
+	}
+	switch {
+	case err != nil:
+		plan.Path = path
+		plan.Schema = schema
+		plan.Error = err.Error()
+		res.Failed++
+	case plan.From == plan.To:
+		return
+	case dryRun:
+		res.Pending++
+	default:
+		res.Migrated++
+	}
+	res.Files = append(res.Files, plan)
+}
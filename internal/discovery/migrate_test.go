@@ -0,0 +1,59 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"yt-vod-manager/internal/runstore"
+)
+
+func TestMigrateDryRunReportsThenUpgradesRuns(t *testing.T) {
+	tmp := t.TempDir()
+	runsDir := filepath.Join(tmp, "runs")
+	runDir := filepath.Join(runsDir, "20240101T000000Z_src")
+	if err := os.MkdirAll(runDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(runstore.JobsManifestPath(runDir), []byte(`{"schema_version":1,"run_id":"r","playlist_id":"PL1","jobs":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(runstore.RunMetaPath(runDir), []byte(`{"run_id":"r","source_url":"https://example.com"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(tmp, "config", "projects.json")
+
+	plan, err := Migrate(MigrateOptions{ConfigPath: configPath, RunsDir: runsDir, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Checked != 2 || plan.Pending != 2 || plan.Migrated != 0 {
+		t.Fatalf("unexpected dry run result: %+v", plan)
+	}
+	if _, err := os.Stat(runstore.JobsManifestPath(runDir) + ".v1.bak"); !os.IsNotExist(err) {
+		t.Fatal("dry run must not write backups")
+	}
+
+	res, err := Migrate(MigrateOptions{ConfigPath: configPath, RunsDir: runsDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Migrated != 2 || res.Failed != 0 {
+		t.Fatalf("unexpected migrate result: %+v", res)
+	}
+	meta, err := runstore.LoadRunMeta(runDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.SchemaVersion != runstore.RunMetaSchemaVersion || meta.SourceURL != "https://example.com" {
+		t.Fatalf("unexpected migrated run meta: %+v", meta)
+	}
+
+	again, err := Migrate(MigrateOptions{ConfigPath: configPath, RunsDir: runsDir, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Pending != 0 {
+		t.Fatalf("expected nothing pending after migrate, got %+v", again)
+	}
+}
@@ -0,0 +1,53 @@
+package discovery
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// normalizeMirrorList cleans and dedupes mirror directories.
+func normalizeMirrorList(raw []string) []string {
+	out := make([]string, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for _, m := range raw {
+		v := strings.TrimSpace(m)
+		if v == "" {
+			continue
+		}
+		v = filepath.Clean(v)
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// ResolveMirrors returns the directories a project's media is copied to. A
+// project's own mirrors are used as given; otherwise each global mirror gets
+// a subdirectory named after the project (or fallbackName for ad hoc runs).
+func ResolveMirrors(project Project, global GlobalSettings, fallbackName string) []string {
+	if mirrors := normalizeMirrorList(project.Mirrors); len(mirrors) > 0 {
+		return mirrors
+	}
+	name := strings.TrimSpace(project.Name)
+	if name == "" {
+		name = strings.TrimSpace(fallbackName)
+	}
+	if name == "" {
+		return nil
+	}
+	roots := normalizeMirrorList(global.Mirrors)
+	out := make([]string, 0, len(roots))
+	for _, root := range roots {
+		out = append(out, filepath.Join(root, name))
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package discovery
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+func TestResolveMirrorsPrefersProjectOverGlobalRoots(t *testing.T) {
+	global := GlobalSettings{Mirrors: []string{"/mnt/nas/", " /mnt/usb", "/mnt/nas"}}
+
+	if got := ResolveMirrors(Project{Name: "talks"}, global, ""); !reflect.DeepEqual(got, []string{"/mnt/nas/talks", "/mnt/usb/talks"}) {
+		t.Fatalf("unexpected global mirrors: %v", got)
+	}
+	if got := ResolveMirrors(Project{Name: "talks", Mirrors: []string{"/backup/talks"}}, global, ""); !reflect.DeepEqual(got, []string{"/backup/talks"}) {
+		t.Fatalf("expected project mirrors to win, got %v", got)
+	}
+	if got := ResolveMirrors(Project{}, global, "20240101T000000Z"); !reflect.DeepEqual(got, []string{"/mnt/nas/20240101T000000Z", "/mnt/usb/20240101T000000Z"}) {
+		t.Fatalf("expected ad hoc runs to use the fallback name, got %v", got)
+	}
+	if got := ResolveMirrors(Project{}, global, ""); got != nil {
+		t.Fatalf("expected no mirrors without a name, got %v", got)
+	}
+}
+
+func TestProjectStatusRowReportsMirrorLag(t *testing.T) {
+	runsDir := t.TempDir()
+	source := "https://www.youtube.com/playlist?list=PLmirror"
+	mirrors := []string{"/mnt/nas/talks", "/mnt/usb/talks"}
+	copied := func(m string) model.Replica { return model.Replica{Mirror: m, Status: model.ReplicaCopied} }
+	mf := model.JobsManifest{
+		SourceURL: source,
+		Completed: 4,
+		Total:     5,
+		Jobs: []model.Job{
+			{VideoID: "a", Status: model.StatusCompleted, CompletedAt: "2024-01-03T00:00:00Z", Replicas: []model.Replica{copied(mirrors[0]), copied(mirrors[1])}},
+			{VideoID: "b", Status: model.StatusCompleted, CompletedAt: "2024-01-02T00:00:00Z", Replicas: []model.Replica{copied(mirrors[0])}},
+			{VideoID: "c", Status: model.StatusCompleted, CompletedAt: "2024-01-04T00:00:00Z", Replicas: []model.Replica{{Mirror: mirrors[1], Status: model.ReplicaFailed}}},
+			{VideoID: "d", Status: model.StatusCompleted, CompletedAt: "2024-01-01T00:00:00Z", RemovedUpstream: true},
+			{VideoID: "e", Status: model.StatusPending},
+		},
+	}
+	if err := runstore.WriteJSON(filepath.Join(runsDir, "run1", "manifest.jobs.json"), mf); err != nil {
+		t.Fatal(err)
+	}
+
+	row, err := buildProjectStatusRow(runsDir, Project{Name: "talks", SourceURL: source}, mirrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.MirrorLag != 2 || row.MirrorFailed != 1 || row.MirrorLagSince != "2024-01-02T00:00:00Z" {
+		t.Fatalf("unexpected mirror lag: lag=%d failed=%d since=%q", row.MirrorLag, row.MirrorFailed, row.MirrorLagSince)
+	}
+}
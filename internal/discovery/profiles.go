@@ -0,0 +1,241 @@
+package discovery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/ytdlp"
+)
+
+// DownloadSettings are the settings a profile bundles and the global
+// settings default. Empty values (nil for booleans) are unset.
+type DownloadSettings struct {
+	Fragments      int    `json:"fragments,omitempty"`
+	Order          string `json:"order,omitempty"`
+	Quality        string `json:"quality,omitempty"`
+	VideoCodec     string `json:"video_codec,omitempty"`
+	HDR            string `json:"hdr,omitempty"`
+	Container      string `json:"container,omitempty"`
+	JSRuntime      string `json:"js_runtime,omitempty"`
+	DeliveryMode   string `json:"delivery_mode,omitempty"`
+	NoSubs         *bool  `json:"no_subs,omitempty"`
+	SubLangs       string `json:"sub_langs,omitempty"`
+	LiveChat       *bool  `json:"live_chat,omitempty"`
+	LiveChatFormat string `json:"live_chat_format,omitempty"`
+	Schedule       string `json:"schedule,omitempty"`
+	// WriteThumbnails saves each video's thumbnail next to the media.
+	WriteThumbnails *bool `json:"write_thumbnails,omitempty"`
+	// AudioOnly downloads the best audio stream and extracts it instead of
+	// the video.
+	AudioOnly *bool `json:"audio_only,omitempty"`
+}
+
+// ProjectProfile is a named set of download settings. A project that names
+// it in "profile" uses its values for settings the project leaves unset,
+// before falling back to the global settings.
+type ProjectProfile struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	DownloadSettings
+}
+
+type SaveProfileOptions struct {
+	ConfigPath string
+	Profile    ProjectProfile
+	// Replace allows overwriting an existing profile of the same name.
+	Replace bool
+}
+
+type SaveProfileResult struct {
+	ConfigPath string         `json:"config_path"`
+	Profile    ProjectProfile `json:"profile"`
+	Created    bool           `json:"created"`
+}
+
+func normalizeDownloadSettings(raw DownloadSettings) DownloadSettings {
+	norm := raw
+	if norm.Fragments < 0 {
+		norm.Fragments = 0
+	}
+	norm.Order = strings.TrimSpace(norm.Order)
+	norm.Quality = canonicalQuality(norm.Quality)
+	norm.VideoCodec = canonicalFormatValue(norm.VideoCodec)
+	norm.HDR = canonicalFormatValue(norm.HDR)
+	norm.Container = canonicalFormatValue(norm.Container)
+	if v, ok := parseOptionalJSRuntime(norm.JSRuntime); ok {
+		norm.JSRuntime = v
+	} else {
+		norm.JSRuntime = ""
+	}
+	norm.DeliveryMode = strings.TrimSpace(norm.DeliveryMode)
+	norm.NoSubs = copyBoolPtr(norm.NoSubs)
+	norm.SubLangs = strings.TrimSpace(norm.SubLangs)
+	norm.LiveChat = copyBoolPtr(norm.LiveChat)
+	if v, ok := parseLiveChatFormat(norm.LiveChatFormat); ok {
+		norm.LiveChatFormat = v
+	} else {
+		norm.LiveChatFormat = ""
+	}
+	norm.Schedule = strings.TrimSpace(norm.Schedule)
+	norm.WriteThumbnails = copyBoolPtr(norm.WriteThumbnails)
+	norm.AudioOnly = copyBoolPtr(norm.AudioOnly)
+	return norm
+}
+
+// validateDownloadSettings applies the AddProject checks.
+func validateDownloadSettings(s DownloadSettings) error {
+	if s.Fragments < 0 {
+		return fmt.Errorf("fragments must be >= 0")
+	}
+	if _, ok := parseOptionalJSRuntime(s.JSRuntime); !ok {
+		return fmt.Errorf("js runtime must be auto or a comma-separated list of: deno, node, quickjs, bun")
+	}
+	if err := ytdlp.CheckFormatOptions(s.Quality, s.VideoCodec, s.HDR, s.Container); err != nil {
+		return err
+	}
+	if _, ok := parseLiveChatFormat(s.LiveChatFormat); !ok {
+		return fmt.Errorf("live chat format must be one of: txt, ass")
+	}
+	if _, err := normalizeSchedule(s.Schedule); err != nil {
+		return err
+	}
+	return nil
+}
+
+func normalizeProfiles(raw []ProjectProfile) []ProjectProfile {
+	out := make([]ProjectProfile, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for _, p := range raw {
+		p.Name = canonicalProjectName(p.Name)
+		if p.Name == "" || p.Name == DefaultProfileName || seen[p.Name] {
+			continue
+		}
+		seen[p.Name] = true
+		p.Description = strings.TrimSpace(p.Description)
+		p.DownloadSettings = normalizeDownloadSettings(p.DownloadSettings)
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// findProfile returns the named profile; the built-in default profile has
+// no settings of its own.
+func findProfile(profiles []ProjectProfile, name string) (ProjectProfile, bool) {
+	target := canonicalProjectName(name)
+	for _, p := range profiles {
+		if p.Name == target {
+			return p, true
+		}
+	}
+	return ProjectProfile{}, false
+}
+
+// checkProfileExists rejects project profiles that are not defined.
+func checkProfileExists(profiles []ProjectProfile, name string) error {
+	target := canonicalProjectName(name)
+	if target == "" || target == DefaultProfileName {
+		return nil
+	}
+	if _, ok := findProfile(profiles, target); !ok {
+		return fmt.Errorf("profile %q not found (see `profile list`)", target)
+	}
+	return nil
+}
+
+func ListProfiles(configPath string) ([]ProjectProfile, error) {
+	reg, _, err := EnsureProjectRegistry(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return reg.Profiles, nil
+}
+
+func FindProfileByName(configPath, name string) (ProjectProfile, error) {
+	reg, _, err := EnsureProjectRegistry(configPath)
+	if err != nil {
+		return ProjectProfile{}, err
+	}
+	p, ok := findProfile(reg.Profiles, name)
+	if !ok {
+		return ProjectProfile{}, fmt.Errorf("profile %q not found", canonicalProjectName(name))
+	}
+	return p, nil
+}
+
+// SaveProfile creates a profile, or replaces it when opts.Replace is set.
+func SaveProfile(opts SaveProfileOptions) (SaveProfileResult, error) {
+	configPath := normalizeConfigPath(opts.ConfigPath)
+	reg, _, err := EnsureProjectRegistry(configPath)
+	if err != nil {
+		return SaveProfileResult{}, err
+	}
+	profile := opts.Profile
+	profile.Name = canonicalProjectName(profile.Name)
+	if profile.Name == "" {
+		return SaveProfileResult{}, fmt.Errorf("profile name is required")
+	}
+	if profile.Name == DefaultProfileName {
+		return SaveProfileResult{}, fmt.Errorf("%q is the built-in profile; use `settings set` to change the global defaults", DefaultProfileName)
+	}
+	if err := validateDownloadSettings(profile.DownloadSettings); err != nil {
+		return SaveProfileResult{}, err
+	}
+	profile.Description = strings.TrimSpace(profile.Description)
+	profile.DownloadSettings = normalizeDownloadSettings(profile.DownloadSettings)
+
+	created := true
+	for i := range reg.Profiles {
+		if reg.Profiles[i].Name != profile.Name {
+			continue
+		}
+		if !opts.Replace {
+			return SaveProfileResult{}, fmt.Errorf("profile %q already exists (use `profile edit`)", profile.Name)
+		}
+		reg.Profiles[i] = profile
+		created = false
+	}
+	if created {
+		reg.Profiles = append(reg.Profiles, profile)
+	}
+	reg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	if err := saveProjectRegistry(configPath, reg); err != nil {
+		return SaveProfileResult{}, err
+	}
+	return SaveProfileResult{ConfigPath: configPath, Profile: profile, Created: created}, nil
+}
+
+// RemoveProfile deletes a profile no project uses.
+func RemoveProfile(configPath, name string) (ProjectProfile, error) {
+	configPath = normalizeConfigPath(configPath)
+	reg, _, err := EnsureProjectRegistry(configPath)
+	if err != nil {
+		return ProjectProfile{}, err
+	}
+	target := canonicalProjectName(name)
+	users := make([]string, 0)
+	for _, p := range reg.Projects {
+		if canonicalProjectName(p.Profile) == target {
+			users = append(users, p.Name)
+		}
+	}
+	if len(users) > 0 {
+		return ProjectProfile{}, fmt.Errorf("profile %q is used by: %s", target, strings.Join(users, ", "))
+	}
+	for i, p := range reg.Profiles {
+		if p.Name != target {
+			continue
+		}
+		reg.Profiles = append(reg.Profiles[:i], reg.Profiles[i+1:]...)
+		reg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		if err := saveProjectRegistry(configPath, reg); err != nil {
+			return ProjectProfile{}, err
+		}
+		return p, nil
+	}
+	return ProjectProfile{}, fmt.Errorf("profile %q not found", target)
+}
@@ -0,0 +1,110 @@
+package discovery
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfileSettingsSitBetweenProjectAndGlobal(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "projects.json")
+	if _, err := SaveProfile(SaveProfileOptions{ConfigPath: cfg, Profile: ProjectProfile{
+		Name:             "Lightweight",
+		DownloadSettings: DownloadSettings{Quality: "720p", NoSubs: boolPtr(true), AudioOnly: boolPtr(true)},
+	}}); err != nil {
+		t.Fatalf("save profile: %v", err)
+	}
+	global, err := GetGlobalSettings(cfg)
+	if err != nil {
+		t.Fatalf("global: %v", err)
+	}
+	global.Quality = "2160p"
+	global.Order = "newest"
+	global.WriteThumbnails = boolPtr(true)
+	if _, err := UpdateGlobalSettings(UpdateGlobalSettingsOptions{ConfigPath: cfg, Global: global}); err != nil {
+		t.Fatalf("update global: %v", err)
+	}
+	if _, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: "light", SourceURL: "https://example.com/a", Profile: "lightweight", NoSubs: boolPtr(false)}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	p, sources, err := ExplainProjectSettings(cfg, "light")
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if p.Quality != "720p" || p.Order != "newest" || *p.NoSubs || !*p.AudioOnly || !*p.WriteThumbnails {
+		t.Fatalf("unexpected effective settings: quality=%q order=%q no_subs=%v audio_only=%v thumbnails=%v", p.Quality, p.Order, *p.NoSubs, *p.AudioOnly, *p.WriteThumbnails)
+	}
+	want := map[string]string{
+		"quality":          SettingFromProfile,
+		"order":            SettingFromGlobal,
+		"no_subs":          SettingFromProject,
+		"audio_only":       SettingFromProfile,
+		"write_thumbnails": SettingFromGlobal,
+	}
+	for _, s := range sources {
+		if w, ok := want[s.Key]; ok && s.Source != w {
+			t.Fatalf("%s: source %q, want %q", s.Key, s.Source, w)
+		}
+	}
+
+	// Editing the profile changes every project that uses it.
+	edited, err := FindProfileByName(cfg, "lightweight")
+	if err != nil {
+		t.Fatalf("find profile: %v", err)
+	}
+	edited.Quality = "480p"
+	if _, err := SaveProfile(SaveProfileOptions{ConfigPath: cfg, Profile: edited}); err == nil {
+		t.Fatalf("expected saving an existing profile without Replace to fail")
+	}
+	if _, err := SaveProfile(SaveProfileOptions{ConfigPath: cfg, Profile: edited, Replace: true}); err != nil {
+		t.Fatalf("replace profile: %v", err)
+	}
+	p, err = FindProjectByName(cfg, "light")
+	if err != nil {
+		t.Fatalf("find project: %v", err)
+	}
+	if p.Quality != "480p" {
+		t.Fatalf("project should follow the edited profile, got quality %q", p.Quality)
+	}
+}
+
+func TestProfileValidation(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "projects.json")
+	if _, err := SaveProfile(SaveProfileOptions{ConfigPath: cfg, Profile: ProjectProfile{Name: DefaultProfileName}}); err == nil {
+		t.Fatalf("expected the built-in default profile to be rejected")
+	}
+	if _, err := SaveProfile(SaveProfileOptions{ConfigPath: cfg, Profile: ProjectProfile{
+		Name:             "bad",
+		DownloadSettings: DownloadSettings{Quality: "8k"},
+	}}); err == nil {
+		t.Fatalf("expected invalid quality to be rejected")
+	}
+	_, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: "a", SourceURL: "https://example.com/a", Profile: "missing"})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+
+	if _, err := SaveProfile(SaveProfileOptions{ConfigPath: cfg, Profile: ProjectProfile{Name: "archive-max"}}); err != nil {
+		t.Fatalf("save profile: %v", err)
+	}
+	if _, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: "a", SourceURL: "https://example.com/a", Profile: "archive-max"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := RemoveProfile(cfg, "archive-max"); err == nil || !strings.Contains(err.Error(), "used by: a") {
+		t.Fatalf("expected in-use profile removal to fail, got %v", err)
+	}
+	if _, err := RemoveProject(RemoveProjectOptions{ConfigPath: cfg, Name: "a"}); err != nil {
+		t.Fatalf("remove project: %v", err)
+	}
+	if _, err := RemoveProfile(cfg, "archive-max"); err != nil {
+		t.Fatalf("remove profile: %v", err)
+	}
+	profiles, err := ListProfiles(cfg)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(profiles) != 0 {
+		t.Fatalf("expected no profiles left, got %+v", profiles)
+	}
+}
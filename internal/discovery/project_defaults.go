@@ -11,10 +11,17 @@ const (
 	DefaultBrowserCookieAgent = "chrome"
 	DefaultDownloadLimitMBps  = 0
 	DefaultProxyMode          = ProxyModeOff
+	DefaultDedupMode          = DedupModeHardlink
+	DefaultLiveChatFormat     = LiveChatFormatText
+	DefaultLockTTLMinutes     = 30
+	DefaultSnapshotRetention  = 30
 
 	JSRuntimeAuto    = "auto"
 	JSRuntimeDeno    = "deno"
 	JSRuntimeNode    = "node"
 	JSRuntimeQuickJS = "quickjs"
 	JSRuntimeBun     = "bun"
+
+	LiveChatFormatText = "txt"
+	LiveChatFormatASS  = "ass"
 )
@@ -0,0 +1,251 @@
+package discovery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type UpdateProjectOptions struct {
+	ConfigPath string
+	Name       string
+	// Update edits the stored project in place; settings it leaves empty
+	// inherit from the profile and global settings.
+	Update func(*Project) error
+	// Unset lists settings (keys as in settings explain) reset to inherit
+	// after Update ran.
+	Unset []string
+}
+
+type UpdateProjectResult struct {
+	Project      Project  `json:"project"`
+	PreviousName string   `json:"previous_name,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+// projectSettingUnsetters reset one project setting to inherit.
+var projectSettingUnsetters = map[string]func(*Project){
+	"active":               func(p *Project) { p.Active = nil },
+	"profile":              func(p *Project) { p.Profile = "" },
+	"output_dir":           func(p *Project) { p.OutputDir = "" },
+	"cookies_path":         func(p *Project) { p.CookiesPath = "" },
+	"cookies_from_browser": func(p *Project) { p.CookiesFromBrowser = "" },
+	"workers":              func(p *Project) { p.Workers = 0 },
+	"fragments":            func(p *Project) { p.Fragments = 0 },
+	"order":                func(p *Project) { p.Order = "" },
+	"quality":              func(p *Project) { p.Quality = "" },
+	"video_codec":          func(p *Project) { p.VideoCodec = "" },
+	"hdr":                  func(p *Project) { p.HDR = "" },
+	"container":            func(p *Project) { p.Container = "" },
+	"js_runtime":           func(p *Project) { p.JSRuntime = "" },
+	"delivery_mode":        func(p *Project) { p.DeliveryMode = "" },
+	"no_subs":              func(p *Project) { p.NoSubs = nil },
+	"sub_langs":            func(p *Project) { p.SubLangs = "" },
+	"live_chat":            func(p *Project) { p.LiveChat = nil },
+	"live_chat_format":     func(p *Project) { p.LiveChatFormat = "" },
+	"schedule":             func(p *Project) { p.Schedule = "" },
+	"write_thumbnails":     func(p *Project) { p.WriteThumbnails = nil },
+	"audio_only":           func(p *Project) { p.AudioOnly = nil },
+	"storage":              func(p *Project) { p.Storage = nil },
+	"mirrors":              func(p *Project) { p.Mirrors = nil },
+}
+
+// ProjectSettingKey maps a setting or flag name (quality, sub-langs,
+// subtitles, ...) to the key UpdateProjectOptions.Unset accepts.
+func ProjectSettingKey(raw string) (string, bool) {
+	key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(raw)), "-", "_")
+	switch key {
+	case "subtitles":
+		key = "no_subs"
+	case "cookies":
+		key = "cookies_path"
+	case "delivery":
+		key = "delivery_mode"
+	case "thumbnails":
+		key = "write_thumbnails"
+	}
+	_, ok := projectSettingUnsetters[key]
+	return key, ok
+}
+
+func projectSettingKeys() []string {
+	keys := make([]string, 0, len(projectSettingUnsetters))
+	for k := range projectSettingUnsetters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DownloadSettings returns the project's own download settings.
+func (p Project) DownloadSettings() DownloadSettings {
+	return DownloadSettings{
+		Fragments:       p.Fragments,
+		Order:           p.Order,
+		Quality:         p.Quality,
+		VideoCodec:      p.VideoCodec,
+		HDR:             p.HDR,
+		Container:       p.Container,
+		JSRuntime:       p.JSRuntime,
+		DeliveryMode:    p.DeliveryMode,
+		NoSubs:          p.NoSubs,
+		SubLangs:        p.SubLangs,
+		LiveChat:        p.LiveChat,
+		LiveChatFormat:  p.LiveChatFormat,
+		Schedule:        p.Schedule,
+		WriteThumbnails: p.WriteThumbnails,
+		AudioOnly:       p.AudioOnly,
+	}
+}
+
+func (p *Project) SetDownloadSettings(ds DownloadSettings) {
+	p.Fragments = ds.Fragments
+	p.Order = ds.Order
+	p.Quality = ds.Quality
+	p.VideoCodec = ds.VideoCodec
+	p.HDR = ds.HDR
+	p.Container = ds.Container
+	p.JSRuntime = ds.JSRuntime
+	p.DeliveryMode = ds.DeliveryMode
+	p.NoSubs = ds.NoSubs
+	p.SubLangs = ds.SubLangs
+	p.LiveChat = ds.LiveChat
+	p.LiveChatFormat = ds.LiveChatFormat
+	p.Schedule = ds.Schedule
+	p.WriteThumbnails = ds.WriteThumbnails
+	p.AudioOnly = ds.AudioOnly
+}
+
+// checkProjectSettings applies the AddProject checks to p's settings and
+// returns it canonicalized. Name and source uniqueness are left to callers.
+func checkProjectSettings(reg ProjectRegistry, p Project) (Project, error) {
+	if p.Workers < 0 {
+		return Project{}, fmt.Errorf("workers must be >= 0")
+	}
+	ds := p.DownloadSettings()
+	if err := validateDownloadSettings(ds); err != nil {
+		return Project{}, err
+	}
+	ds = normalizeDownloadSettings(ds)
+	schedule, err := normalizeSchedule(ds.Schedule)
+	if err != nil {
+		return Project{}, err
+	}
+	storage := normalizeStorageTarget(p.Storage)
+	if storage != nil {
+		if err := storage.Validate(); err != nil {
+			return Project{}, err
+		}
+	}
+	if err := checkProfileExists(reg.Profiles, p.Profile); err != nil {
+		return Project{}, err
+	}
+
+	out := p
+	out.SourceURL = strings.TrimSpace(p.SourceURL)
+	out.Profile = defaultIfEmpty(canonicalProjectName(p.Profile), DefaultProfileName)
+	out.OutputDir = strings.TrimSpace(p.OutputDir)
+	out.CookiesPath = strings.TrimSpace(p.CookiesPath)
+	out.CookiesFromBrowser = strings.TrimSpace(p.CookiesFromBrowser)
+	out.SetDownloadSettings(ds)
+	out.Schedule = schedule
+	out.Storage = storage
+	out.Mirrors = normalizeMirrorList(p.Mirrors)
+	if out.Active == nil {
+		out.Active = boolPtr(true)
+	}
+	return out, nil
+}
+
+// UpdateProject changes only what opts.Update and opts.Unset touch, with the
+// same validation as AddProject. Renaming or changing the source URL keeps the
+// project's runs, which are matched by project id.
+func UpdateProject(opts UpdateProjectOptions) (UpdateProjectResult, error) {
+	configPath := normalizeConfigPath(opts.ConfigPath)
+	reg, _, err := EnsureProjectRegistry(configPath)
+	if err != nil {
+		return UpdateProjectResult{}, err
+	}
+	current, err := findRegistryProject(reg, opts.Name)
+	if err != nil {
+		return UpdateProjectResult{}, err
+	}
+
+	updated := current
+	updated.Mirrors = append([]string(nil), current.Mirrors...)
+	if opts.Update != nil {
+		if err := opts.Update(&updated); err != nil {
+			return UpdateProjectResult{}, err
+		}
+	}
+	for _, raw := range opts.Unset {
+		key, ok := ProjectSettingKey(raw)
+		if !ok {
+			return UpdateProjectResult{}, fmt.Errorf("unknown setting %q (one of: %s)", raw, strings.Join(projectSettingKeys(), ", "))
+		}
+		projectSettingUnsetters[key](&updated)
+	}
+
+	updated.ID = current.ID
+	updated.Name = canonicalProjectName(updated.Name)
+	if updated.Name == "" {
+		return UpdateProjectResult{}, fmt.Errorf("project name is required")
+	}
+	if strings.TrimSpace(updated.SourceURL) == "" {
+		return UpdateProjectResult{}, fmt.Errorf("source URL is required")
+	}
+	updated, err = checkProjectSettings(reg, updated)
+	if err != nil {
+		return UpdateProjectResult{}, err
+	}
+	canonicalSource := normalizeSourceURL(updated.SourceURL)
+	index := -1
+	for i, p := range reg.Projects {
+		if p.Name == current.Name {
+			index = i
+			continue
+		}
+		if p.Name == updated.Name {
+			return UpdateProjectResult{}, fmt.Errorf("project %q already exists", updated.Name)
+		}
+		if normalizeSourceURL(p.SourceURL) == canonicalSource {
+			return UpdateProjectResult{}, fmt.Errorf("source already tracked by project %q", p.Name)
+		}
+	}
+
+	res := UpdateProjectResult{Project: updated}
+	if updated.Name != current.Name {
+		res.PreviousName = current.Name
+		res.Warnings = renameWarnings(
+			EffectiveProject(current, reg.Global, reg.Profiles),
+			EffectiveProject(updated, reg.Global, reg.Profiles),
+		)
+	}
+
+	reg.Projects[index] = updated
+	sort.Slice(reg.Projects, func(i, j int) bool {
+		return reg.Projects[i].Name < reg.Projects[j].Name
+	})
+	reg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	if err := saveProjectRegistry(configPath, reg); err != nil {
+		return UpdateProjectResult{}, err
+	}
+	return res, nil
+}
+
+// renameWarnings reports locations derived from the project name (global
+// output root, storage prefix, mirror roots) that a rename moves.
+func renameWarnings(before, after Project) []string {
+	var out []string
+	if before.OutputDir != after.OutputDir {
+		out = append(out, fmt.Sprintf("output directory changes from %s to %s; move existing downloads or pin it with --output-dir", before.OutputDir, after.OutputDir))
+	}
+	if before.Storage != nil && after.Storage != nil && before.Storage.Prefix != after.Storage.Prefix {
+		out = append(out, fmt.Sprintf("storage prefix changes from %s to %s", before.Storage.Prefix, after.Storage.Prefix))
+	}
+	if strings.Join(before.Mirrors, ",") != strings.Join(after.Mirrors, ",") {
+		out = append(out, fmt.Sprintf("mirror directories change to %s", strings.Join(after.Mirrors, ", ")))
+	}
+	return out
+}
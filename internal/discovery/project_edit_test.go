@@ -0,0 +1,113 @@
+package discovery
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateProjectChangesOnlyGivenSettings(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "projects.json")
+	if _, err := AddProject(AddProjectOptions{
+		ConfigPath: cfg,
+		Name:       "demo",
+		SourceURL:  "https://example.com/a",
+		Quality:    "1080p",
+		Order:      "newest",
+		SubLangs:   "all",
+		Schedule:   "6h",
+	}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	res, err := UpdateProject(UpdateProjectOptions{
+		ConfigPath: cfg,
+		Name:       "demo",
+		Update: func(p *Project) error {
+			p.Quality = "720P"
+			p.Active = boolPtr(false)
+			return nil
+		},
+		Unset: []string{"sub-langs"},
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	p := res.Project
+	if p.Quality != "720p" || *p.Active {
+		t.Fatalf("given settings not applied: quality=%q active=%v", p.Quality, *p.Active)
+	}
+	if p.Order != "newest" || p.Schedule != "6h" {
+		t.Fatalf("other settings must be kept: order=%q schedule=%q", p.Order, p.Schedule)
+	}
+	if p.SubLangs != "" {
+		t.Fatalf("unset setting should inherit, got sub_langs=%q", p.SubLangs)
+	}
+
+	if _, err := UpdateProject(UpdateProjectOptions{ConfigPath: cfg, Name: "demo", Update: func(p *Project) error {
+		p.Quality = "8k"
+		return nil
+	}}); err == nil {
+		t.Fatalf("expected AddProject validation to reject quality 8k")
+	}
+	if _, err := UpdateProject(UpdateProjectOptions{ConfigPath: cfg, Name: "demo", Unset: []string{"colour"}}); err == nil {
+		t.Fatalf("expected unknown unset key to fail")
+	}
+	stored, err := LoadProjects(cfg)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if stored.Projects[0].Quality != "720p" {
+		t.Fatalf("failed update must not be written, got quality %q", stored.Projects[0].Quality)
+	}
+}
+
+func TestUpdateProjectRename(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "projects.json")
+	for _, name := range []string{"one", "two"} {
+		if _, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: name, SourceURL: "https://example.com/" + name}); err != nil {
+			t.Fatalf("add %s: %v", name, err)
+		}
+	}
+	global, err := GetGlobalSettings(cfg)
+	if err != nil {
+		t.Fatalf("global: %v", err)
+	}
+	global.OutputRoot = "/srv/vods"
+	if _, err := UpdateGlobalSettings(UpdateGlobalSettingsOptions{ConfigPath: cfg, Global: global}); err != nil {
+		t.Fatalf("update global: %v", err)
+	}
+
+	rename := func(from, to string) (UpdateProjectResult, error) {
+		return UpdateProject(UpdateProjectOptions{ConfigPath: cfg, Name: from, Update: func(p *Project) error {
+			p.Name = to
+			return nil
+		}})
+	}
+	if _, err := rename("one", "Two"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected name clash, got %v", err)
+	}
+	res, err := rename("one", "First Channel")
+	if err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if res.Project.Name != "first-channel" || res.PreviousName != "one" {
+		t.Fatalf("unexpected rename result: %+v", res)
+	}
+	if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], filepath.Join("/srv/vods", "first-channel")) {
+		t.Fatalf("expected output directory warning, got %v", res.Warnings)
+	}
+	if _, err := FindProjectByName(cfg, "one"); err == nil {
+		t.Fatalf("old name should be gone")
+	}
+	if p, err := FindProjectByName(cfg, "first-channel"); err != nil || p.SourceURL != "https://example.com/one" {
+		t.Fatalf("renamed project: %+v, %v", p, err)
+	}
+
+	if _, err := UpdateProject(UpdateProjectOptions{ConfigPath: cfg, Name: "two", Update: func(p *Project) error {
+		p.SourceURL = "https://example.com/one"
+		return nil
+	}}); err == nil || !strings.Contains(err.Error(), "already tracked") {
+		t.Fatalf("expected duplicate source error, got %v", err)
+	}
+}
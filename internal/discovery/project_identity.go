@@ -0,0 +1,197 @@
+package discovery
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/runstore"
+)
+
+const (
+	RelinkLinked        = "linked"
+	RelinkAlreadyLinked = "already_linked"
+	RelinkSkipped       = "skipped"
+)
+
+type RelinkRunsOptions struct {
+	ConfigPath string
+	RunsDir    string
+	Name       string
+	// FromSource selects the runs recorded with this source URL, typically
+	// the project's URL before it changed.
+	FromSource string
+	// RunIDs selects runs by id instead.
+	RunIDs  []string
+	DryRun  bool
+	LockTTL time.Duration
+}
+
+type RelinkRunItem struct {
+	RunID     string `json:"run_id"`
+	SourceURL string `json:"source_url"`
+	Outcome   string `json:"outcome"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type RelinkRunsResult struct {
+	Project   string          `json:"project"`
+	ProjectID string          `json:"project_id"`
+	DryRun    bool            `json:"dry_run"`
+	Linked    int             `json:"linked"`
+	Items     []RelinkRunItem `json:"items"`
+}
+
+func newProjectID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return runstore.DerivedProjectID(time.Now().UTC().Format(time.RFC3339Nano))
+	}
+	return hex.EncodeToString(b)
+}
+
+func normalizeProjectID(raw string) string {
+	return canonicalProjectName(raw)
+}
+
+// assignProjectIDs gives every project without a usable id one, keeping ids
+// unique within the registry. It reports whether any id changed, in which case
+// the registry must be saved before runs are stamped with the new ids.
+func assignProjectIDs(projects []Project) bool {
+	changed := false
+	seen := make(map[string]bool, len(projects))
+	for i := range projects {
+		id := normalizeProjectID(projects[i].ID)
+		if id == "" || seen[id] {
+			id = runstore.DerivedProjectID(projects[i].SourceURL)
+		}
+		if seen[id] {
+			id = runstore.DerivedProjectID(projects[i].SourceURL + "#" + projects[i].Name)
+		}
+		seen[id] = true
+		if projects[i].ID != id {
+			changed = true
+		}
+		projects[i].ID = id
+	}
+	return changed
+}
+
+// PersistProjectIDs writes ids that projects only have in memory, upgrading an
+// outdated registry first. Sync and relink call it before stamping runs, so a
+// later hand edit of a source URL cannot change the id a run was stamped with.
+// Nothing is written when every id is already saved.
+func PersistProjectIDs(configPath string) error {
+	path := normalizeConfigPath(configPath)
+	if _, err := runstore.UpgradeFile(path, runstore.SchemaProjects, &ProjectRegistry{}); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	reg, assigned, err := readProjectRegistry(path)
+	if err != nil || !assigned {
+		return err
+	}
+	return saveProjectRegistry(path, reg)
+}
+
+// RelinkProjectRuns stamps existing runs with the project's id, so they stay
+// its history after the project's source URL changed.
+func RelinkProjectRuns(opts RelinkRunsOptions) (RelinkRunsResult, error) {
+	if !opts.DryRun {
+		if err := PersistProjectIDs(opts.ConfigPath); err != nil {
+			return RelinkRunsResult{}, err
+		}
+	}
+	reg, _, err := EnsureProjectRegistry(opts.ConfigPath)
+	if err != nil {
+		return RelinkRunsResult{}, err
+	}
+	project, err := findRegistryProject(reg, opts.Name)
+	if err != nil {
+		return RelinkRunsResult{}, err
+	}
+	fromSource := normalizeSourceURL(opts.FromSource)
+	if fromSource == "" && len(opts.RunIDs) == 0 {
+		return RelinkRunsResult{}, fmt.Errorf("select runs with a source URL or run ids")
+	}
+	owners := make(map[string]string, len(reg.Projects))
+	for _, p := range reg.Projects {
+		owners[p.ID] = p.Name
+	}
+	runsDir := defaultIfEmpty(strings.TrimSpace(opts.RunsDir), "runs")
+
+	var runDirs []string
+	if len(opts.RunIDs) > 0 {
+		for _, id := range opts.RunIDs {
+			dir := filepath.Join(runsDir, strings.TrimSpace(id))
+			if _, err := os.Stat(runstore.RunMetaPath(dir)); err != nil {
+				return RelinkRunsResult{}, fmt.Errorf("run %q has no run.json in %s (see `repair`)", id, runsDir)
+			}
+			runDirs = append(runDirs, dir)
+		}
+	} else {
+		all, err := runstore.ListRunDirs(runsDir)
+		if err != nil {
+			return RelinkRunsResult{}, err
+		}
+		for _, dir := range all {
+			if _, source := runIdentity(dir); normalizeSourceURL(source) == fromSource {
+				runDirs = append(runDirs, dir)
+			}
+		}
+	}
+
+	res := RelinkRunsResult{Project: project.Name, ProjectID: project.ID, DryRun: opts.DryRun, Items: []RelinkRunItem{}}
+	for _, dir := range runDirs {
+		item, err := relinkRun(dir, project, owners, opts)
+		if err != nil {
+			return res, err
+		}
+		if item.Outcome == RelinkLinked {
+			res.Linked++
+		}
+		res.Items = append(res.Items, item)
+	}
+	return res, nil
+}
+
+func relinkRun(runDir string, project Project, owners map[string]string, opts RelinkRunsOptions) (RelinkRunItem, error) {
+	meta, err := runstore.LoadRunMeta(runDir)
+	if err != nil {
+		return RelinkRunItem{}, err
+	}
+	item := RelinkRunItem{RunID: defaultIfEmpty(meta.RunID, filepath.Base(runDir)), SourceURL: meta.SourceURL}
+	switch owner, owned := owners[meta.ProjectID]; {
+	case meta.ProjectID == project.ID:
+		item.Outcome = RelinkAlreadyLinked
+		return item, nil
+	case meta.ProjectID != "" && owned:
+		item.Outcome = RelinkSkipped
+		item.Reason = fmt.Sprintf("belongs to project %q", owner)
+		return item, nil
+	}
+	item.Outcome = RelinkLinked
+	if opts.DryRun {
+		return item, nil
+	}
+
+	lock, err := runstore.AcquireRunLock(runDir, opts.LockTTL)
+	if err != nil {
+		return RelinkRunItem{}, err
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+	meta.ProjectID = project.ID
+	if err := runstore.SaveRunMeta(runDir, meta); err != nil {
+		return RelinkRunItem{}, err
+	}
+	return item, nil
+}
@@ -0,0 +1,220 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"yt-vod-manager/internal/runstore"
+)
+
+func writeTestRun(t *testing.T, runsDir, runID, projectID, sourceURL string) string {
+	t.Helper()
+	runDir := filepath.Join(runsDir, runID)
+	if err := runstore.SaveRunMeta(runDir, runstore.RunMeta{RunID: runID, ProjectID: projectID, SourceURL: sourceURL}); err != nil {
+		t.Fatal(err)
+	}
+	return runDir
+}
+
+func TestProjectIDsAreStable(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "projects.json")
+	legacy := `{"schema_version":2,"projects":[{"name":"old","source_url":"https://example.com/old"}]}`
+	if err := os.WriteFile(cfg, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	first, err := FindProjectByName(cfg, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := FindProjectByName(cfg, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID == "" || first.ID != again.ID {
+		t.Fatalf("legacy project id must be assigned and stable across loads: %q vs %q", first.ID, again.ID)
+	}
+
+	added, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: "new", SourceURL: "https://example.com/new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.Project.ID == "" || added.Project.ID == first.ID {
+		t.Fatalf("new project needs its own id, got %q", added.Project.ID)
+	}
+	replaced, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: "new", SourceURL: "https://example.com/new", Quality: "720p", ReplaceIfNameExists: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaced.Project.ID != added.Project.ID {
+		t.Fatalf("replace must keep the id: %q vs %q", replaced.Project.ID, added.Project.ID)
+	}
+	updated, err := UpdateProject(UpdateProjectOptions{ConfigPath: cfg, Name: "old", Update: func(p *Project) error {
+		p.SourceURL = "https://example.com/channel/UC1"
+		return nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Project.ID != first.ID {
+		t.Fatalf("changing the source must keep the id: %q vs %q", updated.Project.ID, first.ID)
+	}
+}
+
+func TestMigratedProjectIDsArePersisted(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "projects.json")
+	runsDir := filepath.Join(dir, "runs")
+	legacy := `{"schema_version":2,"projects":[{"name":"talks","source_url":"https://example.com/@talks"}]}`
+	if err := os.WriteFile(cfg, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := FindProjectByName(cfg, "talks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(cfg); string(data) != legacy {
+		t.Fatalf("loading must not rewrite the registry:\n%s", data)
+	}
+	if err := PersistProjectIDs(cfg); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"id": "`+p.ID+`"`) {
+		t.Fatalf("migrated registry must store the project id %q:\n%s", p.ID, data)
+	}
+	// A sync stamps its run with the id, then the source URL is edited by hand.
+	writeTestRun(t, runsDir, "20240101T000000Z_talks", p.ID, p.SourceURL)
+	edited := strings.Replace(string(data), "https://example.com/@talks", "https://example.com/channel/UC1", 1)
+	if err := os.WriteFile(cfg, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	moved, err := FindProjectByName(cfg, "talks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.ID != p.ID {
+		t.Fatalf("id changed with the source URL: %q vs %q", moved.ID, p.ID)
+	}
+	if got, err := latestProjectRunDir(runsDir, moved); err != nil || filepath.Base(got) != "20240101T000000Z_talks" {
+		t.Fatalf("expected the stamped run after the URL change, got %q: %v", got, err)
+	}
+
+	// Entries added by hand without an id get one saved before the next sync.
+	withNew := strings.Replace(edited, `"projects": [`, `"projects": [{"name": "new", "source_url": "https://example.com/@new"},`, 1)
+	if err := os.WriteFile(cfg, []byte(withNew), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	added, err := FindProjectByName(cfg, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := PersistProjectIDs(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(cfg); added.ID == "" || !strings.Contains(string(data), `"id": "`+added.ID+`"`) {
+		t.Fatalf("assigned id %q must be saved:\n%s", added.ID, data)
+	}
+}
+
+func TestRunsAreFoundByProjectID(t *testing.T) {
+	runsDir := t.TempDir()
+	writeTestRun(t, runsDir, "20240101T000000Z_a", "", "https://example.com/@handle")
+	stamped := writeTestRun(t, runsDir, "20240102T000000Z_a", "p1", "https://example.com/@handle")
+
+	// The URL changed: the stamped run still belongs to the project.
+	moved := Project{ID: "p1", SourceURL: "https://example.com/channel/UC1/videos"}
+	if got, err := latestProjectRunDir(runsDir, moved); err != nil || got != stamped {
+		t.Fatalf("expected stamped run, got %q: %v", got, err)
+	}
+	// Another project now tracking the old URL must not take p1's runs, but
+	// still finds unstamped runs from before project ids.
+	other := Project{ID: "p2", SourceURL: "https://example.com/@handle"}
+	if got, err := latestProjectRunDir(runsDir, other); err != nil || filepath.Base(got) != "20240101T000000Z_a" {
+		t.Fatalf("expected only the unstamped run, got %q: %v", got, err)
+	}
+}
+
+func TestRelinkProjectRuns(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "projects.json")
+	runsDir := filepath.Join(dir, "runs")
+	for _, p := range []AddProjectOptions{
+		{Name: "talks", SourceURL: "https://example.com/channel/UC1"},
+		{Name: "other", SourceURL: "https://example.com/@other"},
+	} {
+		p.ConfigPath = cfg
+		if _, err := AddProject(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other, err := FindProjectByName(cfg, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestRun(t, runsDir, "20240101T000000Z_a", "", "https://example.com/@talks")
+	writeTestRun(t, runsDir, "20240102T000000Z_b", other.ID, "https://example.com/@talks/")
+
+	talks, err := FindProjectByName(cfg, "talks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := latestProjectRunDir(runsDir, talks); got != "" {
+		t.Fatalf("runs under the old URL should not match yet, got %q", got)
+	}
+
+	opts := RelinkRunsOptions{ConfigPath: cfg, RunsDir: runsDir, Name: "talks", FromSource: "https://example.com/@talks", DryRun: true}
+	res, err := RelinkProjectRuns(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Linked != 1 || len(res.Items) != 2 || res.Items[1].Outcome != RelinkSkipped {
+		t.Fatalf("unexpected dry run: %+v", res)
+	}
+	if got, _ := latestProjectRunDir(runsDir, talks); got != "" {
+		t.Fatalf("dry run must not stamp runs, got %q", got)
+	}
+
+	opts.DryRun = false
+	if _, err := RelinkProjectRuns(opts); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := latestProjectRunDir(runsDir, talks); filepath.Base(got) != "20240101T000000Z_a" {
+		t.Fatalf("expected relinked run, got %q", got)
+	}
+	res, err = RelinkProjectRuns(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Linked != 0 || res.Items[0].Outcome != RelinkAlreadyLinked {
+		t.Fatalf("second relink should be a no-op: %+v", res)
+	}
+}
+
+func TestPersistProjectIDsSkipsSavedRegistry(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "projects.json")
+	if _, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: "talks", SourceURL: "https://example.com/@talks"}); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.Stat(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := PersistProjectIDs(cfg); err != nil {
+		t.Fatal(err)
+	}
+	after, err := os.Stat(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !after.ModTime().Equal(before.ModTime()) {
+		t.Fatal("registry with saved ids must not be rewritten")
+	}
+	if err := PersistProjectIDs(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Fatalf("missing registry: %v", err)
+	}
+}
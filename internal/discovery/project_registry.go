@@ -10,11 +10,12 @@ import (
 	"time"
 
 	"yt-vod-manager/internal/runstore"
+	"yt-vod-manager/internal/ytdlp"
 )
 
 const (
 	DefaultProjectsConfigPath = "config/projects.json"
-	projectSchemaVersion      = 2
+	projectSchemaVersion      = runstore.ProjectsSchemaVersion
 )
 
 var (
@@ -22,7 +23,12 @@ var (
 	ErrProjectSelectRequired = errors.New("project selection required")
 )
 
+// Project settings left empty (nil for booleans) inherit the global
+// settings, then the built-in defaults; see ResolveProjectSettings.
 type Project struct {
+	// ID links the project to its runs; it never changes, so runs stay
+	// attached across renames and source URL changes.
+	ID                 string `json:"id,omitempty"`
 	Name               string `json:"name"`
 	SourceURL          string `json:"source_url"`
 	Active             *bool  `json:"active,omitempty"`
@@ -34,21 +40,39 @@ type Project struct {
 	Fragments          int    `json:"fragments,omitempty"`
 	Order              string `json:"order,omitempty"`
 	Quality            string `json:"quality,omitempty"`
+	VideoCodec         string `json:"video_codec,omitempty"`
+	HDR                string `json:"hdr,omitempty"`
+	Container          string `json:"container,omitempty"`
 	JSRuntime          string `json:"js_runtime,omitempty"`
 	DeliveryMode       string `json:"delivery_mode,omitempty"`
-	NoSubs             bool   `json:"no_subs,omitempty"`
+	NoSubs             *bool  `json:"no_subs,omitempty"`
 	SubLangs           string `json:"sub_langs,omitempty"`
+	LiveChat           *bool  `json:"live_chat,omitempty"`
+	LiveChatFormat     string `json:"live_chat_format,omitempty"`
+	Schedule           string `json:"schedule,omitempty"`
+	WriteThumbnails    *bool  `json:"write_thumbnails,omitempty"`
+	AudioOnly          *bool  `json:"audio_only,omitempty"`
+	// Storage uploads completed media to object storage instead of keeping
+	// the archive only on the local disk.
+	Storage *StorageTarget `json:"storage,omitempty"`
+	// Mirrors are directories every completed download is copied to; they
+	// replace the global mirrors for this project.
+	Mirrors []string `json:"mirrors,omitempty"`
 }
 
 type ProjectRegistry struct {
-	SchemaVersion int            `json:"schema_version"`
-	UpdatedAt     string         `json:"updated_at"`
-	Global        GlobalSettings `json:"global,omitempty"`
-	Projects      []Project      `json:"projects"`
+	SchemaVersion int              `json:"schema_version"`
+	UpdatedAt     string           `json:"updated_at"`
+	Global        GlobalSettings   `json:"global,omitempty"`
+	Profiles      []ProjectProfile `json:"profiles,omitempty"`
+	Projects      []Project        `json:"projects"`
 }
 
 type AddProjectOptions struct {
-	ConfigPath          string
+	ConfigPath string
+	// ID is only for restoring a project with its runs (projects import of
+	// an exported CSV); new projects get a fresh id, replaced ones keep theirs.
+	ID                  string
 	Name                string
 	SourceURL           string
 	Profile             string
@@ -59,10 +83,20 @@ type AddProjectOptions struct {
 	Fragments           int
 	Order               string
 	Quality             string
+	VideoCodec          string
+	HDR                 string
+	Container           string
 	JSRuntime           string
 	DeliveryMode        string
-	NoSubs              bool
+	NoSubs              *bool
 	SubLangs            string
+	LiveChat            *bool
+	LiveChatFormat      string
+	Schedule            string
+	WriteThumbnails     *bool
+	AudioOnly           *bool
+	Storage             *StorageTarget
+	Mirrors             []string
 	Active              *bool
 	ReplaceIfNameExists bool
 }
@@ -99,6 +133,9 @@ func normalizeConfigPath(path string) string {
 	return p
 }
 
+// EnsureProjectRegistry loads the registry, creating it when missing. Loading
+// never rewrites an existing file; ids assigned in memory are saved by the
+// next change or by PersistProjectIDs.
 func EnsureProjectRegistry(configPath string) (ProjectRegistry, bool, error) {
 	path := normalizeConfigPath(configPath)
 	reg, err := loadProjectRegistry(path)
@@ -133,15 +170,34 @@ func AddProject(opts AddProjectOptions) (AddProjectResult, error) {
 	if sourceURL == "" {
 		return AddProjectResult{}, fmt.Errorf("source URL is required")
 	}
-	if opts.Workers < 0 {
-		return AddProjectResult{}, fmt.Errorf("workers must be >= 0")
-	}
-	if opts.Fragments < 0 {
-		return AddProjectResult{}, fmt.Errorf("fragments must be >= 0")
-	}
-	jsRuntime, ok := parseJSRuntime(opts.JSRuntime)
-	if !ok {
-		return AddProjectResult{}, fmt.Errorf("js runtime must be auto or a comma-separated list of: deno, node, quickjs, bun")
+	project, err := checkProjectSettings(reg, Project{
+		SourceURL:          sourceURL,
+		Active:             opts.Active,
+		Profile:            opts.Profile,
+		OutputDir:          opts.OutputDir,
+		CookiesPath:        opts.CookiesPath,
+		CookiesFromBrowser: opts.CookiesFromBrowser,
+		Workers:            opts.Workers,
+		Fragments:          opts.Fragments,
+		Order:              opts.Order,
+		Quality:            opts.Quality,
+		VideoCodec:         opts.VideoCodec,
+		HDR:                opts.HDR,
+		Container:          opts.Container,
+		JSRuntime:          opts.JSRuntime,
+		DeliveryMode:       opts.DeliveryMode,
+		NoSubs:             opts.NoSubs,
+		SubLangs:           opts.SubLangs,
+		LiveChat:           opts.LiveChat,
+		LiveChatFormat:     opts.LiveChatFormat,
+		Schedule:           opts.Schedule,
+		WriteThumbnails:    opts.WriteThumbnails,
+		AudioOnly:          opts.AudioOnly,
+		Storage:            opts.Storage,
+		Mirrors:            opts.Mirrors,
+	})
+	if err != nil {
+		return AddProjectResult{}, err
 	}
 	canonicalSource := normalizeSourceURL(sourceURL)
 	for _, p := range reg.Projects {
@@ -162,44 +218,8 @@ func AddProject(opts AddProjectOptions) (AddProjectResult, error) {
 		return AddProjectResult{}, fmt.Errorf("project name is required")
 	}
 
-	project := Project{
-		Name:               name,
-		SourceURL:          sourceURL,
-		Active:             opts.Active,
-		Profile:            strings.TrimSpace(opts.Profile),
-		OutputDir:          strings.TrimSpace(opts.OutputDir),
-		CookiesPath:        strings.TrimSpace(opts.CookiesPath),
-		CookiesFromBrowser: strings.TrimSpace(opts.CookiesFromBrowser),
-		Workers:            opts.Workers,
-		Fragments:          opts.Fragments,
-		Order:              strings.TrimSpace(opts.Order),
-		Quality:            strings.TrimSpace(opts.Quality),
-		JSRuntime:          jsRuntime,
-		DeliveryMode:       strings.TrimSpace(opts.DeliveryMode),
-		NoSubs:             opts.NoSubs,
-		SubLangs:           strings.TrimSpace(opts.SubLangs),
-	}
-	if project.Profile == "" {
-		project.Profile = DefaultProfileName
-	}
-	if project.Active == nil {
-		project.Active = boolPtr(true)
-	}
-	if project.Fragments <= 0 {
-		project.Fragments = DefaultFragments
-	}
-	if project.Order == "" {
-		project.Order = DefaultOrder
-	}
-	if project.Quality == "" {
-		project.Quality = DefaultQuality
-	}
-	if project.JSRuntime == "" {
-		project.JSRuntime = DefaultJSRuntime
-	}
-	if project.SubLangs == "" {
-		project.SubLangs = DefaultSubtitleLanguage
-	}
+	project.Name = name
+	project.ID = normalizeProjectID(opts.ID)
 
 	created := true
 	replaced := false
@@ -208,6 +228,7 @@ func AddProject(opts AddProjectOptions) (AddProjectResult, error) {
 			if !opts.ReplaceIfNameExists {
 				return AddProjectResult{}, fmt.Errorf("project %q already exists (use --replace)", name)
 			}
+			project.ID = reg.Projects[i].ID
 			reg.Projects[i] = project
 			created = false
 			replaced = true
@@ -215,6 +236,14 @@ func AddProject(opts AddProjectOptions) (AddProjectResult, error) {
 		}
 	}
 	if !replaced {
+		for _, p := range reg.Projects {
+			if project.ID != "" && p.ID == project.ID {
+				return AddProjectResult{}, fmt.Errorf("project id %q is already used by project %q", project.ID, p.Name)
+			}
+		}
+		if project.ID == "" {
+			project.ID = newProjectID()
+		}
 		reg.Projects = append(reg.Projects, project)
 	}
 
@@ -277,6 +306,8 @@ func ListProjects(opts ListProjectsOptions) (ListProjectsResult, error) {
 	}, nil
 }
 
+// LoadProjects returns the registry as configured; unset project settings
+// stay empty. Use EffectiveProject for the values a sync would use.
 func LoadProjects(configPath string) (ProjectRegistry, error) {
 	reg, _, err := EnsureProjectRegistry(configPath)
 	if err != nil {
@@ -285,16 +316,25 @@ func LoadProjects(configPath string) (ProjectRegistry, error) {
 	return reg, nil
 }
 
+// FindProjectByName returns the named project with inherited settings
+// filled in.
 func FindProjectByName(configPath, name string) (Project, error) {
 	reg, _, err := EnsureProjectRegistry(configPath)
 	if err != nil {
 		return Project{}, err
 	}
+	p, err := findRegistryProject(reg, name)
+	if err != nil {
+		return Project{}, err
+	}
+	return EffectiveProject(p, reg.Global, reg.Profiles), nil
+}
+
+func findRegistryProject(reg ProjectRegistry, name string) (Project, error) {
 	target := canonicalProjectName(name)
 	if target == "" {
 		return Project{}, fmt.Errorf("project name is required")
 	}
-
 	for _, p := range reg.Projects {
 		if strings.EqualFold(p.Name, target) {
 			return p, nil
@@ -307,6 +347,8 @@ func ResolveProjectSelection(configPath string, projectName string, all bool) ([
 	return ResolveProjectSelectionFiltered(configPath, projectName, all, false)
 }
 
+// ResolveProjectSelectionFiltered returns the selected projects with
+// inherited settings filled in.
 func ResolveProjectSelectionFiltered(configPath string, projectName string, all bool, activeOnly bool) ([]Project, error) {
 	reg, _, err := EnsureProjectRegistry(configPath)
 	if err != nil {
@@ -322,7 +364,7 @@ func ResolveProjectSelectionFiltered(configPath string, projectName string, all
 			if activeOnly && !isProjectActive(p) {
 				continue
 			}
-			projects = append(projects, p)
+			projects = append(projects, EffectiveProject(p, reg.Global, reg.Profiles))
 		}
 		if len(projects) == 0 {
 			if activeOnly {
@@ -359,7 +401,7 @@ func ResolveProjectSelectionFiltered(configPath string, projectName string, all
 		if activeOnly && !isProjectActive(p) {
 			continue
 		}
-		selected = append(selected, p)
+		selected = append(selected, EffectiveProject(p, reg.Global, reg.Profiles))
 		seen[key] = true
 	}
 	if len(selected) == 0 {
@@ -371,15 +413,23 @@ func ResolveProjectSelectionFiltered(configPath string, projectName string, all
 	return selected, nil
 }
 
+// loadProjectRegistry reads the registry without writing it. Projects without
+// a saved id get their derived one in memory, which is the id the next save
+// or PersistProjectIDs writes.
 func loadProjectRegistry(path string) (ProjectRegistry, error) {
+	reg, _, err := readProjectRegistry(path)
+	return reg, err
+}
+
+// readProjectRegistry reads and normalizes the registry and reports whether
+// projects were given ids that are not saved yet.
+func readProjectRegistry(path string) (ProjectRegistry, bool, error) {
 	var reg ProjectRegistry
-	if err := runstore.ReadJSON(path, &reg); err != nil {
-		return ProjectRegistry{}, err
-	}
-	if reg.SchemaVersion == 0 {
-		reg.SchemaVersion = projectSchemaVersion
+	if err := runstore.ReadVersionedJSON(path, runstore.SchemaProjects, &reg); err != nil {
+		return ProjectRegistry{}, false, err
 	}
 	reg.Global = normalizeGlobalSettings(reg.Global)
+	reg.Profiles = normalizeProfiles(reg.Profiles)
 	if reg.Projects == nil {
 		reg.Projects = []Project{}
 	}
@@ -387,45 +437,51 @@ func loadProjectRegistry(path string) (ProjectRegistry, error) {
 	for _, p := range reg.Projects {
 		p.Name = canonicalProjectName(p.Name)
 		p.SourceURL = strings.TrimSpace(p.SourceURL)
-		p.Profile = strings.TrimSpace(p.Profile)
+		p.Profile = canonicalProjectName(p.Profile)
 		p.OutputDir = strings.TrimSpace(p.OutputDir)
 		p.CookiesPath = strings.TrimSpace(p.CookiesPath)
 		p.CookiesFromBrowser = strings.TrimSpace(p.CookiesFromBrowser)
 		p.Order = strings.TrimSpace(p.Order)
-		p.Quality = strings.TrimSpace(p.Quality)
+		p.Quality = canonicalQuality(p.Quality)
+		p.VideoCodec = canonicalFormatValue(p.VideoCodec)
+		p.HDR = canonicalFormatValue(p.HDR)
+		p.Container = canonicalFormatValue(p.Container)
 		p.JSRuntime = strings.TrimSpace(p.JSRuntime)
 		p.DeliveryMode = strings.TrimSpace(p.DeliveryMode)
 		p.SubLangs = strings.TrimSpace(p.SubLangs)
+		p.Schedule = strings.TrimSpace(p.Schedule)
+		p.Storage = normalizeStorageTarget(p.Storage)
+		p.Mirrors = normalizeMirrorList(p.Mirrors)
+		if v, ok := parseLiveChatFormat(p.LiveChatFormat); ok {
+			p.LiveChatFormat = v
+		} else {
+			p.LiveChatFormat = ""
+		}
 		if p.Profile == "" {
 			p.Profile = DefaultProfileName
 		}
 		if p.Active == nil {
 			p.Active = boolPtr(true)
 		}
-		if p.Fragments <= 0 {
-			p.Fragments = DefaultFragments
-		}
-		if p.Order == "" {
-			p.Order = DefaultOrder
+		if p.Workers < 0 {
+			p.Workers = 0
 		}
-		if p.Quality == "" {
-			p.Quality = DefaultQuality
+		if p.Fragments < 0 {
+			p.Fragments = 0
 		}
-		if v, ok := parseJSRuntime(p.JSRuntime); ok {
+		if v, ok := parseOptionalJSRuntime(p.JSRuntime); ok {
 			p.JSRuntime = v
 		} else {
-			p.JSRuntime = DefaultJSRuntime
-		}
-		if p.SubLangs == "" {
-			p.SubLangs = DefaultSubtitleLanguage
+			p.JSRuntime = ""
 		}
 		if p.Name == "" || p.SourceURL == "" {
 			continue
 		}
 		normalized = append(normalized, p)
 	}
+	assigned := assignProjectIDs(normalized)
 	reg.Projects = normalized
-	return reg, nil
+	return reg, assigned, nil
 }
 
 func isProjectActive(p Project) bool {
@@ -440,12 +496,20 @@ func boolPtr(v bool) *bool {
 	return &b
 }
 
+func copyBoolPtr(v *bool) *bool {
+	if v == nil {
+		return nil
+	}
+	return boolPtr(*v)
+}
+
 func saveProjectRegistry(path string, reg ProjectRegistry) error {
 	reg.SchemaVersion = projectSchemaVersion
 	if strings.TrimSpace(reg.UpdatedAt) == "" {
 		reg.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
 	}
 	reg.Global = normalizeGlobalSettings(reg.Global)
+	reg.Profiles = normalizeProfiles(reg.Profiles)
 	if reg.Projects == nil {
 		reg.Projects = []Project{}
 	}
@@ -475,6 +539,40 @@ func parseJSRuntime(raw string) (string, bool) {
 	return strings.Join(runtimes, ","), true
 }
 
+// parseOptionalJSRuntime is parseJSRuntime that keeps an empty value unset.
+func parseOptionalJSRuntime(raw string) (string, bool) {
+	if strings.TrimSpace(raw) == "" {
+		return "", true
+	}
+	return parseJSRuntime(raw)
+}
+
+func canonicalQuality(raw string) string {
+	if v, ok := ytdlp.NormalizeQuality(raw); ok && strings.TrimSpace(raw) != "" {
+		return v
+	}
+	return strings.TrimSpace(raw)
+}
+
+func canonicalFormatValue(raw string) string {
+	v := strings.ToLower(strings.TrimSpace(raw))
+	if v == "auto" {
+		return ""
+	}
+	return v
+}
+
+func parseLiveChatFormat(raw string) (string, bool) {
+	switch v := strings.ToLower(strings.TrimSpace(raw)); v {
+	case "":
+		return "", true
+	case LiveChatFormatText, LiveChatFormatASS:
+		return v, true
+	default:
+		return "", false
+	}
+}
+
 func parseJSRuntimeList(raw string) ([]string, bool) {
 	s := strings.TrimSpace(raw)
 	if s == "" {
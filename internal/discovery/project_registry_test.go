@@ -22,9 +22,15 @@ func TestLoadProjectRegistryAppliesUserDefaults(t *testing.T) {
 	if len(reg.Projects) != 1 {
 		t.Fatalf("expected 1 project, got %d", len(reg.Projects))
 	}
-	p := reg.Projects[0]
-	if p.Workers != 0 {
-		t.Fatalf("workers override default mismatch: got %d want %d", p.Workers, 0)
+	if raw := reg.Projects[0]; raw.Fragments != 0 || raw.Quality != "" || raw.NoSubs != nil {
+		t.Fatalf("unset settings should stay unset in the registry: %+v", raw)
+	}
+	p, err := FindProjectByName(cfg, "demo")
+	if err != nil {
+		t.Fatalf("find project failed: %v", err)
+	}
+	if p.Workers != DefaultWorkers {
+		t.Fatalf("workers default mismatch: got %d want %d", p.Workers, DefaultWorkers)
 	}
 	if p.Fragments != DefaultFragments {
 		t.Fatalf("fragments default mismatch: got %d want %d", p.Fragments, DefaultFragments)
@@ -113,3 +119,72 @@ func TestAddProjectAcceptsJSRuntimeFallbackChain(t *testing.T) {
 		t.Fatalf("unexpected normalized chain: %q", res.Project.JSRuntime)
 	}
 }
+
+func TestAddProjectRejectsInvalidQuality(t *testing.T) {
+	tmp := t.TempDir()
+	cfg := tmp + "/projects.json"
+
+	_, err := AddProject(AddProjectOptions{
+		ConfigPath: cfg,
+		Name:       "bad-quality",
+		SourceURL:  "https://example.com/src",
+		Quality:    "8k",
+	})
+	if err == nil {
+		t.Fatal("expected invalid quality error")
+	}
+}
+
+func TestAddProjectCanonicalizesFormatSettings(t *testing.T) {
+	tmp := t.TempDir()
+	cfg := tmp + "/projects.json"
+
+	res, err := AddProject(AddProjectOptions{
+		ConfigPath: cfg,
+		Name:       "formats",
+		SourceURL:  "https://example.com/src",
+		Quality:    "4K",
+		VideoCodec: "AV1,vp9",
+		HDR:        "off",
+		Container:  "MKV",
+	})
+	if err != nil {
+		t.Fatalf("add project failed: %v", err)
+	}
+	p := res.Project
+	if p.Quality != "2160p" || p.VideoCodec != "av1,vp9" || p.HDR != "off" || p.Container != "mkv" {
+		t.Fatalf("unexpected format settings: %+v", p)
+	}
+}
+
+func TestAddProjectValidatesAndKeepsStorageTarget(t *testing.T) {
+	tmp := t.TempDir()
+	cfg := tmp + "/projects.json"
+
+	_, err := AddProject(AddProjectOptions{
+		ConfigPath: cfg,
+		Name:       "no-bucket",
+		SourceURL:  "https://example.com/a",
+		Storage:    &StorageTarget{Endpoint: "http://minio.local:9000"},
+	})
+	if err == nil {
+		t.Fatal("expected storage target without bucket to be rejected")
+	}
+
+	_, err = AddProject(AddProjectOptions{
+		ConfigPath: cfg,
+		Name:       "remote",
+		SourceURL:  "https://example.com/b",
+		Storage:    &StorageTarget{Endpoint: " http://minio.local:9000 ", Bucket: "vods", Prefix: "/remote/", DeleteLocal: true},
+	})
+	if err != nil {
+		t.Fatalf("add project failed: %v", err)
+	}
+	p, err := FindProjectByName(cfg, "remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Storage == nil || p.Storage.Endpoint != "http://minio.local:9000" || p.Storage.Prefix != "remote" || !p.Storage.DeleteLocal {
+		t.Fatalf("unexpected storage target: %+v", p.Storage)
+	}
+}
@@ -0,0 +1,161 @@
+package discovery
+
+import (
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	SettingFromProject = "project"
+	SettingFromProfile = "profile"
+	SettingFromGlobal  = "global"
+	SettingFromDefault = "default"
+)
+
+// SettingSource is one effective project setting and the layer it came from.
+type SettingSource struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// settingDefaultLabels describe built-in defaults that are empty values.
+var settingDefaultLabels = map[string]string{
+	"video_codec":   "auto",
+	"hdr":           "auto",
+	"container":     "auto",
+	"delivery_mode": "auto",
+	"output_dir":    "<run dir>/downloads",
+}
+
+// EffectiveProject fills every unset project setting from the project's
+// profile, the global settings, then the built-in defaults.
+func EffectiveProject(p Project, global GlobalSettings, profiles []ProjectProfile) Project {
+	out, _ := ResolveProjectSettings(p, global, profiles)
+	return out
+}
+
+// ResolveProjectSettings returns the effective project and, per setting, the
+// layer its value came from. A value is unset when it is empty (or nil for
+// booleans) in a layer. A profile the registry does not define is skipped.
+func ResolveProjectSettings(p Project, global GlobalSettings, profiles []ProjectProfile) (Project, []SettingSource) {
+	profile, _ := findProfile(profiles, p.Profile)
+	ps, gs := profile.DownloadSettings, global.DownloadSettings
+	r := settingResolver{}
+	out := p
+	out.Workers = r.num("workers", DefaultWorkers, p.Workers, 0, global.Workers)
+	out.Fragments = r.num("fragments", DefaultFragments, p.Fragments, ps.Fragments, gs.Fragments)
+	out.Order = r.str("order", DefaultOrder, p.Order, ps.Order, gs.Order)
+	out.Quality = r.str("quality", DefaultQuality, p.Quality, ps.Quality, gs.Quality)
+	out.VideoCodec = r.str("video_codec", "", p.VideoCodec, ps.VideoCodec, gs.VideoCodec)
+	out.HDR = r.str("hdr", "", p.HDR, ps.HDR, gs.HDR)
+	out.Container = r.str("container", "", p.Container, ps.Container, gs.Container)
+	out.JSRuntime = r.str("js_runtime", DefaultJSRuntime, p.JSRuntime, ps.JSRuntime, gs.JSRuntime)
+	out.DeliveryMode = r.str("delivery_mode", "", p.DeliveryMode, ps.DeliveryMode, gs.DeliveryMode)
+	out.NoSubs = r.flag("no_subs", false, p.NoSubs, ps.NoSubs, gs.NoSubs)
+	out.SubLangs = r.str("sub_langs", DefaultSubtitleLanguage, p.SubLangs, ps.SubLangs, gs.SubLangs)
+	out.LiveChat = r.flag("live_chat", false, p.LiveChat, ps.LiveChat, gs.LiveChat)
+	out.LiveChatFormat = r.str("live_chat_format", DefaultLiveChatFormat, p.LiveChatFormat, ps.LiveChatFormat, gs.LiveChatFormat)
+	out.Schedule = r.str("schedule", "", p.Schedule, ps.Schedule, gs.Schedule)
+	out.WriteThumbnails = r.flag("write_thumbnails", false, p.WriteThumbnails, ps.WriteThumbnails, gs.WriteThumbnails)
+	out.AudioOnly = r.flag("audio_only", false, p.AudioOnly, ps.AudioOnly, gs.AudioOnly)
+	out.CookiesPath = r.str("cookies_path", "", p.CookiesPath, "", global.CookiesPath)
+	out.CookiesFromBrowser = r.str("cookies_from_browser", "", p.CookiesFromBrowser, "", global.CookiesFromBrowser)
+
+	// Global output, storage and mirror settings are roots shared by all
+	// projects, so each project gets its own directory or key prefix there.
+	globalOutput := ""
+	if root := strings.TrimSpace(global.OutputRoot); root != "" && p.Name != "" {
+		globalOutput = filepath.Join(root, p.Name)
+	}
+	out.OutputDir = r.str("output_dir", "", p.OutputDir, "", globalOutput)
+
+	out.Storage = p.Storage
+	switch {
+	case p.Storage != nil:
+		r.add("storage", p.Storage.String(), SettingFromProject)
+	case global.Storage != nil && p.Name != "":
+		target := *global.Storage
+		target.Prefix = path.Join(target.Prefix, p.Name)
+		out.Storage = &target
+		r.add("storage", target.String(), SettingFromGlobal)
+	default:
+		r.add("storage", "", SettingFromDefault)
+	}
+
+	out.Mirrors = ResolveMirrors(p, global, "")
+	source := SettingFromDefault
+	switch {
+	case len(normalizeMirrorList(p.Mirrors)) > 0:
+		source = SettingFromProject
+	case len(out.Mirrors) > 0:
+		source = SettingFromGlobal
+	}
+	r.add("mirrors", strings.Join(out.Mirrors, ", "), source)
+	return out, r.out
+}
+
+// settingLayers are the layers a setting is looked up in, in order; the
+// resolver methods take one value per layer.
+var settingLayers = []string{SettingFromProject, SettingFromProfile, SettingFromGlobal}
+
+type settingResolver struct {
+	out []SettingSource
+}
+
+func (r *settingResolver) add(key, value, source string) {
+	if value == "" && source == SettingFromDefault {
+		value = defaultIfEmpty(settingDefaultLabels[key], "(none)")
+	}
+	r.out = append(r.out, SettingSource{Key: key, Value: value, Source: source})
+}
+
+func (r *settingResolver) str(key, def string, layers ...string) string {
+	for i, v := range layers {
+		if strings.TrimSpace(v) != "" {
+			r.add(key, v, settingLayers[i])
+			return v
+		}
+	}
+	r.add(key, def, SettingFromDefault)
+	return def
+}
+
+func (r *settingResolver) num(key string, def int, layers ...int) int {
+	for i, v := range layers {
+		if v > 0 {
+			r.add(key, strconv.Itoa(v), settingLayers[i])
+			return v
+		}
+	}
+	r.add(key, strconv.Itoa(def), SettingFromDefault)
+	return def
+}
+
+func (r *settingResolver) flag(key string, def bool, layers ...*bool) *bool {
+	for i, v := range layers {
+		if v != nil {
+			r.add(key, strconv.FormatBool(*v), settingLayers[i])
+			return boolPtr(*v)
+		}
+	}
+	r.add(key, strconv.FormatBool(def), SettingFromDefault)
+	return boolPtr(def)
+}
+
+// ExplainProjectSettings resolves the named project against its profile and
+// the global settings of configPath.
+func ExplainProjectSettings(configPath, name string) (Project, []SettingSource, error) {
+	reg, _, err := EnsureProjectRegistry(configPath)
+	if err != nil {
+		return Project{}, nil, err
+	}
+	p, err := findRegistryProject(reg, name)
+	if err != nil {
+		return Project{}, nil, err
+	}
+	effective, sources := ResolveProjectSettings(p, reg.Global, reg.Profiles)
+	return effective, sources, nil
+}
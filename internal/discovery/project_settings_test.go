@@ -0,0 +1,76 @@
+package discovery
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectsInheritGlobalSettings(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "projects.json")
+	if _, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: "plain", SourceURL: "https://example.com/a"}); err != nil {
+		t.Fatalf("add plain: %v", err)
+	}
+	if _, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: "pinned", SourceURL: "https://example.com/b", Quality: "1080p", NoSubs: boolPtr(false)}); err != nil {
+		t.Fatalf("add pinned: %v", err)
+	}
+	global, err := GetGlobalSettings(cfg)
+	if err != nil {
+		t.Fatalf("global: %v", err)
+	}
+	global.Quality = "720p"
+	global.NoSubs = boolPtr(true)
+	global.OutputRoot = "/srv/vods"
+	if _, err := UpdateGlobalSettings(UpdateGlobalSettingsOptions{ConfigPath: cfg, Global: global}); err != nil {
+		t.Fatalf("update global: %v", err)
+	}
+
+	plain, err := FindProjectByName(cfg, "plain")
+	if err != nil {
+		t.Fatalf("find plain: %v", err)
+	}
+	if plain.Quality != "720p" || !*plain.NoSubs || plain.OutputDir != filepath.Join("/srv/vods", "plain") {
+		t.Fatalf("plain should inherit global settings: %+v", plain)
+	}
+	if plain.Order != DefaultOrder || plain.Fragments != DefaultFragments {
+		t.Fatalf("plain should fall back to defaults: order=%q fragments=%d", plain.Order, plain.Fragments)
+	}
+	pinned, err := FindProjectByName(cfg, "pinned")
+	if err != nil {
+		t.Fatalf("find pinned: %v", err)
+	}
+	if pinned.Quality != "1080p" || *pinned.NoSubs {
+		t.Fatalf("explicit project values must win over global: quality=%q no_subs=%v", pinned.Quality, *pinned.NoSubs)
+	}
+
+	_, sources, err := ExplainProjectSettings(cfg, "pinned")
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	want := map[string]string{
+		"quality":    SettingFromProject,
+		"no_subs":    SettingFromProject,
+		"output_dir": SettingFromGlobal,
+		"order":      SettingFromDefault,
+	}
+	for _, s := range sources {
+		if w, ok := want[s.Key]; ok && s.Source != w {
+			t.Fatalf("%s: source %q, want %q", s.Key, s.Source, w)
+		}
+		delete(want, s.Key)
+	}
+	if len(want) > 0 {
+		t.Fatalf("missing settings in explain: %v", want)
+	}
+}
+
+func TestUpdateGlobalSettingsValidatesProjectDefaults(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "projects.json")
+	global, err := GetGlobalSettings(cfg)
+	if err != nil {
+		t.Fatalf("global: %v", err)
+	}
+	global.Quality = "999p"
+	if _, err := UpdateGlobalSettings(UpdateGlobalSettingsOptions{ConfigPath: cfg, Global: global}); err == nil {
+		t.Fatal("expected invalid global quality to be rejected")
+	}
+}
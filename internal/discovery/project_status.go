@@ -21,6 +21,8 @@ type ProjectStatusResult struct {
 	ConfigPath string              `json:"config_path"`
 	Rows       []ProjectStatusItem `json:"projects"`
 	Totals     ProjectStatusTotals `json:"totals"`
+	// Sync is set while a sync holds the workspace lock.
+	Sync *SyncLockInfo `json:"sync,omitempty"`
 }
 
 type ProjectStatusItem struct {
@@ -40,6 +42,26 @@ type ProjectStatusItem struct {
 	FailedPermanent int    `json:"permanent_failure_count"`
 	SkippedPrivate  int    `json:"skipped_private_count"`
 	Remaining       int    `json:"remaining"`
+	// RemovedUpstream counts tombstoned jobs; RemovedSaved those of them we
+	// still hold locally.
+	RemovedUpstream int            `json:"removed_upstream_count"`
+	RemovedSaved    int            `json:"removed_saved_count"`
+	RemovedVideos   []RemovedVideo `json:"removed_videos,omitempty"`
+	// MirrorLag counts completed jobs not yet verified on every mirror;
+	// MirrorLagSince is the oldest completion among them.
+	Mirrors        []string `json:"mirrors,omitempty"`
+	MirrorLag      int      `json:"mirror_lag_count"`
+	MirrorFailed   int      `json:"mirror_failed_count"`
+	MirrorLagSince string   `json:"mirror_lag_since,omitempty"`
+}
+
+// RemovedVideo is a video that is no longer listed by its source.
+type RemovedVideo struct {
+	VideoID   string `json:"video_id"`
+	Title     string `json:"title,omitempty"`
+	RemovedAt string `json:"removed_at,omitempty"`
+	LocalCopy bool   `json:"local_copy"`
+	MediaPath string `json:"media_path,omitempty"`
 }
 
 type ProjectStatusTotals struct {
@@ -53,6 +75,9 @@ type ProjectStatusTotals struct {
 	Running         int `json:"running_count"`
 	FailedRetryable int `json:"retryable_failure_count"`
 	FailedPermanent int `json:"permanent_failure_count"`
+	RemovedUpstream int `json:"removed_upstream_count"`
+	RemovedSaved    int `json:"removed_saved_count"`
+	MirrorLag       int `json:"mirror_lag_count"`
 }
 
 func ProjectStatus(opts ProjectStatusOptions) (ProjectStatusResult, error) {
@@ -67,11 +92,16 @@ func ProjectStatus(opts ProjectStatusOptions) (ProjectStatusResult, error) {
 		return ProjectStatusResult{}, err
 	}
 
+	global, err := ReadGlobalSettings(configPath)
+	if err != nil {
+		return ProjectStatusResult{}, err
+	}
+
 	rows := make([]ProjectStatusItem, 0, len(projects))
 	totals := ProjectStatusTotals{}
 
 	for _, p := range projects {
-		row, err := buildProjectStatusRow(runsDir, p)
+		row, err := buildProjectStatusRow(runsDir, p, ResolveMirrors(p, global, ""))
 		if err != nil {
 			return ProjectStatusResult{}, err
 		}
@@ -83,6 +113,9 @@ func ProjectStatus(opts ProjectStatusOptions) (ProjectStatusResult, error) {
 		totals.Running += row.Running
 		totals.FailedRetryable += row.FailedRetryable
 		totals.FailedPermanent += row.FailedPermanent
+		totals.RemovedUpstream += row.RemovedUpstream
+		totals.RemovedSaved += row.RemovedSaved
+		totals.MirrorLag += row.MirrorLag
 		switch row.State {
 		case "healthy":
 			totals.Healthy++
@@ -97,11 +130,15 @@ func ProjectStatus(opts ProjectStatusOptions) (ProjectStatusResult, error) {
 		return rows[i].Project < rows[j].Project
 	})
 
-	return ProjectStatusResult{
+	res := ProjectStatusResult{
 		ConfigPath: configPath,
 		Rows:       rows,
 		Totals:     totals,
-	}, nil
+	}
+	if info, err := InspectSyncLock(runsDir, 0); err == nil && info.Locked {
+		res.Sync = &info
+	}
+	return res, nil
 }
 
 func ResolveRunDirForProject(configPath, projectName, runsDir string) (string, Project, error) {
@@ -113,7 +150,7 @@ func ResolveRunDirForProject(configPath, projectName, runsDir string) (string, P
 	if baseRuns == "" {
 		baseRuns = "runs"
 	}
-	runDir, err := latestRunDirBySource(baseRuns, project.SourceURL)
+	runDir, err := latestProjectRunDir(baseRuns, project)
 	if err != nil {
 		return "", Project{}, err
 	}
@@ -123,15 +160,42 @@ func ResolveRunDirForProject(configPath, projectName, runsDir string) (string, P
 	return runDir, project, nil
 }
 
-func buildProjectStatusRow(runsDir string, project Project) (ProjectStatusItem, error) {
+// ResolveOrCreateRunDirForProject is ResolveRunDirForProject, except that a
+// project with no run yet gets an empty one (see CreateEmptyRun), so files
+// can be imported before the first sync.
+func ResolveOrCreateRunDirForProject(configPath, projectName, runsDir string) (string, Project, bool, error) {
+	project, err := FindProjectByName(configPath, projectName)
+	if err != nil {
+		return "", Project{}, false, err
+	}
+	baseRuns := strings.TrimSpace(runsDir)
+	if baseRuns == "" {
+		baseRuns = "runs"
+	}
+	runDir, err := latestProjectRunDir(baseRuns, project)
+	if err != nil {
+		return "", Project{}, false, err
+	}
+	if runDir != "" {
+		return runDir, project, false, nil
+	}
+	runDir, err = CreateEmptyRun(baseRuns, project.ID, project.SourceURL, project.Profile)
+	if err != nil {
+		return "", Project{}, false, err
+	}
+	return runDir, project, true, nil
+}
+
+func buildProjectStatusRow(runsDir string, project Project, mirrors []string) (ProjectStatusItem, error) {
 	row := ProjectStatusItem{
 		Project:   project.Name,
 		SourceURL: project.SourceURL,
 		JSRuntime: projectJSRuntime(project),
 		State:     "never_synced",
+		Mirrors:   mirrors,
 	}
 
-	runDir, err := latestRunDirBySource(runsDir, project.SourceURL)
+	runDir, err := latestProjectRunDir(runsDir, project)
 	if err != nil {
 		return ProjectStatusItem{}, err
 	}
@@ -152,7 +216,7 @@ func buildProjectStatusRow(runsDir string, project Project) (ProjectStatusItem,
 	}
 
 	var mf model.JobsManifest
-	if err := runstore.ReadJSON(filepath.Join(runDir, "manifest.jobs.json"), &mf); err != nil {
+	if err := runstore.ReadJobsManifest(filepath.Join(runDir, "manifest.jobs.json"), &mf); err != nil {
 		if metaErr != nil {
 			return ProjectStatusItem{}, err
 		}
@@ -172,6 +236,23 @@ func buildProjectStatusRow(runsDir string, project Project) (ProjectStatusItem,
 	row.FailedPermanent = mf.FailedPermanent
 	row.SkippedPrivate = mf.SkippedPrivate
 	row.Remaining = mf.Pending + mf.Running + mf.FailedRetryable
+	for _, j := range mf.Jobs {
+		countMirrorLag(&row, j)
+		if !j.RemovedUpstream {
+			continue
+		}
+		row.RemovedUpstream++
+		if j.LocalCopy {
+			row.RemovedSaved++
+		}
+		row.RemovedVideos = append(row.RemovedVideos, RemovedVideo{
+			VideoID:   j.VideoID,
+			Title:     j.Title,
+			RemovedAt: j.RemovedAt,
+			LocalCopy: j.LocalCopy,
+			MediaPath: j.MediaPath,
+		})
+	}
 	if strings.TrimSpace(row.SourceTitle) == "" {
 		row.SourceTitle = strings.TrimSpace(mf.SourceTitle)
 	}
@@ -182,6 +263,37 @@ func buildProjectStatusRow(runsDir string, project Project) (ProjectStatusItem,
 	return row, nil
 }
 
+func countMirrorLag(row *ProjectStatusItem, j model.Job) {
+	if len(row.Mirrors) == 0 || j.Status != model.StatusCompleted || (j.RemovedUpstream && !j.LocalCopy) || j.HeldElsewhere() {
+		return
+	}
+	if j.Replicated(row.Mirrors) {
+		return
+	}
+	row.MirrorLag++
+	for _, r := range j.Replicas {
+		if r.Status == model.ReplicaFailed {
+			row.MirrorFailed++
+			break
+		}
+	}
+	if j.CompletedAt != "" && (row.MirrorLagSince == "" || j.CompletedAt < row.MirrorLagSince) {
+		row.MirrorLagSince = j.CompletedAt
+	}
+}
+
+// StatusCounts keys the row's job counts by model status.
+func (row ProjectStatusItem) StatusCounts() map[string]int {
+	return map[string]int{
+		model.StatusCompleted:       row.Completed,
+		model.StatusPending:         row.Pending,
+		model.StatusRunning:         row.Running,
+		model.StatusFailedRetryable: row.FailedRetryable,
+		model.StatusFailedPermanent: row.FailedPermanent,
+		model.StatusSkippedPrivate:  row.SkippedPrivate,
+	}
+}
+
 func projectJSRuntime(project Project) string {
 	v, ok := parseJSRuntime(project.JSRuntime)
 	if !ok {
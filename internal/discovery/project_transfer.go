@@ -0,0 +1,559 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ProjectsFormatTakeout is subscriptions.csv from a YouTube Takeout.
+	ProjectsFormatTakeout = "takeout"
+	ProjectsFormatOPML    = "opml"
+	// ProjectsFormatFetchlist is one "Name|URL" or "URL" per line.
+	ProjectsFormatFetchlist = "fetchlist"
+	// ProjectsFormatCSV is the workspace CSV written by ExportProjects: one
+	// row per project with its portable settings.
+	ProjectsFormatCSV = "csv"
+
+	ProjectImportCreated = "created"
+	ProjectImportExists  = "exists"
+	ProjectImportFailed  = "failed"
+)
+
+// projectCSVColumns are the settings a workspace CSV carries. Cookies and
+// storage targets are left out: they point at files and credentials on the
+// machine the workspace came from. The id keeps copied runs attached.
+var projectCSVColumns = []string{
+	"name", "source_url", "active", "profile", "output_dir", "workers", "fragments",
+	"order", "quality", "video_codec", "hdr", "container", "js_runtime",
+	"delivery_mode", "no_subs", "sub_langs", "live_chat", "live_chat_format",
+	"schedule", "mirrors", "id", "write_thumbnails", "audio_only",
+}
+
+// ProjectEntry is one source read from an import file. Settings is only set
+// for workspace CSV rows.
+type ProjectEntry struct {
+	Name      string
+	SourceURL string
+	Settings  *AddProjectOptions
+}
+
+type ImportProjectsOptions struct {
+	ConfigPath string
+	Path       string
+	// Format is detected from the file when empty.
+	Format string
+	// Template holds the settings each new project starts from; its
+	// ConfigPath, Name and SourceURL are ignored.
+	Template AddProjectOptions
+	DryRun   bool
+}
+
+type ImportProjectsItem struct {
+	Name      string `json:"name"`
+	SourceURL string `json:"source_url"`
+	Outcome   string `json:"outcome"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ImportProjectsResult struct {
+	ConfigPath string               `json:"config_path"`
+	Format     string               `json:"format"`
+	DryRun     bool                 `json:"dry_run"`
+	Read       int                  `json:"read"`
+	Created    int                  `json:"created"`
+	Exists     int                  `json:"exists"`
+	Failed     int                  `json:"failed"`
+	Items      []ImportProjectsItem `json:"items"`
+}
+
+// DefaultProjectTemplate is what `add` would use without flags: every
+// setting is left to inherit the global settings.
+func DefaultProjectTemplate() AddProjectOptions {
+	return AddProjectOptions{
+		Profile: DefaultProfileName,
+		Active:  boolPtr(true),
+	}
+}
+
+// ImportProjects adds a project for every source in the file that no project
+// tracks yet, through AddProject. Names come from the file (channel titles,
+// fetchlist aliases) or the URL, made unique against the registry.
+func ImportProjects(opts ImportProjectsOptions) (ImportProjectsResult, error) {
+	configPath := normalizeConfigPath(opts.ConfigPath)
+	entries, format, err := ReadProjectsFile(opts.Path, opts.Format)
+	if err != nil {
+		return ImportProjectsResult{}, err
+	}
+	reg, err := loadProjectRegistry(configPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return ImportProjectsResult{}, err
+	}
+
+	res := ImportProjectsResult{
+		ConfigPath: configPath,
+		Format:     format,
+		DryRun:     opts.DryRun,
+		Read:       len(entries),
+		Items:      []ImportProjectsItem{},
+	}
+	known := append([]Project(nil), reg.Projects...)
+	for _, e := range entries {
+		item := ImportProjectsItem{Name: e.Name, SourceURL: e.SourceURL}
+		if p, ok := projectBySource(known, e.SourceURL); ok {
+			item.Name = p.Name
+			item.Outcome = ProjectImportExists
+			res.Exists++
+			res.Items = append(res.Items, item)
+			continue
+		}
+		add := opts.Template
+		if e.Settings != nil {
+			add = *e.Settings
+		}
+		add.ConfigPath = configPath
+		add.SourceURL = e.SourceURL
+		add.ReplaceIfNameExists = false
+		base := e.Name
+		if canonicalProjectName(base) == "" {
+			base = suggestProjectName(e.SourceURL)
+		}
+		add.Name = ensureUniqueProjectName(base, known, false)
+		item.Name = add.Name
+
+		if !opts.DryRun {
+			added, err := AddProject(add)
+			if err != nil {
+				item.Outcome = ProjectImportFailed
+				item.Error = err.Error()
+				res.Failed++
+				res.Items = append(res.Items, item)
+				continue
+			}
+			item.Name = added.Project.Name
+		}
+		known = append(known, Project{Name: item.Name, SourceURL: e.SourceURL})
+		item.Outcome = ProjectImportCreated
+		res.Created++
+		res.Items = append(res.Items, item)
+	}
+	return res, nil
+}
+
+func projectBySource(projects []Project, sourceURL string) (Project, bool) {
+	target := normalizeSourceURL(sourceURL)
+	for _, p := range projects {
+		if normalizeSourceURL(p.SourceURL) == target {
+			return p, true
+		}
+	}
+	return Project{}, false
+}
+
+// ReadProjectsFile parses a Takeout subscriptions.csv, an OPML file, a
+// fetchlist or a workspace CSV, and returns the format it read.
+func ReadProjectsFile(path, format string) ([]ProjectEntry, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("read %s: %w", path, err)
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = detectProjectsFormat(path, data)
+	}
+	var entries []ProjectEntry
+	switch format {
+	case ProjectsFormatTakeout:
+		entries, err = parseTakeoutCSV(data)
+	case ProjectsFormatCSV:
+		entries, err = parseProjectsCSV(data)
+	case ProjectsFormatOPML:
+		entries, err = parseOPML(data)
+	case ProjectsFormatFetchlist:
+		entries, err = parseFetchlist(bytes.NewReader(data))
+	default:
+		return nil, "", fmt.Errorf("unknown import format %q (expected takeout, opml, fetchlist, or csv)", format)
+	}
+	if err != nil {
+		return nil, format, fmt.Errorf("parse %s as %s: %w", path, format, err)
+	}
+	return dedupeEntries(entries), format, nil
+}
+
+// ReadFetchlist reads a fetchlist: one source URL per line, optionally
+// prefixed with "Name|". Blank lines and # comments are skipped.
+func ReadFetchlist(path string) ([]ProjectEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open fetchlist %s: %w", path, err)
+	}
+	defer f.Close()
+	entries, err := parseFetchlist(f)
+	if err != nil {
+		return nil, fmt.Errorf("read fetchlist %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+func detectProjectsFormat(path string, data []byte) string {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".opml", ".xml":
+		return ProjectsFormatOPML
+	case ".csv":
+		firstLine, _, _ := bytes.Cut(trimmed, []byte("\n"))
+		if bytes.Contains(bytes.ToLower(firstLine), []byte("source_url")) {
+			return ProjectsFormatCSV
+		}
+		return ProjectsFormatTakeout
+	}
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		return ProjectsFormatOPML
+	}
+	return ProjectsFormatFetchlist
+}
+
+func parseFetchlist(r io.Reader) ([]ProjectEntry, error) {
+	entries := make([]ProjectEntry, 0)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e := ProjectEntry{SourceURL: line}
+		if name, src, ok := strings.Cut(line, "|"); ok {
+			e = ProjectEntry{Name: strings.TrimSpace(name), SourceURL: strings.TrimSpace(src)}
+		}
+		if e.SourceURL != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
+
+// readCSVTable returns the rows of a CSV file with a header, keyed by
+// lower-cased column name.
+func readCSVTable(data []byte) ([]map[string]string, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := make([]string, len(records[0]))
+	for i, h := range records[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(h))
+	}
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, v := range rec {
+			if i < len(header) {
+				row[header[i]] = strings.TrimSpace(v)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseTakeoutCSV reads Takeout's "Channel Id,Channel Url,Channel Title".
+func parseTakeoutCSV(data []byte) ([]ProjectEntry, error) {
+	rows, err := readCSVTable(data)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]ProjectEntry, 0, len(rows))
+	for _, row := range rows {
+		src := row["channel url"]
+		if src == "" && row["channel id"] != "" {
+			src = "https://www.youtube.com/channel/" + row["channel id"]
+		}
+		if src == "" {
+			continue
+		}
+		entries = append(entries, ProjectEntry{Name: row["channel title"], SourceURL: channelVideosURL(src)})
+	}
+	return entries, nil
+}
+
+func parseProjectsCSV(data []byte) ([]ProjectEntry, error) {
+	rows, err := readCSVTable(data)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]ProjectEntry, 0, len(rows))
+	for i, row := range rows {
+		if row["source_url"] == "" {
+			continue
+		}
+		opts, err := projectOptionsFromCSV(row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+		entries = append(entries, ProjectEntry{Name: row["name"], SourceURL: row["source_url"], Settings: &opts})
+	}
+	return entries, nil
+}
+
+func projectOptionsFromCSV(row map[string]string) (AddProjectOptions, error) {
+	opts := AddProjectOptions{
+		ID:             row["id"],
+		Profile:        row["profile"],
+		OutputDir:      row["output_dir"],
+		Order:          row["order"],
+		Quality:        row["quality"],
+		VideoCodec:     row["video_codec"],
+		HDR:            row["hdr"],
+		Container:      row["container"],
+		JSRuntime:      row["js_runtime"],
+		DeliveryMode:   row["delivery_mode"],
+		SubLangs:       row["sub_langs"],
+		LiveChatFormat: row["live_chat_format"],
+		Schedule:       row["schedule"],
+	}
+	if v := row["mirrors"]; v != "" {
+		opts.Mirrors = strings.Split(v, ";")
+	}
+	var err error
+	for col, dst := range map[string]*int{"workers": &opts.Workers, "fragments": &opts.Fragments} {
+		if v := row[col]; v != "" {
+			if *dst, err = strconv.Atoi(v); err != nil {
+				return opts, fmt.Errorf("%s must be a number", col)
+			}
+		}
+	}
+	for col, dst := range map[string]**bool{
+		"no_subs":          &opts.NoSubs,
+		"live_chat":        &opts.LiveChat,
+		"write_thumbnails": &opts.WriteThumbnails,
+		"audio_only":       &opts.AudioOnly,
+	} {
+		if v := row[col]; v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return opts, fmt.Errorf("%s must be true or false", col)
+			}
+			*dst = boolPtr(b)
+		}
+	}
+	if v := row["active"]; v != "" {
+		active, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("active must be true or false")
+		}
+		opts.Active = boolPtr(active)
+	}
+	return opts, nil
+}
+
+type opmlDoc struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title string `xml:"title"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []opmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// parseOPML reads feed outlines at any depth. YouTube feed URLs are turned
+// back into channel or playlist URLs; htmlUrl wins when present.
+func parseOPML(data []byte) ([]ProjectEntry, error) {
+	var doc opmlDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	entries := make([]ProjectEntry, 0)
+	var walk func([]opmlOutline)
+	walk = func(outlines []opmlOutline) {
+		for _, o := range outlines {
+			src := strings.TrimSpace(o.HTMLURL)
+			if src == "" {
+				src = sourceFromFeedURL(o.XMLURL)
+			}
+			if src != "" {
+				entries = append(entries, ProjectEntry{Name: firstNonEmpty(o.Title, o.Text), SourceURL: channelVideosURL(src)})
+			}
+			walk(o.Outlines)
+		}
+	}
+	walk(doc.Body.Outlines)
+	return entries, nil
+}
+
+func sourceFromFeedURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || !strings.HasSuffix(u.Host, "youtube.com") || u.Path != "/feeds/videos.xml" {
+		return ""
+	}
+	q := u.Query()
+	switch {
+	case q.Get("channel_id") != "":
+		return "https://www.youtube.com/channel/" + q.Get("channel_id")
+	case q.Get("playlist_id") != "":
+		return "https://www.youtube.com/playlist?list=" + q.Get("playlist_id")
+	case q.Get("user") != "":
+		return "https://www.youtube.com/user/" + q.Get("user")
+	}
+	return ""
+}
+
+func feedURLForSource(sourceURL string) string {
+	u, err := url.Parse(strings.TrimSpace(sourceURL))
+	if err != nil || !strings.HasSuffix(u.Host, "youtube.com") {
+		return ""
+	}
+	if list := u.Query().Get("list"); list != "" {
+		return "https://www.youtube.com/feeds/videos.xml?playlist_id=" + url.QueryEscape(list)
+	}
+	if m := channelRootPattern.FindStringSubmatch(u.Path); m != nil && m[1] == "channel" {
+		return "https://www.youtube.com/feeds/videos.xml?channel_id=" + url.QueryEscape(m[2])
+	}
+	return ""
+}
+
+var channelRootPattern = regexp.MustCompile(`^/(channel|c|user)/([^/]+)(/videos)?/?$|^/(@[^/]+)(/videos)?/?$`)
+
+// channelVideosURL points a bare YouTube channel URL at its videos tab; the
+// channel root lists tabs rather than videos. Other URLs are kept as they are.
+func channelVideosURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || !strings.HasSuffix(u.Host, "youtube.com") || u.RawQuery != "" {
+		return strings.TrimSpace(raw)
+	}
+	if channelRootPattern.MatchString(u.Path) && !strings.HasSuffix(strings.TrimSuffix(u.Path, "/"), "/videos") {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/videos"
+	}
+	u.Scheme = "https"
+	return u.String()
+}
+
+func dedupeEntries(entries []ProjectEntry) []ProjectEntry {
+	seen := make(map[string]bool, len(entries))
+	out := make([]ProjectEntry, 0, len(entries))
+	for _, e := range entries {
+		key := normalizeSourceURL(e.SourceURL)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, e)
+	}
+	return out
+}
+
+// ExportProjects writes every configured project as OPML or a workspace CSV
+// and returns how many it wrote.
+func ExportProjects(w io.Writer, configPath, format string) (int, error) {
+	reg, err := LoadProjects(configPath)
+	if err != nil {
+		return 0, err
+	}
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case ProjectsFormatOPML, "":
+		return len(reg.Projects), writeProjectsOPML(w, reg.Projects)
+	case ProjectsFormatCSV:
+		return len(reg.Projects), writeProjectsCSV(w, reg.Projects)
+	default:
+		return 0, fmt.Errorf("unknown export format %q (expected opml or csv)", format)
+	}
+}
+
+func writeProjectsOPML(w io.Writer, projects []Project) error {
+	doc := opmlDoc{Version: "1.1"}
+	doc.Head.Title = "yt-vod-manager projects"
+	group := opmlOutline{Text: "yt-vod-manager projects", Title: "yt-vod-manager projects"}
+	for _, p := range projects {
+		o := opmlOutline{Text: p.Name, Title: p.Name, HTMLURL: p.SourceURL}
+		// Handle URLs have no feed without resolving the channel ID.
+		if feed := feedURLForSource(p.SourceURL); feed != "" {
+			o.Type = "rss"
+			o.XMLURL = feed
+		}
+		group.Outlines = append(group.Outlines, o)
+	}
+	doc.Body.Outlines = []opmlOutline{group}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func writeProjectsCSV(w io.Writer, projects []Project) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(projectCSVColumns); err != nil {
+		return err
+	}
+	for _, p := range projects {
+		if err := cw.Write([]string{
+			p.Name,
+			p.SourceURL,
+			strconv.FormatBool(isProjectActive(p)),
+			p.Profile,
+			p.OutputDir,
+			strconv.Itoa(p.Workers),
+			strconv.Itoa(p.Fragments),
+			p.Order,
+			p.Quality,
+			p.VideoCodec,
+			p.HDR,
+			p.Container,
+			p.JSRuntime,
+			p.DeliveryMode,
+			formatOptionalBool(p.NoSubs),
+			p.SubLangs,
+			formatOptionalBool(p.LiveChat),
+			p.LiveChatFormat,
+			p.Schedule,
+			strings.Join(p.Mirrors, ";"),
+			p.ID,
+			formatOptionalBool(p.WriteThumbnails),
+			formatOptionalBool(p.AudioOnly),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatOptionalBool leaves unset values empty so they keep inheriting.
+func formatOptionalBool(v *bool) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatBool(*v)
+}
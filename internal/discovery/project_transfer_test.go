@@ -0,0 +1,145 @@
+package discovery
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestImportProjectsFromTakeoutCSV(t *testing.T) {
+	tmp := t.TempDir()
+	cfg := filepath.Join(tmp, "projects.json")
+	if _, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: "tracked", SourceURL: "https://www.youtube.com/channel/UCtracked/videos"}); err != nil {
+		t.Fatal(err)
+	}
+	takeout := filepath.Join(tmp, "subscriptions.csv")
+	data := "\xef\xbb\xbfChannel Id,Channel Url,Channel Title\n" +
+		"UCaaa,http://www.youtube.com/channel/UCaaa,Some Talks\n" +
+		"UCtracked,http://www.youtube.com/channel/UCtracked,Tracked Already\n" +
+		"UCbbb,http://www.youtube.com/channel/UCbbb,Tracked\n" +
+		"UCaaa,http://www.youtube.com/channel/UCaaa,Some Talks\n"
+	if err := os.WriteFile(takeout, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	template := DefaultProjectTemplate()
+	template.Quality = "1080p"
+	template.Active = boolPtr(false)
+
+	plan, err := ImportProjects(ImportProjectsOptions{ConfigPath: cfg, Path: takeout, Template: template, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Format != ProjectsFormatTakeout || plan.Read != 3 || plan.Created != 2 || plan.Exists != 1 {
+		t.Fatalf("unexpected dry-run plan: %+v", plan)
+	}
+	if reg, _ := LoadProjects(cfg); len(reg.Projects) != 1 {
+		t.Fatalf("dry run must not add projects, got %d", len(reg.Projects))
+	}
+
+	res, err := ImportProjects(ImportProjectsOptions{ConfigPath: cfg, Path: takeout, Template: template})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 2 || res.Failed != 0 || !reflect.DeepEqual(plan.Items, res.Items) {
+		t.Fatalf("expected the import to match its plan, got %+v vs %+v", res.Items, plan.Items)
+	}
+	talks, err := FindProjectByName(cfg, "some-talks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if talks.SourceURL != "https://www.youtube.com/channel/UCaaa/videos" || talks.Quality != "1080p" || isProjectActive(talks) {
+		t.Fatalf("unexpected imported project: %+v", talks)
+	}
+	// The channel titled "Tracked" must not clobber the existing project.
+	if p, err := FindProjectByName(cfg, "tracked-2"); err != nil || p.SourceURL != "https://www.youtube.com/channel/UCbbb/videos" {
+		t.Fatalf("expected a unique name for a clashing title, got %+v: %v", p, err)
+	}
+
+	again, err := ImportProjects(ImportProjectsOptions{ConfigPath: cfg, Path: takeout, Template: template})
+	if err != nil || again.Created != 0 || again.Exists != 3 {
+		t.Fatalf("expected a second import to add nothing, got %+v: %v", again, err)
+	}
+}
+
+func TestReadProjectsFileOPMLAndFetchlist(t *testing.T) {
+	tmp := t.TempDir()
+	opml := filepath.Join(tmp, "subs.opml")
+	data := `<?xml version="1.0"?>
+<opml version="1.1"><body><outline text="YouTube Subscriptions" title="YouTube Subscriptions">
+<outline text="Chan A" title="Chan A" type="rss" xmlUrl="https://www.youtube.com/feeds/videos.xml?channel_id=UCaaa"/>
+<outline text="List B" type="rss" xmlUrl="https://www.youtube.com/feeds/videos.xml?playlist_id=PLbbb"/>
+<outline text="Blog" type="rss" xmlUrl="https://example.com/feed.xml"/>
+</outline></body></opml>`
+	if err := os.WriteFile(opml, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entries, format, err := ReadProjectsFile(opml, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ProjectEntry{
+		{Name: "Chan A", SourceURL: "https://www.youtube.com/channel/UCaaa/videos"},
+		{Name: "List B", SourceURL: "https://www.youtube.com/playlist?list=PLbbb"},
+	}
+	if format != ProjectsFormatOPML || !reflect.DeepEqual(entries, want) {
+		t.Fatalf("unexpected OPML entries (%s): %+v", format, entries)
+	}
+
+	entries, format, err = ReadProjectsFile("../../data/fetchlist.example.txt", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != ProjectsFormatFetchlist || len(entries) != 2 || entries[0].Name != "Example Channel" || entries[1].Name != "" {
+		t.Fatalf("unexpected fetchlist entries (%s): %+v", format, entries)
+	}
+}
+
+func TestExportProjectsRoundTripsThroughImport(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "src.json")
+	if _, err := AddProject(AddProjectOptions{ConfigPath: src, Name: "talks", SourceURL: "https://www.youtube.com/@talks/videos", Quality: "720p", NoSubs: boolPtr(true), Schedule: "6h", Mirrors: []string{"/mnt/a", "/mnt/b"}, Active: boolPtr(false)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddProject(AddProjectOptions{ConfigPath: src, Name: "list", SourceURL: "https://www.youtube.com/playlist?list=PLx"}); err != nil {
+		t.Fatal(err)
+	}
+	srcReg, err := LoadProjects(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{ProjectsFormatCSV, ProjectsFormatOPML} {
+		var buf bytes.Buffer
+		if n, err := ExportProjects(&buf, src, format); err != nil || n != 2 {
+			t.Fatalf("export %s: n=%d err=%v", format, n, err)
+		}
+		file := filepath.Join(tmp, "projects."+format)
+		if err := os.WriteFile(file, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		dst := filepath.Join(tmp, format+".json")
+		res, err := ImportProjects(ImportProjectsOptions{ConfigPath: dst, Path: file, Template: DefaultProjectTemplate()})
+		if err != nil || res.Format != format || res.Created != 2 {
+			t.Fatalf("import %s: %+v: %v", format, res, err)
+		}
+		dstReg, err := LoadProjects(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range srcReg.Projects {
+			got, want := dstReg.Projects[i], srcReg.Projects[i]
+			if format == ProjectsFormatOPML {
+				// OPML carries names and sources only.
+				if got.Name != want.Name || got.SourceURL != want.SourceURL {
+					t.Fatalf("OPML round trip: got %+v, want %+v", got, want)
+				}
+				continue
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("CSV round trip: got %+v, want %+v", got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package discovery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+const (
+	RepairFileOK      = "ok"
+	RepairFileMissing = "missing"
+	RepairFileCorrupt = "corrupt"
+
+	// RepairFromDiscovery marks a manifest rebuilt from a fresh listing
+	// rather than from a saved raw manifest.
+	RepairFromDiscovery = "discovery"
+)
+
+type RepairOptions struct {
+	RunID   string
+	RunDir  string
+	RunsDir string
+	Latest  bool
+	// SourceURL, Profile and OutputDir come from the project and are used
+	// when run.json cannot tell; ProjectID is stamped into a restored run.json.
+	SourceURL string
+	Profile   string
+	OutputDir string
+	ProjectID string
+	// Fresh rebuilds from a new listing even when a saved one parses.
+	Fresh              bool
+	CookiesPath        string
+	CookiesFromBrowser string
+	JSRuntime          string
+	DryRun             bool
+	LockTTL            time.Duration
+	SnapshotRetention  int
+}
+
+type RepairResult struct {
+	RunID           string   `json:"run_id"`
+	RunDir          string   `json:"run_dir"`
+	DryRun          bool     `json:"dry_run"`
+	Manifest        string   `json:"manifest"`
+	ManifestError   string   `json:"manifest_error,omitempty"`
+	RunMeta         string   `json:"run_meta"`
+	RunMetaError    string   `json:"run_meta_error,omitempty"`
+	RebuiltFrom     string   `json:"rebuilt_from,omitempty"`
+	Jobs            int      `json:"jobs"`
+	ManifestRebuilt bool     `json:"manifest_rebuilt"`
+	RunMetaRestored bool     `json:"run_meta_restored"`
+	TempFiles       []string `json:"temp_files"`
+	Quarantined     []string `json:"quarantined"`
+	QuarantineDir   string   `json:"quarantine_dir,omitempty"`
+}
+
+// NeedsRepair reports whether a run's state files could not be read.
+func (r RepairResult) NeedsRepair() bool {
+	return r.Manifest != RepairFileOK || r.RunMeta != RepairFileOK || len(r.TempFiles) > 0
+}
+
+// RepairRun makes a run readable again. A missing or unparsable
+// manifest.jobs.json is rebuilt from the newest raw snapshot that parses (or a
+// fresh listing), and run.json is rewritten from it. Broken files and temp
+// files left by interrupted atomic writes are moved to
+// <run>/quarantine/<timestamp>/ rather than deleted. Job state is not
+// recovered here; archive.RepairJobs marks jobs completed from disk.
+func RepairRun(opts RepairOptions) (RepairResult, error) {
+	runDir, err := resolveRunDir(opts.RunDir, opts.RunID, opts.RunsDir, opts.Latest)
+	if err != nil {
+		return RepairResult{}, err
+	}
+	if st, err := os.Stat(runDir); err != nil || !st.IsDir() {
+		return RepairResult{}, fmt.Errorf("run directory %s not found", runDir)
+	}
+	runLock, err := runstore.AcquireRunLock(runDir, opts.LockTTL)
+	if err != nil {
+		return RepairResult{}, err
+	}
+	defer func() {
+		_ = runLock.Release()
+	}()
+
+	now := time.Now().UTC()
+	res := RepairResult{
+		RunID:       filepath.Base(runDir),
+		RunDir:      runDir,
+		DryRun:      opts.DryRun,
+		Quarantined: []string{},
+	}
+	if res.TempFiles, err = findAtomicTempFiles(runDir); err != nil {
+		return res, err
+	}
+
+	jobsPath := runstore.JobsManifestPath(runDir)
+	var mf model.JobsManifest
+	res.Manifest, res.ManifestError, err = checkStateFile(runstore.ReadJobsManifest(jobsPath, &mf))
+	if err != nil {
+		return res, err
+	}
+	var meta runstore.RunMeta
+	var metaErr error
+	meta, metaErr = runstore.LoadRunMeta(runDir)
+	res.RunMeta, res.RunMetaError, err = checkStateFile(metaErr)
+	if err != nil {
+		return res, err
+	}
+	if res.Manifest == RepairFileOK {
+		res.Jobs = len(mf.Jobs)
+	} else {
+		// A type error can leave a partly decoded manifest behind.
+		mf = model.JobsManifest{}
+	}
+	if !res.NeedsRepair() {
+		return res, nil
+	}
+
+	sourceURL := firstNonEmpty(opts.SourceURL, mf.SourceURL, meta.SourceURL)
+	profile := firstNonEmpty(mf.Profile, meta.Profile, opts.Profile, "default")
+	var src sourceManifest
+	if res.Manifest != RepairFileOK {
+		src, res.RebuiltFrom, err = repairSource(runDir, sourceURL, opts)
+		if err != nil {
+			return res, err
+		}
+		res.Jobs = len(src.Entries)
+	}
+	if opts.DryRun {
+		return res, nil
+	}
+
+	quarantine := func(path string) error {
+		if _, err := os.Lstat(path); err != nil {
+			return nil
+		}
+		if res.QuarantineDir == "" {
+			res.QuarantineDir = filepath.Join(runDir, "quarantine", now.Format("20060102T150405Z"))
+			if err := runstore.Mkdir(res.QuarantineDir); err != nil {
+				return err
+			}
+		}
+		rel, err := filepath.Rel(runDir, path)
+		if err != nil {
+			rel = filepath.Base(path)
+		}
+		dst := filepath.Join(res.QuarantineDir, rel)
+		if err := runstore.Mkdir(filepath.Dir(dst)); err != nil {
+			return err
+		}
+		if err := os.Rename(path, dst); err != nil {
+			return fmt.Errorf("quarantine %s: %w", path, err)
+		}
+		res.Quarantined = append(res.Quarantined, dst)
+		return nil
+	}
+	for _, f := range res.TempFiles {
+		if err := quarantine(f); err != nil {
+			return res, err
+		}
+	}
+
+	if res.Manifest != RepairFileOK {
+		if res.RebuiltFrom == RepairFromDiscovery {
+			rawPath := filepath.Join(runDir, "manifest.raw.json")
+			if err := saveSourceSnapshot(runDir, rawPath, now, src.Raw, opts.SnapshotRetention); err != nil {
+				return res, err
+			}
+			if err := runstore.WriteBytes(rawPath, src.Raw); err != nil {
+				return res, err
+			}
+		}
+		jobs, _, _ := mergeJobs(nil, src)
+		pending, skippedPrivate := countJobs(jobs)
+		mf = model.JobsManifest{
+			SchemaVersion:  runstore.JobsManifestSchemaVersion,
+			GeneratedAt:    now.Format(time.RFC3339),
+			RunID:          res.RunID,
+			Profile:        profile,
+			SourceURL:      sourceURL,
+			SourceID:       src.ID,
+			SourceTitle:    src.Title,
+			SourceType:     src.Type,
+			PlaylistID:     src.ID,
+			PlaylistTitle:  src.Title,
+			Total:          len(jobs),
+			Pending:        pending,
+			SkippedPrivate: skippedPrivate,
+			Jobs:           jobs,
+		}
+		if err := quarantine(jobsPath); err != nil {
+			return res, err
+		}
+		if err := runstore.WriteJSON(jobsPath, mf); err != nil {
+			return res, err
+		}
+		res.ManifestRebuilt = true
+	}
+
+	if res.RunMeta != RepairFileOK || res.ManifestRebuilt {
+		if res.RunMeta != RepairFileOK {
+			if err := quarantine(runstore.RunMetaPath(runDir)); err != nil {
+				return res, err
+			}
+			meta = runstore.RunMeta{RunID: res.RunID, CreatedAt: now.Format(time.RFC3339), OutputDir: strings.TrimSpace(opts.OutputDir)}
+		}
+		meta.UpdatedAt = now.Format(time.RFC3339)
+		meta.Profile = profile
+		meta.ProjectID = firstNonEmpty(strings.TrimSpace(opts.ProjectID), meta.ProjectID)
+		meta.SourceURL = sourceURL
+		meta.SourceID = firstNonEmpty(mf.SourceID, meta.SourceID)
+		meta.SourceTitle = firstNonEmpty(mf.SourceTitle, meta.SourceTitle)
+		meta.SourceType = firstNonEmpty(mf.SourceType, meta.SourceType)
+		meta.RawManifestPath = filepath.Join(runDir, "manifest.raw.json")
+		meta.JobsManifestPath = jobsPath
+		meta.TotalEntries = len(mf.Jobs)
+		meta.Pending, meta.SkippedPrivate = countJobs(mf.Jobs)
+		if err := runstore.SaveRunMeta(runDir, meta); err != nil {
+			return res, err
+		}
+		res.RunMetaRestored = true
+	}
+	return res, nil
+}
+
+// ResolveRunDirForRepair is ResolveRunDirForProject for runs whose state
+// files may be unreadable: when no run.json or manifest names the project's
+// source, runs are matched by the webpage_url of their saved raw manifests.
+func ResolveRunDirForRepair(configPath, projectName, runsDir string) (string, Project, error) {
+	project, err := FindProjectByName(configPath, projectName)
+	if err != nil {
+		return "", Project{}, err
+	}
+	baseRuns := strings.TrimSpace(runsDir)
+	if baseRuns == "" {
+		baseRuns = "runs"
+	}
+	runDir, err := latestProjectRunDir(baseRuns, project)
+	if err != nil {
+		return "", Project{}, err
+	}
+	if runDir != "" {
+		return runDir, project, nil
+	}
+	dirs, err := runstore.ListRunDirs(baseRuns)
+	if err != nil {
+		return "", Project{}, err
+	}
+	slices.Reverse(dirs)
+	target := normalizeSourceURL(project.SourceURL)
+	for _, dir := range dirs {
+		for _, raw := range savedRawManifests(dir) {
+			var c ytDLPCollection
+			if json.Unmarshal(raw.data, &c) == nil && normalizeSourceURL(c.WebpageURL) == target {
+				return dir, project, nil
+			}
+		}
+	}
+	return "", Project{}, fmt.Errorf("no run found for project %q; pass --run-dir", project.Name)
+}
+
+// checkStateFile sorts a read error into ok, missing or corrupt. Files from
+// a newer binary are not corrupt and are never repaired.
+func checkStateFile(err error) (string, string, error) {
+	switch {
+	case err == nil:
+		return RepairFileOK, "", nil
+	case errors.Is(err, runstore.ErrSchemaTooNew):
+		return "", "", err
+	case errors.Is(err, fs.ErrNotExist):
+		return RepairFileMissing, err.Error(), nil
+	default:
+		return RepairFileCorrupt, err.Error(), nil
+	}
+}
+
+type savedRawManifest struct {
+	name string
+	data []byte
+}
+
+// savedRawManifests returns the run's raw listings newest first: snapshots,
+// then manifest.raw.json. Unreadable ones are left out.
+func savedRawManifests(runDir string) []savedRawManifest {
+	out := []savedRawManifest{}
+	snaps, _ := runstore.ListSnapshots(runDir)
+	for i := len(snaps) - 1; i >= 0; i-- {
+		if data, err := runstore.ReadSnapshot(snaps[i].Path); err == nil {
+			out = append(out, savedRawManifest{name: snaps[i].Name, data: data})
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(runDir, "manifest.raw.json")); err == nil {
+		out = append(out, savedRawManifest{name: "manifest.raw.json", data: data})
+	}
+	return out
+}
+
+func repairSource(runDir, sourceURL string, opts RepairOptions) (sourceManifest, string, error) {
+	if !opts.Fresh {
+		for _, raw := range savedRawManifests(runDir) {
+			if src, err := parseSourceManifest(raw.data, sourceURL); err == nil {
+				return src, raw.name, nil
+			}
+		}
+	}
+	if strings.TrimSpace(sourceURL) == "" {
+		return sourceManifest{}, "", fmt.Errorf("no saved listing parses and the source URL is unknown; pass --project or --source")
+	}
+	if opts.DryRun {
+		return sourceManifest{}, RepairFromDiscovery, nil
+	}
+	src, err := fetchSourceManifest(sourceURL, opts.CookiesPath, opts.CookiesFromBrowser, opts.JSRuntime)
+	if err != nil {
+		return sourceManifest{}, "", err
+	}
+	return src, RepairFromDiscovery, nil
+}
+
+// findAtomicTempFiles lists temp files runstore.WriteBytes left behind in the
+// run directory and its snapshots when a write was interrupted.
+func findAtomicTempFiles(runDir string) ([]string, error) {
+	out := []string{}
+	for _, dir := range []string{runDir, runstore.SnapshotDir(runDir)} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasPrefix(e.Name(), runstore.TempFilePrefix) {
+				out = append(out, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	return out, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+func TestRepairRunRebuildsCorruptManifestFromLastGoodSnapshot(t *testing.T) {
+	runsDir := t.TempDir()
+	runDir := filepath.Join(runsDir, "run1")
+	source := "https://www.youtube.com/playlist?list=PLrepair"
+	good := []byte(`{"id":"PLrepair","title":"Talks","webpage_url":"` + source + `","entries":[{"id":"a","title":"A"},{"id":"b","title":"B"}]}`)
+	if _, err := runstore.SaveSnapshot(runDir, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), good, 0); err != nil {
+		t.Fatal(err)
+	}
+	// The newest snapshot is truncated, so the older one must be used.
+	if _, err := runstore.SaveSnapshot(runDir, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), good[:20], 0); err != nil {
+		t.Fatal(err)
+	}
+	jobsPath := filepath.Join(runDir, "manifest.jobs.json")
+	if err := os.WriteFile(jobsPath, []byte(`{"run_id": "run1", "jobs": [`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tmpFile := filepath.Join(runDir, runstore.TempFilePrefix+"123")
+	if err := os.WriteFile(tmpFile, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Neither run.json nor the manifest names the source any more.
+	if found, _, err := ResolveRunDirForRepair(writeRepairProject(t, source), "talks", runsDir); err != nil || found != runDir {
+		t.Fatalf("expected run matched by its raw snapshot, got %q: %v", found, err)
+	}
+
+	plan, err := RepairRun(RepairOptions{RunDir: runDir, SourceURL: source, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Manifest != RepairFileCorrupt || plan.RunMeta != RepairFileMissing || plan.RebuiltFrom != "raw_20240101T000000Z.json.gz" || len(plan.TempFiles) != 1 {
+		t.Fatalf("unexpected dry-run report: %+v", plan)
+	}
+	if _, err := os.Stat(tmpFile); err != nil {
+		t.Fatalf("dry run must not move files: %v", err)
+	}
+
+	res, err := RepairRun(RepairOptions{RunDir: runDir, SourceURL: source})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.ManifestRebuilt || !res.RunMetaRestored || res.Jobs != 2 || len(res.Quarantined) != 2 {
+		t.Fatalf("unexpected repair result: %+v", res)
+	}
+	for _, name := range []string{"manifest.jobs.json", runstore.TempFilePrefix + "123"} {
+		if _, err := os.Stat(filepath.Join(res.QuarantineDir, name)); err != nil {
+			t.Fatalf("expected %s quarantined: %v", name, err)
+		}
+	}
+	var mf model.JobsManifest
+	if err := runstore.ReadJobsManifest(jobsPath, &mf); err != nil {
+		t.Fatal(err)
+	}
+	if mf.SourceURL != source || len(mf.Jobs) != 2 || mf.Jobs[0].Status != model.StatusPending {
+		t.Fatalf("unexpected rebuilt manifest: %+v", mf)
+	}
+	meta, err := runstore.LoadRunMeta(runDir)
+	if err != nil || meta.SourceURL != source || meta.TotalEntries != 2 {
+		t.Fatalf("expected run.json restored, got %+v: %v", meta, err)
+	}
+
+	again, err := RepairRun(RepairOptions{RunDir: runDir})
+	if err != nil || again.NeedsRepair() {
+		t.Fatalf("expected a healthy run after repair, got %+v: %v", again, err)
+	}
+}
+
+func TestRepairRunLeavesNewerSchemaAlone(t *testing.T) {
+	runDir := t.TempDir()
+	jobsPath := filepath.Join(runDir, "manifest.jobs.json")
+	if err := os.WriteFile(jobsPath, []byte(`{"schema_version": 999, "jobs": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RepairRun(RepairOptions{RunDir: runDir, SourceURL: "https://www.youtube.com/@x"}); err == nil {
+		t.Fatal("expected a manifest from a newer binary to be refused")
+	}
+	if _, err := os.Stat(jobsPath); err != nil {
+		t.Fatalf("manifest from a newer binary was moved: %v", err)
+	}
+}
+
+func writeRepairProject(t *testing.T, source string) string {
+	t.Helper()
+	cfg := filepath.Join(t.TempDir(), "projects.json")
+	if _, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: "talks", SourceURL: source}); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
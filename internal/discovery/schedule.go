@@ -0,0 +1,210 @@
+package discovery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const minScheduleInterval = time.Minute
+
+var scheduleShortcuts = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// Schedule is a parsed project schedule: either a fixed interval ("6h",
+// "@every 30m") or a five-field cron expression evaluated in local time.
+type Schedule struct {
+	Raw      string
+	Interval time.Duration
+	cron     *cronSpec
+}
+
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+func ParseSchedule(raw string) (Schedule, error) {
+	v := strings.Join(strings.Fields(raw), " ")
+	if v == "" {
+		return Schedule{}, fmt.Errorf("schedule is empty")
+	}
+	lower := strings.ToLower(v)
+	if strings.HasPrefix(lower, "@every ") {
+		return parseIntervalSchedule(v, strings.TrimSpace(v[len("@every "):]))
+	}
+	if expr, ok := scheduleShortcuts[lower]; ok {
+		spec, err := parseCronSpec(expr)
+		if err != nil {
+			return Schedule{}, err
+		}
+		return Schedule{Raw: lower, cron: spec}, nil
+	}
+	if !strings.Contains(v, " ") {
+		return parseIntervalSchedule(v, v)
+	}
+	spec, err := parseCronSpec(v)
+	if err != nil {
+		return Schedule{}, err
+	}
+	return Schedule{Raw: v, cron: spec}, nil
+}
+
+func parseIntervalSchedule(raw, value string) (Schedule, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("invalid schedule %q (use an interval like 6h or a cron expression like \"0 3 * * *\")", raw)
+	}
+	if d < minScheduleInterval {
+		return Schedule{}, fmt.Errorf("schedule interval %s is below the %s minimum", d, minScheduleInterval)
+	}
+	return Schedule{Raw: raw, Interval: d}, nil
+}
+
+// Next returns the first run time strictly after t.
+func (s Schedule) Next(t time.Time) time.Time {
+	if s.Interval > 0 {
+		return t.Add(s.Interval)
+	}
+	if s.cron == nil {
+		return time.Time{}
+	}
+	return s.cron.next(t)
+}
+
+func (s Schedule) IsInterval() bool {
+	return s.Interval > 0
+}
+
+func normalizeSchedule(raw string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return "", nil
+	}
+	s, err := ParseSchedule(raw)
+	if err != nil {
+		return "", err
+	}
+	return s.Raw, nil
+}
+
+func parseCronSpec(expr string) (*cronSpec, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q (expected 5 fields: minute hour day-of-month month day-of-week)", expr)
+	}
+	var spec cronSpec
+	var err error
+	if spec.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("cron minute: %w", err)
+	}
+	if spec.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("cron hour: %w", err)
+	}
+	if spec.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("cron day-of-month: %w", err)
+	}
+	if spec.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("cron month: %w", err)
+	}
+	if spec.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("cron day-of-week: %w", err)
+	}
+	// 7 is an alias for Sunday.
+	if spec.dow&(1<<7) != 0 {
+		spec.dow |= 1
+	}
+	spec.domAny = strings.HasPrefix(fields[2], "*")
+	spec.dowAny = strings.HasPrefix(fields[4], "*")
+	return &spec, nil
+}
+
+// parseCronField supports *, single values, ranges (a-b), lists (a,b) and steps (*/n, a-b/n).
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart, step = part[:i], n
+		}
+		start, end := lo, hi
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			a, errA := strconv.Atoi(bounds[0])
+			b, errB := strconv.Atoi(bounds[1])
+			if errA != nil || errB != nil || a > b {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+			start, end = a, b
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", rangePart)
+			}
+			start, end = n, n
+			if step > 1 {
+				end = hi
+			}
+		}
+		if start < lo || end > hi {
+			return 0, fmt.Errorf("value out of range %d-%d in %q", lo, hi, part)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (c *cronSpec) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron semantics: when both day fields are restricted,
+// either one matching is enough.
+func (c *cronSpec) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
@@ -0,0 +1,70 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleNext(t *testing.T) {
+	base := time.Date(2024, 3, 15, 10, 20, 30, 0, time.Local) // Friday
+	cases := []struct {
+		raw  string
+		want time.Time
+	}{
+		{"6h", base.Add(6 * time.Hour)},
+		{"@every 30m", base.Add(30 * time.Minute)},
+		{"0 3 * * *", time.Date(2024, 3, 16, 3, 0, 0, 0, time.Local)},
+		{"*/15 * * * *", time.Date(2024, 3, 15, 10, 30, 0, 0, time.Local)},
+		{"0 9 * * 1-5", time.Date(2024, 3, 18, 9, 0, 0, 0, time.Local)},
+		{"30 4 1 * *", time.Date(2024, 4, 1, 4, 30, 0, 0, time.Local)},
+		{"@daily", time.Date(2024, 3, 16, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tc := range cases {
+		s, err := ParseSchedule(tc.raw)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.raw, err)
+		}
+		if got := s.Next(base); !got.Equal(tc.want) {
+			t.Fatalf("%q: next = %s, want %s", tc.raw, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"soon", "10s", "61 * * * *", "* * *", "0 0 31-1 * *"} {
+		if _, err := ParseSchedule(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestPlanDaemonKeepsCadenceAndDropsUnscheduled(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	projects := []Project{
+		{Name: "fresh", Schedule: "1h", Active: boolPtr(true)},
+		{Name: "resumed", Schedule: "6h", Active: boolPtr(true)},
+		{Name: "paused", Schedule: "1h", Active: boolPtr(false)},
+		{Name: "inherits", Active: boolPtr(true)},
+	}
+	st := DaemonState{Projects: map[string]DaemonProjectState{
+		"resumed": {Schedule: "6h", LastFinishedAt: now.Add(-time.Hour).Format(time.RFC3339)},
+		"removed": {Schedule: "1h", NextRunAt: now.Format(time.RFC3339)},
+	}}
+
+	jobs, warnings := PlanDaemon(&st, projects, "", now)
+	if len(warnings) != 0 || len(jobs) != 2 {
+		t.Fatalf("unexpected plan: jobs=%+v warnings=%v", jobs, warnings)
+	}
+	if jobs[0].Project.Name != "fresh" || !jobs[0].NextRun.Equal(now) {
+		t.Fatalf("expected new interval project to be due now, got %+v", jobs[0])
+	}
+	if jobs[1].Project.Name != "resumed" || !jobs[1].NextRun.Equal(now.Add(5*time.Hour)) {
+		t.Fatalf("expected resumed project to keep its cadence, got %+v", jobs[1])
+	}
+	if _, ok := st.Projects["removed"]; ok {
+		t.Fatal("expected state for removed project to be dropped")
+	}
+
+	jobs, _ = PlanDaemon(&st, projects, "@daily", now)
+	if len(jobs) != 3 {
+		t.Fatalf("expected default schedule to pick up unscheduled project, got %+v", jobs)
+	}
+}
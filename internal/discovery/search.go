@@ -0,0 +1,243 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SearchMatchTitle     = "title"
+	SearchMatchMetadata  = "metadata"
+	SearchMatchSubtitles = "subtitles"
+
+	DefaultSearchLimit   = 50
+	maxSubtitleHitsShown = 5
+)
+
+type SearchOptions struct {
+	ConfigPath string
+	RunsDir    string
+	Query      string
+	TitleOnly  bool
+	Project    string
+	Status     string
+	After      string
+	Before     string
+	Limit      int
+}
+
+type SearchResult struct {
+	Query     string      `json:"query"`
+	IndexPath string      `json:"index_path"`
+	Total     int         `json:"total"`
+	Truncated bool        `json:"truncated"`
+	Hits      []SearchHit `json:"hits"`
+	Warnings  []string    `json:"warnings,omitempty"`
+}
+
+type SearchHit struct {
+	VideoID          string        `json:"video_id"`
+	Project          string        `json:"project"`
+	RunID            string        `json:"run_id"`
+	Title            string        `json:"title"`
+	Status           string        `json:"status"`
+	Reason           string        `json:"reason,omitempty"`
+	UploadDate       string        `json:"upload_date,omitempty"`
+	Path             string        `json:"path,omitempty"`
+	MatchedIn        []string      `json:"matched_in"`
+	SubtitleHitCount int           `json:"subtitle_hit_count,omitempty"`
+	SubtitleHits     []SubtitleHit `json:"subtitle_hits,omitempty"`
+}
+
+type SubtitleHit struct {
+	Lang  string `json:"lang"`
+	Start string `json:"start"`
+	Text  string `json:"text"`
+}
+
+// Search matches every query term case-insensitively against titles, stored
+// metadata and subtitle cues. The index is refreshed first, so results reflect
+// the latest syncs even when the post-sync update was skipped.
+func Search(opts SearchOptions) (SearchResult, error) {
+	configPath := normalizeConfigPath(opts.ConfigPath)
+	runsDir := defaultIfEmpty(opts.RunsDir, "runs")
+	terms := strings.Fields(strings.ToLower(opts.Query))
+	if len(terms) == 0 {
+		return SearchResult{}, errors.New("search query is required")
+	}
+	after, err := parseSearchDate("after", opts.After)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	before, err := parseSearchDate("before", opts.Before)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	statuses, err := parseStatusFilter(opts.Status)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	projects := map[string]bool{}
+	if strings.TrimSpace(opts.Project) != "" {
+		selected, err := ResolveProjectSelection(configPath, opts.Project, false)
+		if err != nil {
+			return SearchResult{}, err
+		}
+		for _, p := range selected {
+			projects[p.Name] = true
+		}
+	}
+	limit := opts.Limit
+	if limit < 0 {
+		limit = 0
+	}
+
+	idx, refresh, err := refreshSearchIndex(configPath, runsDir)
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	runs := make([]searchIndexRun, 0, len(idx.Runs))
+	for _, r := range idx.Runs {
+		if len(projects) > 0 && !projects[r.Project] {
+			continue
+		}
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Project < runs[j].Project
+	})
+
+	res := SearchResult{Query: strings.TrimSpace(opts.Query), IndexPath: refresh.IndexPath, Hits: []SearchHit{}, Warnings: refresh.Warnings}
+	for _, r := range runs {
+		cuesByVideo := map[string][]SubtitleHit{}
+		if !opts.TitleOnly {
+			cuesByVideo = matchSubtitleCues(r.Subtitles, terms)
+		}
+		for _, doc := range r.Docs {
+			if len(statuses) > 0 && !statuses[doc.Status] {
+				continue
+			}
+			if !dateInRange(searchDocDate(doc), after, before) {
+				continue
+			}
+			hit, ok := matchSearchDoc(doc, terms, opts.TitleOnly, cuesByVideo[doc.VideoID])
+			if !ok {
+				continue
+			}
+			hit.Project = r.Project
+			hit.RunID = r.RunID
+			res.Total++
+			if limit > 0 && len(res.Hits) >= limit {
+				res.Truncated = true
+				continue
+			}
+			res.Hits = append(res.Hits, hit)
+		}
+	}
+	return res, nil
+}
+
+func matchSearchDoc(doc searchDoc, terms []string, titleOnly bool, cues []SubtitleHit) (SearchHit, bool) {
+	hit := SearchHit{
+		VideoID:    doc.VideoID,
+		Title:      doc.Title,
+		Status:     doc.Status,
+		Reason:     doc.Reason,
+		UploadDate: doc.UploadDate,
+		Path:       doc.Path,
+		MatchedIn:  []string{},
+	}
+	title := strings.ToLower(doc.Title)
+	if containsAllTerms(title, terms) {
+		hit.MatchedIn = append(hit.MatchedIn, SearchMatchTitle)
+	}
+	if !titleOnly {
+		meta := strings.ToLower(doc.Metadata)
+		if len(hit.MatchedIn) == 0 && meta != "" && containsAllTerms(title+"\n"+meta, terms) {
+			hit.MatchedIn = append(hit.MatchedIn, SearchMatchMetadata)
+		}
+		if len(cues) > 0 {
+			hit.MatchedIn = append(hit.MatchedIn, SearchMatchSubtitles)
+			hit.SubtitleHitCount = len(cues)
+			hit.SubtitleHits = cues[:min(len(cues), maxSubtitleHitsShown)]
+		}
+	}
+	return hit, len(hit.MatchedIn) > 0
+}
+
+func matchSubtitleCues(subs map[string]searchSubtitles, terms []string) map[string][]SubtitleHit {
+	paths := make([]string, 0, len(subs))
+	for path := range subs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	out := map[string][]SubtitleHit{}
+	for _, path := range paths {
+		s := subs[path]
+		for _, cue := range s.Cues {
+			if containsAllTerms(strings.ToLower(cue.Text), terms) {
+				out[s.VideoID] = append(out[s.VideoID], SubtitleHit{Lang: s.Lang, Start: cue.Start, Text: cue.Text})
+			}
+		}
+	}
+	return out
+}
+
+func containsAllTerms(text string, terms []string) bool {
+	for _, t := range terms {
+		if !strings.Contains(text, t) {
+			return false
+		}
+	}
+	return true
+}
+
+func searchDocDate(doc searchDoc) string {
+	if doc.UploadDate != "" {
+		return doc.UploadDate
+	}
+	if len(doc.CompletedAt) >= 10 {
+		return doc.CompletedAt[:10]
+	}
+	return ""
+}
+
+func dateInRange(date, after, before string) bool {
+	if after == "" && before == "" {
+		return true
+	}
+	if date == "" {
+		return false
+	}
+	if after != "" && date < after {
+		return false
+	}
+	if before != "" && date > before {
+		return false
+	}
+	return true
+}
+
+func parseSearchDate(name, raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return "", fmt.Errorf("--%s must be a date like 2024-01-31", name)
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+func defaultIfEmpty(v, fallback string) string {
+	if strings.TrimSpace(v) == "" {
+		return fallback
+	}
+	return strings.TrimSpace(v)
+}
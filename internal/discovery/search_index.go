@@ -0,0 +1,369 @@
+package discovery
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+const searchIndexFileName = "search-index.json"
+
+var (
+	searchMediaIDPattern    = regexp.MustCompile(`\[([A-Za-z0-9_-]{6,})\]\.[^.]+$`)
+	searchSubtitleIDPattern = regexp.MustCompile(`\[([A-Za-z0-9_-]{6,})\]\.([^.]+)\.vtt$`)
+	searchUploadDatePattern = regexp.MustCompile(`^(\d{4})(\d{2})(\d{2})_`)
+	vttTagPattern           = regexp.MustCompile(`<[^>]*>`)
+)
+
+var searchMediaExt = map[string]bool{
+	".mp4": true, ".mkv": true, ".webm": true, ".m4v": true,
+	".mov": true, ".avi": true, ".flv": true, ".ts": true, ".m4a": true, ".mp3": true,
+	".opus": true, ".ogg": true, ".aac": true, ".flac": true, ".wav": true,
+}
+
+// searchIndex caches what search needs from every project's latest run. Runs
+// and subtitle files are keyed by a size/mtime stamp so unchanged inputs are
+// not parsed again.
+type searchIndex struct {
+	SchemaVersion int                       `json:"schema_version"`
+	UpdatedAt     string                    `json:"updated_at,omitempty"`
+	Runs          map[string]searchIndexRun `json:"runs"`
+}
+
+type searchIndexRun struct {
+	Project       string                     `json:"project"`
+	RunID         string                     `json:"run_id"`
+	RunDir        string                     `json:"run_dir"`
+	OutputDir     string                     `json:"output_dir"`
+	ManifestStamp string                     `json:"manifest_stamp"`
+	Docs          []searchDoc                `json:"docs"`
+	Subtitles     map[string]searchSubtitles `json:"subtitles,omitempty"`
+}
+
+type searchDoc struct {
+	VideoID     string `json:"video_id"`
+	Title       string `json:"title"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason,omitempty"`
+	CompletedAt string `json:"completed_at,omitempty"`
+	UploadDate  string `json:"upload_date,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Metadata    string `json:"metadata,omitempty"`
+}
+
+type searchSubtitles struct {
+	VideoID string        `json:"video_id"`
+	Lang    string        `json:"lang"`
+	Stamp   string        `json:"stamp"`
+	Cues    []subtitleCue `json:"cues"`
+}
+
+type subtitleCue struct {
+	Start string `json:"start"`
+	Text  string `json:"text"`
+}
+
+type rawEntryMetadata struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Channel     string `json:"channel"`
+	Uploader    string `json:"uploader"`
+	UploadDate  string `json:"upload_date"`
+}
+
+type UpdateSearchIndexResult struct {
+	IndexPath    string `json:"index_path"`
+	Runs         int    `json:"runs"`
+	RunsUpdated  int    `json:"runs_updated"`
+	SubtitlesNew int    `json:"subtitles_parsed"`
+	// Warnings lists runs that could not be read; their previous index
+	// entries are kept.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+func searchIndexPath(runsDir string) string {
+	return filepath.Join(runsDir, searchIndexFileName)
+}
+
+func loadSearchIndex(runsDir string) searchIndex {
+	idx := searchIndex{SchemaVersion: 1, Runs: map[string]searchIndexRun{}}
+	var stored searchIndex
+	if err := runstore.ReadJSON(searchIndexPath(runsDir), &stored); err != nil || stored.Runs == nil {
+		return idx
+	}
+	idx.Runs = stored.Runs
+	return idx
+}
+
+// UpdateSearchIndex refreshes the on-disk search index for the latest run of
+// every configured project, reparsing only manifests and subtitles that changed.
+func UpdateSearchIndex(configPath, runsDir string) (UpdateSearchIndexResult, error) {
+	runsDir = defaultIfEmpty(runsDir, "runs")
+	_, res, err := refreshSearchIndex(configPath, runsDir)
+	if err != nil {
+		return UpdateSearchIndexResult{}, err
+	}
+	return res, nil
+}
+
+// refreshSearchIndex rebuilds stale index entries and saves the index when
+// anything changed. A missing config simply yields an empty index, and a run
+// that cannot be read is skipped with a warning.
+func refreshSearchIndex(configPath, runsDir string) (searchIndex, UpdateSearchIndexResult, error) {
+	res := UpdateSearchIndexResult{IndexPath: searchIndexPath(runsDir)}
+	reg, err := loadProjectRegistry(normalizeConfigPath(configPath))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return searchIndex{}, res, err
+	}
+	prev := loadSearchIndex(runsDir)
+	next := searchIndex{SchemaVersion: 1, Runs: map[string]searchIndexRun{}}
+	for _, p := range reg.Projects {
+		runDir, err := latestProjectRunDir(runsDir, p)
+		if err != nil {
+			return searchIndex{}, res, err
+		}
+		if runDir == "" {
+			continue
+		}
+		entry, updated, parsed, err := refreshSearchIndexRun(prev.Runs[runDir], EffectiveProject(p, reg.Global, reg.Profiles), runDir)
+		if err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("project %s: skipped %s: %v", p.Name, runDir, err))
+			old, ok := prev.Runs[runDir]
+			if !ok {
+				continue
+			}
+			entry, updated, parsed = old, false, 0
+		}
+		next.Runs[runDir] = entry
+		res.Runs++
+		if updated {
+			res.RunsUpdated++
+		}
+		res.SubtitlesNew += parsed
+	}
+	if res.RunsUpdated > 0 || len(next.Runs) != len(prev.Runs) {
+		next.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		if err := runstore.WriteJSON(res.IndexPath, next); err != nil {
+			return searchIndex{}, res, err
+		}
+	}
+	return next, res, nil
+}
+
+func refreshSearchIndexRun(prev searchIndexRun, project Project, runDir string) (searchIndexRun, bool, int, error) {
+	jobsPath := filepath.Join(runDir, "manifest.jobs.json")
+	meta, _ := runstore.LoadRunMeta(runDir)
+	outputDir := defaultIfEmpty(meta.OutputDir, defaultIfEmpty(project.OutputDir, filepath.Join(runDir, "downloads")))
+	stamp := fileStamp(jobsPath) + "|" + fileStamp(filepath.Join(runDir, "manifest.raw.json"))
+
+	entry := prev
+	entry.Project = project.Name
+	entry.RunDir = runDir
+	entry.OutputDir = outputDir
+	media, subtitleFiles := scanSearchOutputDir(outputDir)
+
+	updated := false
+	if prev.ManifestStamp != stamp || prev.RunDir != runDir {
+		var mf model.JobsManifest
+		if err := runstore.ReadJobsManifest(jobsPath, &mf); err != nil {
+			return searchIndexRun{}, false, 0, err
+		}
+		rawMeta := readRawEntryMetadata(filepath.Join(runDir, "manifest.raw.json"))
+		entry.RunID = defaultIfEmpty(mf.RunID, filepath.Base(runDir))
+		entry.ManifestStamp = stamp
+		entry.Docs = make([]searchDoc, 0, len(mf.Jobs))
+		for _, j := range mf.Jobs {
+			raw := rawMeta[j.VideoID]
+			entry.Docs = append(entry.Docs, searchDoc{
+				VideoID:     j.VideoID,
+				Title:       j.Title,
+				Status:      j.Status,
+				Reason:      j.Reason,
+				CompletedAt: j.CompletedAt,
+				UploadDate:  formatUploadDate(raw.UploadDate),
+				Path:        j.MediaPath,
+				Metadata:    strings.Join(nonEmpty(raw.Description, raw.Channel, raw.Uploader), "\n"),
+			})
+		}
+		updated = true
+	}
+	for i := range entry.Docs {
+		doc := &entry.Docs[i]
+		if path := media[doc.VideoID]; path != "" {
+			if _, err := os.Stat(doc.Path); strings.TrimSpace(doc.Path) == "" || err != nil {
+				doc.Path = path
+				updated = true
+			}
+		}
+		if doc.UploadDate == "" && doc.Path != "" {
+			if m := searchUploadDatePattern.FindStringSubmatch(filepath.Base(doc.Path)); m != nil {
+				doc.UploadDate = m[1] + "-" + m[2] + "-" + m[3]
+				updated = true
+			}
+		}
+	}
+
+	parsed := 0
+	subs := make(map[string]searchSubtitles, len(subtitleFiles))
+	for path, ref := range subtitleFiles {
+		stamp := fileStamp(path)
+		if old, ok := prev.Subtitles[path]; ok && old.Stamp == stamp {
+			subs[path] = old
+			continue
+		}
+		cues, err := parseVTTCues(path)
+		if err != nil {
+			continue
+		}
+		ref.Stamp = stamp
+		ref.Cues = cues
+		subs[path] = ref
+		parsed++
+	}
+	if parsed > 0 || len(subs) != len(prev.Subtitles) {
+		updated = true
+	}
+	entry.Subtitles = subs
+	return entry, updated, parsed, nil
+}
+
+func scanSearchOutputDir(root string) (map[string]string, map[string]searchSubtitles) {
+	media := make(map[string]string)
+	subs := make(map[string]searchSubtitles)
+	_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := d.Name()
+		if m := searchSubtitleIDPattern.FindStringSubmatch(name); m != nil {
+			if m[2] != "live_chat" {
+				subs[path] = searchSubtitles{VideoID: m[1], Lang: m[2]}
+			}
+			return nil
+		}
+		if !searchMediaExt[strings.ToLower(filepath.Ext(name))] {
+			return nil
+		}
+		if m := searchMediaIDPattern.FindStringSubmatch(name); m != nil {
+			media[m[1]] = path
+		}
+		return nil
+	})
+	return media, subs
+}
+
+// parseVTTCues keeps cue start times and text, dropping styling tags and the
+// repeated lines that rolling auto-captions produce.
+func parseVTTCues(path string) ([]subtitleCue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cues := make([]subtitleCue, 0, 128)
+	lastText := ""
+	start := ""
+	var text []string
+	flush := func() {
+		if start == "" {
+			return
+		}
+		joined := strings.Join(text, " ")
+		if joined != "" && joined != lastText {
+			cues = append(cues, subtitleCue{Start: start, Text: joined})
+			lastText = joined
+		}
+		start = ""
+		text = text[:0]
+	}
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "":
+			flush()
+		case strings.Contains(line, "-->"):
+			flush()
+			start = trimCueTimestamp(strings.TrimSpace(strings.SplitN(line, "-->", 2)[0]))
+		case start != "":
+			clean := strings.TrimSpace(vttTagPattern.ReplaceAllString(line, ""))
+			if clean != "" && clean != lastText {
+				text = append(text, clean)
+			}
+		}
+	}
+	flush()
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read subtitles %s: %w", path, err)
+	}
+	return cues, nil
+}
+
+func trimCueTimestamp(ts string) string {
+	if i := strings.IndexByte(ts, '.'); i >= 0 {
+		ts = ts[:i]
+	}
+	if strings.Count(ts, ":") == 1 {
+		ts = "00:" + ts
+	}
+	return ts
+}
+
+func readRawEntryMetadata(path string) map[string]rawEntryMetadata {
+	out := make(map[string]rawEntryMetadata)
+	var raw struct {
+		Entries []rawEntryMetadata `json:"entries"`
+	}
+	if err := runstore.ReadJSON(path, &raw); err != nil {
+		return out
+	}
+	for _, e := range raw.Entries {
+		if strings.TrimSpace(e.ID) != "" {
+			out[e.ID] = e
+		}
+	}
+	return out
+}
+
+func formatUploadDate(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if len(raw) != 8 {
+		return ""
+	}
+	return raw[:4] + "-" + raw[4:6] + "-" + raw[6:]
+}
+
+func fileStamp(path string) string {
+	st, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d", st.Size(), st.ModTime().UnixNano())
+}
+
+func nonEmpty(values ...string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			out = append(out, strings.TrimSpace(v))
+		}
+	}
+	return out
+}
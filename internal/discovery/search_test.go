@@ -0,0 +1,160 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
+)
+
+func setupSearchWorkspace(t *testing.T) (string, string, string) {
+	t.Helper()
+	tmp := t.TempDir()
+	cfg := filepath.Join(tmp, "projects.json")
+	runsDir := filepath.Join(tmp, "runs")
+	if _, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: "talks", SourceURL: "https://example.com/talks"}); err != nil {
+		t.Fatalf("add project failed: %v", err)
+	}
+
+	runDir := filepath.Join(runsDir, "20240101T000000Z_talks")
+	mf := model.JobsManifest{
+		SchemaVersion: 1,
+		RunID:         "20240101T000000Z_talks",
+		SourceURL:     "https://example.com/talks",
+		Jobs: []model.Job{
+			{JobID: "j1", Index: 1, VideoID: "talk000001", Title: "Intro to Go generics", Status: model.StatusCompleted},
+			{JobID: "j2", Index: 2, VideoID: "talk000002", Title: "Rust ownership", Status: model.StatusPending},
+		},
+	}
+	if err := runstore.WriteJSON(filepath.Join(runDir, "manifest.jobs.json"), mf); err != nil {
+		t.Fatal(err)
+	}
+	raw := `{"entries":[{"id":"talk000002","description":"Borrow checker deep dive","upload_date":"20230510"}]}`
+	if err := os.WriteFile(filepath.Join(runDir, "manifest.raw.json"), []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	media := filepath.Join(runDir, "downloads", "Speaker", "20240105_Intro_[talk000001].mp4")
+	if err := os.MkdirAll(filepath.Dir(media), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(media, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	vtt := "WEBVTT\n\n00:00:01.000 --> 00:00:03.000\nwelcome to the talk\n\n00:01:10.500 --> 00:01:12.000\ntype <c>parameters</c> are here\n"
+	if err := os.WriteFile(filepath.Join(filepath.Dir(media), "20240105_Intro_[talk000001].en.vtt"), []byte(vtt), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return cfg, runsDir, media
+}
+
+func TestSearchMatchesTitleMetadataAndSubtitles(t *testing.T) {
+	cfg, runsDir, media := setupSearchWorkspace(t)
+
+	res, err := Search(SearchOptions{ConfigPath: cfg, RunsDir: runsDir, Query: "type parameters"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if res.Total != 1 || res.Hits[0].VideoID != "talk000001" {
+		t.Fatalf("unexpected subtitle search result: %+v", res)
+	}
+	hit := res.Hits[0]
+	if hit.Project != "talks" || hit.Path != media || hit.UploadDate != "2024-01-05" {
+		t.Fatalf("unexpected hit details: %+v", hit)
+	}
+	if len(hit.SubtitleHits) != 1 || hit.SubtitleHits[0].Start != "00:01:10" || hit.SubtitleHits[0].Text != "type parameters are here" {
+		t.Fatalf("unexpected subtitle hits: %+v", hit.SubtitleHits)
+	}
+
+	res, err = Search(SearchOptions{ConfigPath: cfg, RunsDir: runsDir, Query: "borrow"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if res.Total != 1 || res.Hits[0].MatchedIn[0] != SearchMatchMetadata {
+		t.Fatalf("unexpected metadata search result: %+v", res)
+	}
+
+	res, err = Search(SearchOptions{ConfigPath: cfg, RunsDir: runsDir, Query: "borrow", TitleOnly: true})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if res.Total != 0 {
+		t.Fatalf("expected title-only search to skip metadata, got %+v", res)
+	}
+}
+
+func TestSearchFiltersByStatusAndDate(t *testing.T) {
+	cfg, runsDir, _ := setupSearchWorkspace(t)
+
+	res, err := Search(SearchOptions{ConfigPath: cfg, RunsDir: runsDir, Query: "o", Status: "pending"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if res.Total != 1 || res.Hits[0].VideoID != "talk000002" {
+		t.Fatalf("unexpected status-filtered result: %+v", res)
+	}
+
+	res, err = Search(SearchOptions{ConfigPath: cfg, RunsDir: runsDir, Query: "o", After: "2024-01-01"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if res.Total != 1 || res.Hits[0].VideoID != "talk000001" {
+		t.Fatalf("unexpected date-filtered result: %+v", res)
+	}
+
+	if _, err := Search(SearchOptions{ConfigPath: cfg, RunsDir: runsDir, Query: "o", Status: "bogus"}); err == nil {
+		t.Fatal("expected unknown status to be rejected")
+	}
+}
+
+func TestUpdateSearchIndexIsIncremental(t *testing.T) {
+	cfg, runsDir, _ := setupSearchWorkspace(t)
+
+	first, err := UpdateSearchIndex(cfg, runsDir)
+	if err != nil {
+		t.Fatalf("index update failed: %v", err)
+	}
+	if first.Runs != 1 || first.RunsUpdated != 1 || first.SubtitlesNew != 1 {
+		t.Fatalf("unexpected first update: %+v", first)
+	}
+	second, err := UpdateSearchIndex(cfg, runsDir)
+	if err != nil {
+		t.Fatalf("index update failed: %v", err)
+	}
+	if second.RunsUpdated != 0 || second.SubtitlesNew != 0 {
+		t.Fatalf("expected unchanged inputs to be reused, got %+v", second)
+	}
+}
+
+func TestSearchSkipsUnreadableRuns(t *testing.T) {
+	cfg, runsDir, _ := setupSearchWorkspace(t)
+	if _, err := UpdateSearchIndex(cfg, runsDir); err != nil {
+		t.Fatalf("index update failed: %v", err)
+	}
+	if _, err := AddProject(AddProjectOptions{ConfigPath: cfg, Name: "broken", SourceURL: "https://example.com/broken"}); err != nil {
+		t.Fatal(err)
+	}
+	brokenRun := filepath.Join(runsDir, "20240102T000000Z_broken")
+	writeTestRun(t, runsDir, filepath.Base(brokenRun), "", "https://example.com/broken")
+	if err := os.WriteFile(filepath.Join(brokenRun, "manifest.jobs.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A corrupt manifest in an already indexed run keeps its previous entry.
+	writeTestRun(t, runsDir, "20240101T000000Z_talks", "", "https://example.com/talks")
+	if err := os.WriteFile(filepath.Join(runsDir, "20240101T000000Z_talks", "manifest.jobs.json"), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Search(SearchOptions{ConfigPath: cfg, RunsDir: runsDir, Query: "generics"})
+	if err != nil {
+		t.Fatalf("search must survive unreadable runs: %v", err)
+	}
+	if res.Total != 1 || res.Hits[0].VideoID != "talk000001" {
+		t.Fatalf("expected the previously indexed hit, got %+v", res)
+	}
+	if len(res.Warnings) != 2 {
+		t.Fatalf("expected a warning per unreadable run, got %q", res.Warnings)
+	}
+}
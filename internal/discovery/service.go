@@ -16,12 +16,17 @@ import (
 )
 
 type Options struct {
-	SourceURL          string
-	Profile            string
+	SourceURL string
+	Profile   string
+	// ProjectID is stamped into run.json so the run stays with its project
+	// when the project's source URL changes.
+	ProjectID          string
 	RunsDir            string
 	CookiesPath        string
 	CookiesFromBrowser string
 	JSRuntime          string
+	// SnapshotRetention caps the raw source snapshots kept per run.
+	SnapshotRetention int
 }
 
 type Result struct {
@@ -35,14 +40,18 @@ type Result struct {
 }
 
 type RefreshOptions struct {
-	RunID              string
-	RunDir             string
-	RunsDir            string
-	Latest             bool
-	SourceURL          string
+	RunID     string
+	RunDir    string
+	RunsDir   string
+	Latest    bool
+	SourceURL string
+	// ProjectID, when set, is stamped into run.json (see Options).
+	ProjectID          string
 	CookiesPath        string
 	CookiesFromBrowser string
 	JSRuntime          string
+	LockTTL            time.Duration
+	SnapshotRetention  int
 }
 
 type RefreshResult struct {
@@ -54,15 +63,21 @@ type RefreshResult struct {
 	Pending          int
 	SkippedPrivate   int
 	Added            int
+	// RemovedUpstream counts jobs newly or still tombstoned by this refresh.
+	RemovedUpstream int
 }
 
 type UpsertOptions struct {
-	SourceURL          string
-	Profile            string
+	SourceURL string
+	Profile   string
+	// ProjectID finds the project's runs by id rather than source URL.
+	ProjectID          string
 	RunsDir            string
 	CookiesPath        string
 	CookiesFromBrowser string
 	JSRuntime          string
+	LockTTL            time.Duration
+	SnapshotRetention  int
 }
 
 type UpsertResult struct {
@@ -123,15 +138,18 @@ func Run(opts Options) (Result, error) {
 	}
 
 	rawPath := filepath.Join(runDir, "manifest.raw.json")
+	if err := saveSourceSnapshot(runDir, rawPath, now, src.Raw, opts.SnapshotRetention); err != nil {
+		return Result{}, err
+	}
 	if err := runstore.WriteBytes(rawPath, src.Raw); err != nil {
 		return Result{}, err
 	}
 
-	jobs, _ := mergeJobs(nil, src)
+	jobs, _, _ := mergeJobs(nil, src)
 	pending, skippedPrivate := countJobs(jobs)
 
 	mf := model.JobsManifest{
-		SchemaVersion:   1,
+		SchemaVersion:   runstore.JobsManifestSchemaVersion,
 		GeneratedAt:     now.Format(time.RFC3339),
 		RunID:           runID,
 		Profile:         profile,
@@ -161,6 +179,7 @@ func Run(opts Options) (Result, error) {
 		CreatedAt:        now.Format(time.RFC3339),
 		UpdatedAt:        now.Format(time.RFC3339),
 		Profile:          profile,
+		ProjectID:        strings.TrimSpace(opts.ProjectID),
 		SourceURL:        strings.TrimSpace(opts.SourceURL),
 		SourceID:         src.ID,
 		SourceTitle:      src.Title,
@@ -186,12 +205,61 @@ func Run(opts Options) (Result, error) {
 	}, nil
 }
 
+// CreateEmptyRun writes a run for sourceURL with no jobs, without asking the
+// source for its listing. The next refresh fills it in.
+func CreateEmptyRun(runsDir, projectID, sourceURL, profile string) (string, error) {
+	sourceURL = strings.TrimSpace(sourceURL)
+	if sourceURL == "" {
+		return "", fmt.Errorf("source URL is required")
+	}
+	now := time.Now().UTC()
+	if strings.TrimSpace(profile) == "" {
+		profile = "default"
+	}
+	if strings.TrimSpace(runsDir) == "" {
+		runsDir = "runs"
+	}
+	runID := fmt.Sprintf("%s_imported", now.Format("20060102T150405Z"))
+	runDir := filepath.Join(runsDir, runID)
+	if err := runstore.Mkdir(runDir); err != nil {
+		return "", err
+	}
+
+	jobsPath := filepath.Join(runDir, "manifest.jobs.json")
+	mf := model.JobsManifest{
+		SchemaVersion: runstore.JobsManifestSchemaVersion,
+		GeneratedAt:   now.Format(time.RFC3339),
+		RunID:         runID,
+		Profile:       profile,
+		SourceURL:     sourceURL,
+		SourceType:    detectSourceType(sourceURL),
+		Jobs:          []model.Job{},
+	}
+	if err := runstore.WriteJSON(jobsPath, mf); err != nil {
+		return "", err
+	}
+	meta := runstore.RunMeta{
+		RunID:            runID,
+		CreatedAt:        now.Format(time.RFC3339),
+		UpdatedAt:        now.Format(time.RFC3339),
+		Profile:          profile,
+		ProjectID:        strings.TrimSpace(projectID),
+		SourceURL:        sourceURL,
+		SourceType:       mf.SourceType,
+		JobsManifestPath: jobsPath,
+	}
+	if err := runstore.SaveRunMeta(runDir, meta); err != nil {
+		return "", err
+	}
+	return runDir, nil
+}
+
 func Refresh(opts RefreshOptions) (RefreshResult, error) {
 	runDir, err := resolveRunDir(opts.RunDir, opts.RunID, opts.RunsDir, opts.Latest)
 	if err != nil {
 		return RefreshResult{}, err
 	}
-	runLock, err := runstore.AcquireRunLock(runDir)
+	runLock, err := runstore.AcquireRunLock(runDir, opts.LockTTL)
 	if err != nil {
 		return RefreshResult{}, err
 	}
@@ -201,7 +269,7 @@ func Refresh(opts RefreshOptions) (RefreshResult, error) {
 
 	jobsPath := filepath.Join(runDir, "manifest.jobs.json")
 	var mf model.JobsManifest
-	if err := runstore.ReadJSON(jobsPath, &mf); err != nil {
+	if err := runstore.ReadJobsManifest(jobsPath, &mf); err != nil {
 		return RefreshResult{}, err
 	}
 	if mf.RunID == "" {
@@ -227,7 +295,7 @@ func Refresh(opts RefreshOptions) (RefreshResult, error) {
 		return RefreshResult{}, err
 	}
 
-	jobs, added := mergeJobs(mf.Jobs, src)
+	jobs, added, removed := mergeJobs(mf.Jobs, src)
 	pending, skippedPrivate := countJobs(jobs)
 	now := time.Now().UTC()
 
@@ -252,6 +320,9 @@ func Refresh(opts RefreshOptions) (RefreshResult, error) {
 	}
 
 	rawPath := filepath.Join(runDir, "manifest.raw.json")
+	if err := saveSourceSnapshot(runDir, rawPath, now, src.Raw, opts.SnapshotRetention); err != nil {
+		return RefreshResult{}, err
+	}
 	if err := runstore.WriteBytes(rawPath, src.Raw); err != nil {
 		return RefreshResult{}, err
 	}
@@ -270,6 +341,9 @@ func Refresh(opts RefreshOptions) (RefreshResult, error) {
 		meta.CreatedAt = now.Format(time.RFC3339)
 	}
 	meta.UpdatedAt = now.Format(time.RFC3339)
+	if id := strings.TrimSpace(opts.ProjectID); id != "" {
+		meta.ProjectID = id
+	}
 	meta.SourceURL = sourceURL
 	meta.SourceID = src.ID
 	meta.SourceTitle = src.Title
@@ -292,6 +366,7 @@ func Refresh(opts RefreshOptions) (RefreshResult, error) {
 		Pending:          pending,
 		SkippedPrivate:   skippedPrivate,
 		Added:            added,
+		RemovedUpstream:  removed,
 	}, nil
 }
 
@@ -305,7 +380,7 @@ func UpsertBySource(opts UpsertOptions) (UpsertResult, error) {
 		runsDir = "runs"
 	}
 
-	runDir, err := latestRunDirBySource(runsDir, sourceURL)
+	runDir, err := findLatestRunDir(runsDir, opts.ProjectID, sourceURL)
 	if err != nil {
 		return UpsertResult{}, err
 	}
@@ -313,10 +388,12 @@ func UpsertBySource(opts UpsertOptions) (UpsertResult, error) {
 		res, err := Run(Options{
 			SourceURL:          sourceURL,
 			Profile:            opts.Profile,
+			ProjectID:          opts.ProjectID,
 			RunsDir:            runsDir,
 			CookiesPath:        opts.CookiesPath,
 			CookiesFromBrowser: opts.CookiesFromBrowser,
 			JSRuntime:          opts.JSRuntime,
+			SnapshotRetention:  opts.SnapshotRetention,
 		})
 		if err != nil {
 			return UpsertResult{}, err
@@ -332,9 +409,12 @@ func UpsertBySource(opts UpsertOptions) (UpsertResult, error) {
 		RunsDir:            runsDir,
 		Latest:             false,
 		SourceURL:          sourceURL,
+		ProjectID:          opts.ProjectID,
 		CookiesPath:        opts.CookiesPath,
 		CookiesFromBrowser: opts.CookiesFromBrowser,
 		JSRuntime:          opts.JSRuntime,
+		LockTTL:            opts.LockTTL,
+		SnapshotRetention:  opts.SnapshotRetention,
 	})
 	if err != nil {
 		return UpsertResult{}, err
@@ -345,7 +425,15 @@ func UpsertBySource(opts UpsertOptions) (UpsertResult, error) {
 	}, nil
 }
 
-func latestRunDirBySource(runsDir, sourceURL string) (string, error) {
+// latestProjectRunDir returns the newest run of a project (see findLatestRunDir).
+func latestProjectRunDir(runsDir string, p Project) (string, error) {
+	return findLatestRunDir(runsDir, p.ID, p.SourceURL)
+}
+
+// findLatestRunDir returns the newest run stamped with projectID. Runs without
+// a project id, from before ids or synced as a bare source, are matched by
+// sourceURL instead; with no projectID every run is matched that way.
+func findLatestRunDir(runsDir, projectID, sourceURL string) (string, error) {
 	dirs, err := runstore.ListRunDirs(runsDir)
 	if err != nil {
 		return "", err
@@ -354,35 +442,37 @@ func latestRunDirBySource(runsDir, sourceURL string) (string, error) {
 
 	target := normalizeSourceURL(sourceURL)
 	for _, runDir := range dirs {
-		meta, err := runstore.LoadRunMeta(runDir)
-		if err == nil && normalizeSourceURL(meta.SourceURL) == target {
-			return runDir, nil
-		}
-
-		var mf model.JobsManifest
-		if err := runstore.ReadJSON(filepath.Join(runDir, "manifest.jobs.json"), &mf); err == nil {
-			if normalizeSourceURL(mf.SourceURL) == target {
+		runProjectID, runSource := runIdentity(runDir)
+		if projectID != "" && runProjectID != "" {
+			if runProjectID == projectID {
 				return runDir, nil
 			}
+			continue
+		}
+		if target != "" && normalizeSourceURL(runSource) == target {
+			return runDir, nil
 		}
 	}
 	return "", nil
 }
 
-func normalizeSourceURL(raw string) string {
-	s := strings.TrimSpace(raw)
-	if s == "" {
-		return ""
-	}
-	u, err := url.Parse(s)
-	if err != nil {
-		return s
+// runIdentity reads a run's project id and source URL from run.json, falling
+// back to the jobs manifest for the source.
+func runIdentity(runDir string) (projectID, sourceURL string) {
+	if meta, err := runstore.LoadRunMeta(runDir); err == nil {
+		projectID, sourceURL = strings.TrimSpace(meta.ProjectID), meta.SourceURL
 	}
-	u.Fragment = ""
-	if strings.HasSuffix(u.Path, "/") && u.Path != "/" {
-		u.Path = strings.TrimSuffix(u.Path, "/")
+	if strings.TrimSpace(sourceURL) == "" {
+		var mf model.JobsManifest
+		if err := runstore.ReadJobsManifest(filepath.Join(runDir, "manifest.jobs.json"), &mf); err == nil {
+			sourceURL = mf.SourceURL
+		}
 	}
-	return u.String()
+	return projectID, sourceURL
+}
+
+func normalizeSourceURL(raw string) string {
+	return runstore.NormalizeSourceURL(raw)
 }
 
 func resolveRunDir(runDir, runID, runsDir string, latest bool) (string, error) {
@@ -416,7 +506,10 @@ func fetchSourceManifest(sourceURL, cookiesPath, cookiesFromBrowser, jsRuntime s
 	if err != nil {
 		return sourceManifest{}, err
 	}
+	return parseSourceManifest(raw, sourceURL)
+}
 
+func parseSourceManifest(raw []byte, sourceURL string) (sourceManifest, error) {
 	var c ytDLPCollection
 	if err := json.Unmarshal(raw, &c); err != nil {
 		return sourceManifest{}, fmt.Errorf("parse yt-dlp source JSON: %w", err)
@@ -445,7 +538,11 @@ func fetchSourceManifest(sourceURL, cookiesPath, cookiesFromBrowser, jsRuntime s
 	}, nil
 }
 
-func mergeJobs(existing []model.Job, src sourceManifest) ([]model.Job, int) {
+// mergeJobs rebuilds the job list from the current source entries. Existing
+// jobs that are no longer listed are kept after them as tombstones, so their
+// history (and any saved media) stays tracked.
+func mergeJobs(existing []model.Job, src sourceManifest) ([]model.Job, int, int) {
+	now := time.Now().UTC().Format(time.RFC3339)
 	existingByVideoID := make(map[string]model.Job, len(existing))
 	for _, j := range existing {
 		id := strings.TrimSpace(j.VideoID)
@@ -459,19 +556,33 @@ func mergeJobs(existing []model.Job, src sourceManifest) ([]model.Job, int) {
 	}
 
 	jobs := make([]model.Job, 0, len(src.Entries))
+	listed := make(map[string]bool, len(src.Entries))
 	added := 0
 	for i, e := range src.Entries {
 		videoID := fallbackVideoID(e.ID, i+1)
 		jobID := fmt.Sprintf("%s:%d:%s", src.ID, i+1, videoID)
+		listed[videoID] = true
 
 		if old, ok := existingByVideoID[videoID]; ok {
 			old.JobID = jobID
 			old.Index = i + 1
 			old.VideoID = videoID
-			if e.Title != "" {
+			old.VideoURL = resolveVideoURL(videoID, e.VideoURL)
+
+			if strings.TrimSpace(e.Title) == deletedEntryTitle {
+				// Keep the title we knew; the placeholder carries no information.
+				markRemovedUpstream(&old, now)
+				jobs = append(jobs, old)
+				continue
+			}
+			if e.Title != "" && !e.Private && old.Title != "" && e.Title != old.Title {
+				old.AppendHistory(model.JobEvent{At: now, Kind: model.JobEventRetitled, From: old.Title, To: e.Title})
+			}
+			// A private placeholder title never replaces the one we knew.
+			if e.Title != "" && (!e.Private || old.Title == "") {
 				old.Title = e.Title
 			}
-			old.VideoURL = resolveVideoURL(videoID, e.VideoURL)
+			restoreUpstream(&old, now)
 
 			if e.Private {
 				if old.Status != model.StatusCompleted {
@@ -506,7 +617,21 @@ func mergeJobs(existing []model.Job, src sourceManifest) ([]model.Job, int) {
 		added++
 	}
 
-	return jobs, added
+	removed := 0
+	for _, j := range existing {
+		id := strings.TrimSpace(j.VideoID)
+		if id == "" || listed[id] {
+			continue
+		}
+		j = existingByVideoID[id]
+		markRemovedUpstream(&j, now)
+		j.Index = len(jobs) + 1
+		j.JobID = fmt.Sprintf("%s:%d:%s", src.ID, j.Index, id)
+		jobs = append(jobs, j)
+		removed++
+	}
+
+	return jobs, added, removed
 }
 
 func countJobs(jobs []model.Job) (pending int, skippedPrivate int) {
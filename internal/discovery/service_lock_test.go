@@ -14,7 +14,7 @@ func TestRefresh_FailsWhenRunDirectoryIsLocked(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	lock, err := runstore.AcquireRunLock(runDir)
+	lock, err := runstore.AcquireRunLock(runDir, 0)
 	if err != nil {
 		t.Fatalf("acquire lock: %v", err)
 	}
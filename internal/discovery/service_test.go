@@ -1,9 +1,11 @@
 package discovery
 
 import (
+	"path/filepath"
 	"testing"
 
 	"yt-vod-manager/internal/model"
+	"yt-vod-manager/internal/runstore"
 )
 
 func TestMergeJobs_PreservesStateAndAddsNew(t *testing.T) {
@@ -36,7 +38,7 @@ func TestMergeJobs_PreservesStateAndAddsNew(t *testing.T) {
 		},
 	}
 
-	jobs, added := mergeJobs(existing, src)
+	jobs, added, _ := mergeJobs(existing, src)
 	if added != 1 {
 		t.Fatalf("expected 1 added job, got %d", added)
 	}
@@ -72,7 +74,7 @@ func TestMergeJobs_ConvertsRunningToRetryable(t *testing.T) {
 		Entries: []sourceEntry{{ID: "a", Title: "A", VideoURL: "https://www.youtube.com/watch?v=a"}},
 	}
 
-	jobs, added := mergeJobs(existing, src)
+	jobs, added, _ := mergeJobs(existing, src)
 	if added != 0 {
 		t.Fatalf("expected no added jobs, got %d", added)
 	}
@@ -86,3 +88,134 @@ func TestMergeJobs_ConvertsRunningToRetryable(t *testing.T) {
 		t.Fatalf("expected interrupted reason, got %q", jobs[0].Reason)
 	}
 }
+
+func TestMergeJobs_KeepsRemovedVideosAsTombstones(t *testing.T) {
+	existing := []model.Job{
+		{JobID: "src:1:a", Index: 1, VideoID: "a", Title: "A", Status: model.StatusCompleted},
+		{JobID: "src:2:b", Index: 2, VideoID: "b", Title: "B", Status: model.StatusPending},
+		{JobID: "src:3:c", Index: 3, VideoID: "c", Title: "C", Status: model.StatusCompleted},
+		{JobID: "src:4:d", Index: 4, VideoID: "d", Title: "D", Status: model.StatusCompleted},
+	}
+	src := sourceManifest{
+		ID:    "src",
+		Title: "source",
+		Entries: []sourceEntry{
+			{ID: "c", Title: "C"},
+			{ID: "d", Title: "[Deleted video]", Private: true},
+		},
+	}
+
+	jobs, added, removed := mergeJobs(existing, src)
+	if added != 0 || removed != 2 {
+		t.Fatalf("expected 0 added and 2 removed, got %d and %d", added, removed)
+	}
+	if len(jobs) != 4 {
+		t.Fatalf("expected 4 jobs, got %d", len(jobs))
+	}
+	if jobs[0].VideoID != "c" || jobs[0].RemovedUpstream {
+		t.Fatalf("expected listed job c first and not removed, got %+v", jobs[0])
+	}
+	if d := jobs[1]; d.VideoID != "d" || !d.RemovedUpstream || d.Title != "D" || !d.LocalCopy {
+		t.Fatalf("expected deleted placeholder d tombstoned with title kept, got %+v", d)
+	}
+	a := jobs[2]
+	if a.VideoID != "a" || !a.RemovedUpstream || a.Status != model.StatusCompleted || !a.LocalCopy || a.RemovedAt == "" {
+		t.Fatalf("expected completed tombstone for a, got %+v", a)
+	}
+	if a.Index != 3 || a.JobID != "src:3:a" {
+		t.Fatalf("expected tombstone reindexed after listed entries, got index=%d job_id=%s", a.Index, a.JobID)
+	}
+	b := jobs[3]
+	if b.VideoID != "b" || !b.RemovedUpstream || b.LocalCopy || b.Status != model.StatusSkippedPrivate || b.Reason != "removed_upstream" {
+		t.Fatalf("expected pending b tombstoned as skipped, got %+v", b)
+	}
+
+	// A second refresh keeps the original removal time; a reappearing video is restored.
+	removedAt := a.RemovedAt
+	a.RemovedAt = "2020-01-01T00:00:00Z"
+	jobs[2] = a
+	src.Entries = []sourceEntry{{ID: "c", Title: "C"}, {ID: "b", Title: "B"}}
+	jobs, _, removed = mergeJobs(jobs, src)
+	if removed != 2 {
+		t.Fatalf("expected 2 tombstones kept, got %d", removed)
+	}
+	if jobs[1].VideoID != "b" || jobs[1].RemovedUpstream || jobs[1].Status != model.StatusPending {
+		t.Fatalf("expected b restored to pending, got %+v", jobs[1])
+	}
+	for _, j := range jobs {
+		if j.VideoID == "a" && j.RemovedAt != "2020-01-01T00:00:00Z" {
+			t.Fatalf("expected removal time preserved (was %s), got %s", removedAt, j.RemovedAt)
+		}
+	}
+}
+
+func TestCreateEmptyRunIsAdoptedByRefresh(t *testing.T) {
+	runsDir := t.TempDir()
+	source := "https://www.youtube.com/@talks"
+	runDir, err := CreateEmptyRun(runsDir, "", source, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found, err := findLatestRunDir(runsDir, "", source); err != nil || found != runDir {
+		t.Fatalf("expected the empty run to be found by source, got %q: %v", found, err)
+	}
+	var mf model.JobsManifest
+	if err := runstore.ReadJobsManifest(filepath.Join(runDir, "manifest.jobs.json"), &mf); err != nil {
+		t.Fatal(err)
+	}
+	if mf.SourceURL != source || mf.Profile != "default" || len(mf.Jobs) != 0 {
+		t.Fatalf("unexpected empty run manifest: %+v", mf)
+	}
+
+	// Imported jobs keep their state once the listing is merged in.
+	imported := []model.Job{
+		{JobID: "imported:1:a", Index: 1, VideoID: "a", Status: model.StatusCompleted, Reason: "imported"},
+		{JobID: "imported:2:z", Index: 2, VideoID: "z", Status: model.StatusCompleted, Reason: model.ReasonImportedArchive},
+	}
+	jobs, added, removed := mergeJobs(imported, sourceManifest{ID: "src", Entries: []sourceEntry{{ID: "a", Title: "A"}, {ID: "b", Title: "B"}}})
+	if added != 1 || removed != 1 {
+		t.Fatalf("expected 1 added and 1 removed, got %d and %d", added, removed)
+	}
+	if jobs[0].JobID != "src:1:a" || jobs[0].Status != model.StatusCompleted || jobs[0].Title != "A" {
+		t.Fatalf("expected imported job adopted by the listing, got %+v", jobs[0])
+	}
+	if z := jobs[2]; z.VideoID != "z" || !z.RemovedUpstream || z.Status != model.StatusCompleted {
+		t.Fatalf("expected unlisted import kept as a tombstone, got %+v", z)
+	}
+}
+
+func TestMergeJobs_EmptyListingKeepsJobs(t *testing.T) {
+	existing := []model.Job{{JobID: "src:1:a", Index: 1, VideoID: "a", Status: model.StatusCompleted}}
+	jobs, _, removed := mergeJobs(existing, sourceManifest{ID: "src"})
+	if removed != 1 || len(jobs) != 1 || !jobs[0].RemovedUpstream {
+		t.Fatalf("expected job kept as tombstone, got removed=%d jobs=%+v", removed, jobs)
+	}
+
+	jobs, _, removed = mergeJobs(jobs, sourceManifest{ID: "src", Entries: []sourceEntry{{ID: "a", Title: "A"}}})
+	if removed != 0 || jobs[0].RemovedUpstream || jobs[0].Status != model.StatusCompleted {
+		t.Fatalf("expected job restored once listed again, got %+v", jobs[0])
+	}
+}
+
+func TestMergeJobs_RecordsTitleChanges(t *testing.T) {
+	existing := []model.Job{
+		{JobID: "src:1:a", Index: 1, VideoID: "a", Title: "Old", Status: model.StatusCompleted},
+		{JobID: "src:2:b", Index: 2, VideoID: "b", Title: "Known", Status: model.StatusCompleted},
+	}
+	src := sourceManifest{
+		ID: "src",
+		Entries: []sourceEntry{
+			{ID: "a", Title: "New"},
+			{ID: "b", Title: "[Private video]", Private: true},
+		},
+	}
+
+	jobs, _, _ := mergeJobs(existing, src)
+	a := jobs[0]
+	if a.Title != "New" || len(a.History) != 1 || a.History[0].Kind != model.JobEventRetitled || a.History[0].From != "Old" || a.History[0].To != "New" {
+		t.Fatalf("expected retitle recorded for a, got %+v", a)
+	}
+	if b := jobs[1]; b.Title != "Known" || len(b.History) != 0 {
+		t.Fatalf("expected private placeholder to keep known title without event, got %+v", b)
+	}
+}
@@ -0,0 +1,33 @@
+package discovery
+
+import (
+	"bytes"
+	"os"
+	"time"
+
+	"yt-vod-manager/internal/runstore"
+)
+
+// saveSourceSnapshot records raw as the newest source snapshot. Call it before
+// rawPath is overwritten: runs created before snapshots existed get their
+// current manifest.raw.json kept as the first snapshot, so diff works at once.
+func saveSourceSnapshot(runDir, rawPath string, now time.Time, raw []byte, keep int) error {
+	if keep <= 0 {
+		keep = DefaultSnapshotRetention
+	}
+	existing, err := runstore.ListSnapshots(runDir)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		if st, statErr := os.Stat(rawPath); statErr == nil && st.ModTime().UTC().Before(now.Truncate(time.Second)) {
+			if prev, readErr := os.ReadFile(rawPath); readErr == nil && !bytes.Equal(prev, raw) {
+				if _, err := runstore.SaveSnapshot(runDir, st.ModTime(), prev, keep); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	_, err = runstore.SaveSnapshot(runDir, now, raw, keep)
+	return err
+}
@@ -0,0 +1,28 @@
+package discovery
+
+import (
+	"strings"
+
+	"yt-vod-manager/internal/objstore"
+)
+
+// StorageTarget is a project's S3-compatible archive destination.
+type StorageTarget = objstore.Target
+
+// normalizeStorageTarget trims a target; one without endpoint and bucket is
+// treated as unset.
+func normalizeStorageTarget(t *StorageTarget) *StorageTarget {
+	if t == nil {
+		return nil
+	}
+	out := *t
+	out.Endpoint = strings.TrimSpace(out.Endpoint)
+	out.Bucket = strings.TrimSpace(out.Bucket)
+	out.Prefix = strings.Trim(strings.TrimSpace(out.Prefix), "/")
+	out.Region = strings.TrimSpace(out.Region)
+	out.CredentialsFile = strings.TrimSpace(out.CredentialsFile)
+	if out.Endpoint == "" && out.Bucket == "" {
+		return nil
+	}
+	return &out
+}
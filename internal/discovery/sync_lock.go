@@ -0,0 +1,40 @@
+package discovery
+
+import (
+	"errors"
+	"time"
+
+	"yt-vod-manager/internal/runstore"
+)
+
+// SyncLockInfo describes the process currently running a sync, if any.
+type SyncLockInfo = runstore.RunLockInfo
+
+type SyncLockOptions = runstore.SyncLockOptions
+
+type SyncLock struct {
+	lock runstore.RunLock
+}
+
+// AcquireSyncLock serializes sync runs (manual, timer or daemon) across a
+// runs directory so they do not race on the project config or run discovery.
+func AcquireSyncLock(runsDir string, opts SyncLockOptions) (SyncLock, error) {
+	lock, err := runstore.AcquireSyncLock(runsDir, opts)
+	if err != nil {
+		return SyncLock{}, err
+	}
+	return SyncLock{lock: lock}, nil
+}
+
+func (l SyncLock) Release() error {
+	return l.lock.Release()
+}
+
+func InspectSyncLock(runsDir string, ttl time.Duration) (SyncLockInfo, error) {
+	return runstore.InspectSyncLock(runsDir, ttl)
+}
+
+// IsSyncLocked reports whether err came from a sync lock held by another process.
+func IsSyncLocked(err error) bool {
+	return errors.Is(err, runstore.ErrRunLocked)
+}
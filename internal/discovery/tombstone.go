@@ -0,0 +1,59 @@
+package discovery
+
+import (
+	"os"
+	"strings"
+
+	"yt-vod-manager/internal/model"
+)
+
+const (
+	reasonRemovedUpstream = "removed_upstream"
+	deletedEntryTitle     = "[Deleted video]"
+)
+
+// markRemovedUpstream turns a job into a tombstone. Jobs we never finished
+// can no longer be downloaded, so they leave the download queue; completed
+// jobs keep their status and record whether the media is still saved, on
+// disk or on the project's storage target.
+func markRemovedUpstream(j *model.Job, now string) {
+	if !j.RemovedUpstream {
+		j.RemovedUpstream = true
+		j.RemovedAt = now
+		j.AppendHistory(model.JobEvent{At: now, Kind: model.JobEventRemovedUpstream, From: j.Status})
+	}
+	j.LocalCopy = hasLocalCopy(*j)
+	if j.Status != model.StatusCompleted && j.Status != model.StatusSkippedPrivate {
+		if err := model.TransitionJobStatus(j, model.StatusSkippedPrivate, reasonRemovedUpstream); err != nil {
+			j.Status = model.StatusSkippedPrivate
+			j.Reason = reasonRemovedUpstream
+		}
+	}
+}
+
+// restoreUpstream clears the tombstone of a video that is listed again.
+func restoreUpstream(j *model.Job, now string) {
+	if !j.RemovedUpstream {
+		return
+	}
+	j.RemovedUpstream = false
+	j.RemovedAt = ""
+	j.LocalCopy = false
+	j.AppendHistory(model.JobEvent{At: now, Kind: model.JobEventRestoredUpstream})
+}
+
+func hasLocalCopy(j model.Job) bool {
+	if j.Status != model.StatusCompleted {
+		return false
+	}
+	if j.RemoteKey != "" {
+		return true
+	}
+	path := strings.TrimSpace(j.MediaPath)
+	if path == "" {
+		// Older manifests did not record the media path; trust the status.
+		return true
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
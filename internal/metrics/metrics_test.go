@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteTextRendersFamilies(t *testing.T) {
+	families := []Family{
+		{Name: "ytvm_jobs", Help: "Jobs by status.", Type: TypeGauge, Samples: []Sample{
+			{Labels: []Label{{Name: "project", Value: `a"b`}, {Name: "status", Value: "completed"}}, Value: 3},
+		}},
+		{Name: "ytvm_empty", Help: "Skipped.", Type: TypeCounter},
+	}
+	var buf bytes.Buffer
+	if err := WriteText(&buf, families); err != nil {
+		t.Fatalf("write text: %v", err)
+	}
+	want := "# HELP ytvm_jobs Jobs by status.\n# TYPE ytvm_jobs gauge\nytvm_jobs{project=\"a\\\"b\",status=\"completed\"} 3\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestStateRoundTripAndHistogram(t *testing.T) {
+	path := StatePath(t.TempDir())
+	st := NewState()
+	p := st.Project("talks")
+	p.DownloadSeconds.Observe(5)
+	p.DownloadSeconds.Observe(45)
+	p.DownloadSeconds.Observe(99999)
+	p.Syncs[SyncResultSuccess]++
+	if err := st.Save(path); err != nil {
+		t.Fatalf("save state: %v", err)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("load state: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteText(&buf, loaded.Families()); err != nil {
+		t.Fatalf("write text: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`ytvm_download_duration_seconds_bucket{project="talks",le="10"} 1`,
+		`ytvm_download_duration_seconds_bucket{project="talks",le="60"} 2`,
+		`ytvm_download_duration_seconds_bucket{project="talks",le="+Inf"} 3`,
+		`ytvm_download_duration_seconds_count{project="talks"} 3`,
+		`ytvm_syncs_total{project="talks",result="success"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in output:\n%s", want, out)
+		}
+	}
+
+	missing, err := LoadState(filepath.Join(t.TempDir(), "absent.json"))
+	if err != nil || len(missing.Projects) != 0 {
+		t.Fatalf("expected empty state for missing file, got %+v err=%v", missing, err)
+	}
+}
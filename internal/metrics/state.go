@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	StateFileName = "metrics-state.json"
+
+	SyncResultSuccess = "success"
+	SyncResultFailure = "failure"
+)
+
+// DownloadDurationBuckets are upper bounds in seconds for per-video download time.
+var DownloadDurationBuckets = []float64{10, 30, 60, 120, 300, 600, 1200, 1800, 3600, 7200}
+
+type Histogram struct {
+	Bounds []float64 `json:"bounds"`
+	Counts []uint64  `json:"counts"`
+	Sum    float64   `json:"sum"`
+	Count  uint64    `json:"count"`
+}
+
+func (h *Histogram) Observe(v float64) {
+	if len(h.Bounds) == 0 {
+		h.Bounds = append([]float64(nil), DownloadDurationBuckets...)
+	}
+	if len(h.Counts) != len(h.Bounds) {
+		h.Counts = make([]uint64, len(h.Bounds))
+	}
+	for i, bound := range h.Bounds {
+		if v <= bound {
+			h.Counts[i]++
+			break
+		}
+	}
+	h.Sum += v
+	h.Count++
+}
+
+type ProjectState struct {
+	DownloadedBytes    float64            `json:"downloaded_bytes"`
+	DownloadSeconds    Histogram          `json:"download_seconds"`
+	Errors             map[string]float64 `json:"errors,omitempty"`
+	LockContention     float64            `json:"lock_contention"`
+	Syncs              map[string]float64 `json:"syncs,omitempty"`
+	LastRefreshSeconds float64            `json:"last_refresh_seconds"`
+	LastSuccessUnix    float64            `json:"last_success_unix,omitempty"`
+}
+
+// State holds counters accumulated across sync invocations, keyed by project.
+type State struct {
+	SchemaVersion int                      `json:"schema_version"`
+	UpdatedAt     string                   `json:"updated_at,omitempty"`
+	Projects      map[string]*ProjectState `json:"projects"`
+}
+
+func StatePath(runsDir string) string {
+	return filepath.Join(runsDir, StateFileName)
+}
+
+func NewState() *State {
+	return &State{SchemaVersion: 1, Projects: map[string]*ProjectState{}}
+}
+
+// LoadState returns an empty state when the file does not exist yet.
+func LoadState(path string) (*State, error) {
+	st := NewState()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return st, nil
+		}
+		return nil, fmt.Errorf("read metrics state %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("parse metrics state %s: %w", path, err)
+	}
+	if st.Projects == nil {
+		st.Projects = map[string]*ProjectState{}
+	}
+	return st, nil
+}
+
+func (s *State) Save(path string) error {
+	s.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal metrics state: %w", err)
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create metrics state dir %s: %w", dir, err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write metrics state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("replace metrics state %s: %w", path, err)
+	}
+	return nil
+}
+
+func (s *State) Project(name string) *ProjectState {
+	p, ok := s.Projects[name]
+	if !ok || p == nil {
+		p = &ProjectState{}
+		s.Projects[name] = p
+	}
+	if p.Errors == nil {
+		p.Errors = map[string]float64{}
+	}
+	if p.Syncs == nil {
+		p.Syncs = map[string]float64{}
+	}
+	return p
+}
+
+// Families renders the accumulated counters and last-sync gauges.
+func (s *State) Families() []Family {
+	names := make([]string, 0, len(s.Projects))
+	for name := range s.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bytes := Family{Name: "ytvm_downloaded_bytes_total", Help: "Bytes of media downloaded by archive runs.", Type: TypeCounter}
+	durations := Family{Name: "ytvm_download_duration_seconds", Help: "Wall time of successful video downloads.", Type: TypeHistogram}
+	errs := Family{Name: "ytvm_download_errors_total", Help: "Failed download attempts by error class.", Type: TypeCounter}
+	locks := Family{Name: "ytvm_lock_contention_total", Help: "Runs skipped because the run directory was locked by another process.", Type: TypeCounter}
+	syncs := Family{Name: "ytvm_syncs_total", Help: "Sync attempts by result.", Type: TypeCounter}
+	refresh := Family{Name: "ytvm_refresh_duration_seconds", Help: "Duration of the most recent source refresh.", Type: TypeGauge}
+	lastSuccess := Family{Name: "ytvm_last_success_timestamp_seconds", Help: "Unix time of the last sync that finished without errors.", Type: TypeGauge}
+
+	for _, name := range names {
+		p := s.Projects[name]
+		project := Label{Name: "project", Value: name}
+		bytes.Samples = append(bytes.Samples, Sample{Labels: []Label{project}, Value: p.DownloadedBytes})
+		durations.Samples = append(durations.Samples, histogramSamples(durations.Name, project, p.DownloadSeconds)...)
+		for _, class := range sortedKeys(p.Errors) {
+			errs.Samples = append(errs.Samples, Sample{Labels: []Label{project, {Name: "class", Value: class}}, Value: p.Errors[class]})
+		}
+		locks.Samples = append(locks.Samples, Sample{Labels: []Label{project}, Value: p.LockContention})
+		for _, result := range sortedKeys(p.Syncs) {
+			syncs.Samples = append(syncs.Samples, Sample{Labels: []Label{project, {Name: "result", Value: result}}, Value: p.Syncs[result]})
+		}
+		refresh.Samples = append(refresh.Samples, Sample{Labels: []Label{project}, Value: p.LastRefreshSeconds})
+		if p.LastSuccessUnix > 0 {
+			lastSuccess.Samples = append(lastSuccess.Samples, Sample{Labels: []Label{project}, Value: p.LastSuccessUnix})
+		}
+	}
+	return []Family{bytes, durations, errs, locks, syncs, refresh, lastSuccess}
+}
+
+func histogramSamples(name string, project Label, h Histogram) []Sample {
+	bounds := h.Bounds
+	if len(bounds) == 0 {
+		bounds = DownloadDurationBuckets
+	}
+	out := make([]Sample, 0, len(bounds)+3)
+	cumulative := uint64(0)
+	for i, bound := range bounds {
+		if i < len(h.Counts) {
+			cumulative += h.Counts[i]
+		}
+		out = append(out, Sample{
+			Name:   name + "_bucket",
+			Labels: []Label{project, {Name: "le", Value: strconv.FormatFloat(bound, 'g', -1, 64)}},
+			Value:  float64(cumulative),
+		})
+	}
+	out = append(out,
+		Sample{Name: name + "_bucket", Labels: []Label{project, {Name: "le", Value: formatValue(math.Inf(1))}}, Value: float64(h.Count)},
+		Sample{Name: name + "_sum", Labels: []Label{project}, Value: h.Sum},
+		Sample{Name: name + "_count", Labels: []Label{project}, Value: float64(h.Count)},
+	)
+	return out
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FailureRule maps a yt-dlp error message to a failure reason. Rules are
+// checked in order and the first match wins.
+type FailureRule struct {
+	// Pattern is a case-insensitive substring, or a Go regular expression
+	// when Regex is set.
+	Pattern string `json:"pattern"`
+	Regex   bool   `json:"regex,omitempty"`
+	// Reason is stored as the job's reason, e.g. "members_only".
+	Reason string `json:"reason"`
+	// Class groups reasons for metrics and history; defaults to Reason.
+	Class       string `json:"class,omitempty"`
+	Retryable   bool   `json:"retryable"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+func (r FailureRule) Validate() error {
+	if strings.TrimSpace(r.Pattern) == "" {
+		return fmt.Errorf("failure rule pattern is required")
+	}
+	if strings.TrimSpace(r.Reason) == "" {
+		return fmt.Errorf("failure rule %q: reason is required", r.Pattern)
+	}
+	if r.Regex {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("failure rule %q: %w", r.Pattern, err)
+		}
+	}
+	return nil
+}
@@ -54,6 +54,18 @@ var allowedTransitions = map[string]map[string]bool{
 	},
 }
 
+// KnownStatuses lists job statuses in lifecycle order, for reports and totals.
+func KnownStatuses() []string {
+	return []string{
+		StatusCompleted,
+		StatusPending,
+		StatusRunning,
+		StatusFailedRetryable,
+		StatusFailedPermanent,
+		StatusSkippedPrivate,
+	}
+}
+
 func IsKnownStatus(status string) bool {
 	_, ok := allowedTransitions[status]
 	return ok
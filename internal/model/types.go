@@ -1,5 +1,7 @@
 package model
 
+import "strings"
+
 // JobsManifest is the canonical per-run job state file.
 type JobsManifest struct {
 	SchemaVersion   int    `json:"schema_version"`
@@ -34,4 +36,155 @@ type Job struct {
 	LastError     string `json:"last_error,omitempty"`
 	LastAttemptAt string `json:"last_attempt_at,omitempty"`
 	CompletedAt   string `json:"completed_at,omitempty"`
+
+	FormatID   string `json:"format_id,omitempty"`
+	Height     int    `json:"height,omitempty"`
+	VideoCodec string `json:"video_codec,omitempty"`
+	MediaPath  string `json:"media_path,omitempty"`
+
+	// RemoteKey and RemoteETag record the media object on the project's
+	// storage target once it is uploaded.
+	RemoteKey  string `json:"remote_key,omitempty"`
+	RemoteETag string `json:"remote_etag,omitempty"`
+
+	// Replicas track copies of the media on the configured mirror directories.
+	Replicas []Replica `json:"replicas,omitempty"`
+
+	// RemovedUpstream marks a tombstone: the video no longer appears in the
+	// source (deleted, or dropped from the playlist) but the job is kept.
+	RemovedUpstream bool   `json:"removed_upstream,omitempty"`
+	RemovedAt       string `json:"removed_at,omitempty"`
+	LocalCopy       bool   `json:"local_copy,omitempty"`
+
+	History []JobEvent `json:"history,omitempty"`
+}
+
+const (
+	JobEventFormatUpgrade    = "format_upgrade"
+	JobEventAttempt          = "attempt"
+	JobEventRemovedUpstream  = "removed_upstream"
+	JobEventRestoredUpstream = "restored_upstream"
+	JobEventRetitled         = "retitled"
+	JobEventRenamed          = "renamed"
+	JobEventImported         = "imported"
+
+	// MaxJobAttempts caps the attempt entries kept in a job's History; older
+	// attempts are dropped first. Other event kinds are not counted.
+	MaxJobAttempts = 20
+	// MaxJobHistory caps the whole History; the oldest events of any kind
+	// are dropped first.
+	MaxJobHistory = 50
+)
+
+// ReasonImportedArchive completes a job listed in an imported yt-dlp download
+// archive when no file for it was found: the media is kept outside this tool,
+// so runs neither re-download it nor look for it on disk.
+const ReasonImportedArchive = "imported_archive"
+
+// Replica statuses. A configured mirror without a replica entry has not been
+// attempted yet.
+const (
+	ReplicaCopied = "copied"
+	ReplicaFailed = "failed"
+)
+
+// Replica is the copy of a job's media (and sidecars) on one mirror. Size and
+// SHA256 describe the media file as verified after the copy.
+type Replica struct {
+	Mirror   string `json:"mirror"`
+	Status   string `json:"status"`
+	Path     string `json:"path,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`
+	CopiedAt string `json:"copied_at,omitempty"`
+	Attempts int    `json:"attempts,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type JobEvent struct {
+	At   string `json:"at"`
+	Kind string `json:"kind"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	// Attempt fields.
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Worker     int    `json:"worker,omitempty"`
+	Proxy      string `json:"proxy,omitempty"`
+	Outcome    string `json:"outcome,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	ErrorClass string `json:"error_class,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Log        string `json:"log,omitempty"`
+}
+
+// AppendHistory records ev and trims the oldest events beyond MaxJobHistory.
+// Every History append goes through here so no event kind grows unbounded.
+func (j *Job) AppendHistory(ev JobEvent) {
+	j.History = append(j.History, ev)
+	if drop := len(j.History) - MaxJobHistory; drop > 0 {
+		j.History = append(j.History[:0:0], j.History[drop:]...)
+	}
+}
+
+// AppendAttempt records an attempt event and trims the oldest attempts beyond
+// MaxJobAttempts.
+func (j *Job) AppendAttempt(ev JobEvent) {
+	ev.Kind = JobEventAttempt
+	j.AppendHistory(ev)
+	attempts := 0
+	for _, e := range j.History {
+		if e.Kind == JobEventAttempt {
+			attempts++
+		}
+	}
+	if attempts <= MaxJobAttempts {
+		return
+	}
+	drop := attempts - MaxJobAttempts
+	kept := j.History[:0]
+	for _, e := range j.History {
+		if e.Kind == JobEventAttempt && drop > 0 {
+			drop--
+			continue
+		}
+		kept = append(kept, e)
+	}
+	j.History = kept
+}
+
+// Replica returns the job's replica entry for mirror, if any.
+func (j *Job) Replica(mirror string) (Replica, bool) {
+	for _, r := range j.Replicas {
+		if r.Mirror == mirror {
+			return r, true
+		}
+	}
+	return Replica{}, false
+}
+
+// SetReplica adds or replaces the replica entry for r.Mirror.
+func (j *Job) SetReplica(r Replica) {
+	for i := range j.Replicas {
+		if j.Replicas[i].Mirror == r.Mirror {
+			j.Replicas[i] = r
+			return
+		}
+	}
+	j.Replicas = append(j.Replicas, r)
+}
+
+// Replicated reports whether every mirror holds a verified copy.
+func (j *Job) Replicated(mirrors []string) bool {
+	for _, m := range mirrors {
+		if r, ok := j.Replica(m); !ok || r.Status != ReplicaCopied {
+			return false
+		}
+	}
+	return true
+}
+
+// HeldElsewhere reports a job completed from a download archive alone.
+func (j *Job) HeldElsewhere() bool {
+	return j.Status == StatusCompleted && j.Reason == ReasonImportedArchive && strings.TrimSpace(j.MediaPath) == ""
 }
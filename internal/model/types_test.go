@@ -0,0 +1,38 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAppendAttemptCapsAttemptsOnly(t *testing.T) {
+	job := Job{History: []JobEvent{{At: "t0", Kind: JobEventFormatUpgrade, From: "720p", To: "1080p"}}}
+	for i := 1; i <= MaxJobAttempts+5; i++ {
+		job.AppendAttempt(JobEvent{At: fmt.Sprintf("t%d", i), Outcome: StatusFailedRetryable})
+	}
+
+	if len(job.History) != MaxJobAttempts+1 {
+		t.Fatalf("expected %d entries, got %d", MaxJobAttempts+1, len(job.History))
+	}
+	if job.History[0].Kind != JobEventFormatUpgrade {
+		t.Fatalf("expected non-attempt event to be kept, got %+v", job.History[0])
+	}
+	if job.History[1].At != "t6" || job.History[len(job.History)-1].At != fmt.Sprintf("t%d", MaxJobAttempts+5) {
+		t.Fatalf("expected oldest attempts dropped, got first=%s last=%s", job.History[1].At, job.History[len(job.History)-1].At)
+	}
+}
+
+func TestAppendHistoryCapsAllEvents(t *testing.T) {
+	var job Job
+	for i := 1; i <= MaxJobHistory+5; i++ {
+		job.AppendHistory(JobEvent{At: fmt.Sprintf("t%d", i), Kind: JobEventRetitled})
+	}
+	job.AppendAttempt(JobEvent{At: "last", Outcome: StatusCompleted})
+
+	if len(job.History) != MaxJobHistory {
+		t.Fatalf("expected %d entries, got %d", MaxJobHistory, len(job.History))
+	}
+	if job.History[0].At != "t7" || job.History[len(job.History)-1].At != "last" {
+		t.Fatalf("expected oldest events dropped, got first=%s last=%s", job.History[0].At, job.History[len(job.History)-1].At)
+	}
+}
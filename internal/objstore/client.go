@@ -0,0 +1,295 @@
+package objstore
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// uploadAttempts bounds retries of a single PUT or part upload.
+const uploadAttempts = 3
+
+// Upload stores the file at path under key. Files larger than one part go
+// through a multipart upload, which is aborted again if any part fails.
+func (c *Client) Upload(key, path string) (Object, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Object{}, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return Object{}, err
+	}
+	size := st.Size()
+	if size <= c.partSize {
+		etag, err := c.putPart(key, nil, io.NewSectionReader(f, 0, size), size)
+		if err != nil {
+			return Object{}, err
+		}
+		return Object{Key: key, ETag: etag, Size: size}, nil
+	}
+	return c.uploadMultipart(key, f, size)
+}
+
+func (c *Client) uploadMultipart(key string, f *os.File, size int64) (Object, error) {
+	partSize := c.partSize
+	if size > partSize*maxParts {
+		partSize = (size + maxParts - 1) / maxParts
+	}
+
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := c.doXML(http.MethodPost, key, url.Values{"uploads": {""}}, nil, "create multipart upload", &initiated); err != nil {
+		return Object{}, err
+	}
+	if initiated.UploadID == "" {
+		return Object{}, fmt.Errorf("storage create multipart upload %s: no upload id in response", key)
+	}
+
+	type completedPart struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	complete := struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{}
+	for n, off := 1, int64(0); off < size; n, off = n+1, off+partSize {
+		length := min(partSize, size-off)
+		q := url.Values{"partNumber": {strconv.Itoa(n)}, "uploadId": {initiated.UploadID}}
+		etag, err := c.putPart(key, q, io.NewSectionReader(f, off, length), length)
+		if err != nil {
+			_ = c.abortMultipart(key, initiated.UploadID)
+			return Object{}, err
+		}
+		complete.Parts = append(complete.Parts, completedPart{PartNumber: n, ETag: `"` + etag + `"`})
+	}
+
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		_ = c.abortMultipart(key, initiated.UploadID)
+		return Object{}, err
+	}
+	var completed struct {
+		ETag string `xml:"ETag"`
+	}
+	if err := c.doXML(http.MethodPost, key, url.Values{"uploadId": {initiated.UploadID}}, body, "complete multipart upload", &completed); err != nil {
+		_ = c.abortMultipart(key, initiated.UploadID)
+		return Object{}, err
+	}
+	return Object{Key: key, ETag: trimETag(completed.ETag), Size: size}, nil
+}
+
+func (c *Client) putPart(key string, q url.Values, body *io.SectionReader, length int64) (string, error) {
+	var lastErr error
+	for attempt := 1; attempt <= uploadAttempts; attempt++ {
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		var rc io.ReadCloser
+		if length > 0 {
+			// An empty body must go out as Content-Length: 0, not chunked.
+			rc = io.NopCloser(body)
+		}
+		req, err := c.newRequest(http.MethodPut, key, q, rc, unsignedPayload)
+		if err != nil {
+			return "", err
+		}
+		req.ContentLength = length
+		resp, err := c.http.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("storage upload %s: %w", key, err)
+			continue
+		}
+		err = checkResponse(resp, "upload", key)
+		etag := trimETag(resp.Header.Get("ETag"))
+		drainClose(resp)
+		if err == nil {
+			return etag, nil
+		}
+		lastErr = err
+		var se *Error
+		if errors.As(err, &se) && se.StatusCode < 500 {
+			break
+		}
+	}
+	return "", lastErr
+}
+
+func (c *Client) abortMultipart(key, uploadID string) error {
+	resp, err := c.send(http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil)
+	if err != nil {
+		return err
+	}
+	defer drainClose(resp)
+	return checkResponse(resp, "abort multipart upload", key)
+}
+
+// Head returns the object's ETag and size, or ErrNotFound.
+func (c *Client) Head(key string) (Object, error) {
+	resp, err := c.send(http.MethodHead, key, nil, nil)
+	if err != nil {
+		return Object{}, err
+	}
+	defer drainClose(resp)
+	if resp.StatusCode == http.StatusNotFound {
+		return Object{}, fmt.Errorf("storage head %s: %w", key, ErrNotFound)
+	}
+	if err := checkResponse(resp, "head", key); err != nil {
+		return Object{}, err
+	}
+	return Object{Key: key, ETag: trimETag(resp.Header.Get("ETag")), Size: resp.ContentLength}, nil
+}
+
+// List returns every object whose key starts with prefix.
+func (c *Client) List(prefix string) ([]Object, error) {
+	var out []Object
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		var page struct {
+			Contents []struct {
+				Key  string `xml:"Key"`
+				ETag string `xml:"ETag"`
+				Size int64  `xml:"Size"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		if err := c.doXML(http.MethodGet, "", q, nil, "list", &page); err != nil {
+			return nil, err
+		}
+		for _, o := range page.Contents {
+			out = append(out, Object{Key: o.Key, ETag: trimETag(o.ETag), Size: o.Size})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return out, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+func (c *Client) Delete(key string) error {
+	resp, err := c.send(http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer drainClose(resp)
+	return checkResponse(resp, "delete", key)
+}
+
+func (c *Client) doXML(method, key string, q url.Values, body []byte, op string, out any) error {
+	resp, err := c.send(method, key, q, body)
+	if err != nil {
+		return err
+	}
+	defer drainClose(resp)
+	if err := checkResponse(resp, op, key); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("storage %s %s: %w", op, key, err)
+	}
+	// S3 can report a failed CompleteMultipartUpload in a 200 response.
+	if err := parseErrorBody(data, resp.StatusCode, op, key); err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("storage %s %s: parse response: %w", op, key, err)
+	}
+	return nil
+}
+
+func (c *Client) send(method, key string, q url.Values, body []byte) (*http.Response, error) {
+	payloadHash := emptyPayloadHash
+	var rc io.ReadCloser
+	if len(body) > 0 {
+		payloadHash = hashHex(body)
+		rc = io.NopCloser(bytes.NewReader(body))
+	}
+	req, err := c.newRequest(method, key, q, rc, payloadHash)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("storage %s %s: %w", strings.ToLower(method), key, err)
+	}
+	return resp, nil
+}
+
+func (c *Client) newRequest(method, key string, q url.Values, body io.ReadCloser, payloadHash string) (*http.Request, error) {
+	u := *c.endpoint
+	base := strings.TrimRight(u.Path, "/")
+	bucket := strings.TrimSpace(c.target.Bucket)
+	if c.target.VirtualHosted {
+		u.Host = bucket + "." + u.Host
+	} else {
+		base += "/" + bucket
+	}
+	if key != "" || base == "" {
+		base += "/"
+	}
+	u.Path = base + key
+	u.RawPath = uriEncode(base, false) + uriEncode(key, false)
+	u.RawQuery = canonicalQuery(q)
+
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Body = body
+	}
+	signRequest(req, c.creds, c.target.Region, payloadHash, c.now())
+	return req, nil
+}
+
+func checkResponse(resp *http.Response, op, key string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err := parseErrorBody(data, resp.StatusCode, op, key); err != nil {
+		return err
+	}
+	return &Error{StatusCode: resp.StatusCode, Op: op, Key: key}
+}
+
+func parseErrorBody(data []byte, status int, op, key string) error {
+	if !bytes.Contains(data, []byte("<Error>")) {
+		return nil
+	}
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	_ = xml.Unmarshal(data, &e)
+	if status < 300 {
+		status = http.StatusInternalServerError
+	}
+	return &Error{StatusCode: status, Code: e.Code, Message: e.Message, Op: op, Key: key}
+}
+
+func drainClose(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	_ = resp.Body.Close()
+}
+
+func trimETag(etag string) string {
+	return strings.Trim(strings.TrimSpace(etag), `"`)
+}
@@ -0,0 +1,153 @@
+package objstore_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"yt-vod-manager/internal/objstore"
+	"yt-vod-manager/internal/objstore/objstoretest"
+)
+
+func newTestClient(t *testing.T, srv *objstoretest.Server) *objstore.Client {
+	t.Helper()
+	c, err := objstore.New(srv.Target("archive"))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return c
+}
+
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestUploadHeadListDelete(t *testing.T) {
+	srv := objstoretest.NewServer(t, "media")
+	c := newTestClient(t, srv)
+
+	key := c.Target().Key("sub dir/20240101_Title_[abc123XYZ].mp4")
+	obj, err := c.Upload(key, writeTestFile(t, "v.mp4", []byte("video bytes")))
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if obj.Key != "archive/sub dir/20240101_Title_[abc123XYZ].mp4" || obj.ETag == "" || obj.Size != 11 {
+		t.Fatalf("unexpected object: %+v", obj)
+	}
+	if data, ok := srv.Object(obj.Key); !ok || string(data) != "video bytes" {
+		t.Fatalf("stored object = %q, %v", data, ok)
+	}
+
+	head, err := c.Head(obj.Key)
+	if err != nil || head.ETag != obj.ETag || head.Size != 11 {
+		t.Fatalf("head = %+v, %v", head, err)
+	}
+	if _, err := c.Head(c.Target().Key("missing")); !errors.Is(err, objstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	if _, err := c.Upload(c.Target().Key("empty.txt"), writeTestFile(t, "empty.txt", nil)); err != nil {
+		t.Fatalf("upload empty file: %v", err)
+	}
+	srv.Put("other/outside.mp4", []byte("x"))
+	srv.PageSize = 1
+	objects, err := c.List("archive/")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(objects) != 2 || objects[0].Key != "archive/empty.txt" || objects[1].ETag != obj.ETag {
+		t.Fatalf("unexpected listing: %+v", objects)
+	}
+
+	if err := c.Delete(obj.Key); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, ok := srv.Object(obj.Key); ok {
+		t.Fatal("object still stored after delete")
+	}
+}
+
+func TestUploadLargeFileUsesMultipart(t *testing.T) {
+	srv := objstoretest.NewServer(t, "media")
+	c := newTestClient(t, srv)
+	objstore.SetPartSize(c, 1024)
+
+	data := bytes.Repeat([]byte("0123456789"), 350)
+	srv.FailNextPuts(1)
+	obj, err := c.Upload("big.mkv", writeTestFile(t, "big.mkv", data))
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if got, _ := srv.Object("big.mkv"); !bytes.Equal(got, data) {
+		t.Fatalf("multipart object corrupted: %d bytes stored", len(got))
+	}
+	if want := "-4"; len(obj.ETag) < 3 || obj.ETag[len(obj.ETag)-2:] != want {
+		t.Fatalf("expected a 4-part multipart etag, got %q", obj.ETag)
+	}
+	if srv.PendingUploads() != 0 {
+		t.Fatalf("multipart upload left open")
+	}
+}
+
+func TestFailedMultipartUploadIsAborted(t *testing.T) {
+	srv := objstoretest.NewServer(t, "media")
+	c := newTestClient(t, srv)
+	objstore.SetPartSize(c, 1024)
+
+	srv.FailNextPuts(10)
+	if _, err := c.Upload("big.mkv", writeTestFile(t, "big.mkv", make([]byte, 3000))); err == nil {
+		t.Fatal("expected upload to fail")
+	}
+	if srv.PendingUploads() != 0 {
+		t.Fatal("failed multipart upload was not aborted")
+	}
+	if len(srv.Keys()) != 0 {
+		t.Fatalf("unexpected objects after failed upload: %v", srv.Keys())
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	if _, err := objstore.LoadCredentials(""); err == nil {
+		t.Fatal("expected missing env credentials to fail")
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "env-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	c, err := objstore.LoadCredentials("")
+	if err != nil || c.AccessKeyID != "env-key" || c.SecretAccessKey != "env-secret" {
+		t.Fatalf("env credentials = %+v, %v", c, err)
+	}
+
+	path := writeTestFile(t, "credentials", []byte("[backup]\naws_access_key_id=b\naws_secret_access_key=b\n\n[default]\naws_access_key_id = file-key\naws_secret_access_key = file-secret\n"))
+	c, err = objstore.LoadCredentials(path)
+	if err != nil || c.AccessKeyID != "file-key" || c.SecretAccessKey != "file-secret" {
+		t.Fatalf("file credentials = %+v, %v", c, err)
+	}
+}
+
+func TestTargetValidateAndKey(t *testing.T) {
+	if err := (objstore.Target{Bucket: "b"}).Validate(); err == nil {
+		t.Fatal("expected missing endpoint to fail")
+	}
+	if err := (objstore.Target{Endpoint: "ftp://host", Bucket: "b"}).Validate(); err == nil {
+		t.Fatal("expected non-http endpoint to fail")
+	}
+	target := objstore.Target{Endpoint: "minio.local:9000", Bucket: "b", Prefix: "/vods/"}
+	if err := target.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if got := target.Key("a/b.mp4"); got != "vods/a/b.mp4" {
+		t.Fatalf("key = %q", got)
+	}
+	if got := target.String(); got != "s3://b/vods" {
+		t.Fatalf("string = %q", got)
+	}
+}
@@ -0,0 +1,5 @@
+package objstore
+
+func SetPartSize(c *Client, n int64) {
+	c.partSize = n
+}
@@ -0,0 +1,216 @@
+package objstore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultRegion = "us-east-1"
+
+	defaultPartSize = 16 << 20
+	maxParts        = 10000
+)
+
+// ErrNotFound is returned by Head for keys that do not exist.
+var ErrNotFound = errors.New("object not found")
+
+// Target is where a project's archive lives on S3-compatible storage. Keys are
+// <prefix>/<path relative to the output dir>. Requests are path-style
+// (endpoint/bucket/key) unless VirtualHosted is set, which AWS requires for
+// newer buckets; MinIO and most self-hosted stores want path-style.
+type Target struct {
+	Endpoint        string `json:"endpoint"`
+	Bucket          string `json:"bucket"`
+	Prefix          string `json:"prefix,omitempty"`
+	Region          string `json:"region,omitempty"`
+	VirtualHosted   bool   `json:"virtual_hosted,omitempty"`
+	CredentialsFile string `json:"credentials_file,omitempty"`
+	// DeleteLocal removes local media and sidecars once they are uploaded.
+	DeleteLocal bool `json:"delete_local,omitempty"`
+}
+
+func (t Target) Validate() error {
+	if strings.TrimSpace(t.Endpoint) == "" {
+		return fmt.Errorf("storage endpoint is required")
+	}
+	if strings.TrimSpace(t.Bucket) == "" {
+		return fmt.Errorf("storage bucket is required")
+	}
+	if _, err := t.endpointURL(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Key joins the target prefix and a slash-separated relative path.
+func (t Target) Key(rel string) string {
+	prefix := strings.Trim(strings.TrimSpace(t.Prefix), "/")
+	rel = strings.TrimLeft(rel, "/")
+	if prefix == "" {
+		return rel
+	}
+	return prefix + "/" + rel
+}
+
+// String renders the target for listings, e.g. s3://bucket/prefix.
+func (t Target) String() string {
+	return "s3://" + path.Join(strings.TrimSpace(t.Bucket), strings.Trim(strings.TrimSpace(t.Prefix), "/"))
+}
+
+func (t Target) endpointURL() (*url.URL, error) {
+	raw := strings.TrimRight(strings.TrimSpace(t.Endpoint), "/")
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid storage endpoint %q", t.Endpoint)
+	}
+	return u, nil
+}
+
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// LoadCredentials reads credentials from file when one is given, otherwise
+// from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN. The
+// file uses the AWS shared credentials format; the [default] section (or
+// the first one) is used, and a file without sections works too.
+func LoadCredentials(file string) (Credentials, error) {
+	if strings.TrimSpace(file) == "" {
+		c := Credentials{
+			AccessKeyID:     strings.TrimSpace(os.Getenv("AWS_ACCESS_KEY_ID")),
+			SecretAccessKey: strings.TrimSpace(os.Getenv("AWS_SECRET_ACCESS_KEY")),
+			SessionToken:    strings.TrimSpace(os.Getenv("AWS_SESSION_TOKEN")),
+		}
+		if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+			return Credentials{}, fmt.Errorf("storage credentials missing: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY or a credentials file")
+		}
+		return c, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("open credentials file %s: %w", file, err)
+	}
+	defer f.Close()
+
+	sections := map[string]map[string]string{}
+	first := ""
+	current := ""
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			if first == "" {
+				first = current
+			}
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if sections[current] == nil {
+			sections[current] = map[string]string{}
+		}
+		sections[current][strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+	if err := sc.Err(); err != nil {
+		return Credentials{}, fmt.Errorf("read credentials file %s: %w", file, err)
+	}
+
+	values := sections[""]
+	if v, ok := sections["default"]; ok {
+		values = v
+	} else if values == nil {
+		values = sections[first]
+	}
+	c := Credentials{
+		AccessKeyID:     values["aws_access_key_id"],
+		SecretAccessKey: values["aws_secret_access_key"],
+		SessionToken:    values["aws_session_token"],
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("credentials file %s has no aws_access_key_id/aws_secret_access_key", file)
+	}
+	return c, nil
+}
+
+// Object is a stored object as reported by the store.
+type Object struct {
+	Key  string `json:"key"`
+	ETag string `json:"etag"`
+	Size int64  `json:"size"`
+}
+
+// Error is a non-2xx response from the store.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Op         string
+	Key        string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("storage %s %s: HTTP %d", e.Op, e.Key, e.StatusCode)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+type Client struct {
+	target   Target
+	creds    Credentials
+	endpoint *url.URL
+	http     *http.Client
+	partSize int64
+	now      func() time.Time
+}
+
+// New validates the target and loads its credentials.
+func New(t Target) (*Client, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	creds, err := LoadCredentials(t.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, _ := t.endpointURL()
+	if strings.TrimSpace(t.Region) == "" {
+		t.Region = DefaultRegion
+	}
+	return &Client{
+		target:   t,
+		creds:    creds,
+		endpoint: endpoint,
+		http:     &http.Client{Timeout: 30 * time.Minute},
+		partSize: defaultPartSize,
+		now:      time.Now,
+	}, nil
+}
+
+func (c *Client) Target() Target {
+	return c.target
+}
@@ -0,0 +1,308 @@
+// Package objstoretest runs an in-process S3 stand-in for tests. It speaks the
+// path-style subset objstore uses: PUT/GET/HEAD/DELETE object, multipart
+// uploads and ListObjectsV2. Requests must carry a SigV4 Authorization header
+// for the test access key; signatures themselves are not recomputed.
+package objstoretest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"yt-vod-manager/internal/objstore"
+)
+
+const (
+	AccessKey = "AKIATESTFAKE"
+	SecretKey = "fake-secret"
+)
+
+type object struct {
+	data []byte
+	etag string
+}
+
+type Server struct {
+	*httptest.Server
+	Bucket string
+	// PageSize caps keys per ListObjectsV2 page; tests lower it to force
+	// continuation tokens.
+	PageSize int
+
+	mu       sync.Mutex
+	objects  map[string]object
+	uploads  map[string]map[int][]byte
+	nextID   int
+	failPuts int
+	credFile string
+}
+
+// NewServer starts a fake store with one empty bucket; it is closed when the
+// test ends.
+func NewServer(t testing.TB, bucket string) *Server {
+	t.Helper()
+	s := &Server{
+		Bucket:   bucket,
+		PageSize: 1000,
+		objects:  map[string]object{},
+		uploads:  map[string]map[int][]byte{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+
+	s.credFile = filepath.Join(t.TempDir(), "credentials")
+	creds := fmt.Sprintf("[default]\naws_access_key_id = %s\naws_secret_access_key = %s\n", AccessKey, SecretKey)
+	if err := os.WriteFile(s.credFile, []byte(creds), 0o600); err != nil {
+		t.Fatalf("write fake credentials: %v", err)
+	}
+	return s
+}
+
+// Target points objstore at this server, with credentials in a temp file.
+func (s *Server) Target(prefix string) objstore.Target {
+	return objstore.Target{
+		Endpoint:        s.URL,
+		Bucket:          s.Bucket,
+		Prefix:          prefix,
+		CredentialsFile: s.credFile,
+	}
+}
+
+// FailNextPuts makes the next n object or part uploads return HTTP 500.
+func (s *Server) FailNextPuts(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failPuts = n
+}
+
+func (s *Server) Object(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.objects[key]
+	return o.data, ok
+}
+
+func (s *Server) Put(key string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = object{data: append([]byte(nil), data...), etag: md5Hex(data)}
+}
+
+func (s *Server) Remove(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+}
+
+func (s *Server) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PendingUploads counts multipart uploads neither completed nor aborted.
+func (s *Server) PendingUploads() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.uploads)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential="+AccessKey+"/") || r.Header.Get("X-Amz-Date") == "" {
+		writeError(w, http.StatusForbidden, "AccessDenied", "missing or unknown credentials")
+		return
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s.Bucket {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "bucket does not exist")
+		return
+	}
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		s.list(w, q)
+	case r.Method == http.MethodPut:
+		if r.ContentLength < 0 {
+			writeError(w, http.StatusLengthRequired, "MissingContentLength", "chunked uploads are not supported")
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		if s.failPuts > 0 {
+			s.failPuts--
+			writeError(w, http.StatusInternalServerError, "InternalError", "injected failure")
+			return
+		}
+		if id := q.Get("uploadId"); id != "" {
+			parts, ok := s.uploads[id]
+			if !ok {
+				writeError(w, http.StatusNotFound, "NoSuchUpload", "upload does not exist")
+				return
+			}
+			n, _ := strconv.Atoi(q.Get("partNumber"))
+			parts[n] = data
+			w.Header().Set("ETag", `"`+md5Hex(data)+`"`)
+			return
+		}
+		o := object{data: data, etag: md5Hex(data)}
+		s.objects[key] = o
+		w.Header().Set("ETag", `"`+o.etag+`"`)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		s.nextID++
+		id := fmt.Sprintf("upload-%d", s.nextID)
+		s.uploads[id] = map[int][]byte{}
+		writeXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucket, Key: key, UploadId: id})
+	case r.Method == http.MethodPost && q.Get("uploadId") != "":
+		s.complete(w, r, key, q.Get("uploadId"))
+	case r.Method == http.MethodDelete && q.Get("uploadId") != "":
+		delete(s.uploads, q.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		o, ok := s.objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", "key does not exist")
+			return
+		}
+		w.Header().Set("ETag", `"`+o.etag+`"`)
+		w.Header().Set("Content-Length", strconv.Itoa(len(o.data)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(o.data)
+		}
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented", r.Method+" is not supported by the fake")
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, q map[string][]string) {
+	prefix := first(q["prefix"])
+	after := first(q["continuation-token"])
+	keys := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		if strings.HasPrefix(k, prefix) && k > after {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	type content struct {
+		Key  string
+		ETag string
+		Size int
+	}
+	res := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Name                  string
+		Prefix                string
+		KeyCount              int
+		IsTruncated           bool
+		NextContinuationToken string `xml:",omitempty"`
+		Contents              []content
+	}{Name: s.Bucket, Prefix: prefix}
+	if s.PageSize > 0 && len(keys) > s.PageSize {
+		keys = keys[:s.PageSize]
+		res.IsTruncated = true
+		res.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, k := range keys {
+		o := s.objects[k]
+		res.Contents = append(res.Contents, content{Key: k, ETag: `"` + o.etag + `"`, Size: len(o.data)})
+	}
+	res.KeyCount = len(res.Contents)
+	writeXML(w, res)
+}
+
+func (s *Server) complete(w http.ResponseWriter, r *http.Request, key, id string) {
+	parts, ok := s.uploads[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchUpload", "upload does not exist")
+		return
+	}
+	var req struct {
+		Parts []struct {
+			PartNumber int
+			ETag       string
+		} `xml:"Part"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Parts) == 0 {
+		writeError(w, http.StatusBadRequest, "MalformedXML", "invalid CompleteMultipartUpload body")
+		return
+	}
+	var data bytes.Buffer
+	var sums []byte
+	for i, p := range req.Parts {
+		part, ok := parts[p.PartNumber]
+		if !ok || p.PartNumber != i+1 || strings.Trim(p.ETag, `"`) != md5Hex(part) {
+			writeError(w, http.StatusBadRequest, "InvalidPart", fmt.Sprintf("part %d does not match", p.PartNumber))
+			return
+		}
+		data.Write(part)
+		sum := md5.Sum(part)
+		sums = append(sums, sum[:]...)
+	}
+	delete(s.uploads, id)
+	total := md5.Sum(sums)
+	o := object{data: data.Bytes(), etag: fmt.Sprintf("%s-%d", hex.EncodeToString(total[:]), len(req.Parts))}
+	s.objects[key] = o
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Bucket  string
+		Key     string
+		ETag    string
+	}{Bucket: s.Bucket, Key: key, ETag: `"` + o.etag + `"`})
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = io.WriteString(w, xml.Header)
+	_ = xml.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+		Message string
+	}{Code: code, Message: msg})
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func first(v []string) string {
+	if len(v) == 0 {
+		return ""
+	}
+	return v[0]
+}
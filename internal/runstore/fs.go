@@ -6,13 +6,17 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 )
 
 type RunMeta struct {
-	RunID            string `json:"run_id"`
-	CreatedAt        string `json:"created_at"`
-	UpdatedAt        string `json:"updated_at,omitempty"`
-	Profile          string `json:"profile"`
+	SchemaVersion int    `json:"schema_version"`
+	RunID         string `json:"run_id"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at,omitempty"`
+	Profile       string `json:"profile"`
+	// ProjectID links the run to its project independent of SourceURL.
+	ProjectID        string `json:"project_id,omitempty"`
 	SourceURL        string `json:"source_url"`
 	SourceID         string `json:"source_id,omitempty"`
 	SourceTitle      string `json:"source_title,omitempty"`
@@ -25,6 +29,10 @@ type RunMeta struct {
 	SkippedPrivate   int    `json:"skipped_private"`
 }
 
+// TempFilePrefix names the temp files WriteBytes renames into place; one left
+// behind marks an interrupted write.
+const TempFilePrefix = ".ytvm-tmp-"
+
 func Mkdir(path string) error {
 	if err := os.MkdirAll(path, 0o755); err != nil {
 		return fmt.Errorf("create directory %s: %w", path, err)
@@ -33,12 +41,17 @@ func Mkdir(path string) error {
 }
 
 func WriteBytes(path string, data []byte) error {
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return fmt.Errorf("create parent for %s: %w", path, err)
 	}
+	return replaceFile(path, data)
+}
 
-	tmp, err := os.CreateTemp(dir, ".ytvm-tmp-*")
+// replaceFile atomically replaces path inside an existing directory; unlike
+// WriteBytes it fails instead of creating a missing parent.
+func replaceFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, TempFilePrefix+"*")
 	if err != nil {
 		return fmt.Errorf("create temp file for %s: %w", path, err)
 	}
@@ -99,7 +112,7 @@ func LatestRunDir(runsDir string) (string, error) {
 
 	dirs := make([]string, 0, len(entries))
 	for _, e := range entries {
-		if e.IsDir() {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
 			dirs = append(dirs, e.Name())
 		}
 	}
@@ -122,7 +135,7 @@ func ListRunDirs(runsDir string) ([]string, error) {
 
 	dirs := make([]string, 0, len(entries))
 	for _, e := range entries {
-		if e.IsDir() {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
 			dirs = append(dirs, filepath.Join(runsDir, e.Name()))
 		}
 	}
@@ -136,12 +149,23 @@ func RunMetaPath(runDir string) string {
 
 func LoadRunMeta(runDir string) (RunMeta, error) {
 	var meta RunMeta
-	if err := ReadJSON(RunMetaPath(runDir), &meta); err != nil {
+	if err := ReadVersionedJSON(RunMetaPath(runDir), SchemaRunMeta, &meta); err != nil {
 		return RunMeta{}, err
 	}
 	return meta, nil
 }
 
 func SaveRunMeta(runDir string, meta RunMeta) error {
+	meta.SchemaVersion = RunMetaSchemaVersion
 	return WriteJSON(RunMetaPath(runDir), meta)
 }
+
+// JobsManifestPath is the canonical job state file of a run.
+func JobsManifestPath(runDir string) string {
+	return filepath.Join(runDir, "manifest.jobs.json")
+}
+
+// ReadJobsManifest reads a jobs manifest, migrating older schemas in memory.
+func ReadJobsManifest(path string, v any) error {
+	return ReadVersionedJSON(path, SchemaJobsManifest, v)
+}
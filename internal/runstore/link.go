@@ -0,0 +1,39 @@
+package runstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	LinkModeHardlink = "hardlink"
+	LinkModeReflink  = "reflink"
+	LinkModeSymlink  = "symlink"
+)
+
+// LinkFile makes dst share src's data without copying it; dst must not exist yet.
+func LinkFile(src, dst, mode string) error {
+	if err := Mkdir(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	var err error
+	switch mode {
+	case LinkModeHardlink:
+		err = os.Link(src, dst)
+	case LinkModeReflink:
+		err = cloneFile(src, dst)
+	case LinkModeSymlink:
+		target, absErr := filepath.Abs(src)
+		if absErr != nil {
+			return fmt.Errorf("resolve %s: %w", src, absErr)
+		}
+		err = os.Symlink(target, dst)
+	default:
+		return fmt.Errorf("unknown link mode %q", mode)
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s -> %s: %w", mode, src, dst, err)
+	}
+	return nil
+}
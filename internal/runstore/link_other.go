@@ -0,0 +1,9 @@
+//go:build !linux
+
+package runstore
+
+import "errors"
+
+func cloneFile(src, dst string) error {
+	return errors.New("reflink is only supported on linux")
+}
@@ -1,54 +1,108 @@
 package runstore
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	runLockDirName   = ".run.lock"
 	runLockOwnerFile = "owner.json"
+
+	// DefaultRunLockTTL is how long a lock survives without a heartbeat.
+	DefaultRunLockTTL = 30 * time.Minute
 )
 
+// ErrRunLocked is wrapped by AcquireRunLock when another process holds the lock.
+var ErrRunLocked = errors.New("run directory is locked")
+
 type RunLock struct {
 	lockDir string
+	owner   runLockOwner
+	stop    chan struct{}
+	done    chan struct{}
+	once    *sync.Once
 }
 
 type runLockOwner struct {
-	PID       int    `json:"pid"`
-	CreatedAt string `json:"created_at"`
-	Hostname  string `json:"hostname,omitempty"`
+	PID         int    `json:"pid"`
+	CreatedAt   string `json:"created_at"`
+	Hostname    string `json:"hostname,omitempty"`
+	HeartbeatAt string `json:"heartbeat_at,omitempty"`
+	TTLSeconds  int    `json:"ttl_seconds,omitempty"`
+	// Nonce tells this acquisition apart from a later one by the same PID.
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// RunLockInfo describes the current holder of a run lock.
+type RunLockInfo struct {
+	RunDir      string `json:"run_dir"`
+	LockPath    string `json:"lock_path"`
+	Locked      bool   `json:"locked"`
+	PID         int    `json:"pid,omitempty"`
+	Hostname    string `json:"hostname,omitempty"`
+	CreatedAt   string `json:"created_at,omitempty"`
+	HeartbeatAt string `json:"heartbeat_at,omitempty"`
+	TTLSeconds  int    `json:"ttl_seconds,omitempty"`
+	SameHost    bool   `json:"same_host"`
+	OwnerAlive  bool   `json:"owner_alive"`
+	Stale       bool   `json:"stale"`
+	StaleReason string `json:"stale_reason,omitempty"`
 }
 
-func AcquireRunLock(runDir string) (RunLock, error) {
+// AcquireRunLock takes the run lock, recovering it first when the previous
+// owner is provably gone (same host, dead PID) or its heartbeat is older than
+// its TTL. While held, owner.json is refreshed every ttl/3. A zero ttl uses
+// DefaultRunLockTTL.
+func AcquireRunLock(runDir string, ttl time.Duration) (RunLock, error) {
 	target := strings.TrimSpace(runDir)
 	if target == "" {
 		return RunLock{}, fmt.Errorf("run directory is required")
 	}
+	if ttl <= 0 {
+		ttl = DefaultRunLockTTL
+	}
 
 	lockDir := filepath.Join(target, runLockDirName)
 	if err := os.Mkdir(lockDir, 0o755); err != nil {
-		if os.IsExist(err) {
-			ownerPath := filepath.Join(lockDir, runLockOwnerFile)
-			var owner runLockOwner
-			if readErr := ReadJSON(ownerPath, &owner); readErr == nil && owner.PID > 0 && owner.CreatedAt != "" {
-				return RunLock{}, fmt.Errorf(
-					"run directory is locked: %s (pid=%d created_at=%s host=%s)",
-					target, owner.PID, owner.CreatedAt, owner.Hostname,
-				)
+		if !os.IsExist(err) {
+			return RunLock{}, fmt.Errorf("acquire run lock for %s: %w", target, err)
+		}
+		info, inspectErr := InspectRunLock(target, ttl)
+		if inspectErr != nil {
+			return RunLock{}, inspectErr
+		}
+		if !info.Stale {
+			return RunLock{}, lockedError(info)
+		}
+		fmt.Fprintf(os.Stderr, "warn  recovering stale run lock %s: %s\n", lockDir, info.StaleReason)
+		if err := removeStaleLock(target, info, ttl); err != nil {
+			return RunLock{}, err
+		}
+		if err := os.Mkdir(lockDir, 0o755); err != nil {
+			if os.IsExist(err) {
+				return RunLock{}, fmt.Errorf("%w: %s (taken by another process during recovery)", ErrRunLocked, target)
 			}
-			return RunLock{}, fmt.Errorf("run directory is locked: %s", target)
+			return RunLock{}, fmt.Errorf("acquire run lock for %s: %w", target, err)
 		}
-		return RunLock{}, fmt.Errorf("acquire run lock for %s: %w", target, err)
 	}
 
+	now := time.Now().UTC().Format(time.RFC3339)
 	owner := runLockOwner{
-		PID:       os.Getpid(),
-		CreatedAt: time.Now().UTC().Format(time.RFC3339),
-		Hostname:  hostnameOrUnknown(),
+		PID:         os.Getpid(),
+		CreatedAt:   now,
+		Hostname:    hostnameOrUnknown(),
+		HeartbeatAt: now,
+		TTLSeconds:  int(ttl / time.Second),
+		Nonce:       newLockNonce(),
 	}
 	ownerPath := filepath.Join(lockDir, runLockOwnerFile)
 	if err := WriteJSON(ownerPath, owner); err != nil {
@@ -56,13 +110,55 @@ func AcquireRunLock(runDir string) (RunLock, error) {
 		return RunLock{}, fmt.Errorf("write run lock owner for %s: %w", target, err)
 	}
 
-	return RunLock{lockDir: lockDir}, nil
+	lock := RunLock{
+		lockDir: lockDir,
+		owner:   owner,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		once:    &sync.Once{},
+	}
+	go lock.heartbeat(ownerPath, max(ttl/3, time.Second))
+	return lock, nil
+}
+
+func (l RunLock) heartbeat(ownerPath string, every time.Duration) {
+	defer close(l.done)
+	owner := l.owner
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			// Stop refreshing a lock that was force-removed or recovered by
+			// someone else; writing would recreate it.
+			if !l.stillOwned() {
+				fmt.Fprintf(os.Stderr, "warn  run lock %s is no longer held by this process; heartbeat stopped\n", l.lockDir)
+				<-l.stop
+				return
+			}
+			owner.HeartbeatAt = time.Now().UTC().Format(time.RFC3339)
+			if err := writeLockOwnerFile(ownerPath, owner); err != nil {
+				fmt.Fprintf(os.Stderr, "warn  run lock heartbeat failed: %v\n", err)
+			}
+		}
+	}
 }
 
 func (l RunLock) Release() error {
 	if strings.TrimSpace(l.lockDir) == "" {
 		return nil
 	}
+	if l.once != nil {
+		l.once.Do(func() {
+			close(l.stop)
+			<-l.done
+		})
+	}
+	if !l.stillOwned() {
+		return nil
+	}
 	_ = os.Remove(filepath.Join(l.lockDir, runLockOwnerFile))
 	if err := os.Remove(l.lockDir); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("release run lock %s: %w", l.lockDir, err)
@@ -70,6 +166,128 @@ func (l RunLock) Release() error {
 	return nil
 }
 
+// stillOwned is true only while the owner record is this acquisition's: same
+// PID and nonce. A removed lock, or one recreated by another process that has
+// not written its owner yet, is not ours.
+func (l RunLock) stillOwned() bool {
+	var current runLockOwner
+	if err := ReadJSON(filepath.Join(l.lockDir, runLockOwnerFile), &current); err != nil {
+		return false
+	}
+	return current.PID == l.owner.PID && current.Nonce == l.owner.Nonce
+}
+
+// writeLockOwnerFile refreshes the owner record without recreating a lock
+// directory that was removed in the meantime.
+func writeLockOwnerFile(path string, owner runLockOwner) error {
+	data, err := json.MarshalIndent(owner, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal JSON for %s: %w", path, err)
+	}
+	return replaceFile(path, append(data, '\n'))
+}
+
+func newLockNonce() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// InspectRunLock reports who holds the lock for runDir and whether it is
+// stale. ttl applies when the owner did not record its own.
+func InspectRunLock(runDir string, ttl time.Duration) (RunLockInfo, error) {
+	if ttl <= 0 {
+		ttl = DefaultRunLockTTL
+	}
+	lockDir := filepath.Join(strings.TrimSpace(runDir), runLockDirName)
+	info := RunLockInfo{RunDir: runDir, LockPath: lockDir}
+	stat, err := os.Stat(lockDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return info, nil
+		}
+		return info, fmt.Errorf("inspect run lock %s: %w", lockDir, err)
+	}
+	info.Locked = true
+
+	var owner runLockOwner
+	if err := ReadJSON(filepath.Join(lockDir, runLockOwnerFile), &owner); err != nil || owner.PID <= 0 {
+		// The owner record is written right after mkdir; give a slow writer the TTL.
+		if time.Since(stat.ModTime()) > ttl {
+			info.Stale = true
+			info.StaleReason = fmt.Sprintf("no owner record and lock is older than %s", ttl)
+		}
+		return info, nil
+	}
+	info.PID = owner.PID
+	info.Hostname = owner.Hostname
+	info.CreatedAt = owner.CreatedAt
+	info.HeartbeatAt = owner.HeartbeatAt
+	info.TTLSeconds = owner.TTLSeconds
+	info.SameHost = owner.Hostname != "" && owner.Hostname == hostnameOrUnknown()
+	info.OwnerAlive = !info.SameHost || processAlive(owner.PID)
+
+	if owner.TTLSeconds > 0 {
+		ttl = time.Duration(owner.TTLSeconds) * time.Second
+	}
+	last := firstNonEmptyTime(owner.HeartbeatAt, owner.CreatedAt)
+	switch {
+	case info.SameHost && !info.OwnerAlive:
+		info.Stale = true
+		info.StaleReason = fmt.Sprintf("owner process %d is no longer running", owner.PID)
+	case !last.IsZero() && time.Since(last) > ttl:
+		info.Stale = true
+		info.StaleReason = fmt.Sprintf("no heartbeat since %s (ttl %s)", last.Format(time.RFC3339), ttl)
+	}
+	return info, nil
+}
+
+// RemoveRunLock deletes the lock regardless of its owner.
+func RemoveRunLock(runDir string) error {
+	lockDir := filepath.Join(strings.TrimSpace(runDir), runLockDirName)
+	if err := os.RemoveAll(lockDir); err != nil {
+		return fmt.Errorf("remove run lock %s: %w", lockDir, err)
+	}
+	return nil
+}
+
+// removeStaleLock re-checks the owner right before deleting so a lock that a
+// competing process just recovered is left alone.
+func removeStaleLock(runDir string, seen RunLockInfo, ttl time.Duration) error {
+	again, err := InspectRunLock(runDir, ttl)
+	if err != nil {
+		return err
+	}
+	if !again.Locked {
+		return nil
+	}
+	if !again.Stale || again.PID != seen.PID || again.CreatedAt != seen.CreatedAt {
+		return lockedError(again)
+	}
+	return RemoveRunLock(runDir)
+}
+
+func lockedError(info RunLockInfo) error {
+	if info.PID > 0 && info.CreatedAt != "" {
+		return fmt.Errorf(
+			"%w: %s (pid=%d created_at=%s host=%s)",
+			ErrRunLocked, info.RunDir, info.PID, info.CreatedAt, info.Hostname,
+		)
+	}
+	return fmt.Errorf("%w: %s", ErrRunLocked, info.RunDir)
+}
+
+func firstNonEmptyTime(values ...string) time.Time {
+	for _, v := range values {
+		if t, err := time.Parse(time.RFC3339, strings.TrimSpace(v)); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
 func hostnameOrUnknown() string {
 	host, err := os.Hostname()
 	if err != nil {
@@ -1,11 +1,17 @@
 package runstore
 
-import "testing"
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
 
 func TestAcquireRunLock_BlocksConcurrentAcquire(t *testing.T) {
 	runDir := t.TempDir()
 
-	lock, err := AcquireRunLock(runDir)
+	lock, err := AcquireRunLock(runDir, 0)
 	if err != nil {
 		t.Fatalf("acquire first lock: %v", err)
 	}
@@ -13,15 +19,15 @@ func TestAcquireRunLock_BlocksConcurrentAcquire(t *testing.T) {
 		_ = lock.Release()
 	}()
 
-	if _, err := AcquireRunLock(runDir); err == nil {
-		t.Fatalf("expected second acquire to fail")
+	if _, err := AcquireRunLock(runDir, 0); !errors.Is(err, ErrRunLocked) {
+		t.Fatalf("expected second acquire to fail with ErrRunLocked, got %v", err)
 	}
 
 	if err := lock.Release(); err != nil {
 		t.Fatalf("release lock: %v", err)
 	}
 
-	lock2, err := AcquireRunLock(runDir)
+	lock2, err := AcquireRunLock(runDir, 0)
 	if err != nil {
 		t.Fatalf("acquire after release: %v", err)
 	}
@@ -29,3 +35,198 @@ func TestAcquireRunLock_BlocksConcurrentAcquire(t *testing.T) {
 		t.Fatalf("release second lock: %v", err)
 	}
 }
+
+func writeLockOwner(t *testing.T, runDir string, owner runLockOwner) {
+	t.Helper()
+	lockDir := filepath.Join(runDir, runLockDirName)
+	if err := os.MkdirAll(lockDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteJSON(filepath.Join(lockDir, runLockOwnerFile), owner); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireRunLock_RecoversStaleLocks(t *testing.T) {
+	now := time.Now().UTC()
+	cases := []struct {
+		name  string
+		owner runLockOwner
+	}{
+		{"dead pid on same host", runLockOwner{PID: 1 << 30, CreatedAt: now.Format(time.RFC3339), Hostname: hostnameOrUnknown()}},
+		{"expired heartbeat on other host", runLockOwner{PID: os.Getpid(), CreatedAt: now.Add(-3 * time.Hour).Format(time.RFC3339), Hostname: "elsewhere", HeartbeatAt: now.Add(-2 * time.Hour).Format(time.RFC3339), TTLSeconds: 1800}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			runDir := t.TempDir()
+			writeLockOwner(t, runDir, tc.owner)
+
+			info, err := InspectRunLock(runDir, 0)
+			if err != nil || !info.Locked || !info.Stale {
+				t.Fatalf("expected stale lock, got %+v err=%v", info, err)
+			}
+			lock, err := AcquireRunLock(runDir, 0)
+			if err != nil {
+				t.Fatalf("expected stale lock to be recovered: %v", err)
+			}
+			if err := lock.Release(); err != nil {
+				t.Fatalf("release lock: %v", err)
+			}
+		})
+	}
+}
+
+func TestAcquireRunLock_KeepsLiveRemoteLock(t *testing.T) {
+	runDir := t.TempDir()
+	now := time.Now().UTC().Format(time.RFC3339)
+	writeLockOwner(t, runDir, runLockOwner{PID: 4242, CreatedAt: now, Hostname: "elsewhere", HeartbeatAt: now})
+
+	if _, err := AcquireRunLock(runDir, 0); !errors.Is(err, ErrRunLocked) {
+		t.Fatalf("expected fresh remote lock to block, got %v", err)
+	}
+	if err := RemoveRunLock(runDir); err != nil {
+		t.Fatalf("remove lock: %v", err)
+	}
+	info, err := InspectRunLock(runDir, 0)
+	if err != nil || info.Locked {
+		t.Fatalf("expected lock to be gone, got %+v err=%v", info, err)
+	}
+}
+
+func TestAcquireRunLock_RefreshesHeartbeat(t *testing.T) {
+	runDir := t.TempDir()
+	lock, err := AcquireRunLock(runDir, 3*time.Second)
+	if err != nil {
+		t.Fatalf("acquire lock: %v", err)
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+
+	time.Sleep(2100 * time.Millisecond)
+	var owner runLockOwner
+	if err := ReadJSON(filepath.Join(runDir, runLockDirName, runLockOwnerFile), &owner); err != nil {
+		t.Fatal(err)
+	}
+	if owner.HeartbeatAt == owner.CreatedAt || owner.TTLSeconds != 3 {
+		t.Fatalf("expected heartbeat to advance, got %+v", owner)
+	}
+}
+
+func TestRunLockHeartbeatDoesNotRecreateRemovedLock(t *testing.T) {
+	runDir := t.TempDir()
+	lock, err := AcquireRunLock(runDir, 3*time.Second)
+	if err != nil {
+		t.Fatalf("acquire lock: %v", err)
+	}
+	if err := RemoveRunLock(runDir); err != nil {
+		t.Fatalf("remove lock: %v", err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if _, err := os.Stat(filepath.Join(runDir, runLockDirName)); !os.IsNotExist(err) {
+		t.Fatalf("expected removed lock to stay removed, stat err=%v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("release after removal: %v", err)
+	}
+}
+
+func TestRunLockHeartbeatStopsWhenLockIsTakenOver(t *testing.T) {
+	cases := []struct {
+		name     string
+		takeOver func(t *testing.T, runDir string, mine runLockOwner)
+	}{
+		{"same pid new nonce", func(t *testing.T, runDir string, mine runLockOwner) {
+			mine.Nonce = "other"
+			writeLockOwner(t, runDir, mine)
+		}},
+		{"recreated without owner yet", func(t *testing.T, runDir string, _ runLockOwner) {
+			if err := RemoveRunLock(runDir); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Mkdir(filepath.Join(runDir, runLockDirName), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			runDir := t.TempDir()
+			lock, err := AcquireRunLock(runDir, 3*time.Second)
+			if err != nil {
+				t.Fatalf("acquire lock: %v", err)
+			}
+			tc.takeOver(t, runDir, lock.owner)
+			ownerPath := filepath.Join(runDir, runLockDirName, runLockOwnerFile)
+			before, _ := os.ReadFile(ownerPath)
+
+			time.Sleep(1100 * time.Millisecond)
+			after, _ := os.ReadFile(ownerPath)
+			if string(after) != string(before) {
+				t.Fatalf("heartbeat touched another owner's lock:\n%s", after)
+			}
+			if err := lock.Release(); err != nil {
+				t.Fatalf("release after takeover: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(runDir, runLockDirName)); err != nil {
+				t.Fatalf("release must leave the new owner's lock alone: %v", err)
+			}
+		})
+	}
+}
+
+func TestAcquireSyncLock_NoWaitAndWait(t *testing.T) {
+	runsDir := t.TempDir()
+
+	lock, err := AcquireSyncLock(runsDir, SyncLockOptions{})
+	if err != nil {
+		t.Fatalf("acquire sync lock: %v", err)
+	}
+	if _, err := AcquireSyncLock(runsDir, SyncLockOptions{}); !errors.Is(err, ErrRunLocked) {
+		t.Fatalf("expected no-wait acquire to fail with ErrRunLocked, got %v", err)
+	}
+	info, err := InspectSyncLock(runsDir, 0)
+	if err != nil {
+		t.Fatalf("inspect sync lock: %v", err)
+	}
+	if !info.Locked || info.PID != os.Getpid() {
+		t.Fatalf("expected sync lock held by this process, got %+v", info)
+	}
+
+	var waitedOn RunLockInfo
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = lock.Release()
+	}()
+	lock2, err := AcquireSyncLock(runsDir, SyncLockOptions{
+		Wait:   true,
+		Poll:   20 * time.Millisecond,
+		OnWait: func(info RunLockInfo) { waitedOn = info },
+	})
+	if err != nil {
+		t.Fatalf("expected waiting acquire to succeed after release, got %v", err)
+	}
+	defer func() {
+		_ = lock2.Release()
+	}()
+	if waitedOn.PID != os.Getpid() {
+		t.Fatalf("expected OnWait to report the holder, got %+v", waitedOn)
+	}
+}
+
+func TestAcquireSyncLock_WaitTimeout(t *testing.T) {
+	runsDir := t.TempDir()
+
+	lock, err := AcquireSyncLock(runsDir, SyncLockOptions{})
+	if err != nil {
+		t.Fatalf("acquire sync lock: %v", err)
+	}
+	defer func() {
+		_ = lock.Release()
+	}()
+
+	_, err = AcquireSyncLock(runsDir, SyncLockOptions{Wait: true, Timeout: 60 * time.Millisecond, Poll: 20 * time.Millisecond})
+	if !errors.Is(err, ErrRunLocked) {
+		t.Fatalf("expected timeout wrapping ErrRunLocked, got %v", err)
+	}
+}
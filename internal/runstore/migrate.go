@@ -0,0 +1,241 @@
+package runstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Schemas of the versioned state files.
+const (
+	SchemaJobsManifest = "jobs_manifest"
+	SchemaRunMeta      = "run_meta"
+	SchemaProjects     = "projects"
+)
+
+// Current schema versions written by this binary.
+const (
+	JobsManifestSchemaVersion = 2
+	RunMetaSchemaVersion      = 2
+	ProjectsSchemaVersion     = 3
+)
+
+// ErrSchemaTooNew matches errors for files written by a newer binary.
+var ErrSchemaTooNew = errors.New("schema version is newer than this binary supports")
+
+type SchemaTooNewError struct {
+	Path      string
+	Schema    string
+	Found     int
+	Supported int
+}
+
+func (e *SchemaTooNewError) Error() string {
+	return fmt.Sprintf("%s has schema_version %d, but this yt-vod-manager only understands %s up to version %d; upgrade yt-vod-manager (self-update) before opening it", e.Path, e.Found, e.Schema, e.Supported)
+}
+
+func (e *SchemaTooNewError) Unwrap() error {
+	return ErrSchemaTooNew
+}
+
+// Migration upgrades one schema from version From to From+1. Apply edits the
+// decoded document in place and returns a note per change it made.
+type Migration struct {
+	Schema  string
+	From    int
+	Summary string
+	Apply   func(doc map[string]any) []string
+}
+
+// MigrationPlan describes what opening (or migrating) a file does to it.
+type MigrationPlan struct {
+	Path    string   `json:"path"`
+	Schema  string   `json:"schema"`
+	From    int      `json:"from_version"`
+	To      int      `json:"to_version"`
+	Changes []string `json:"changes,omitempty"`
+	Backup  string   `json:"backup,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+func (p MigrationPlan) Pending() bool {
+	return p.Error == "" && p.From < p.To
+}
+
+var schemaVersions = map[string]struct{ base, current int }{
+	// Files without schema_version are read as the base version.
+	SchemaJobsManifest: {base: 1, current: JobsManifestSchemaVersion},
+	SchemaRunMeta:      {base: 0, current: RunMetaSchemaVersion},
+	SchemaProjects:     {base: 1, current: ProjectsSchemaVersion},
+}
+
+var migrations = []Migration{
+	{
+		Schema:  SchemaJobsManifest,
+		From:    1,
+		Summary: "fill source_id/source_title from the legacy playlist_id/playlist_title fields",
+		Apply: func(doc map[string]any) []string {
+			var notes []string
+			for _, pair := range [][2]string{{"source_id", "playlist_id"}, {"source_title", "playlist_title"}} {
+				if s, _ := doc[pair[0]].(string); s != "" {
+					continue
+				}
+				if legacy, _ := doc[pair[1]].(string); legacy != "" {
+					doc[pair[0]] = legacy
+					notes = append(notes, fmt.Sprintf("set %s from %s", pair[0], pair[1]))
+				}
+			}
+			return notes
+		},
+	},
+	{
+		Schema:  SchemaRunMeta,
+		From:    0,
+		Summary: "add schema_version to run.json",
+		Apply:   func(map[string]any) []string { return nil },
+	},
+	{
+		Schema:  SchemaProjects,
+		From:    1,
+		Summary: "stamp schema_version 2 (project fields are normalized on load)",
+		Apply:   func(map[string]any) []string { return nil },
+	},
+	{
+		Schema:  SchemaRunMeta,
+		From:    1,
+		Summary: "add project_id (runs without one are matched to projects by source URL)",
+		Apply:   func(map[string]any) []string { return nil },
+	},
+	{
+		Schema:  SchemaProjects,
+		From:    2,
+		Summary: "add a persistent project id (derived from the source URL for existing projects)",
+		Apply:   assignDerivedProjectIDs,
+	},
+}
+
+// ReadVersionedJSON reads a state file of the given schema into v, applying
+// pending migrations in memory. Files from a newer binary are refused.
+// Reading never touches the disk; only UpgradeFile backs up and rewrites.
+func ReadVersionedJSON(path, schema string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read file %s: %w", path, err)
+	}
+	plan, migrated, err := migrateDocument(path, schema, data)
+	if err != nil {
+		return err
+	}
+	if plan.Pending() {
+		data = migrated
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parse JSON %s: %w", path, err)
+	}
+	return nil
+}
+
+// PlanMigration reports which migrations a file needs without touching it.
+func PlanMigration(path, schema string) (MigrationPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return MigrationPlan{Path: path, Schema: schema}, fmt.Errorf("read file %s: %w", path, err)
+	}
+	plan, _, err := migrateDocument(path, schema, data)
+	return plan, err
+}
+
+// UpgradeFile migrates a file on disk: the original is backed up, then the
+// migrated document is decoded into v (the owner's typed struct) and written
+// back, so field order and normalization match regular writes.
+func UpgradeFile(path, schema string, v any) (MigrationPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return MigrationPlan{Path: path, Schema: schema}, fmt.Errorf("read file %s: %w", path, err)
+	}
+	plan, migrated, err := migrateDocument(path, schema, data)
+	if err != nil || !plan.Pending() {
+		return plan, err
+	}
+	if plan.Backup, err = backupOriginal(path, plan.From, data); err != nil {
+		return plan, err
+	}
+	if err := json.Unmarshal(migrated, v); err != nil {
+		return plan, fmt.Errorf("parse JSON %s: %w", path, err)
+	}
+	return plan, WriteJSON(path, v)
+}
+
+func migrateDocument(path, schema string, data []byte) (MigrationPlan, []byte, error) {
+	versions, ok := schemaVersions[schema]
+	if !ok {
+		return MigrationPlan{}, nil, fmt.Errorf("unknown schema %q", schema)
+	}
+	plan := MigrationPlan{Path: path, Schema: schema, To: versions.current}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return plan, nil, fmt.Errorf("parse JSON %s: %w", path, err)
+	}
+	plan.From = versions.base
+	if raw, ok := doc["schema_version"].(json.Number); ok {
+		n, err := raw.Int64()
+		if err != nil {
+			return plan, nil, fmt.Errorf("parse JSON %s: invalid schema_version %q", path, raw)
+		}
+		// 0 is what unversioned structs wrote; read it like a missing field.
+		if n > 0 {
+			plan.From = int(n)
+		}
+	}
+	if plan.From > versions.current {
+		err := &SchemaTooNewError{Path: path, Schema: schema, Found: plan.From, Supported: versions.current}
+		plan.Error = err.Error()
+		return plan, nil, err
+	}
+	if plan.From == versions.current {
+		return plan, data, nil
+	}
+
+	for version := plan.From; version < versions.current; version++ {
+		step, ok := findMigration(schema, version)
+		if !ok {
+			return plan, nil, fmt.Errorf("no migration for %s from version %d", schema, version)
+		}
+		plan.Changes = append(plan.Changes, fmt.Sprintf("v%d -> v%d: %s", version, version+1, step.Summary))
+		for _, note := range step.Apply(doc) {
+			plan.Changes = append(plan.Changes, "  "+note)
+		}
+	}
+	doc["schema_version"] = versions.current
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return plan, nil, fmt.Errorf("marshal migrated %s: %w", path, err)
+	}
+	return plan, migrated, nil
+}
+
+func findMigration(schema string, from int) (Migration, bool) {
+	for _, m := range migrations {
+		if m.Schema == schema && m.From == from {
+			return m, true
+		}
+	}
+	return Migration{}, false
+}
+
+// backupOriginal keeps the first pre-upgrade copy; later calls leave it alone.
+func backupOriginal(path string, version int, data []byte) (string, error) {
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if _, err := os.Stat(backup); err == nil {
+		return backup, nil
+	}
+	if err := WriteBytes(backup, data); err != nil {
+		return "", fmt.Errorf("back up %s before migration: %w", path, err)
+	}
+	return backup, nil
+}
@@ -0,0 +1,74 @@
+package runstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testManifest struct {
+	SchemaVersion int    `json:"schema_version"`
+	SourceID      string `json:"source_id,omitempty"`
+	PlaylistID    string `json:"playlist_id"`
+}
+
+func TestReadVersionedJSONMigratesInMemoryOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.jobs.json")
+	original := []byte(`{"schema_version":1,"playlist_id":"PL1"}`)
+	if err := os.WriteFile(path, original, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var mf testManifest
+	if err := ReadJobsManifest(path, &mf); err != nil {
+		t.Fatal(err)
+	}
+	if mf.SchemaVersion != JobsManifestSchemaVersion || mf.SourceID != "PL1" {
+		t.Fatalf("expected migrated manifest, got %+v", mf)
+	}
+	if _, err := os.Stat(path + ".v1.bak"); !os.IsNotExist(err) {
+		t.Fatal("reading must not write a backup")
+	}
+	if data, _ := os.ReadFile(path); string(data) != string(original) {
+		t.Fatalf("reading must not rewrite the file, got %s", data)
+	}
+
+	plan, err := UpgradeFile(path, SchemaJobsManifest, &testManifest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Pending() || plan.From != 1 || plan.To != JobsManifestSchemaVersion || len(plan.Changes) == 0 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	backup, err := os.ReadFile(path + ".v1.bak")
+	if err != nil {
+		t.Fatalf("expected upgrade to back up the original: %v", err)
+	}
+	if string(backup) != string(original) {
+		t.Fatalf("backup differs from original: %s", backup)
+	}
+	after, err := PlanMigration(path, SchemaJobsManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Pending() {
+		t.Fatalf("expected file upgraded on disk, got %+v", after)
+	}
+}
+
+func TestReadVersionedJSONRefusesNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	if err := os.WriteFile(path, []byte(`{"schema_version":99,"run_id":"r"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var meta RunMeta
+	err := ReadVersionedJSON(path, SchemaRunMeta, &meta)
+	var tooNew *SchemaTooNewError
+	if !errors.Is(err, ErrSchemaTooNew) || !errors.As(err, &tooNew) || tooNew.Found != 99 {
+		t.Fatalf("expected schema too new error, got %v", err)
+	}
+	if _, err := os.Stat(path + ".v99.bak"); !os.IsNotExist(err) {
+		t.Fatal("newer files must not be backed up or touched")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package runstore
+
+// processAlive cannot check liveness here, so locks only expire via TTL.
+func processAlive(pid int) bool {
+	return pid > 0
+}
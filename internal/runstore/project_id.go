@@ -0,0 +1,68 @@
+package runstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// NormalizeSourceURL returns the form source URLs are compared in: trimmed,
+// without fragment or trailing slash.
+func NormalizeSourceURL(raw string) string {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return ""
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+	u.Fragment = ""
+	if strings.HasSuffix(u.Path, "/") && u.Path != "/" {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+	}
+	return u.String()
+}
+
+// DerivedProjectID is the id a project saved before project ids gets. It is
+// derived from the source URL, so the migration and every reader agree on it.
+func DerivedProjectID(sourceURL string) string {
+	sum := sha256.Sum256([]byte(NormalizeSourceURL(sourceURL)))
+	return hex.EncodeToString(sum[:6])
+}
+
+// assignDerivedProjectIDs gives every project entry of a registry document
+// without an id its derived one, keeping ids unique.
+func assignDerivedProjectIDs(doc map[string]any) []string {
+	projects, _ := doc["projects"].([]any)
+	seen := make(map[string]bool, len(projects))
+	for _, raw := range projects {
+		if p, ok := raw.(map[string]any); ok {
+			if id, _ := p["id"].(string); strings.TrimSpace(id) != "" {
+				seen[strings.ToLower(strings.TrimSpace(id))] = true
+			}
+		}
+	}
+	var notes []string
+	for _, raw := range projects {
+		p, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		if id, _ := p["id"].(string); strings.TrimSpace(id) != "" {
+			continue
+		}
+		name, _ := p["name"].(string)
+		source, _ := p["source_url"].(string)
+		id := DerivedProjectID(source)
+		if seen[id] {
+			id = DerivedProjectID(source + "#" + name)
+		}
+		seen[id] = true
+		p["id"] = id
+		notes = append(notes, fmt.Sprintf("set id %s for project %q", id, name))
+	}
+	return notes
+}
@@ -0,0 +1,114 @@
+package runstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	snapshotDirName    = "snapshots"
+	snapshotPrefix     = "raw_"
+	snapshotSuffix     = ".json.gz"
+	snapshotTimeLayout = "20060102T150405Z"
+)
+
+// Snapshot is one compressed copy of a source's raw manifest.
+type Snapshot struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	TakenAt time.Time `json:"taken_at"`
+}
+
+func SnapshotDir(runDir string) string {
+	return filepath.Join(runDir, snapshotDirName)
+}
+
+// SaveSnapshot gzips raw into <run>/snapshots/raw_<UTC timestamp>.json.gz and
+// prunes the oldest snapshots beyond keep (keep <= 0 keeps everything).
+func SaveSnapshot(runDir string, at time.Time, raw []byte, keep int) (Snapshot, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return Snapshot{}, fmt.Errorf("compress snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return Snapshot{}, fmt.Errorf("compress snapshot: %w", err)
+	}
+
+	at = at.UTC().Truncate(time.Second)
+	name := snapshotPrefix + at.Format(snapshotTimeLayout) + snapshotSuffix
+	snap := Snapshot{Name: name, Path: filepath.Join(SnapshotDir(runDir), name), TakenAt: at}
+	if err := WriteBytes(snap.Path, buf.Bytes()); err != nil {
+		return Snapshot{}, err
+	}
+	if keep <= 0 {
+		return snap, nil
+	}
+
+	all, err := ListSnapshots(runDir)
+	if err != nil {
+		return snap, err
+	}
+	for len(all) > keep {
+		if err := os.Remove(all[0].Path); err != nil && !os.IsNotExist(err) {
+			return snap, fmt.Errorf("prune snapshot %s: %w", all[0].Path, err)
+		}
+		all = all[1:]
+	}
+	return snap, nil
+}
+
+// ListSnapshots returns the run's snapshots, oldest first.
+func ListSnapshots(runDir string) ([]Snapshot, error) {
+	dir := SnapshotDir(runDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	out := make([]Snapshot, 0, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, snapshotSuffix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, snapshotPrefix), snapshotSuffix)
+		at, err := time.Parse(snapshotTimeLayout, stamp)
+		if err != nil {
+			continue
+		}
+		out = append(out, Snapshot{Name: name, Path: filepath.Join(dir, name), TakenAt: at})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].TakenAt.Before(out[j].TakenAt)
+	})
+	return out, nil
+}
+
+// ReadSnapshot returns the decompressed raw manifest of a snapshot.
+func ReadSnapshot(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot %s: %w", path, err)
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot %s: %w", path, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,35 @@
+package runstore
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSaveSnapshotRoundTripsAndPrunesOldest(t *testing.T) {
+	runDir := t.TempDir()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		raw := []byte(`{"id":"src","n":` + string(rune('0'+i)) + `}`)
+		if _, err := SaveSnapshot(runDir, base.Add(time.Duration(i)*time.Hour), raw, 3); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snaps, err := ListSnapshots(runDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 3 {
+		t.Fatalf("expected 3 snapshots after pruning, got %d", len(snaps))
+	}
+	if snaps[0].Name != "raw_20240101T130000Z.json.gz" || !snaps[2].TakenAt.Equal(base.Add(3*time.Hour)) {
+		t.Fatalf("expected oldest pruned and order oldest-first, got %+v", snaps)
+	}
+	data, err := ReadSnapshot(snaps[2].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"id":"src","n":3}` {
+		t.Fatalf("unexpected snapshot content %q", data)
+	}
+}
@@ -0,0 +1,95 @@
+package runstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	syncLockDirName = ".sync"
+
+	defaultSyncLockPoll = 2 * time.Second
+)
+
+// SyncLockOptions controls how AcquireSyncLock behaves when a sync is already
+// running for the workspace.
+type SyncLockOptions struct {
+	TTL time.Duration
+	// Wait keeps polling until the lock frees up; otherwise a held lock
+	// fails immediately with ErrRunLocked.
+	Wait bool
+	// Timeout bounds Wait; zero waits indefinitely.
+	Timeout time.Duration
+	Poll    time.Duration
+	// OnWait is called once with the current holder before waiting starts.
+	OnWait func(RunLockInfo)
+	// Stop aborts a wait when closed.
+	Stop <-chan struct{}
+}
+
+// SyncLockDir is the lock target shared by every sync in a runs directory.
+func SyncLockDir(runsDir string) string {
+	dir := strings.TrimSpace(runsDir)
+	if dir == "" {
+		dir = "runs"
+	}
+	return filepath.Join(dir, syncLockDirName)
+}
+
+// AcquireSyncLock takes the workspace-wide sync lock. It is a RunLock on
+// <runs>/.sync, so stale owners are recovered the same way.
+func AcquireSyncLock(runsDir string, opts SyncLockOptions) (RunLock, error) {
+	dir := SyncLockDir(runsDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return RunLock{}, fmt.Errorf("create sync lock dir %s: %w", dir, err)
+	}
+	poll := opts.Poll
+	if poll <= 0 {
+		poll = defaultSyncLockPoll
+	}
+	var deadline time.Time
+	if opts.Timeout > 0 {
+		deadline = time.Now().Add(opts.Timeout)
+	}
+
+	notified := false
+	for {
+		lock, err := AcquireRunLock(dir, opts.TTL)
+		if err == nil {
+			return lock, nil
+		}
+		if !errors.Is(err, ErrRunLocked) || !opts.Wait {
+			return RunLock{}, syncLockError(err)
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return RunLock{}, fmt.Errorf("timed out after %s waiting for sync lock: %w", opts.Timeout, syncLockError(err))
+		}
+		if !notified && opts.OnWait != nil {
+			if info, inspectErr := InspectRunLock(dir, opts.TTL); inspectErr == nil && info.Locked {
+				opts.OnWait(info)
+			}
+			notified = true
+		}
+		select {
+		case <-opts.Stop:
+			return RunLock{}, fmt.Errorf("stopped while waiting for sync lock: %w", syncLockError(err))
+		case <-time.After(poll):
+		}
+	}
+}
+
+// InspectSyncLock reports whether a sync currently holds the workspace lock.
+func InspectSyncLock(runsDir string, ttl time.Duration) (RunLockInfo, error) {
+	return InspectRunLock(SyncLockDir(runsDir), ttl)
+}
+
+func syncLockError(err error) error {
+	if errors.Is(err, ErrRunLocked) {
+		return fmt.Errorf("sync already running: %w", err)
+	}
+	return err
+}
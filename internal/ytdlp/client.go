@@ -19,6 +19,8 @@ const (
 	StreamStderr OutputStream = "stderr"
 )
 
+const outputTemplate = "%(uploader)s/%(upload_date)s_%(title).200B_[%(id)s].%(ext)s"
+
 type FlatPlaylistOptions struct {
 	SourceURL          string
 	CookiesPath        string
@@ -34,6 +36,9 @@ type DownloadOptions struct {
 	CookiesPath        string
 	CookiesFromBrowser string
 	Quality            string
+	VideoCodec         string
+	HDR                string
+	Container          string
 	DeliveryMode       string
 	SubLangs           string
 	DownloadLimitMBps  float64
@@ -44,10 +49,15 @@ type DownloadOptions struct {
 	EchoOutput         bool
 	Progress           func(stream OutputStream, line string)
 	JSRuntime          string
+	// AudioOnly downloads the best audio stream and extracts it; Quality,
+	// VideoCodec, HDR and Container do not apply.
+	AudioOnly       bool
+	WriteThumbnails bool
 }
 
 type DownloadResult struct {
 	Command []string
+	Media   MediaInfo
 }
 
 type DependencyReport struct {
@@ -168,45 +178,46 @@ func DownloadVideo(opts DownloadOptions) (DownloadResult, error) {
 		"--restrict-filenames",
 		"-N", fmt.Sprintf("%d", fragments),
 		"-P", opts.OutputDir,
-		"-o", "%(uploader)s/%(upload_date)s_%(title).200B_[%(id)s].%(ext)s",
-		"--download-archive", opts.DownloadArchive,
+		"-o", outputTemplate,
+	}
+	if strings.TrimSpace(opts.DownloadArchive) != "" {
+		args = append(args, "--download-archive", opts.DownloadArchive)
 	}
-	switch strings.ToLower(strings.TrimSpace(opts.DeliveryMode)) {
-	case "fragmented":
+	fragmented := strings.EqualFold(strings.TrimSpace(opts.DeliveryMode), "fragmented")
+	if fragmented {
 		args = append(args,
 			"--hls-prefer-native",
 			"--downloader", "m3u8:native",
-			"-f", selectFormat(opts.Quality, true),
 		)
-	default:
-		args = append(args, "-f", selectFormat(opts.Quality, false))
-	}
-	if strings.TrimSpace(opts.CookiesPath) != "" {
-		cookiesPath, err := resolveCookiesPath(opts.CookiesPath)
-		if err != nil {
-			return DownloadResult{}, err
-		}
-		args = append(args, "--cookies", cookiesPath)
 	}
-	if strings.TrimSpace(opts.CookiesFromBrowser) != "" {
-		args = append(args, "--cookies-from-browser", opts.CookiesFromBrowser)
-	}
-	if opts.DownloadLimitMBps > 0 {
-		args = append(args, "--limit-rate", formatRateLimitMBps(opts.DownloadLimitMBps))
+	args, err := appendFormatArgs(args, opts, fragmented)
+	if err != nil {
+		return DownloadResult{}, err
 	}
-	if strings.TrimSpace(opts.ProxyURL) != "" {
-		args = append(args, "--proxy", strings.TrimSpace(opts.ProxyURL))
+	if opts.WriteThumbnails {
+		args = append(args, "--write-thumbnail")
 	}
-	args, err := appendJSRuntimeArgs(args, opts.JSRuntime)
+	args, err = appendAccessArgs(args, opts)
 	if err != nil {
 		return DownloadResult{}, err
 	}
+	infoFile, err := os.CreateTemp("", "ytvm-media-*.tsv")
+	if err != nil {
+		return DownloadResult{}, fmt.Errorf("create media info file: %w", err)
+	}
+	infoPath := infoFile.Name()
+	_ = infoFile.Close()
+	defer os.Remove(infoPath)
+	args = append(args, "--print-to-file", "after_move:"+mediaInfoTemplate, infoPath)
 	args = append(args, opts.VideoURL)
 
 	if err := runCommand(args, opts); err != nil {
 		return DownloadResult{Command: append([]string{"yt-dlp"}, args...)}, err
 	}
-	return DownloadResult{Command: append([]string{"yt-dlp"}, args...)}, nil
+	return DownloadResult{
+		Command: append([]string{"yt-dlp"}, args...),
+		Media:   readMediaInfoFile(infoPath),
+	}, nil
 }
 
 func DownloadSubtitles(opts DownloadOptions) (DownloadResult, error) {
@@ -225,29 +236,44 @@ func DownloadSubtitles(opts DownloadOptions) (DownloadResult, error) {
 		"--newline",
 		"--restrict-filenames",
 		"-P", opts.OutputDir,
-		"-o", "%(uploader)s/%(upload_date)s_%(title).200B_[%(id)s].%(ext)s",
+		"-o", outputTemplate,
 		"--write-subs",
 		"--write-auto-subs",
 		"--sub-langs", lang,
 		"--convert-subs", "vtt",
 	}
-	if strings.TrimSpace(opts.CookiesPath) != "" {
-		cookiesPath, err := resolveCookiesPath(opts.CookiesPath)
-		if err != nil {
-			return DownloadResult{}, err
-		}
-		args = append(args, "--cookies", cookiesPath)
+	args, err := appendAccessArgs(args, opts)
+	if err != nil {
+		return DownloadResult{}, err
 	}
-	if strings.TrimSpace(opts.CookiesFromBrowser) != "" {
-		args = append(args, "--cookies-from-browser", opts.CookiesFromBrowser)
+	args = append(args, opts.VideoURL)
+
+	if err := runCommand(args, opts); err != nil {
+		return DownloadResult{Command: append([]string{"yt-dlp"}, args...)}, err
 	}
-	if opts.DownloadLimitMBps > 0 {
-		args = append(args, "--limit-rate", formatRateLimitMBps(opts.DownloadLimitMBps))
+	return DownloadResult{Command: append([]string{"yt-dlp"}, args...)}, nil
+}
+
+// DownloadLiveChat writes the chat replay of a past stream as <name>.live_chat.json.
+func DownloadLiveChat(opts DownloadOptions) (DownloadResult, error) {
+	if strings.TrimSpace(opts.VideoURL) == "" {
+		return DownloadResult{}, fmt.Errorf("video URL is required")
 	}
-	if strings.TrimSpace(opts.ProxyURL) != "" {
-		args = append(args, "--proxy", strings.TrimSpace(opts.ProxyURL))
+	if strings.TrimSpace(opts.OutputDir) == "" {
+		return DownloadResult{}, fmt.Errorf("output directory is required")
+	}
+
+	args := []string{
+		"--no-playlist",
+		"--skip-download",
+		"--newline",
+		"--restrict-filenames",
+		"-P", opts.OutputDir,
+		"-o", outputTemplate,
+		"--write-subs",
+		"--sub-langs", "live_chat",
 	}
-	args, err := appendJSRuntimeArgs(args, opts.JSRuntime)
+	args, err := appendAccessArgs(args, opts)
 	if err != nil {
 		return DownloadResult{}, err
 	}
@@ -259,30 +285,24 @@ func DownloadSubtitles(opts DownloadOptions) (DownloadResult, error) {
 	return DownloadResult{Command: append([]string{"yt-dlp"}, args...)}, nil
 }
 
-func selectFormat(rawQuality string, fragmented bool) string {
-	quality := strings.ToLower(strings.TrimSpace(rawQuality))
-	switch quality {
-	case "", "best":
-		if fragmented {
-			return "bv*[protocol*=m3u8]+ba[protocol*=m3u8]/b[protocol*=m3u8]"
-		}
-		return "bv*+ba/b"
-	case "1080p", "1080", "hd":
-		if fragmented {
-			return "bv*[protocol*=m3u8][height<=1080]+ba[protocol*=m3u8]/b[protocol*=m3u8][height<=1080]"
-		}
-		return "bv*[height<=1080]+ba/b[height<=1080]"
-	case "720p", "720", "sd", "small":
-		if fragmented {
-			return "bv*[protocol*=m3u8][height<=720]+ba[protocol*=m3u8]/b[protocol*=m3u8][height<=720]"
-		}
-		return "bv*[height<=720]+ba/b[height<=720]"
-	default:
-		if fragmented {
-			return "bv*[protocol*=m3u8]+ba[protocol*=m3u8]/b[protocol*=m3u8]"
+func appendAccessArgs(args []string, opts DownloadOptions) ([]string, error) {
+	if strings.TrimSpace(opts.CookiesPath) != "" {
+		cookiesPath, err := resolveCookiesPath(opts.CookiesPath)
+		if err != nil {
+			return nil, err
 		}
-		return "bv*+ba/b"
+		args = append(args, "--cookies", cookiesPath)
+	}
+	if strings.TrimSpace(opts.CookiesFromBrowser) != "" {
+		args = append(args, "--cookies-from-browser", opts.CookiesFromBrowser)
+	}
+	if opts.DownloadLimitMBps > 0 {
+		args = append(args, "--limit-rate", formatRateLimitMBps(opts.DownloadLimitMBps))
+	}
+	if strings.TrimSpace(opts.ProxyURL) != "" {
+		args = append(args, "--proxy", strings.TrimSpace(opts.ProxyURL))
 	}
+	return appendJSRuntimeArgs(args, opts.JSRuntime)
 }
 
 func normalizeSubLangs(raw string) string {
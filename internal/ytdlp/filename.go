@@ -0,0 +1,92 @@
+package ytdlp
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// outputTitleMaxBytes matches the .200B width of the title in outputTemplate.
+const outputTitleMaxBytes = 200
+
+var (
+	timestampPattern = regexp.MustCompile(`[0-9]+(?::[0-9]+)+`)
+
+	accentChars = func() map[rune]string {
+		from := []rune("ÂÃÄÀÁÅÆÇÈÉÊËÌÍÎÏÐÑÒÓÔÕÖŐØŒÙÚÛÜŰÝÞßàáâãäåæçèéêëìíîïðñòóôõöőøœùúûüűýþÿ")
+		to := []string{
+			"A", "A", "A", "A", "A", "A", "AE", "C", "E", "E", "E", "E", "I", "I", "I", "I", "D", "N",
+			"O", "O", "O", "O", "O", "O", "O", "OE", "U", "U", "U", "U", "U", "Y", "TH", "ss",
+			"a", "a", "a", "a", "a", "a", "ae", "c", "e", "e", "e", "e", "i", "i", "i", "i", "o", "n",
+			"o", "o", "o", "o", "o", "o", "o", "oe", "u", "u", "u", "u", "u", "y", "th", "y",
+		}
+		m := make(map[rune]string, len(from))
+		for i, r := range from {
+			m[r] = to[i]
+		}
+		return m
+	}()
+)
+
+// OutputStem is the file name outputTemplate produces for a video, without
+// the extension: "<upload_date>_<title>_[<id>]".
+func OutputStem(uploadDate, title, videoID string) string {
+	return uploadDate + "_" + RestrictedTitle(title) + "_[" + videoID + "]"
+}
+
+// RestrictedTitle renders a title the way %(title).200B does under
+// --restrict-filenames (yt-dlp's sanitize_filename with restricted=True).
+func RestrictedTitle(title string) string {
+	s := truncateUTF8(title, outputTitleMaxBytes)
+	s = timestampPattern.ReplaceAllStringFunc(s, func(m string) string {
+		return strings.ReplaceAll(m, ":", "_")
+	})
+
+	var b strings.Builder
+	for _, r := range s {
+		if v, ok := accentChars[r]; ok {
+			b.WriteString(v)
+			continue
+		}
+		switch {
+		case r == '?' || r < 32 || r == 127 || r == '"':
+		case r == ':':
+			b.WriteString("_-")
+		case strings.ContainsRune(`\/|*<>`, r):
+			b.WriteByte('_')
+		case strings.ContainsRune("!&'()[]{}$;`^,#", r) || unicode.IsSpace(r) || r > 127:
+			if !unicode.In(r, unicode.C, unicode.M) {
+				b.WriteByte('_')
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	out := b.String()
+	for strings.Contains(out, "__") {
+		out = strings.ReplaceAll(out, "__", "_")
+	}
+	out = strings.Trim(out, "_")
+	out = strings.TrimPrefix(out, "-_")
+	if strings.HasPrefix(out, "-") {
+		out = "_" + out[1:]
+	}
+	out = strings.TrimLeft(out, ".")
+	if out == "" {
+		return "_"
+	}
+	return out
+}
+
+func truncateUTF8(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	s = s[:max]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
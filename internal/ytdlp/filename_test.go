@@ -0,0 +1,35 @@
+package ytdlp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRestrictedTitleMatchesYTDLPRestrictFilenames(t *testing.T) {
+	cases := map[string]string{
+		"Go Generics: A Deep Dive":     "Go_Generics_-_A_Deep_Dive",
+		"What's new? (Part 2)":         "What_s_new_Part_2",
+		"Café Crème Brûlée":            "Cafe_Creme_Brulee",
+		"Live at 12:30:05 / Recap":     "Live_at_12_30_05_Recap",
+		"  __Spaces & Symbols!!__  ":   "Spaces_Symbols",
+		"日本語タイトル":                      "_",
+		"-leading dash":                "_leading_dash",
+		`quote "marks" and <brackets>`: "quote_marks_and_brackets",
+		"...hidden":                    "hidden",
+	}
+	for in, want := range cases {
+		if got := RestrictedTitle(in); got != want {
+			t.Errorf("RestrictedTitle(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRestrictedTitleTruncatesTo200Bytes(t *testing.T) {
+	got := RestrictedTitle(strings.Repeat("a", 250))
+	if len(got) != 200 {
+		t.Fatalf("expected 200 bytes, got %d", len(got))
+	}
+	if stem := OutputStem("20240101", "Hello World", "abc123xyz00"); stem != "20240101_Hello_World_[abc123xyz00]" {
+		t.Fatalf("unexpected stem %q", stem)
+	}
+}
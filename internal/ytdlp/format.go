@@ -0,0 +1,190 @@
+package ytdlp
+
+import (
+	"fmt"
+	"strings"
+)
+
+var qualityHeights = map[string]int{
+	"best":  0,
+	"2160p": 2160,
+	"1440p": 1440,
+	"1080p": 1080,
+	"720p":  720,
+	"480p":  480,
+}
+
+var codecFilters = map[string]string{
+	"av1":  "[vcodec^=av01]",
+	"vp9":  "[vcodec~='^vp0?9']",
+	"h264": "[vcodec^=avc1]",
+}
+
+// CheckFormatOptions validates quality/codec/HDR/container values before any download starts.
+func CheckFormatOptions(quality, videoCodec, hdr, container string) error {
+	if _, ok := NormalizeQuality(quality); !ok {
+		return fmt.Errorf("invalid quality %q (expected best, 2160p, 1440p, 1080p, 720p, or 480p)", strings.TrimSpace(quality))
+	}
+	if _, ok := normalizeVideoCodecs(videoCodec); !ok {
+		return fmt.Errorf("invalid video codec %q (expected auto or an ordered list of: av1, vp9, h264)", strings.TrimSpace(videoCodec))
+	}
+	if _, ok := normalizeHDR(hdr); !ok {
+		return fmt.Errorf("invalid hdr mode %q (expected auto, on, or off)", strings.TrimSpace(hdr))
+	}
+	if _, ok := normalizeContainer(container); !ok {
+		return fmt.Errorf("invalid container %q (expected auto, mp4, or mkv)", strings.TrimSpace(container))
+	}
+	return nil
+}
+
+func NormalizeQuality(raw string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "best":
+		return "best", true
+	case "2160p", "2160", "4k", "uhd":
+		return "2160p", true
+	case "1440p", "1440", "2k", "qhd":
+		return "1440p", true
+	case "1080p", "1080", "hd":
+		return "1080p", true
+	case "720p", "720", "sd", "small":
+		return "720p", true
+	case "480p", "480":
+		return "480p", true
+	default:
+		return "", false
+	}
+}
+
+// QualityHeight returns the height cap of a quality preset; 0 means uncapped.
+func QualityHeight(raw string) int {
+	quality, ok := NormalizeQuality(raw)
+	if !ok {
+		return 0
+	}
+	return qualityHeights[quality]
+}
+
+func normalizeVideoCodecs(raw string) ([]string, bool) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	if s == "" || s == "auto" {
+		return nil, true
+	}
+	out := make([]string, 0, 3)
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ",") {
+		v := strings.TrimSpace(part)
+		switch v {
+		case "":
+			continue
+		case "av01":
+			v = "av1"
+		case "vp09":
+			v = "vp9"
+		case "avc", "avc1", "h.264":
+			v = "h264"
+		}
+		if _, ok := codecFilters[v]; !ok {
+			return nil, false
+		}
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out, true
+}
+
+func normalizeHDR(raw string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "auto":
+		return "auto", true
+	case "on", "yes", "true":
+		return "on", true
+	case "off", "no", "false":
+		return "off", true
+	default:
+		return "", false
+	}
+}
+
+func normalizeContainer(raw string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "auto":
+		return "", true
+	case "mp4":
+		return "mp4", true
+	case "mkv":
+		return "mkv", true
+	default:
+		return "", false
+	}
+}
+
+// selectFormat builds a -f selector chain: preferred codec/HDR variants first,
+// then the plain height-capped fallback.
+func selectFormat(rawQuality, rawCodec, rawHDR string, fragmented bool) (string, error) {
+	if err := CheckFormatOptions(rawQuality, rawCodec, rawHDR, ""); err != nil {
+		return "", err
+	}
+	codecs, _ := normalizeVideoCodecs(rawCodec)
+	hdr, _ := normalizeHDR(rawHDR)
+
+	base := ""
+	audio := "ba"
+	if fragmented {
+		base = "[protocol*=m3u8]"
+		audio = "ba[protocol*=m3u8]"
+	}
+	if height := QualityHeight(rawQuality); height > 0 {
+		base += fmt.Sprintf("[height<=%d]", height)
+	}
+
+	dynamicRanges := []string{""}
+	switch hdr {
+	case "on":
+		dynamicRanges = []string{"[dynamic_range!=SDR]", ""}
+	case "off":
+		dynamicRanges = []string{"[dynamic_range=?SDR]"}
+	}
+
+	selectors := make([]string, 0, len(dynamicRanges)*(len(codecs)+1)+1)
+	for _, dr := range dynamicRanges {
+		for _, codec := range codecs {
+			selectors = append(selectors, "bv*"+base+dr+codecFilters[codec]+"+"+audio)
+		}
+		selectors = append(selectors, "bv*"+base+dr+"+"+audio)
+	}
+	selectors = append(selectors, "b"+base+dynamicRanges[len(dynamicRanges)-1])
+	return strings.Join(selectors, "/"), nil
+}
+
+// appendFormatArgs adds the -f selector and container handling. Audio-only
+// downloads take the best audio stream (or the best combined format when
+// there is none) and extract it with -x, so the video settings do not apply.
+func appendFormatArgs(args []string, opts DownloadOptions, fragmented bool) ([]string, error) {
+	if opts.AudioOnly {
+		format := "ba/b"
+		if fragmented {
+			format = "ba[protocol*=m3u8]/b[protocol*=m3u8]"
+		}
+		return append(args, "-f", format, "-x"), nil
+	}
+	format, err := selectFormat(opts.Quality, opts.VideoCodec, opts.HDR, fragmented)
+	if err != nil {
+		return nil, err
+	}
+	return appendContainerArgs(append(args, "-f", format), opts.Container)
+}
+
+func appendContainerArgs(args []string, rawContainer string) ([]string, error) {
+	container, ok := normalizeContainer(rawContainer)
+	if !ok {
+		return nil, fmt.Errorf("invalid container %q (expected auto, mp4, or mkv)", strings.TrimSpace(rawContainer))
+	}
+	if container == "" {
+		return args, nil
+	}
+	return append(args, "--merge-output-format", container, "--remux-video", container), nil
+}
@@ -0,0 +1,103 @@
+package ytdlp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelectFormatKeepsLegacyPresets(t *testing.T) {
+	cases := []struct {
+		quality    string
+		fragmented bool
+		want       string
+	}{
+		{"best", false, "bv*+ba/b"},
+		{"", true, "bv*[protocol*=m3u8]+ba[protocol*=m3u8]/b[protocol*=m3u8]"},
+		{"1080p", false, "bv*[height<=1080]+ba/b[height<=1080]"},
+		{"hd", true, "bv*[protocol*=m3u8][height<=1080]+ba[protocol*=m3u8]/b[protocol*=m3u8][height<=1080]"},
+		{"720", false, "bv*[height<=720]+ba/b[height<=720]"},
+		{"4k", false, "bv*[height<=2160]+ba/b[height<=2160]"},
+		{"480p", false, "bv*[height<=480]+ba/b[height<=480]"},
+	}
+	for _, tc := range cases {
+		got, err := selectFormat(tc.quality, "", "", tc.fragmented)
+		if err != nil {
+			t.Fatalf("selectFormat(%q): %v", tc.quality, err)
+		}
+		if got != tc.want {
+			t.Fatalf("selectFormat(%q, fragmented=%t) = %q, want %q", tc.quality, tc.fragmented, got, tc.want)
+		}
+	}
+}
+
+func TestSelectFormatCodecAndHDRPreference(t *testing.T) {
+	got, err := selectFormat("1440p", "av1,h264", "on", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "bv*[height<=1440][dynamic_range!=SDR][vcodec^=av01]+ba/" +
+		"bv*[height<=1440][dynamic_range!=SDR][vcodec^=avc1]+ba/" +
+		"bv*[height<=1440][dynamic_range!=SDR]+ba/" +
+		"bv*[height<=1440][vcodec^=av01]+ba/" +
+		"bv*[height<=1440][vcodec^=avc1]+ba/" +
+		"bv*[height<=1440]+ba/" +
+		"b[height<=1440]"
+	if got != want {
+		t.Fatalf("unexpected selector:\n got %s\nwant %s", got, want)
+	}
+
+	got, err = selectFormat("best", "vp9", "off", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "bv*[dynamic_range=?SDR][vcodec~='^vp0?9']+ba/bv*[dynamic_range=?SDR]+ba/b[dynamic_range=?SDR]"
+	if got != want {
+		t.Fatalf("unexpected selector:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestCheckFormatOptionsRejectsUnknownValues(t *testing.T) {
+	if err := CheckFormatOptions("8k", "", "", ""); err == nil {
+		t.Fatal("expected invalid quality error")
+	}
+	if err := CheckFormatOptions("best", "hevc", "", ""); err == nil {
+		t.Fatal("expected invalid codec error")
+	}
+	if err := CheckFormatOptions("best", "", "maybe", ""); err == nil {
+		t.Fatal("expected invalid hdr error")
+	}
+	if err := CheckFormatOptions("best", "", "", "webm"); err == nil {
+		t.Fatal("expected invalid container error")
+	}
+}
+
+func TestAppendContainerArgsRemuxes(t *testing.T) {
+	args, err := appendContainerArgs(nil, "MKV")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(args) != 4 || args[1] != "mkv" || args[2] != "--remux-video" || args[3] != "mkv" {
+		t.Fatalf("unexpected container args: %#v", args)
+	}
+	args, err = appendContainerArgs(nil, "auto")
+	if err != nil || len(args) != 0 {
+		t.Fatalf("expected no args for auto container, got %#v (%v)", args, err)
+	}
+}
+
+func TestAppendFormatArgsAudioOnly(t *testing.T) {
+	args, err := appendFormatArgs(nil, DownloadOptions{AudioOnly: true, Quality: "720p", Container: "mkv"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(args, " ") != "-f ba/b -x" {
+		t.Fatalf("audio-only must ignore video settings, got %#v", args)
+	}
+	args, err = appendFormatArgs(nil, DownloadOptions{Quality: "720p", Container: "mkv"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[0] != "-f" || !strings.Contains(args[1], "[height<=720]") || args[len(args)-1] != "mkv" {
+		t.Fatalf("unexpected video format args: %#v", args)
+	}
+}
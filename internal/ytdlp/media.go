@@ -0,0 +1,129 @@
+package ytdlp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const mediaInfoTemplate = "%(format_id)s\t%(height)s\t%(vcodec)s\t%(filepath)s"
+
+type MediaInfo struct {
+	FormatID   string `json:"format_id,omitempty"`
+	Height     int    `json:"height,omitempty"`
+	VideoCodec string `json:"video_codec,omitempty"`
+	Path       string `json:"path,omitempty"`
+}
+
+func (m MediaInfo) Known() bool {
+	return m.FormatID != "" || m.Height > 0
+}
+
+// ProbeFormat asks yt-dlp which format the current selector would pick, without downloading.
+func ProbeFormat(opts DownloadOptions) (MediaInfo, error) {
+	if strings.TrimSpace(opts.VideoURL) == "" {
+		return MediaInfo{}, fmt.Errorf("video URL is required")
+	}
+	fragmented := strings.EqualFold(strings.TrimSpace(opts.DeliveryMode), "fragmented")
+	format, err := selectFormat(opts.Quality, opts.VideoCodec, opts.HDR, fragmented)
+	if err != nil {
+		return MediaInfo{}, err
+	}
+	args := []string{
+		"--no-playlist",
+		"--simulate",
+		"--print", "%(format_id)s\t%(height)s\t%(vcodec)s",
+		"-f", format,
+	}
+	args, err = appendAccessArgs(args, opts)
+	if err != nil {
+		return MediaInfo{}, err
+	}
+	args = append(args, opts.VideoURL)
+
+	cmd := exec.Command("yt-dlp", args...)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return MediaInfo{}, fmt.Errorf("yt-dlp failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	info, ok := parseMediaInfo(stdout.String())
+	if !ok {
+		return MediaInfo{}, fmt.Errorf("yt-dlp returned no format information")
+	}
+	return info, nil
+}
+
+// ProbeMediaFile reads the height and codec of the first video stream with ffprobe.
+func ProbeMediaFile(path string) (MediaInfo, error) {
+	cmd := exec.Command("ffprobe",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=codec_name,height",
+		"-of", "default=noprint_wrappers=1",
+		path,
+	)
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return MediaInfo{}, fmt.Errorf("ffprobe failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	info := MediaInfo{Path: path}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "codec_name":
+			info.VideoCodec = value
+		case "height":
+			info.Height, _ = strconv.Atoi(value)
+		}
+	}
+	if info.Height <= 0 {
+		return MediaInfo{}, fmt.Errorf("ffprobe found no video stream in %s", path)
+	}
+	return info, nil
+}
+
+func readMediaInfoFile(path string) MediaInfo {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return MediaInfo{}
+	}
+	info, _ := parseMediaInfo(string(data))
+	return info
+}
+
+func parseMediaInfo(raw string) (MediaInfo, bool) {
+	lines := strings.Split(strings.TrimSpace(raw), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		fields := strings.Split(strings.TrimSpace(lines[i]), "\t")
+		if len(fields) < 3 {
+			continue
+		}
+		info := MediaInfo{FormatID: fields[0]}
+		info.Height, _ = strconv.Atoi(fields[1])
+		if fields[2] != "NA" && fields[2] != "none" {
+			info.VideoCodec = fields[2]
+		}
+		if len(fields) > 3 {
+			info.Path = fields[3]
+		}
+		if info.FormatID == "NA" {
+			info.FormatID = ""
+		}
+		if info.Known() {
+			return info, true
+		}
+	}
+	return MediaInfo{}, false
+}
@@ -0,0 +1,17 @@
+package ytdlp
+
+import "testing"
+
+func TestParseMediaInfoUsesLastCompleteLine(t *testing.T) {
+	raw := "junk\n137+140\t1080\tavc1.640028\t/out/a_[abc123].mp4\n"
+	info, ok := parseMediaInfo(raw)
+	if !ok {
+		t.Fatal("expected media info")
+	}
+	if info.FormatID != "137+140" || info.Height != 1080 || info.VideoCodec != "avc1.640028" || info.Path != "/out/a_[abc123].mp4" {
+		t.Fatalf("unexpected media info: %+v", info)
+	}
+	if _, ok := parseMediaInfo("NA\tNA\tnone\n"); ok {
+		t.Fatal("expected unknown format line to be rejected")
+	}
+}
@@ -14,18 +14,23 @@ var allowed = map[string]map[string]bool{
 	"cli": {
 		"archive":   true,
 		"discovery": true,
+		"metrics":   true,
 	},
 	"discovery": {
 		"model":    true,
+		"objstore": true,
 		"runstore": true,
 		"ytdlp":    true,
 	},
 	"archive": {
 		"model":    true,
+		"objstore": true,
 		"runstore": true,
 		"ytdlp":    true,
 	},
+	"metrics":  {},
 	"model":    {},
+	"objstore": {},
 	"runstore": {},
 	"ytdlp":    {},
 }
@@ -1,15 +1,18 @@
 #!/usr/bin/env bash
 set -euo pipefail
 
-# Background-friendly sync runner.
+# Deprecated: kept so existing cron/systemd timers keep working. Call
+#   yt-vod-manager sync --all-projects --active-only --no-wait
+# directly instead; the workspace sync lock replaces the old lock directory.
 # Override paths with:
-#   YTVM_BIN, YTVM_CONFIG, YTVM_RUNS_DIR, YTVM_LOCKDIR
+#   YTVM_BIN, YTVM_CONFIG, YTVM_RUNS_DIR (YTVM_LOCKDIR is ignored)
 
 ROOT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)"
 BIN="${YTVM_BIN:-$ROOT_DIR/bin/yt-vod-manager}"
 CONFIG="${YTVM_CONFIG:-$ROOT_DIR/config/projects.json}"
 RUNS_DIR="${YTVM_RUNS_DIR:-$ROOT_DIR/runs}"
-LOCKDIR="${YTVM_LOCKDIR:-$ROOT_DIR/.sync.lock}"
+
+echo "scripts/sync-active.sh is deprecated; use: yt-vod-manager sync --all-projects --active-only --no-wait" >&2
 
 if [[ ! -x "$BIN" ]]; then
   echo "yt-vod-manager binary not found or not executable: $BIN" >&2
@@ -17,20 +20,11 @@ if [[ ! -x "$BIN" ]]; then
   exit 1
 fi
 
-if ! mkdir "$LOCKDIR" 2>/dev/null; then
-  echo "sync already running; skip"
-  exit 0
-fi
-
-cleanup() {
-  rmdir "$LOCKDIR" >/dev/null 2>&1 || true
-}
-trap cleanup EXIT INT TERM
-
 cd "$ROOT_DIR"
-"$BIN" sync \
+exec "$BIN" sync \
   --all-projects \
   --active-only \
+  --no-wait \
   --config "$CONFIG" \
   --runs-dir "$RUNS_DIR" \
   --progress=false \